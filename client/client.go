@@ -0,0 +1,431 @@
+// Package client is a Go client for the estate API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 2 * time.Second
+)
+
+type (
+	Client struct {
+		baseURL        string
+		httpClient     *http.Client
+		maxRetries     int
+		minBackoff     time.Duration
+		maxBackoff     time.Duration
+		organisationId string
+		apiKey         string
+		bearerToken    string
+	}
+
+	Option func(*Client)
+
+	envelope struct {
+		Code      int             `json:"code"`
+		Message   string          `json:"message"`
+		Data      json.RawMessage `json:"data"`
+		Errors    json.RawMessage `json:"errors"`
+		ErrorCode string          `json:"errorCode"`
+	}
+)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a request failing with a 5xx status or a
+// transport error is retried, and the bounds of the exponential backoff
+// between attempts. Only requests that are safe to repeat are retried.
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithOrganisation acts for an organisation other than the one the
+// credentials belong to. Only administrators may do so.
+func WithOrganisation(organisationId string) Option {
+	return func(c *Client) {
+		c.organisationId = organisationId
+	}
+}
+
+// WithAPIKey authenticates every request with an API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithBearerToken authenticates every request with a JWT. It is ignored
+// when an API key is set too.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.bearerToken = token
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		maxRetries: DefaultMaxRetries,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) CreateEstate(ctx context.Context, param *domain.Estate) (*domain.CreateEstateResponse, error) {
+	resp := &domain.CreateEstateResponse{}
+	err := c.do(ctx, http.MethodPost, "/estate", idempotencyKey(), param, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PutEstate creates the estate under id, or replaces the one already
+// there. The response reports which of the two happened.
+func (c *Client) PutEstate(ctx context.Context, id string, param *domain.Estate) (*domain.PutEstateResponse, error) {
+	resp := &domain.PutEstateResponse{}
+	err := c.do(ctx, http.MethodPut, "/estate/"+url.PathEscape(id), nil, param, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) FindEstateByExternalRef(ctx context.Context, ref string) (*domain.Estate, error) {
+	resp := &domain.Estate{}
+	err := c.do(ctx, http.MethodGet, "/estate?externalRef="+url.QueryEscape(ref), nil, nil, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) PlantPalmTree(ctx context.Context, id string, param *domain.PalmTree) (*domain.PlantPalmTreeResponse, error) {
+	resp := &domain.PlantPalmTreeResponse{}
+	err := c.do(ctx, http.MethodPost, "/estate/"+url.PathEscape(id)+"/tree", idempotencyKey(), param, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) GetTreeStats(ctx context.Context, id string) (*domain.GetTreeStatsResponse, error) {
+	resp := &domain.GetTreeStatsResponse{}
+	err := c.do(ctx, http.MethodGet, "/estate/"+url.PathEscape(id)+"/stats", nil, nil, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetDronePlan returns the drone flying distance over the estate. A zero
+// maxDistance means the drone is not limited.
+func (c *Client) GetDronePlan(ctx context.Context, id string, maxDistance int) (*domain.GetDroneFlyingDistanceResponse, error) {
+	path := "/estate/" + url.PathEscape(id) + "/drone-plan"
+	if maxDistance > 0 {
+		path += "?max-distance=" + strconv.Itoa(maxDistance)
+	}
+
+	resp := &domain.GetDroneFlyingDistanceResponse{}
+	err := c.do(ctx, http.MethodGet, path, nil, nil, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ImportPalmTrees plants many trees in one request. mode is one of the
+// domain.ImportMode values; empty means domain.ImportModeAtomic. An atomic
+// import with invalid rows fails with an *APIError carrying the report.
+// Like PlantPalmTree it sends an Idempotency-Key, so a retry after a lost
+// response does not report the trees it planted as filled.
+func (c *Client) ImportPalmTrees(ctx context.Context, id string, trees []domain.PalmTree, mode string) (*domain.ImportPalmTreesResponse, error) {
+	path := "/estate/" + url.PathEscape(id) + "/trees:import"
+	if mode != "" {
+		path += "?mode=" + url.QueryEscape(mode)
+	}
+
+	resp := &domain.ImportPalmTreesResponse{}
+	err := c.do(ctx, http.MethodPost, path, idempotencyKey(), trees, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Backup streams a backup archive of every estate to w. Streams are not
+// retried, since part of the archive may already have been written.
+func (c *Client) Backup(ctx context.Context, w io.Writer) error {
+	res, err := c.send(ctx, http.MethodGet, "/admin/backup", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		_, err = decode(res, nil)
+		return err
+	}
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
+// Restore uploads a backup archive read from r. conflict is one of the
+// domain.RestoreConflict values; empty means domain.RestoreConflictFail. A
+// restore stopped by a conflict fails with an *APIError carrying the report
+// so far.
+func (c *Client) Restore(ctx context.Context, r io.Reader, conflict string) (*domain.RestoreEstatesResponse, error) {
+	path := "/admin/restore"
+	if conflict != "" {
+		path += "?conflict=" + url.QueryEscape(conflict)
+	}
+
+	res, err := c.send(ctx, http.MethodPost, path, http.Header{common.UtContentType: {common.ContentTypeNDJSON}}, r)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resp := &domain.RestoreEstatesResponse{}
+	_, err = decode(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CreateApiKey is sent once and never retried: a retry could issue a second
+// key, and responses carrying keys are deliberately not stored for replay.
+// An empty organisationId issues a key of the default organisation.
+func (c *Client) CreateApiKey(ctx context.Context, name, organisationId string) (*domain.CreateApiKeyResponse, error) {
+	payload, err := json.Marshal(&domain.CreateApiKeyRequest{Name: name, OrganisationId: organisationId})
+	if err != nil {
+		return nil, err
+	}
+	resp := &domain.CreateApiKeyResponse{}
+	_, err = c.attempt(ctx, http.MethodPost, "/admin/api-keys", nil, payload, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) ListApiKeys(ctx context.Context) ([]domain.ApiKey, error) {
+	resp := []domain.ApiKey{}
+	err := c.do(ctx, http.MethodGet, "/admin/api-keys", nil, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) RevokeApiKey(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/admin/api-keys/"+url.PathEscape(id), nil, nil, nil)
+}
+
+func (c *Client) ListOrganisations(ctx context.Context) ([]domain.Organisation, error) {
+	resp := []domain.Organisation{}
+	err := c.do(ctx, http.MethodGet, "/admin/organisations", nil, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PutOrganisation creates the organisation or replaces its settings. The
+// Id and CreatedAt of param are ignored.
+func (c *Client) PutOrganisation(ctx context.Context, id string, param *domain.Organisation) (*domain.PutOrganisationResponse, error) {
+	resp := &domain.PutOrganisationResponse{}
+	err := c.do(ctx, http.MethodPut, "/admin/organisations/"+url.PathEscape(id), nil, param, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) ListEstatePermissions(ctx context.Context, id string) ([]domain.EstatePermission, error) {
+	resp := &domain.ListEstatePermissionsResponse{}
+	err := c.do(ctx, http.MethodGet, "/estate/"+url.PathEscape(id)+"/permissions", nil, nil, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Permissions, nil
+}
+
+// GrantEstatePermission gives subject the role on the estate, replacing any
+// role it held. Granting is idempotent, so it is retried like a read.
+func (c *Client) GrantEstatePermission(ctx context.Context, id, subject, role string) (*domain.GrantEstatePermissionResponse, error) {
+	resp := &domain.GrantEstatePermissionResponse{}
+	path := "/estate/" + url.PathEscape(id) + "/permissions/" + url.PathEscape(subject)
+	err := c.do(ctx, http.MethodPut, path, nil, &domain.GrantEstatePermissionRequest{Role: role}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) RevokeEstatePermission(ctx context.Context, id, subject string) error {
+	path := "/estate/" + url.PathEscape(id) + "/permissions/" + url.PathEscape(subject)
+	return c.do(ctx, http.MethodDelete, path, nil, nil, nil)
+}
+
+// do sends the request, retrying it after a transport error or a 5xx
+// status when retryable allows.
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = c.attempt(ctx, method, path, header, payload, result)
+		if !retry || attempt >= c.maxRetries || !retryable(method, header) {
+			return err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends the request once and reports whether it may be retried.
+func (c *Client) attempt(ctx context.Context, method, path string, header http.Header, payload []byte, result interface{}) (bool, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+		header = header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set(common.UtContentType, common.ContentTypeJson)
+	}
+	res, err := c.send(ctx, method, path, header, body)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer res.Body.Close()
+	return decode(res, result)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, header http.Header, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", common.ContentTypeJson)
+	if c.organisationId != "" {
+		req.Header.Set(common.HeaderOrganisationId, c.organisationId)
+	}
+	switch {
+	case c.apiKey != "":
+		req.Header.Set(common.HeaderApiKey, c.apiKey)
+	case c.bearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+	return c.httpClient.Do(req)
+}
+
+// retryable reports whether a failed request may be sent again. Reads and
+// PUTs leave the server in the same state however often they run, and a
+// request carrying an Idempotency-Key is answered from the stored response.
+// A bare POST or DELETE is not retried: the first attempt may have gone
+// through, and a retried DELETE would then report the deletion as a 404.
+func retryable(method string, header http.Header) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut:
+		return true
+	}
+	return header.Get(common.HeaderIdempotencyKey) != ""
+}
+
+// idempotencyKey gives a request creating something a key of its own, so
+// the server runs it once however often it is retried.
+func idempotencyKey() http.Header {
+	return http.Header{common.HeaderIdempotencyKey: {uuid.NewString()}}
+}
+
+// decode unwraps the response envelope into result and reports whether the
+// request may be retried.
+func decode(res *http.Response, result interface{}) (bool, error) {
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return true, err
+	}
+
+	if res.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+
+	env := envelope{}
+	decodeErr := json.Unmarshal(raw, &env)
+	if res.StatusCode >= http.StatusBadRequest {
+		return res.StatusCode >= http.StatusInternalServerError, newAPIError(res.StatusCode, env, raw, decodeErr)
+	}
+	if decodeErr != nil {
+		return false, fmt.Errorf("client: decode response: %w", decodeErr)
+	}
+
+	if result == nil || len(env.Data) == 0 {
+		return false, nil
+	}
+	err = json.Unmarshal(env.Data, result)
+	if err != nil {
+		return false, fmt.Errorf("client: decode response data: %w", err)
+	}
+	return false, nil
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.minBackoff << attempt
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	// Equal jitter keeps retrying clients from hammering the server in step
+	// while still waiting at least half the backoff.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
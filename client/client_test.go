@@ -0,0 +1,952 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/generated"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	estatehttp "github.com/davidyunus/sawitpro-estate/src/estate/delivery/http"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/davidyunus/sawitpro-estate/src/middleware"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newServer(t *testing.T, estateUsecase domain.EstateUsecase) *httptest.Server {
+	spec, err := generated.GetSwagger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := middleware.OpenAPIValidator(spec, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.Logger.SetOutput(io.Discard)
+	e.HTTPErrorHandler = helper.HTTPErrorHandler
+	e.Validator = helper.NewValidator()
+	e.Use(middleware.Anonymous())
+	e.Use(middleware.Tenant(organisationUsecase(t)))
+	e.Use(validator)
+	estatehttp.NewEstateHandler(e, estateUsecase, nil, nil, nil, nil)
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// organisationUsecase resolves every request to the organisation it asks
+// for, or to the default one.
+func organisationUsecase(t *testing.T) domain.OrganisationUsecase {
+	organisationMock := mock_domain.NewMockOrganisationUsecase(gomock.NewController(t))
+	organisationMock.EXPECT().ResolveTenant(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *domain.Principal, requested string) (*domain.Organisation, error) {
+			if requested == "" {
+				requested = domain.DefaultOrganisationId
+			}
+			return &domain.Organisation{Id: requested}, nil
+		}).AnyTimes()
+	return organisationMock
+}
+
+func TestCreateEstate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0), WithOrganisation("org"))
+
+	tests := []struct {
+		name       string
+		param      *domain.Estate
+		wantResult *domain.CreateEstateResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:  "success",
+			param: &domain.Estate{Length: 6, Width: 3},
+			wantResult: &domain.CreateEstateResponse{
+				Id: common.UtUuid,
+			},
+			mock: func() {
+				estateMock.EXPECT().CreateEstate(gomock.Any(), &domain.Estate{Length: 6, Width: 3}).
+					DoAndReturn(func(ctx context.Context, param *domain.Estate) (*domain.CreateEstateResponse, error) {
+						assert.Equal(t, "org", domain.TenantId(ctx))
+						return &domain.CreateEstateResponse{Id: common.UtUuid}, nil
+					})
+			},
+		},
+		{
+			name:    "error size limit",
+			param:   &domain.Estate{Length: 600, Width: 3},
+			wantErr: domain.ErrMaxSizeEstate,
+			mock: func() {
+				estateMock.EXPECT().CreateEstate(gomock.Any(), &domain.Estate{Length: 600, Width: 3}).
+					Return(nil, &domain.SizeLimitError{Limit: domain.SizeLimitArea, Max: 50000, Actual: 18000000})
+			},
+		},
+		{
+			name:    "error invalid input",
+			param:   &domain.Estate{Length: 0, Width: 3},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := c.CreateEstate(context.Background(), test.param)
+			assert.True(t, errors.Is(err, test.wantErr), err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	estateMock.EXPECT().CreateEstate(gomock.Any(), gomock.Any()).
+		Return(nil, &domain.SizeLimitError{Limit: domain.SizeLimitArea, Max: 50000, Actual: 18000000})
+	_, err := c.CreateEstate(context.Background(), &domain.Estate{Length: 600, Width: 3})
+
+	var apiErr *APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, &domain.SizeLimitError{Limit: domain.SizeLimitArea, Max: 50000, Actual: 18000000}, apiErr.SizeLimit())
+	}
+
+	_, err = c.PlantPalmTree(context.Background(), common.UtUuid, &domain.PalmTree{X: 1, Y: 1, Height: 40})
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, []domain.FieldError{
+			{Field: "height", Rule: "maximum", Param: "30", Message: "number must be at most 30"},
+		}, apiErr.FieldErrors())
+	}
+}
+
+func TestPlantPalmTree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	tests := []struct {
+		name       string
+		param      *domain.PalmTree
+		wantResult *domain.PlantPalmTreeResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:  "success",
+			param: &domain.PalmTree{X: 3, Y: 1, Height: 10},
+			wantResult: &domain.PlantPalmTreeResponse{
+				Id: common.UtUuid,
+			},
+			mock: func() {
+				estateMock.EXPECT().PlantPalmTree(gomock.Any(), common.UtUuid, &domain.PalmTree{X: 3, Y: 1, Height: 10}).
+					Return(&domain.PlantPalmTreeResponse{Id: common.UtUuid}, nil)
+			},
+		},
+		{
+			name:    "error location filled",
+			param:   &domain.PalmTree{X: 3, Y: 1, Height: 10},
+			wantErr: domain.ErrLocationFilled,
+			mock: func() {
+				estateMock.EXPECT().PlantPalmTree(gomock.Any(), common.UtUuid, &domain.PalmTree{X: 3, Y: 1, Height: 10}).
+					Return(nil, domain.ErrLocationFilled)
+			},
+		},
+		{
+			name:    "error out of bounds",
+			param:   &domain.PalmTree{X: 30, Y: 1, Height: 10},
+			wantErr: domain.ErrOutOfBounds,
+			mock: func() {
+				estateMock.EXPECT().PlantPalmTree(gomock.Any(), common.UtUuid, &domain.PalmTree{X: 30, Y: 1, Height: 10}).
+					Return(nil, domain.ErrOutOfBounds)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := c.PlantPalmTree(context.Background(), common.UtUuid, test.param)
+			assert.True(t, errors.Is(err, test.wantErr), err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestPutEstate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	tests := []struct {
+		name       string
+		id         string
+		wantResult *domain.PutEstateResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success created",
+			id:         common.UtUuidV4,
+			wantResult: &domain.PutEstateResponse{Id: common.UtUuidV4, Created: true},
+			mock: func() {
+				estateMock.EXPECT().PutEstate(gomock.Any(), common.UtUuidV4, &domain.Estate{Length: 6, Width: 3, ExternalRef: "ERP-1"}).
+					Return(&domain.PutEstateResponse{Id: common.UtUuidV4, Created: true}, nil)
+			},
+		},
+		{
+			name:       "success replaced",
+			id:         common.UtUuidV4,
+			wantResult: &domain.PutEstateResponse{Id: common.UtUuidV4},
+			mock: func() {
+				estateMock.EXPECT().PutEstate(gomock.Any(), common.UtUuidV4, &domain.Estate{Length: 6, Width: 3, ExternalRef: "ERP-1"}).
+					Return(&domain.PutEstateResponse{Id: common.UtUuidV4}, nil)
+			},
+		},
+		{
+			name:    "error invalid id",
+			id:      strings.ToUpper(common.UtUuidV4),
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:    "error external ref taken",
+			id:      common.UtUuidV4,
+			wantErr: domain.ErrExternalRefTaken,
+			mock: func() {
+				estateMock.EXPECT().PutEstate(gomock.Any(), common.UtUuidV4, gomock.Any()).
+					Return(nil, domain.ErrExternalRefTaken)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := c.PutEstate(context.Background(), test.id, &domain.Estate{Length: 6, Width: 3, ExternalRef: "ERP-1"})
+			assert.True(t, errors.Is(err, test.wantErr), err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestFindEstateByExternalRef(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	tests := []struct {
+		name       string
+		ref        string
+		wantResult *domain.Estate
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success",
+			ref:        "ERP 1/2",
+			wantResult: &domain.Estate{Uuid: common.UtUuid, Length: 6, Width: 3, ExternalRef: "ERP 1/2"},
+			mock: func() {
+				estateMock.EXPECT().GetEstateByExternalRef(gomock.Any(), "ERP 1/2").
+					Return(&domain.Estate{Uuid: common.UtUuid, Length: 6, Width: 3, ExternalRef: "ERP 1/2"}, nil)
+			},
+		},
+		{
+			name:    "error estate not found",
+			ref:     "ERP-2",
+			wantErr: domain.ErrEstateNotFound,
+			mock: func() {
+				estateMock.EXPECT().GetEstateByExternalRef(gomock.Any(), "ERP-2").Return(nil, domain.ErrEstateNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := c.FindEstateByExternalRef(context.Background(), test.ref)
+			assert.True(t, errors.Is(err, test.wantErr), err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestGetTreeStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	tests := []struct {
+		name       string
+		wantResult *domain.GetTreeStatsResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: &domain.GetTreeStatsResponse{
+				Count:  3,
+				Max:    30,
+				Min:    5,
+				Median: 15,
+			},
+			mock: func() {
+				estateMock.EXPECT().GetTreeStats(gomock.Any(), common.UtUuid).Return(&domain.GetTreeStatsResponse{
+					Count:  3,
+					Max:    30,
+					Min:    5,
+					Median: 15,
+				}, nil)
+			},
+		},
+		{
+			name:    "error estate not found",
+			wantErr: domain.ErrEstateNotFound,
+			mock: func() {
+				estateMock.EXPECT().GetTreeStats(gomock.Any(), common.UtUuid).Return(nil, domain.ErrEstateNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := c.GetTreeStats(context.Background(), common.UtUuid)
+			assert.True(t, errors.Is(err, test.wantErr), err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestGetDronePlan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	tests := []struct {
+		name        string
+		maxDistance int
+		wantResult  *domain.GetDroneFlyingDistanceResponse
+		wantErr     error
+		mock        func()
+	}{
+		{
+			name: "success",
+			wantResult: &domain.GetDroneFlyingDistanceResponse{
+				Distance: 242,
+			},
+			mock: func() {
+				estateMock.EXPECT().GetDroneFlyingDistance(gomock.Any(), common.UtUuid, 0).Return(&domain.GetDroneFlyingDistanceResponse{
+					Distance: 242,
+				}, nil)
+			},
+		},
+		{
+			name:        "success with max distance",
+			maxDistance: 100,
+			wantResult: &domain.GetDroneFlyingDistanceResponse{
+				Distance: 100,
+				Rest:     &domain.Rest{X: 4, Y: 2},
+			},
+			mock: func() {
+				estateMock.EXPECT().GetDroneFlyingDistance(gomock.Any(), common.UtUuid, 100).Return(&domain.GetDroneFlyingDistanceResponse{
+					Distance: 100,
+					Rest:     &domain.Rest{X: 4, Y: 2},
+				}, nil)
+			},
+		},
+		{
+			name:    "error estate not found",
+			wantErr: domain.ErrEstateNotFound,
+			mock: func() {
+				estateMock.EXPECT().GetDroneFlyingDistance(gomock.Any(), common.UtUuid, 0).Return(nil, domain.ErrEstateNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := c.GetDronePlan(context.Background(), common.UtUuid, test.maxDistance)
+			assert.True(t, errors.Is(err, test.wantErr), err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestImportPalmTrees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	trees := []domain.PalmTree{{X: 1, Y: 1, Height: 10}, {X: 9, Y: 1, Height: 5}}
+	report := &domain.ImportPalmTreesResponse{
+		Id:       common.UtUuid,
+		Mode:     domain.ImportModeBestEffort,
+		Total:    2,
+		Imported: 1,
+		Rejected: 1,
+		Errors: []domain.ImportRowError{
+			{Row: 2, X: 9, Y: 1, Code: "location_out_of_bounds", Message: "location is outside the estate"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		mode       string
+		wantResult *domain.ImportPalmTreesResponse
+		wantReport *domain.ImportPalmTreesResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success best effort",
+			mode:       domain.ImportModeBestEffort,
+			wantResult: report,
+			mock: func() {
+				estateMock.EXPECT().ImportPalmTrees(gomock.Any(), common.UtUuid, &domain.ImportPalmTreesRequest{
+					Mode:  domain.ImportModeBestEffort,
+					Trees: trees,
+				}).Return(report, nil)
+			},
+		},
+		{
+			name:       "error import rejected",
+			wantReport: report,
+			wantErr:    domain.ErrImportRejected,
+			mock: func() {
+				// The spec default fills in the omitted mode.
+				estateMock.EXPECT().ImportPalmTrees(gomock.Any(), common.UtUuid, &domain.ImportPalmTreesRequest{
+					Mode:  domain.ImportModeAtomic,
+					Trees: trees,
+				}).Return(nil, domain.ErrImportRejected.WithDetails(report))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := c.ImportPalmTrees(context.Background(), common.UtUuid, trees, test.mode)
+			assert.True(t, errors.Is(err, test.wantErr), err)
+			assert.Equal(t, test.wantResult, got)
+			if test.wantReport != nil {
+				apiErr := &APIError{}
+				assert.True(t, errors.As(err, &apiErr))
+				assert.Equal(t, test.wantReport, apiErr.ImportReport())
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		maxRetries   int
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{
+			name:         "success after retries",
+			failures:     2,
+			status:       http.StatusServiceUnavailable,
+			maxRetries:   3,
+			wantAttempts: 3,
+			wantErr:      false,
+		},
+		{
+			name:         "error retries exhausted",
+			failures:     5,
+			status:       http.StatusInternalServerError,
+			maxRetries:   2,
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "error client error not retried",
+			failures:     5,
+			status:       http.StatusNotFound,
+			maxRetries:   3,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) <= test.failures {
+					w.WriteHeader(test.status)
+					_, _ = w.Write([]byte(`{"code":` + http.StatusText(test.status) + `}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"code":200,"message":"Success get tree stats","data":{"count":1,"max":5,"min":5,"median":5},"errors":null}`))
+			}))
+			defer server.Close()
+
+			c := New(server.URL, WithRetries(test.maxRetries, time.Millisecond, 5*time.Millisecond))
+			_, err := c.GetTreeStats(context.Background(), common.UtUuid)
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantAttempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestRetryMethods(t *testing.T) {
+	tests := []struct {
+		name         string
+		call         func(c *Client) error
+		wantAttempts int32
+	}{
+		{
+			name: "put retried",
+			call: func(c *Client) error {
+				_, err := c.GrantEstatePermission(context.Background(), common.UtUuid, "jwt:alice", domain.RoleViewer)
+				return err
+			},
+			wantAttempts: 3,
+		},
+		{
+			name: "post with idempotency key retried",
+			call: func(c *Client) error {
+				_, err := c.PlantPalmTree(context.Background(), common.UtUuid, &domain.PalmTree{X: 1, Y: 1, Height: 10})
+				return err
+			},
+			wantAttempts: 3,
+		},
+		{
+			name: "delete not retried",
+			call: func(c *Client) error {
+				return c.RevokeEstatePermission(context.Background(), common.UtUuid, "jwt:alice")
+			},
+			wantAttempts: 1,
+		},
+		{
+			name: "delete api key not retried",
+			call: func(c *Client) error {
+				return c.RevokeApiKey(context.Background(), common.UtUuid)
+			},
+			wantAttempts: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer server.Close()
+
+			c := New(server.URL, WithRetries(2, time.Millisecond, time.Millisecond))
+			err := test.call(c)
+			assert.Error(t, err)
+			assert.Equal(t, test.wantAttempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestRetryIdempotencyKey(t *testing.T) {
+	keys := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(common.HeaderIdempotencyKey))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"code":201,"message":"Success create estate","data":{"id":"uuid"},"errors":null}`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithRetries(1, time.Millisecond, time.Millisecond))
+	_, err := c.CreateEstate(context.Background(), &domain.Estate{Length: 6, Width: 3})
+	assert.NoError(t, err)
+	_, err = c.CreateEstate(context.Background(), &domain.Estate{Length: 6, Width: 3})
+	assert.NoError(t, err)
+
+	// Retries of one call share its key; separate calls do not.
+	assert.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+	assert.NotEqual(t, keys[1], keys[2])
+}
+
+func TestImportPalmTreesIdempotencyKey(t *testing.T) {
+	keys := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(common.HeaderIdempotencyKey))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"code":200,"message":"Success import palm trees","data":{"id":"uuid","mode":"atomic","total":1,"imported":1,"rejected":0,"errors":[]},"errors":null}`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithRetries(1, time.Millisecond, time.Millisecond))
+	_, err := c.ImportPalmTrees(context.Background(), common.UtUuid, []domain.PalmTree{{X: 1, Y: 1, Height: 10}}, "")
+	assert.NoError(t, err)
+
+	assert.Len(t, keys, 2)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+}
+
+func TestRetryContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	c := New(server.URL, WithRetries(10, time.Second, time.Second))
+	_, err := c.GetTreeStats(ctx, common.UtUuid)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBackup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time {
+		return createdAt
+	}
+	defer func() {
+		helper.Now = tempNow
+	}()
+
+	tests := []struct {
+		name       string
+		wantResult string
+		wantStatus int
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: `{"format":"sawitpro-estate-backup","version":2,"createdAt":"2024-01-02T03:04:05Z",` +
+				`"organisation":{"id":"default","name":"","sizePolicy":{"maxArea":0,"maxLength":0,"maxWidth":0,"plotSize":0},"createdAt":"0001-01-01T00:00:00Z"}}
+{"uuid":"uuid","length":6,"width":3,"createdAt":"2024-01-02T03:04:05Z","trees":[]}
+`,
+			mock: func() {
+				estateMock.EXPECT().ExportEstates(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, fn func(domain.ExportEstate) error) error {
+						return fn(domain.ExportEstate{Uuid: common.UtUuid, Length: 6, Width: 3, CreatedAt: createdAt, Trees: []domain.ExportPalmTree{}})
+					})
+			},
+		},
+		{
+			name:       "error before streaming",
+			wantStatus: http.StatusInternalServerError,
+			mock: func() {
+				estateMock.EXPECT().ExportEstates(gomock.Any(), gomock.Any()).Return(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got := &strings.Builder{}
+			err := c.Backup(context.Background(), got)
+			assert.Equal(t, test.wantResult, got.String())
+			if test.wantStatus == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			apiErr := &APIError{}
+			assert.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, test.wantStatus, apiErr.StatusCode)
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	archive := `{"format":"sawitpro-estate-backup","version":1,"createdAt":"2024-01-02T03:04:05Z"}
+{"uuid":"uuid","length":6,"width":3,"createdAt":"2024-01-02T03:04:05Z","trees":[]}
+`
+	report := &domain.RestoreEstatesResponse{
+		Conflict: domain.RestoreConflictFail,
+		Total:    1,
+		Estates:  []domain.RestoreResult{{SourceUuid: common.UtUuid, Uuid: common.UtUuid, Status: domain.RestoreStatusConflict}},
+	}
+
+	tests := []struct {
+		name       string
+		conflict   string
+		wantResult *domain.RestoreEstatesResponse
+		wantReport *domain.RestoreEstatesResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:     "success skip",
+			conflict: domain.RestoreConflictSkip,
+			wantResult: &domain.RestoreEstatesResponse{
+				Conflict: domain.RestoreConflictSkip,
+				Total:    1,
+				Skipped:  1,
+				Estates:  []domain.RestoreResult{{SourceUuid: common.UtUuid, Uuid: common.UtUuid, Status: domain.RestoreStatusSkipped}},
+			},
+			mock: func() {
+				estateMock.EXPECT().RestoreEstates(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, param *domain.RestoreEstatesRequest) (*domain.RestoreEstatesResponse, error) {
+						estate, err := param.Estates.Next()
+						assert.NoError(t, err)
+						assert.Equal(t, common.UtUuid, estate.Uuid)
+						return &domain.RestoreEstatesResponse{
+							Conflict: param.Conflict,
+							Total:    1,
+							Skipped:  1,
+							Estates:  []domain.RestoreResult{{SourceUuid: estate.Uuid, Uuid: estate.Uuid, Status: domain.RestoreStatusSkipped}},
+						}, nil
+					})
+			},
+		},
+		{
+			name:       "error restore conflict",
+			wantReport: report,
+			wantErr:    domain.ErrRestoreConflict,
+			mock: func() {
+				estateMock.EXPECT().RestoreEstates(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, param *domain.RestoreEstatesRequest) (*domain.RestoreEstatesResponse, error) {
+						// The spec default fills in the omitted conflict mode.
+						assert.Equal(t, domain.RestoreConflictFail, param.Conflict)
+						return nil, domain.ErrRestoreConflict.WithDetails(report)
+					})
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := c.Restore(context.Background(), strings.NewReader(archive), test.conflict)
+			assert.True(t, errors.Is(err, test.wantErr), err)
+			assert.Equal(t, test.wantResult, got)
+			if test.wantReport != nil {
+				apiErr := &APIError{}
+				assert.True(t, errors.As(err, &apiErr))
+				assert.Equal(t, test.wantReport, apiErr.RestoreReport())
+			}
+		})
+	}
+}
+
+func newAuthServer(t *testing.T, authUsecase domain.AuthUsecase) *httptest.Server {
+	e := echo.New()
+	e.Logger.SetOutput(io.Discard)
+	e.HTTPErrorHandler = helper.HTTPErrorHandler
+	e.Validator = helper.NewValidator()
+	e.Use(middleware.Auth(authUsecase))
+	estatehttp.NewEstateHandler(e, nil, authUsecase, nil, nil, nil)
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestApiKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_domain.NewMockAuthUsecase(ctrl)
+	server := newAuthServer(t, authMock)
+	admin := &domain.Principal{Subject: "alice", Method: domain.AuthMethodJwt, Admin: true}
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	key := domain.ApiKey{Id: common.UtUuid, Name: "ci", Prefix: "spk_abcdefgh", CreatedBy: "alice", CreatedAt: createdAt}
+
+	c := New(server.URL, WithBearerToken("token"), WithRetries(3, 0, 0))
+
+	authMock.EXPECT().AuthenticateToken(gomock.Any(), "token").Return(admin, nil)
+	authMock.EXPECT().CreateApiKey(gomock.Any(), &domain.CreateApiKeyRequest{Name: "ci", OrganisationId: "acme"}).
+		Return(&domain.CreateApiKeyResponse{ApiKey: key, Key: "spk_abcdefghijk"}, nil)
+	created, err := c.CreateApiKey(context.Background(), "ci", "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.CreateApiKeyResponse{ApiKey: key, Key: "spk_abcdefghijk"}, created)
+
+	authMock.EXPECT().AuthenticateToken(gomock.Any(), "token").Return(admin, nil)
+	authMock.EXPECT().CreateApiKey(gomock.Any(), gomock.Any()).Return(nil, errors.New(common.UtSomeError))
+	_, err = c.CreateApiKey(context.Background(), "ci", "")
+	assert.Error(t, err, "creating a key is not retried")
+
+	authMock.EXPECT().AuthenticateToken(gomock.Any(), "token").Return(admin, nil)
+	authMock.EXPECT().ListApiKeys(gomock.Any()).Return([]domain.ApiKey{key}, nil)
+	keys, err := c.ListApiKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.ApiKey{key}, keys)
+
+	authMock.EXPECT().AuthenticateToken(gomock.Any(), "token").Return(admin, nil)
+	authMock.EXPECT().RevokeApiKey(gomock.Any(), common.UtUuid).Return(nil)
+	assert.NoError(t, c.RevokeApiKey(context.Background(), common.UtUuid))
+
+	authMock.EXPECT().AuthenticateToken(gomock.Any(), "token").Return(admin, nil)
+	authMock.EXPECT().RevokeApiKey(gomock.Any(), common.UtUuid).Return(domain.ErrApiKeyNotFound)
+	err = c.RevokeApiKey(context.Background(), common.UtUuid)
+	assert.True(t, errors.Is(err, domain.ErrApiKeyNotFound), err)
+}
+
+func TestAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_domain.NewMockAuthUsecase(ctrl)
+	server := newAuthServer(t, authMock)
+
+	authMock.EXPECT().AuthenticateApiKey(gomock.Any(), "spk_key").
+		Return(&domain.Principal{Subject: common.UtUuid, Method: domain.AuthMethodApiKey}, nil)
+	authMock.EXPECT().ListApiKeys(gomock.Any()).Return(nil, domain.ErrForbidden)
+	_, err := New(server.URL, WithAPIKey("spk_key"), WithBearerToken("token")).ListApiKeys(context.Background())
+	assert.True(t, errors.Is(err, domain.ErrForbidden), err)
+
+	_, err = New(server.URL, WithRetries(0, 0, 0)).ListApiKeys(context.Background())
+	assert.True(t, errors.Is(err, domain.ErrUnauthorized), err)
+}
+
+func newOrganisationServer(t *testing.T, organisationUsecase domain.OrganisationUsecase) *httptest.Server {
+	e := echo.New()
+	e.Logger.SetOutput(io.Discard)
+	e.HTTPErrorHandler = helper.HTTPErrorHandler
+	e.Validator = helper.NewValidator()
+	e.Use(middleware.Anonymous())
+	e.Use(middleware.Tenant(organisationUsecase))
+	estatehttp.NewEstateHandler(e, nil, nil, organisationUsecase, nil, nil)
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOrganisations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	organisationMock := mock_domain.NewMockOrganisationUsecase(ctrl)
+	server := newOrganisationServer(t, organisationMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	defaultOrganisation := &domain.Organisation{Id: domain.DefaultOrganisationId}
+	acme := domain.Organisation{
+		Id:         "acme",
+		Name:       "Acme",
+		Timezone:   "Asia/Makassar",
+		SizePolicy: domain.SizePolicy{MaxLength: 20},
+		CreatedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	param := &domain.Organisation{Name: "Acme", Timezone: "Asia/Makassar", SizePolicy: domain.SizePolicy{MaxLength: 20}}
+
+	organisationMock.EXPECT().ResolveTenant(gomock.Any(), gomock.Any(), "").Return(defaultOrganisation, nil)
+	organisationMock.EXPECT().PutOrganisation(gomock.Any(), "acme", param).
+		Return(&domain.PutOrganisationResponse{Organisation: acme, Created: true}, nil)
+	put, err := c.PutOrganisation(context.Background(), "acme", param)
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.PutOrganisationResponse{Organisation: acme, Created: true}, put)
+
+	organisationMock.EXPECT().ResolveTenant(gomock.Any(), gomock.Any(), "").Return(defaultOrganisation, nil)
+	organisationMock.EXPECT().ListOrganisations(gomock.Any()).Return([]domain.Organisation{acme}, nil)
+	organisations, err := c.ListOrganisations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Organisation{acme}, organisations)
+
+	organisationMock.EXPECT().ResolveTenant(gomock.Any(), gomock.Any(), "globex").Return(nil, domain.ErrOrganisationNotFound)
+	_, err = New(server.URL, WithRetries(0, 0, 0), WithOrganisation("globex")).ListOrganisations(context.Background())
+	assert.True(t, errors.Is(err, domain.ErrOrganisationNotFound), err)
+}
+
+func TestEstatePermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	server := newServer(t, estateMock)
+	c := New(server.URL, WithRetries(0, 0, 0))
+
+	permission := domain.EstatePermission{
+		EstateId:  common.UtUuid,
+		Subject:   "jwt:auth0|bob",
+		Role:      domain.RoleSurveyor,
+		GrantedBy: "jwt:alice",
+		GrantedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	estateMock.EXPECT().GrantEstatePermission(gomock.Any(), common.UtUuid, "jwt:auth0|bob", &domain.GrantEstatePermissionRequest{Role: domain.RoleSurveyor}).
+		Return(&domain.GrantEstatePermissionResponse{EstatePermission: permission, Created: true}, nil)
+	granted, err := c.GrantEstatePermission(context.Background(), common.UtUuid, "jwt:auth0|bob", domain.RoleSurveyor)
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.GrantEstatePermissionResponse{EstatePermission: permission, Created: true}, granted)
+
+	_, err = c.GrantEstatePermission(context.Background(), common.UtUuid, "jwt:auth0|bob", "owner")
+	assert.True(t, errors.Is(err, domain.ErrInvalidInput), err)
+
+	estateMock.EXPECT().ListEstatePermissions(gomock.Any(), common.UtUuid).
+		Return(&domain.ListEstatePermissionsResponse{Permissions: []domain.EstatePermission{permission}}, nil)
+	permissions, err := c.ListEstatePermissions(context.Background(), common.UtUuid)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.EstatePermission{permission}, permissions)
+
+	estateMock.EXPECT().RevokeEstatePermission(gomock.Any(), common.UtUuid, "jwt:auth0|bob").Return(nil)
+	assert.NoError(t, c.RevokeEstatePermission(context.Background(), common.UtUuid, "jwt:auth0|bob"))
+
+	estateMock.EXPECT().RevokeEstatePermission(gomock.Any(), common.UtUuid, "jwt:auth0|bob").Return(domain.ErrForbidden)
+	err = c.RevokeEstatePermission(context.Background(), common.UtUuid, "jwt:auth0|bob")
+	assert.True(t, errors.Is(err, domain.ErrForbidden), err)
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+)
+
+// APIError is returned for every non 2xx response. It matches the domain
+// error with the same code, so callers can write
+// errors.Is(err, domain.ErrEstateNotFound).
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	// Errors holds the raw errors member of the response, see FieldErrors,
+	// SizeLimit, ImportReport and RestoreReport for typed access.
+	Errors json.RawMessage
+}
+
+func newAPIError(status int, env envelope, raw []byte, decodeErr error) *APIError {
+	if decodeErr != nil || env.Message == "" {
+		return &APIError{
+			StatusCode: status,
+			Message:    fmt.Sprintf("%s: %s", http.StatusText(status), raw),
+		}
+	}
+	return &APIError{
+		StatusCode: status,
+		Code:       env.ErrorCode,
+		Message:    env.Message,
+		Errors:     env.Errors,
+	}
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("estate api: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("estate api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*domain.Error)
+	return ok && e.Code != "" && t.Code == e.Code
+}
+
+// FieldErrors returns the rejected fields of a validation error.
+func (e *APIError) FieldErrors() []domain.FieldError {
+	var details []domain.FieldError
+	if json.Unmarshal(e.Errors, &details) != nil {
+		return nil
+	}
+	return details
+}
+
+// SizeLimit returns the violated limit of a domain.ErrMaxSizeEstate error.
+func (e *APIError) SizeLimit() *domain.SizeLimitError {
+	if e.Code != domain.ErrMaxSizeEstate.Code {
+		return nil
+	}
+	limit := &domain.SizeLimitError{}
+	if json.Unmarshal(e.Errors, limit) != nil {
+		return nil
+	}
+	return limit
+}
+
+// ImportReport returns the per-row report of a domain.ErrImportRejected
+// error.
+func (e *APIError) ImportReport() *domain.ImportPalmTreesResponse {
+	if e.Code != domain.ErrImportRejected.Code {
+		return nil
+	}
+	report := &domain.ImportPalmTreesResponse{}
+	if json.Unmarshal(e.Errors, report) != nil {
+		return nil
+	}
+	return report
+}
+
+// RestoreReport returns the report of a restore stopped by a
+// domain.ErrRestoreConflict error. Its last estate is the conflicting one.
+func (e *APIError) RestoreReport() *domain.RestoreEstatesResponse {
+	if e.Code != domain.ErrRestoreConflict.Code {
+		return nil
+	}
+	report := &domain.RestoreEstatesResponse{}
+	if json.Unmarshal(e.Errors, report) != nil {
+		return nil
+	}
+	return report
+}
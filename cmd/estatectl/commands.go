@@ -0,0 +1,445 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/client"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/config"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	estatesql "github.com/davidyunus/sawitpro-estate/src/estate/repository/sql"
+)
+
+// importNamespace derives the id an estate is imported under from its
+// source id, so that running an import again finds the estates it created.
+var importNamespace = uuid.MustParse("6b0f3c1e-8d2a-4f5b-9c7e-2a1d4e6f8b90")
+
+type (
+	// exportFile is the document written by export and read by import.
+	exportFile struct {
+		Estates []domain.ExportEstate `json:"estates"`
+	}
+
+	importResult struct {
+		SourceUuid string `json:"sourceUuid"`
+		Uuid       string `json:"uuid"`
+		Trees      int    `json:"trees"`
+	}
+)
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+func createEstate(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("create-estate")
+	length := flags.Int("length", 0, "estate length in plots")
+	width := flags.Int("width", 0, "estate width in plots")
+	ref := flags.String("ref", "", "external reference code of the estate")
+	if flags.Parse(args) != nil || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	resp, err := a.client.CreateEstate(ctx, &domain.Estate{Length: *length, Width: *width, ExternalRef: *ref})
+	if err != nil {
+		return err
+	}
+	return a.print(resp, []string{"ID"}, [][]interface{}{{resp.Id}})
+}
+
+func plant(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("plant")
+	x := flags.Int("x", 0, "plot column")
+	y := flags.Int("y", 0, "plot row")
+	height := flags.Int("height", 0, "tree height in metres")
+	if flags.Parse(args) != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	resp, err := a.client.PlantPalmTree(ctx, flags.Arg(0), &domain.PalmTree{X: *x, Y: *y, Height: *height})
+	if err != nil {
+		return err
+	}
+	return a.print(resp, []string{"ESTATE"}, [][]interface{}{{resp.Id}})
+}
+
+func stats(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	resp, err := a.client.GetTreeStats(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.print(resp,
+		[]string{"COUNT", "MAX", "MIN", "MEDIAN"},
+		[][]interface{}{{resp.Count, resp.Max, resp.Min, resp.Median}},
+	)
+}
+
+func dronePlan(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("drone-plan")
+	maxDistance := flags.Int("max-distance", 0, "battery limit of the drone in metres")
+	if flags.Parse(args) != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	resp, err := a.client.GetDronePlan(ctx, flags.Arg(0), *maxDistance)
+	if err != nil {
+		return err
+	}
+	row := []interface{}{resp.Distance, "-", "-"}
+	if resp.Rest != nil {
+		row = []interface{}{resp.Distance, resp.Rest.X, resp.Rest.Y}
+	}
+	return a.print(resp, []string{"DISTANCE", "REST X", "REST Y"}, [][]interface{}{row})
+}
+
+// importEstates recreates every estate of an export document through the
+// API. Each estate is PUT under an id derived from the organisation and its
+// source id, then its trees are planted by one atomic import, so an estate
+// is never left half planted. Running the import again after a failure
+// fills the estates left empty and skips the ones already planted instead
+// of creating duplicates. The output maps the new ids to the source ids.
+func importEstates(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	var r io.Reader = a.stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	file := exportFile{}
+	err := json.NewDecoder(r).Decode(&file)
+	if err != nil {
+		return fmt.Errorf("decode %s: %w", args[0], err)
+	}
+
+	results := []importResult{}
+	rows := [][]interface{}{}
+	for _, estate := range file.Estates {
+		id := importedId(a.organisation, estate.Uuid)
+		err := a.importEstate(ctx, id, estate)
+		if err != nil {
+			return fmt.Errorf("estate %s: %w", estate.Uuid, err)
+		}
+
+		results = append(results, importResult{SourceUuid: estate.Uuid, Uuid: id, Trees: len(estate.Trees)})
+		rows = append(rows, []interface{}{estate.Uuid, id, len(estate.Trees)})
+	}
+	return a.print(results, []string{"SOURCE", "ID", "TREES"}, rows)
+}
+
+// importEstate puts the estate under id and plants its trees, unless an
+// earlier run already did.
+func (a *app) importEstate(ctx context.Context, id string, estate domain.ExportEstate) error {
+	resp, err := a.client.PutEstate(ctx, id, &domain.Estate{
+		Length:      estate.Length,
+		Width:       estate.Width,
+		ExternalRef: estate.ExternalRef,
+	})
+	if err != nil {
+		return err
+	}
+	if len(estate.Trees) == 0 {
+		return nil
+	}
+
+	if !resp.Created {
+		stats, err := a.client.GetTreeStats(ctx, id)
+		if err != nil {
+			return err
+		}
+		if stats.Count == len(estate.Trees) {
+			return nil
+		}
+		if stats.Count != 0 {
+			return fmt.Errorf("%s already holds %d of %d trees", id, stats.Count, len(estate.Trees))
+		}
+	}
+
+	trees := make([]domain.PalmTree, len(estate.Trees))
+	for i, tree := range estate.Trees {
+		trees[i] = domain.PalmTree{X: tree.X, Y: tree.Y, Height: tree.Height}
+	}
+	_, err = a.client.ImportPalmTrees(ctx, id, trees, domain.ImportModeAtomic)
+	if err != nil {
+		return fmt.Errorf("trees: %w", err)
+	}
+	return nil
+}
+
+// importedId is the id the estate sourceId is imported under for the
+// organisation, or for the caller's own when organisation is empty.
+func importedId(organisation, sourceId string) string {
+	return uuid.NewSHA1(importNamespace, []byte(organisation+"/"+sourceId)).String()
+}
+
+// exportEstates reads estates and their trees straight from the database,
+// from the organisation given by -org or the default one. The document
+// keeps each estate's external ref and creation time and each tree's
+// planting time, in the shape of a backup archive line.
+func exportEstates(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("export")
+	dsn := flags.String("dsn", os.Getenv(config.EnvDatabaseURL), "PostgreSQL DSN")
+	if flags.Parse(args) != nil || flags.NArg() == 0 || *dsn == "" {
+		return errUsage
+	}
+
+	db, err := sql.Open("postgres", *dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if a.organisation != "" {
+		ctx = domain.WithTenant(ctx, &domain.Organisation{Id: a.organisation})
+	}
+
+	manager := helper.NewManager(db, common.TransactionContextKey)
+	estateRepo := estatesql.NewEstateRepositorySql(db, manager)
+
+	file := exportFile{Estates: []domain.ExportEstate{}}
+	rows := [][]interface{}{}
+	for _, id := range flags.Args() {
+		var estate *domain.ExportEstate
+		err := estateRepo.ExportEstates(ctx, id, func(row domain.EstateExportRow) error {
+			if estate == nil {
+				estate = &domain.ExportEstate{
+					Uuid:        row.Uuid,
+					Length:      row.Length,
+					Width:       row.Width,
+					CreatedAt:   row.CreatedAt,
+					ExternalRef: row.ExternalRef,
+					Trees:       []domain.ExportPalmTree{},
+				}
+			}
+			if row.Tree != nil {
+				estate.Trees = append(estate.Trees, *row.Tree)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if estate == nil {
+			return fmt.Errorf("estate %s: %w", id, domain.ErrEstateNotFound)
+		}
+
+		file.Estates = append(file.Estates, *estate)
+		rows = append(rows, []interface{}{estate.Uuid, estate.Length, estate.Width, len(estate.Trees)})
+	}
+	return a.print(file, []string{"ID", "LENGTH", "WIDTH", "TREES"}, rows)
+}
+
+// backup writes the server's backup archive to -file, or to stdout.
+func backup(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("backup")
+	file := flags.String("file", "", "archive to write, stdout when empty")
+	if flags.Parse(args) != nil || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	if *file == "" {
+		return a.client.Backup(ctx, a.stdout)
+	}
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	err = a.client.Backup(ctx, f)
+	if err != nil {
+		f.Close()
+		os.Remove(*file)
+		return err
+	}
+	return f.Close()
+}
+
+// restore uploads a backup archive. Estates already restored are reported
+// as unchanged, so an interrupted restore can simply be run again.
+func restore(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("restore")
+	conflict := flags.String("conflict", domain.RestoreConflictFail, "on an existing estate with different data: fail, skip or remap")
+	if flags.Parse(args) != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	var r io.Reader = a.stdin
+	if flags.Arg(0) != "-" {
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	resp, err := a.client.Restore(ctx, r, *conflict)
+	if err != nil {
+		apiErr := &client.APIError{}
+		if errors.As(err, &apiErr) {
+			if report := apiErr.RestoreReport(); report != nil && len(report.Estates) > 0 {
+				return fmt.Errorf("estate %s: %w", report.Estates[len(report.Estates)-1].SourceUuid, err)
+			}
+		}
+		return err
+	}
+
+	rows := make([][]interface{}, len(resp.Estates))
+	for i, estate := range resp.Estates {
+		rows[i] = []interface{}{estate.SourceUuid, estate.Uuid, estate.Status, estate.Trees}
+	}
+	return a.print(resp, []string{"SOURCE", "ID", "STATUS", "TREES"}, rows)
+}
+
+func createApiKey(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("create-api-key")
+	organisation := flags.String("organisation", "", "organisation the key acts for, the default one when empty")
+	if flags.Parse(args) != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	resp, err := a.client.CreateApiKey(ctx, flags.Arg(0), *organisation)
+	if err != nil {
+		return err
+	}
+	return a.print(resp, []string{"ID", "NAME", "KEY"}, [][]interface{}{{resp.Id, resp.Name, resp.Key}})
+}
+
+func listApiKeys(ctx context.Context, a *app, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	keys, err := a.client.ListApiKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, len(keys))
+	for i, key := range keys {
+		revoked := "-"
+		if key.RevokedAt != nil {
+			revoked = key.RevokedAt.Format(time.RFC3339)
+		}
+		rows[i] = []interface{}{key.Id, key.Name, key.Prefix, key.CreatedBy, key.CreatedAt.Format(time.RFC3339), revoked}
+	}
+	return a.print(keys, []string{"ID", "NAME", "PREFIX", "CREATED BY", "CREATED AT", "REVOKED AT"}, rows)
+}
+
+func revokeApiKey(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	return a.client.RevokeApiKey(ctx, args[0])
+}
+
+func listPermissions(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	permissions, err := a.client.ListEstatePermissions(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, len(permissions))
+	for i, permission := range permissions {
+		rows[i] = []interface{}{permission.Subject, permission.Role, permission.GrantedBy, permission.GrantedAt.Format(time.RFC3339)}
+	}
+	return a.print(permissions, []string{"SUBJECT", "ROLE", "GRANTED BY", "GRANTED AT"}, rows)
+}
+
+func grant(ctx context.Context, a *app, args []string) error {
+	if len(args) != 3 {
+		return errUsage
+	}
+
+	resp, err := a.client.GrantEstatePermission(ctx, args[0], args[1], args[2])
+	if err != nil {
+		return err
+	}
+	return a.print(resp, []string{"SUBJECT", "ROLE", "CREATED"}, [][]interface{}{{resp.Subject, resp.Role, resp.Created}})
+}
+
+func revoke(ctx context.Context, a *app, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	return a.client.RevokeEstatePermission(ctx, args[0], args[1])
+}
+
+func listOrganisations(ctx context.Context, a *app, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	organisations, err := a.client.ListOrganisations(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, len(organisations))
+	for i, organisation := range organisations {
+		timezone := organisation.Timezone
+		if timezone == "" {
+			timezone = "-"
+		}
+		rows[i] = []interface{}{organisation.Id, organisation.Name, timezone, organisation.CreatedAt.Format(time.RFC3339)}
+	}
+	return a.print(organisations, []string{"ID", "NAME", "TIMEZONE", "CREATED AT"}, rows)
+}
+
+// putOrganisation creates or replaces an organisation. Size limits left at
+// zero fall back to the server's own.
+func putOrganisation(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("put-organisation")
+	name := flags.String("name", "", "display name")
+	timezone := flags.String("timezone", "", "IANA time zone of the organisation's timestamps")
+	maxArea := flags.Int("max-area", 0, "largest estate area in square metres")
+	maxLength := flags.Int("max-length", 0, "longest estate length in plots")
+	maxWidth := flags.Int("max-width", 0, "widest estate width in plots")
+	plotSize := flags.Int("plot-size", 0, "plot size in metres")
+	if flags.Parse(args) != nil || flags.NArg() != 1 || *name == "" {
+		return errUsage
+	}
+
+	resp, err := a.client.PutOrganisation(ctx, flags.Arg(0), &domain.Organisation{
+		Name:     *name,
+		Timezone: *timezone,
+		SizePolicy: domain.SizePolicy{
+			MaxArea:   *maxArea,
+			MaxLength: *maxLength,
+			MaxWidth:  *maxWidth,
+			PlotSize:  *plotSize,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return a.print(resp, []string{"ID", "NAME", "CREATED"}, [][]interface{}{{resp.Id, resp.Name, resp.Created}})
+}
@@ -0,0 +1,154 @@
+// Command estatectl manages estates from the command line.
+//
+//	estatectl [global flags] <command> [flags] [args]
+//
+// Every command talks to the API at -server except export, which reads the
+// database directly through the repositories.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/davidyunus/sawitpro-estate/client"
+)
+
+const (
+	EnvServer = "ESTATECTL_SERVER"
+	EnvApiKey = "ESTATECTL_API_KEY"
+	EnvToken  = "ESTATECTL_TOKEN"
+
+	OutputTable = "table"
+	OutputJson  = "json"
+)
+
+var errUsage = errors.New("usage")
+
+type (
+	app struct {
+		client       *client.Client
+		organisation string
+		output       string
+		stdin        io.Reader
+		stdout       io.Writer
+	}
+
+	command struct {
+		usage string
+		run   func(ctx context.Context, a *app, args []string) error
+	}
+)
+
+var commands = map[string]command{
+	"create-estate": {usage: "create-estate -length N -width N [-ref CODE]", run: createEstate},
+	"plant":         {usage: "plant -x N -y N -height N ESTATE_ID", run: plant},
+	"stats":         {usage: "stats ESTATE_ID", run: stats},
+	"drone-plan":    {usage: "drone-plan [-max-distance N] ESTATE_ID", run: dronePlan},
+	"import":        {usage: "import FILE|-", run: importEstates},
+	"export":        {usage: "export [-dsn DSN] ESTATE_ID...", run: exportEstates},
+	"backup":        {usage: "backup [-file FILE]", run: backup},
+	"restore":       {usage: "restore [-conflict fail|skip|remap] FILE|-", run: restore},
+
+	"create-api-key": {usage: "create-api-key [-organisation ORG] NAME", run: createApiKey},
+	"list-api-keys":  {usage: "list-api-keys", run: listApiKeys},
+	"revoke-api-key": {usage: "revoke-api-key KEY_ID", run: revokeApiKey},
+
+	"list-permissions": {usage: "list-permissions ESTATE_ID", run: listPermissions},
+	"grant":            {usage: "grant ESTATE_ID api_key:KEY_ID|jwt:SUB viewer|surveyor|operator|admin", run: grant},
+	"revoke":           {usage: "revoke ESTATE_ID api_key:KEY_ID|jwt:SUB", run: revoke},
+
+	"list-organisations": {usage: "list-organisations", run: listOrganisations},
+	"put-organisation":   {usage: "put-organisation -name NAME [-timezone TZ] [-max-area N] [-max-length N] [-max-width N] [-plot-size N] ORG_ID", run: putOrganisation},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "estatectl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("estatectl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	server := flags.String("server", envOr(EnvServer, "http://localhost:8080"), "API base URL ($"+EnvServer+")")
+	output := flags.String("output", OutputTable, "output format: table or json")
+	organisation := flags.String("org", "", "organisation to act for (administrators only)")
+	apiKey := flags.String("api-key", os.Getenv(EnvApiKey), "API key to authenticate with ($"+EnvApiKey+")")
+	token := flags.String("token", os.Getenv(EnvToken), "JWT to authenticate with ($"+EnvToken+")")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: estatectl [flags] <command> [args]\n\ncommands:")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintln(stderr, "  "+commands[name].usage)
+		}
+		fmt.Fprintln(stderr, "\nflags:")
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+	if *output != OutputTable && *output != OutputJson {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		flags.Usage()
+		return errUsage
+	}
+
+	opts := []client.Option{}
+	if *organisation != "" {
+		opts = append(opts, client.WithOrganisation(*organisation))
+	}
+	if *apiKey != "" {
+		opts = append(opts, client.WithAPIKey(*apiKey))
+	}
+	if *token != "" {
+		opts = append(opts, client.WithBearerToken(*token))
+	}
+	a := &app{
+		client:       client.New(*server, opts...),
+		organisation: *organisation,
+		output:       *output,
+		stdin:        stdin,
+		stdout:       stdout,
+	}
+
+	err = cmd.run(ctx, a, flags.Args()[1:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(stderr, "usage: estatectl "+cmd.usage)
+	}
+	return err
+}
+
+func envOr(key, fallback string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return fallback
+}
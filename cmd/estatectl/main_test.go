@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	estatehttp "github.com/davidyunus/sawitpro-estate/src/estate/delivery/http"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newServer(t *testing.T, estateUsecase domain.EstateUsecase, authUsecase domain.AuthUsecase, organisationUsecase domain.OrganisationUsecase) *httptest.Server {
+	e := echo.New()
+	e.Logger.SetOutput(io.Discard)
+	e.HTTPErrorHandler = helper.HTTPErrorHandler
+	e.Validator = helper.NewValidator()
+	estatehttp.NewEstateHandler(e, estateUsecase, authUsecase, organisationUsecase, nil, nil)
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	authMock := mock_domain.NewMockAuthUsecase(ctrl)
+	organisationMock := mock_domain.NewMockOrganisationUsecase(ctrl)
+	server := newServer(t, estateMock, authMock, organisationMock)
+
+	tempNow := helper.Now
+	helper.Now = func() time.Time {
+		return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	}
+	defer func() {
+		helper.Now = tempNow
+	}()
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantResult string
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:       "create estate table",
+			args:       []string{"create-estate", "-length", "6", "-width", "3"},
+			wantResult: "ID\n" + common.UtUuid + "\n",
+			mock: func() {
+				estateMock.EXPECT().CreateEstate(gomock.Any(), &domain.Estate{Length: 6, Width: 3}).
+					Return(&domain.CreateEstateResponse{Id: common.UtUuid}, nil)
+			},
+		},
+		{
+			name:       "create estate with external ref",
+			args:       []string{"create-estate", "-length", "6", "-width", "3", "-ref", "ERP-1"},
+			wantResult: "ID\n" + common.UtUuid + "\n",
+			mock: func() {
+				estateMock.EXPECT().CreateEstate(gomock.Any(), &domain.Estate{Length: 6, Width: 3, ExternalRef: "ERP-1"}).
+					Return(&domain.CreateEstateResponse{Id: common.UtUuid}, nil)
+			},
+		},
+		{
+			name:       "stats json",
+			args:       []string{"-output", "json", "stats", common.UtUuid},
+			wantResult: "{\n  \"count\": 3,\n  \"max\": 20,\n  \"min\": 5,\n  \"median\": 10\n}\n",
+			mock: func() {
+				estateMock.EXPECT().GetTreeStats(gomock.Any(), common.UtUuid).
+					Return(&domain.GetTreeStatsResponse{Count: 3, Max: 20, Min: 5, Median: 10}, nil)
+			},
+		},
+		{
+			name:       "drone plan without rest",
+			args:       []string{"drone-plan", common.UtUuid},
+			wantResult: "DISTANCE  REST X  REST Y\n92        -       -\n",
+			mock: func() {
+				estateMock.EXPECT().GetDroneFlyingDistance(gomock.Any(), common.UtUuid, 0).
+					Return(&domain.GetDroneFlyingDistanceResponse{Distance: 92}, nil)
+			},
+		},
+		{
+			name:  "import",
+			args:  []string{"import", "-"},
+			stdin: `{"estates":[{"uuid":"source","length":6,"width":3,"externalRef":"ERP-1","createdAt":"2024-01-02T03:04:05Z","trees":[{"id":7,"x":2,"y":1,"height":10,"plantedAt":"2024-01-02T03:04:05Z"}]}]}`,
+			wantResult: "SOURCE  ID                                    TREES\n" +
+				"source  " + importedId("", "source") + "  1\n",
+			mock: func() {
+				estateMock.EXPECT().PutEstate(gomock.Any(), importedId("", "source"), &domain.Estate{Length: 6, Width: 3, ExternalRef: "ERP-1"}).
+					Return(&domain.PutEstateResponse{Id: importedId("", "source"), Created: true}, nil)
+				estateMock.EXPECT().ImportPalmTrees(gomock.Any(), importedId("", "source"), &domain.ImportPalmTreesRequest{
+					Mode:  domain.ImportModeAtomic,
+					Trees: []domain.PalmTree{{X: 2, Y: 1, Height: 10}},
+				}).Return(&domain.ImportPalmTreesResponse{Id: importedId("", "source"), Imported: 1}, nil)
+			},
+		},
+		{
+			name:  "import again skips planted estate",
+			args:  []string{"import", "-"},
+			stdin: `{"estates":[{"uuid":"source","length":6,"width":3,"trees":[{"x":2,"y":1,"height":10}]}]}`,
+			wantResult: "SOURCE  ID                                    TREES\n" +
+				"source  " + importedId("", "source") + "  1\n",
+			mock: func() {
+				estateMock.EXPECT().PutEstate(gomock.Any(), importedId("", "source"), &domain.Estate{Length: 6, Width: 3}).
+					Return(&domain.PutEstateResponse{Id: importedId("", "source")}, nil)
+				estateMock.EXPECT().GetTreeStats(gomock.Any(), importedId("", "source")).
+					Return(&domain.GetTreeStatsResponse{Count: 1, Max: 10, Min: 10, Median: 10}, nil)
+			},
+		},
+		{
+			name:  "import again fills empty estate",
+			args:  []string{"import", "-"},
+			stdin: `{"estates":[{"uuid":"source","length":6,"width":3,"trees":[{"x":2,"y":1,"height":10}]}]}`,
+			wantResult: "SOURCE  ID                                    TREES\n" +
+				"source  " + importedId("", "source") + "  1\n",
+			mock: func() {
+				estateMock.EXPECT().PutEstate(gomock.Any(), importedId("", "source"), &domain.Estate{Length: 6, Width: 3}).
+					Return(&domain.PutEstateResponse{Id: importedId("", "source")}, nil)
+				estateMock.EXPECT().GetTreeStats(gomock.Any(), importedId("", "source")).
+					Return(&domain.GetTreeStatsResponse{}, nil)
+				estateMock.EXPECT().ImportPalmTrees(gomock.Any(), importedId("", "source"), &domain.ImportPalmTreesRequest{
+					Mode:  domain.ImportModeAtomic,
+					Trees: []domain.PalmTree{{X: 2, Y: 1, Height: 10}},
+				}).Return(&domain.ImportPalmTreesResponse{Id: importedId("", "source"), Imported: 1}, nil)
+			},
+		},
+		{
+			name:    "import partly planted estate",
+			args:    []string{"import", "-"},
+			stdin:   `{"estates":[{"uuid":"source","length":6,"width":3,"trees":[{"x":2,"y":1,"height":10},{"x":3,"y":1,"height":12}]}]}`,
+			wantErr: true,
+			mock: func() {
+				estateMock.EXPECT().PutEstate(gomock.Any(), importedId("", "source"), &domain.Estate{Length: 6, Width: 3}).
+					Return(&domain.PutEstateResponse{Id: importedId("", "source")}, nil)
+				estateMock.EXPECT().GetTreeStats(gomock.Any(), importedId("", "source")).
+					Return(&domain.GetTreeStatsResponse{Count: 1, Max: 10, Min: 10, Median: 10}, nil)
+			},
+		},
+		{
+			name:       "backup to stdout",
+			args:       []string{"backup"},
+			wantResult: `{"format":"sawitpro-estate-backup","version":2,"createdAt":"2024-01-02T03:04:05Z"}` + "\n",
+			mock: func() {
+				estateMock.EXPECT().ExportEstates(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "restore",
+			args: []string{"restore", "-conflict", "remap", "-"},
+			stdin: `{"format":"sawitpro-estate-backup","version":1,"createdAt":"2024-01-02T03:04:05Z"}
+{"uuid":"source","length":6,"width":3,"createdAt":"2024-01-02T03:04:05Z","trees":[]}
+`,
+			wantResult: "SOURCE  ID    STATUS    TREES\n" +
+				"source  " + common.UtUuid + "  remapped  0\n",
+			mock: func() {
+				estateMock.EXPECT().RestoreEstates(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, param *domain.RestoreEstatesRequest) (*domain.RestoreEstatesResponse, error) {
+						assert.Equal(t, domain.RestoreConflictRemap, param.Conflict)
+						return &domain.RestoreEstatesResponse{
+							Conflict: param.Conflict,
+							Total:    1,
+							Remapped: 1,
+							Estates:  []domain.RestoreResult{{SourceUuid: "source", Uuid: common.UtUuid, Status: domain.RestoreStatusRemapped}},
+						}, nil
+					})
+			},
+		},
+		{
+			name:    "restore conflict",
+			args:    []string{"restore", "-"},
+			stdin:   `{"format":"sawitpro-estate-backup","version":1,"createdAt":"2024-01-02T03:04:05Z"}`,
+			wantErr: true,
+			mock: func() {
+				estateMock.EXPECT().RestoreEstates(gomock.Any(), gomock.Any()).Return(nil, domain.ErrRestoreConflict)
+			},
+		},
+		{
+			name:       "create api key",
+			args:       []string{"create-api-key", "ci"},
+			wantResult: "ID    NAME  KEY\n" + common.UtUuid + "  ci    spk_abcdefghijk\n",
+			mock: func() {
+				authMock.EXPECT().CreateApiKey(gomock.Any(), &domain.CreateApiKeyRequest{Name: "ci"}).
+					Return(&domain.CreateApiKeyResponse{
+						ApiKey: domain.ApiKey{Id: common.UtUuid, Name: "ci"},
+						Key:    "spk_abcdefghijk",
+					}, nil)
+			},
+		},
+		{
+			name:       "create api key for organisation",
+			args:       []string{"create-api-key", "-organisation", "acme", "ci"},
+			wantResult: "ID    NAME  KEY\n" + common.UtUuid + "  ci    spk_abcdefghijk\n",
+			mock: func() {
+				authMock.EXPECT().CreateApiKey(gomock.Any(), &domain.CreateApiKeyRequest{Name: "ci", OrganisationId: "acme"}).
+					Return(&domain.CreateApiKeyResponse{
+						ApiKey: domain.ApiKey{Id: common.UtUuid, Name: "ci", OrganisationId: "acme"},
+						Key:    "spk_abcdefghijk",
+					}, nil)
+			},
+		},
+		{
+			name: "list api keys",
+			args: []string{"list-api-keys"},
+			wantResult: "ID    NAME  PREFIX        CREATED BY  CREATED AT            REVOKED AT\n" +
+				common.UtUuid + "  ci    spk_abcdefgh  alice       2024-01-02T03:04:05Z  -\n",
+			mock: func() {
+				authMock.EXPECT().ListApiKeys(gomock.Any()).Return([]domain.ApiKey{{
+					Id:        common.UtUuid,
+					Name:      "ci",
+					Prefix:    "spk_abcdefgh",
+					CreatedBy: "alice",
+					CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				}}, nil)
+			},
+		},
+		{
+			name: "revoke api key",
+			args: []string{"revoke-api-key", common.UtUuid},
+			mock: func() {
+				authMock.EXPECT().RevokeApiKey(gomock.Any(), common.UtUuid).Return(nil)
+			},
+		},
+		{
+			name:    "revoke unknown api key",
+			args:    []string{"revoke-api-key", common.UtUuid},
+			wantErr: true,
+			mock: func() {
+				authMock.EXPECT().RevokeApiKey(gomock.Any(), common.UtUuid).Return(domain.ErrApiKeyNotFound)
+			},
+		},
+		{
+			name: "list permissions",
+			args: []string{"list-permissions", common.UtUuid},
+			wantResult: "SUBJECT  ROLE   GRANTED BY  GRANTED AT\n" +
+				"alice    admin  alice       2024-01-02T03:04:05Z\n",
+			mock: func() {
+				estateMock.EXPECT().ListEstatePermissions(gomock.Any(), common.UtUuid).Return(&domain.ListEstatePermissionsResponse{
+					Permissions: []domain.EstatePermission{{
+						EstateId:  common.UtUuid,
+						Subject:   "alice",
+						Role:      domain.RoleAdmin,
+						GrantedBy: "alice",
+						GrantedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+					}},
+				}, nil)
+			},
+		},
+		{
+			name:       "grant",
+			args:       []string{"grant", common.UtUuid, "bob", "surveyor"},
+			wantResult: "SUBJECT  ROLE      CREATED\nbob      surveyor  true\n",
+			mock: func() {
+				estateMock.EXPECT().GrantEstatePermission(gomock.Any(), common.UtUuid, "bob", &domain.GrantEstatePermissionRequest{Role: domain.RoleSurveyor}).
+					Return(&domain.GrantEstatePermissionResponse{
+						EstatePermission: domain.EstatePermission{EstateId: common.UtUuid, Subject: "bob", Role: domain.RoleSurveyor},
+						Created:          true,
+					}, nil)
+			},
+		},
+		{
+			name:    "grant without role",
+			args:    []string{"grant", common.UtUuid, "bob"},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "revoke",
+			args: []string{"revoke", common.UtUuid, "bob"},
+			mock: func() {
+				estateMock.EXPECT().RevokeEstatePermission(gomock.Any(), common.UtUuid, "bob").Return(nil)
+			},
+		},
+		{
+			name: "list organisations",
+			args: []string{"list-organisations"},
+			wantResult: "ID       NAME     TIMEZONE       CREATED AT\n" +
+				"acme     Acme     Asia/Makassar  2024-01-02T03:04:05Z\n" +
+				"default  Default  -              2024-01-02T03:04:05Z\n",
+			mock: func() {
+				organisationMock.EXPECT().ListOrganisations(gomock.Any()).Return([]domain.Organisation{
+					{Id: "acme", Name: "Acme", Timezone: "Asia/Makassar", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+					{Id: "default", Name: "Default", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+				}, nil)
+			},
+		},
+		{
+			name:       "put organisation",
+			args:       []string{"put-organisation", "-name", "Acme", "-timezone", "Asia/Makassar", "-max-length", "20", "acme"},
+			wantResult: "ID    NAME  CREATED\nacme  Acme  true\n",
+			mock: func() {
+				organisationMock.EXPECT().PutOrganisation(gomock.Any(), "acme", &domain.Organisation{
+					Name:       "Acme",
+					Timezone:   "Asia/Makassar",
+					SizePolicy: domain.SizePolicy{MaxLength: 20},
+				}).Return(&domain.PutOrganisationResponse{
+					Organisation: domain.Organisation{Id: "acme", Name: "Acme", Timezone: "Asia/Makassar"},
+					Created:      true,
+				}, nil)
+			},
+		},
+		{
+			name:    "put organisation without name",
+			args:    []string{"put-organisation", "acme"},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name:    "api error",
+			args:    []string{"stats", common.UtUuid},
+			wantErr: true,
+			mock: func() {
+				estateMock.EXPECT().GetTreeStats(gomock.Any(), common.UtUuid).
+					Return(nil, domain.ErrEstateNotFound)
+			},
+		},
+		{
+			name:    "unknown command",
+			args:    []string{"harvest"},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name:    "missing estate id",
+			args:    []string{"stats"},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name:    "unknown output",
+			args:    []string{"-output", "yaml", "stats", common.UtUuid},
+			wantErr: true,
+			mock:    func() {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			stdout := &bytes.Buffer{}
+			args := append([]string{"-server", server.URL}, tt.args...)
+			err := run(context.Background(), args, strings.NewReader(tt.stdin), stdout, io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantResult, stdout.String())
+		})
+	}
+}
+
+func TestImportedId(t *testing.T) {
+	id := importedId("acme", "source")
+	assert.Equal(t, id, importedId("acme", "source"))
+	assert.NotEqual(t, id, importedId("", "source"))
+	assert.NotEqual(t, id, importedId("acme", "other"))
+}
@@ -1,17 +1,139 @@
+-- Tenants. A zero size limit falls back to the global one, an empty
+-- timezone to TIMEZONE.
+CREATE TABLE organisation (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    maxArea INT NOT NULL DEFAULT 0,
+    maxLength INT NOT NULL DEFAULT 0,
+    maxWidth INT NOT NULL DEFAULT 0,
+    plotSize INT NOT NULL DEFAULT 0,
+    createdAt TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO organisation (id, name) VALUES ('default', 'Default');
+
 CREATE TABLE estate (
     id SERIAL PRIMARY KEY,
+    organisationId VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES organisation (id),
     uuid VARCHAR(36) NOT NULL,
     length INT NOT NULL,
     width INT NOT NULL,
-    createdAt TIMESTAMP NOT NULL DEFAULT NOW()
-)
+    createdAt TIMESTAMP NOT NULL DEFAULT NOW(),
+    externalRef VARCHAR(64)
+);
+
+-- PUT /estate/{id} upserts on the uuid, which is unique across
+-- organisations; external references are unique within an organisation
+-- among the estates that have one.
+CREATE UNIQUE INDEX estate_uuid_idx ON estate (uuid);
+CREATE UNIQUE INDEX estate_organisationId_externalRef_idx ON estate (organisationId, externalRef);
 
 CREATE TABLE palmTreeLocation (
     id SERIAL PRIMARY KEY,
-    estateId BIGINT NOT NULL,
+    organisationId VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES organisation (id),
     uuid VARCHAR(36) NOT NULL,
     x INT NOT NULL,
     y INT NOT NULL,
     height INT NOT NULL,
     createdAt TIMESTAMP NOT NULL DEFAULT NOW()
-)
\ No newline at end of file
+);
+
+-- Tree listings filter on the estate and page on (sort column, id).
+CREATE INDEX palmTreeLocation_uuid_id_idx ON palmTreeLocation (uuid, id);
+CREATE INDEX palmTreeLocation_uuid_height_id_idx ON palmTreeLocation (uuid, height, id);
+CREATE INDEX palmTreeLocation_uuid_x_id_idx ON palmTreeLocation (uuid, x, id);
+CREATE INDEX palmTreeLocation_uuid_y_id_idx ON palmTreeLocation (uuid, y, id);
+CREATE INDEX palmTreeLocation_uuid_createdAt_id_idx ON palmTreeLocation (uuid, createdAt, id);
+CREATE INDEX palmTreeLocation_organisationId_idx ON palmTreeLocation (organisationId);
+
+-- A plot holds at most one tree, whatever plants it concurrently.
+CREATE UNIQUE INDEX palmTreeLocation_uuid_x_y_idx ON palmTreeLocation (uuid, x, y);
+
+-- Responses to requests sent with an Idempotency-Key. Keys are scoped to
+-- the caller and the organisation it acts for; token identifies the
+-- reservation, so a request that outlived it cannot touch the one a retry
+-- took over. statusCode is NULL while the first request is in progress.
+CREATE TABLE idempotencyKey (
+    scope VARCHAR(400) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    token VARCHAR(36) NOT NULL,
+    requestHash VARCHAR(64) NOT NULL,
+    statusCode INT,
+    contentType VARCHAR(255),
+    body BYTEA,
+    expiresAt TIMESTAMP NOT NULL,
+    createdAt TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, key)
+);
+
+-- API keys are stored as SHA-256 hashes; prefix is the start of the key,
+-- kept to tell keys apart. Keys without an organisation act for the
+-- default one.
+CREATE TABLE apiKey (
+    id SERIAL PRIMARY KEY,
+    uuid VARCHAR(36) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    hash VARCHAR(64) NOT NULL UNIQUE,
+    createdBy VARCHAR(255) NOT NULL,
+    organisationId VARCHAR(64) REFERENCES organisation (id),
+    createdAt TIMESTAMP NOT NULL DEFAULT NOW(),
+    revokedAt TIMESTAMP
+);
+
+-- Roles principals hold on estates. subject is "api_key:" and an API key
+-- id or "jwt:" and the sub claim of a token; administrators need no row to
+-- access any estate.
+CREATE TABLE estatePermission (
+    estateUuid VARCHAR(36) NOT NULL REFERENCES estate (uuid) ON DELETE CASCADE,
+    subject VARCHAR(255) NOT NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('viewer', 'surveyor', 'operator', 'admin')),
+    grantedBy VARCHAR(255) NOT NULL,
+    grantedAt TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (estateUuid, subject)
+);
+
+CREATE INDEX estatePermission_subject_idx ON estatePermission (subject);
+
+-- Append-only record of every change, written in the transaction of the
+-- change itself. actor is an API key id or the sub claim of a token;
+-- before and after hold the changed record as JSON.
+CREATE TABLE auditLog (
+    id BIGSERIAL PRIMARY KEY,
+    organisationId VARCHAR(64) NOT NULL REFERENCES organisation (id),
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    estateUuid VARCHAR(36),
+    treeIds BIGINT[],
+    before JSONB,
+    after JSONB,
+    requestId VARCHAR(255),
+    createdAt TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX auditLog_organisationId_id_idx ON auditLog (organisationId, id);
+CREATE INDEX auditLog_estateUuid_id_idx ON auditLog (estateUuid, id);
+CREATE INDEX auditLog_treeIds_idx ON auditLog USING GIN (treeIds);
+
+-- Schema versions applied to this database. /readyz fails while the
+-- highest is below domain.SchemaVersion; a migration that changes the
+-- schema inserts the next version.
+CREATE TABLE schemaVersion (
+    version INT PRIMARY KEY,
+    appliedAt TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Version 2 dropped palmTreeLocation.estateId, which no insert set:
+--   ALTER TABLE palmTreeLocation DROP COLUMN estateId;
+-- Version 3 made plots unique, once duplicate trees are removed:
+--   CREATE UNIQUE INDEX palmTreeLocation_uuid_x_y_idx ON palmTreeLocation (uuid, x, y);
+-- Version 4 scoped idempotency keys to their caller; stored responses
+-- are short-lived, so the table is recreated as above:
+--   DROP TABLE idempotencyKey;
+-- Version 5 qualified role subjects by the way they authenticate:
+--   UPDATE estatePermission SET subject = 'api_key:' || subject WHERE subject IN (SELECT uuid FROM apiKey);
+--   UPDATE estatePermission SET subject = 'jwt:' || subject WHERE subject NOT LIKE 'api\_key:%';
+--   UPDATE estatePermission SET grantedBy = 'api_key:' || grantedBy WHERE grantedBy IN (SELECT uuid FROM apiKey);
+--   UPDATE estatePermission SET grantedBy = 'jwt:' || grantedBy WHERE grantedBy NOT LIKE 'api\_key:%';
+INSERT INTO schemaVersion (version) VALUES (1), (2), (3), (4), (5);
@@ -0,0 +1,1792 @@
+// Package generated provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen/v2 version v2.1.0 DO NOT EDIT.
+package generated
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime"
+)
+
+const (
+	ApiKeyAuthScopes = "ApiKeyAuth.Scopes"
+	BearerAuthScopes = "BearerAuth.Scopes"
+)
+
+// Defines values for EstatePermissionRole.
+const (
+	EstatePermissionRoleAdmin    EstatePermissionRole = "admin"
+	EstatePermissionRoleOperator EstatePermissionRole = "operator"
+	EstatePermissionRoleSurveyor EstatePermissionRole = "surveyor"
+	EstatePermissionRoleViewer   EstatePermissionRole = "viewer"
+)
+
+// Defines values for GrantEstatePermissionRole.
+const (
+	GrantEstatePermissionRoleAdmin    GrantEstatePermissionRole = "admin"
+	GrantEstatePermissionRoleOperator GrantEstatePermissionRole = "operator"
+	GrantEstatePermissionRoleSurveyor GrantEstatePermissionRole = "surveyor"
+	GrantEstatePermissionRoleViewer   GrantEstatePermissionRole = "viewer"
+)
+
+// Defines values for GrantedEstatePermissionRole.
+const (
+	Admin    GrantedEstatePermissionRole = "admin"
+	Operator GrantedEstatePermissionRole = "operator"
+	Surveyor GrantedEstatePermissionRole = "surveyor"
+	Viewer   GrantedEstatePermissionRole = "viewer"
+)
+
+// Defines values for HealthStatus.
+const (
+	HealthStatusFail HealthStatus = "fail"
+	HealthStatusPass HealthStatus = "pass"
+)
+
+// Defines values for HealthCheckName.
+const (
+	Database HealthCheckName = "database"
+	Schema   HealthCheckName = "schema"
+	Shutdown HealthCheckName = "shutdown"
+)
+
+// Defines values for HealthCheckStatus.
+const (
+	HealthCheckStatusFail HealthCheckStatus = "fail"
+	HealthCheckStatusPass HealthCheckStatus = "pass"
+)
+
+// Defines values for RestoreResultStatus.
+const (
+	Conflict  RestoreResultStatus = "conflict"
+	Created   RestoreResultStatus = "created"
+	Remapped  RestoreResultStatus = "remapped"
+	Skipped   RestoreResultStatus = "skipped"
+	Unchanged RestoreResultStatus = "unchanged"
+)
+
+// Defines values for RestoreEstatesParamsConflict.
+const (
+	Fail  RestoreEstatesParamsConflict = "fail"
+	Remap RestoreEstatesParamsConflict = "remap"
+	Skip  RestoreEstatesParamsConflict = "skip"
+)
+
+// Defines values for ListAuditEntriesParamsAction.
+const (
+	ApikeyCreate     ListAuditEntriesParamsAction = "apikey.create"
+	ApikeyRevoke     ListAuditEntriesParamsAction = "apikey.revoke"
+	EstateCreate     ListAuditEntriesParamsAction = "estate.create"
+	EstateReplace    ListAuditEntriesParamsAction = "estate.replace"
+	EstateRestore    ListAuditEntriesParamsAction = "estate.restore"
+	OrganisationPut  ListAuditEntriesParamsAction = "organisation.put"
+	PermissionGrant  ListAuditEntriesParamsAction = "permission.grant"
+	PermissionRevoke ListAuditEntriesParamsAction = "permission.revoke"
+	TreeImport       ListAuditEntriesParamsAction = "tree.import"
+	TreePlant        ListAuditEntriesParamsAction = "tree.plant"
+)
+
+// Defines values for ListPalmTreesParamsSort.
+const (
+	Height         ListPalmTreesParamsSort = "height"
+	Id             ListPalmTreesParamsSort = "id"
+	MinusHeight    ListPalmTreesParamsSort = "-height"
+	MinusId        ListPalmTreesParamsSort = "-id"
+	MinusPlantedAt ListPalmTreesParamsSort = "-plantedAt"
+	MinusX         ListPalmTreesParamsSort = "-x"
+	MinusY         ListPalmTreesParamsSort = "-y"
+	PlantedAt      ListPalmTreesParamsSort = "plantedAt"
+	X              ListPalmTreesParamsSort = "x"
+	Y              ListPalmTreesParamsSort = "y"
+)
+
+// Defines values for ImportPalmTreesParamsMode.
+const (
+	Atomic     ImportPalmTreesParamsMode = "atomic"
+	BestEffort ImportPalmTreesParamsMode = "best-effort"
+)
+
+// ApiKey defines model for ApiKey.
+type ApiKey struct {
+	CreatedAt      time.Time `json:"createdAt"`
+	CreatedBy      string    `json:"createdBy"`
+	Id             string    `json:"id"`
+	Name           string    `json:"name"`
+	OrganisationId *string   `json:"organisationId,omitempty"`
+
+	// Prefix First characters of the key, to tell keys apart.
+	Prefix    string     `json:"prefix"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// ApiKeyListResponse defines model for ApiKeyListResponse.
+type ApiKeyListResponse struct {
+	Code    int          `json:"code"`
+	Data    []ApiKey     `json:"data"`
+	Errors  *interface{} `json:"errors"`
+	Message string       `json:"message"`
+}
+
+// AuditEntry defines model for AuditEntry.
+type AuditEntry struct {
+	Action string `json:"action"`
+	Actor  string `json:"actor"`
+
+	// After The changed record after the change; absent for removals.
+	After *interface{} `json:"after,omitempty"`
+
+	// Before The changed record before the change; absent for creations.
+	Before    *interface{} `json:"before,omitempty"`
+	CreatedAt time.Time    `json:"createdAt"`
+	EstateId  *string      `json:"estateId,omitempty"`
+	Id        int64        `json:"id"`
+	RequestId *string      `json:"requestId,omitempty"`
+	TreeIds   *[]int64     `json:"treeIds,omitempty"`
+}
+
+// AuditPage defines model for AuditPage.
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor *string      `json:"nextCursor,omitempty"`
+}
+
+// AuditPageResponse defines model for AuditPageResponse.
+type AuditPageResponse struct {
+	Code    int          `json:"code"`
+	Data    AuditPage    `json:"data"`
+	Errors  *interface{} `json:"errors"`
+	Message string       `json:"message"`
+}
+
+// CreateApiKey defines model for CreateApiKey.
+type CreateApiKey struct {
+	// Name What the key is for, such as the client using it.
+	Name string `json:"name"`
+
+	// OrganisationId Organisation the key acts for; `default` when omitted.
+	OrganisationId *string `json:"organisationId,omitempty"`
+}
+
+// CreatedApiKey defines model for CreatedApiKey.
+type CreatedApiKey struct {
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy string    `json:"createdBy"`
+	Id        string    `json:"id"`
+
+	// Key The API key. It cannot be retrieved again.
+	Key            string  `json:"key"`
+	Name           string  `json:"name"`
+	OrganisationId *string `json:"organisationId,omitempty"`
+
+	// Prefix First characters of the key, to tell keys apart.
+	Prefix    string     `json:"prefix"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// CreatedApiKeyResponse defines model for CreatedApiKeyResponse.
+type CreatedApiKeyResponse struct {
+	Code    int           `json:"code"`
+	Data    CreatedApiKey `json:"data"`
+	Errors  *interface{}  `json:"errors"`
+	Message string        `json:"message"`
+}
+
+// DronePlan defines model for DronePlan.
+type DronePlan struct {
+	Distance int   `json:"distance"`
+	Rest     *Rest `json:"rest,omitempty"`
+}
+
+// DronePlanResponse defines model for DronePlanResponse.
+type DronePlanResponse struct {
+	Code    int          `json:"code"`
+	Data    DronePlan    `json:"data"`
+	Errors  *interface{} `json:"errors"`
+	Message string       `json:"message"`
+}
+
+// ErrorDetails The fields that failed validation, structured details such as the
+// limit an estate exceeds, or the error message when there are none.
+type ErrorDetails struct {
+	union json.RawMessage
+}
+
+// ErrorDetails0 defines model for .
+type ErrorDetails0 = []FieldError
+
+// ErrorDetails1 defines model for .
+type ErrorDetails1 = map[string]interface{}
+
+// ErrorDetails2 defines model for .
+type ErrorDetails2 = string
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse struct {
+	Code      int          `json:"code"`
+	Data      *interface{} `json:"data"`
+	ErrorCode string       `json:"errorCode"`
+
+	// Errors The fields that failed validation, structured details such as the
+	// limit an estate exceeds, or the error message when there are none.
+	Errors  *ErrorDetails `json:"errors"`
+	Message string        `json:"message"`
+}
+
+// Estate defines model for Estate.
+type Estate struct {
+	// ExternalRef Reference code of the estate in another system, unique among the estates of an organisation.
+	ExternalRef *string `json:"externalRef,omitempty"`
+	Length      int     `json:"length"`
+	Uuid        *string `json:"uuid,omitempty"`
+	Width       int     `json:"width"`
+}
+
+// EstateId defines model for EstateId.
+type EstateId struct {
+	Id string `json:"id"`
+}
+
+// EstateIdResponse defines model for EstateIdResponse.
+type EstateIdResponse struct {
+	Code    int          `json:"code"`
+	Data    EstateId     `json:"data"`
+	Errors  *interface{} `json:"errors"`
+	Message string       `json:"message"`
+}
+
+// EstatePermission defines model for EstatePermission.
+type EstatePermission struct {
+	EstateId  string               `json:"estateId"`
+	GrantedAt time.Time            `json:"grantedAt"`
+	GrantedBy string               `json:"grantedBy"`
+	Role      EstatePermissionRole `json:"role"`
+	Subject   string               `json:"subject"`
+}
+
+// EstatePermissionRole defines model for EstatePermission.Role.
+type EstatePermissionRole string
+
+// EstatePermissionList defines model for EstatePermissionList.
+type EstatePermissionList struct {
+	Permissions []EstatePermission `json:"permissions"`
+}
+
+// EstatePermissionListResponse defines model for EstatePermissionListResponse.
+type EstatePermissionListResponse struct {
+	Code    int                  `json:"code"`
+	Data    EstatePermissionList `json:"data"`
+	Errors  *interface{}         `json:"errors"`
+	Message string               `json:"message"`
+}
+
+// EstatePermissionResponse defines model for EstatePermissionResponse.
+type EstatePermissionResponse struct {
+	Code    int                     `json:"code"`
+	Data    GrantedEstatePermission `json:"data"`
+	Errors  *interface{}            `json:"errors"`
+	Message string                  `json:"message"`
+}
+
+// EstateResponse defines model for EstateResponse.
+type EstateResponse struct {
+	Code    int          `json:"code"`
+	Data    Estate       `json:"data"`
+	Errors  *interface{} `json:"errors"`
+	Message string       `json:"message"`
+}
+
+// ExportEstate defines model for ExportEstate.
+type ExportEstate struct {
+	CreatedAt   time.Time `json:"createdAt"`
+	ExternalRef *string   `json:"externalRef,omitempty"`
+	Length      int       `json:"length"`
+
+	// Permissions Roles held on the estate, omitted when there are none.
+	Permissions *[]EstatePermission `json:"permissions,omitempty"`
+	Trees       []ExportPalmTree    `json:"trees"`
+	Uuid        string              `json:"uuid"`
+	Width       int                 `json:"width"`
+}
+
+// ExportPalmTree defines model for ExportPalmTree.
+type ExportPalmTree struct {
+	Height    int       `json:"height"`
+	Id        int64     `json:"id"`
+	PlantedAt time.Time `json:"plantedAt"`
+	X         int       `json:"x"`
+	Y         int       `json:"y"`
+}
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	Field   string  `json:"field"`
+	Message string  `json:"message"`
+	Param   *string `json:"param,omitempty"`
+	Rule    string  `json:"rule"`
+}
+
+// GrantEstatePermission defines model for GrantEstatePermission.
+type GrantEstatePermission struct {
+	// Role `viewer` reads the estate and its trees. `surveyor` may also
+	// plant trees, and `operator` may also read the drone plan.
+	// `admin` may do both, replace the estate and manage its roles.
+	Role GrantEstatePermissionRole `json:"role"`
+}
+
+// GrantEstatePermissionRole `viewer` reads the estate and its trees. `surveyor` may also
+// plant trees, and `operator` may also read the drone plan.
+// `admin` may do both, replace the estate and manage its roles.
+type GrantEstatePermissionRole string
+
+// GrantedEstatePermission defines model for GrantedEstatePermission.
+type GrantedEstatePermission struct {
+	Created   bool                        `json:"created"`
+	EstateId  string                      `json:"estateId"`
+	GrantedAt time.Time                   `json:"grantedAt"`
+	GrantedBy string                      `json:"grantedBy"`
+	Role      GrantedEstatePermissionRole `json:"role"`
+	Subject   string                      `json:"subject"`
+}
+
+// GrantedEstatePermissionRole defines model for GrantedEstatePermission.Role.
+type GrantedEstatePermissionRole string
+
+// Health defines model for Health.
+type Health struct {
+	Checks *[]HealthCheck `json:"checks,omitempty"`
+	Status HealthStatus   `json:"status"`
+}
+
+// HealthStatus defines model for Health.Status.
+type HealthStatus string
+
+// HealthCheck defines model for HealthCheck.
+type HealthCheck struct {
+	Message *string           `json:"message,omitempty"`
+	Name    HealthCheckName   `json:"name"`
+	Status  HealthCheckStatus `json:"status"`
+}
+
+// HealthCheckName defines model for HealthCheck.Name.
+type HealthCheckName string
+
+// HealthCheckStatus defines model for HealthCheck.Status.
+type HealthCheckStatus string
+
+// HealthResponse defines model for HealthResponse.
+type HealthResponse struct {
+	Code    int          `json:"code"`
+	Data    Health       `json:"data"`
+	Errors  *interface{} `json:"errors"`
+	Message string       `json:"message"`
+}
+
+// ImportPalmTree A palm tree row of an import; range checks are reported per row.
+type ImportPalmTree struct {
+	Height int `json:"height"`
+	X      int `json:"x"`
+	Y      int `json:"y"`
+}
+
+// ImportPalmTreesResponse defines model for ImportPalmTreesResponse.
+type ImportPalmTreesResponse struct {
+	Code    int          `json:"code"`
+	Data    ImportReport `json:"data"`
+	Errors  *interface{} `json:"errors"`
+	Message string       `json:"message"`
+}
+
+// ImportReport defines model for ImportReport.
+type ImportReport struct {
+	Errors   []ImportRowError `json:"errors"`
+	Id       string           `json:"id"`
+	Imported int              `json:"imported"`
+	Mode     string           `json:"mode"`
+	Rejected int              `json:"rejected"`
+	Total    int              `json:"total"`
+}
+
+// ImportRowError defines model for ImportRowError.
+type ImportRowError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Row     int    `json:"row"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+}
+
+// Organisation defines model for Organisation.
+type Organisation struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+
+	// SizePolicy Limits on the dimensions of estates. Zero means unset.
+	SizePolicy SizePolicy `json:"sizePolicy"`
+	Timezone   *string    `json:"timezone,omitempty"`
+}
+
+// OrganisationListResponse defines model for OrganisationListResponse.
+type OrganisationListResponse struct {
+	Code    int            `json:"code"`
+	Data    []Organisation `json:"data"`
+	Errors  *interface{}   `json:"errors"`
+	Message string         `json:"message"`
+}
+
+// OrganisationResponse defines model for OrganisationResponse.
+type OrganisationResponse struct {
+	Code    int                   `json:"code"`
+	Data    PutOrganisationResult `json:"data"`
+	Errors  *interface{}          `json:"errors"`
+	Message string                `json:"message"`
+}
+
+// OutOfBoundsPalmTree defines model for OutOfBoundsPalmTree.
+type OutOfBoundsPalmTree struct {
+	EstateLength int    `json:"estateLength"`
+	EstateWidth  int    `json:"estateWidth"`
+	Height       int    `json:"height"`
+	Id           int64  `json:"id"`
+	Uuid         string `json:"uuid"`
+	X            int    `json:"x"`
+	Y            int    `json:"y"`
+}
+
+// OutOfBoundsPalmTrees defines model for OutOfBoundsPalmTrees.
+type OutOfBoundsPalmTrees struct {
+	Count int                   `json:"count"`
+	Trees []OutOfBoundsPalmTree `json:"trees"`
+}
+
+// OutOfBoundsPalmTreesResponse defines model for OutOfBoundsPalmTreesResponse.
+type OutOfBoundsPalmTreesResponse struct {
+	Code    int                  `json:"code"`
+	Data    OutOfBoundsPalmTrees `json:"data"`
+	Errors  *interface{}         `json:"errors"`
+	Message string               `json:"message"`
+}
+
+// PalmTree defines model for PalmTree.
+type PalmTree struct {
+	Height int `json:"height"`
+	X      int `json:"x"`
+	Y      int `json:"y"`
+}
+
+// PalmTreePage defines model for PalmTreePage.
+type PalmTreePage struct {
+	NextCursor *string          `json:"nextCursor,omitempty"`
+	Trees      []ExportPalmTree `json:"trees"`
+}
+
+// PalmTreePageResponse defines model for PalmTreePageResponse.
+type PalmTreePageResponse struct {
+	Code    int          `json:"code"`
+	Data    PalmTreePage `json:"data"`
+	Errors  *interface{} `json:"errors"`
+	Message string       `json:"message"`
+}
+
+// Problem defines model for Problem.
+type Problem struct {
+	Code   *string `json:"code,omitempty"`
+	Detail *string `json:"detail,omitempty"`
+
+	// Errors The fields that failed validation, structured details such as the
+	// limit an estate exceeds, or the error message when there are none.
+	Errors   *ErrorDetails `json:"errors"`
+	Instance *string       `json:"instance,omitempty"`
+	Status   int           `json:"status"`
+	Title    string        `json:"title"`
+	Type     string        `json:"type"`
+}
+
+// PutEstate defines model for PutEstate.
+type PutEstate struct {
+	Created bool   `json:"created"`
+	Id      string `json:"id"`
+}
+
+// PutEstateResponse defines model for PutEstateResponse.
+type PutEstateResponse struct {
+	Code    int          `json:"code"`
+	Data    PutEstate    `json:"data"`
+	Errors  *interface{} `json:"errors"`
+	Message string       `json:"message"`
+}
+
+// PutOrganisation defines model for PutOrganisation.
+type PutOrganisation struct {
+	Name string `json:"name"`
+
+	// SizePolicy Limits on the dimensions of estates. Zero means unset.
+	SizePolicy *SizePolicy `json:"sizePolicy,omitempty"`
+
+	// Timezone IANA time zone the estates and trees are timestamped in.
+	Timezone *string `json:"timezone,omitempty"`
+}
+
+// PutOrganisationResult defines model for PutOrganisationResult.
+type PutOrganisationResult struct {
+	Created   bool      `json:"created"`
+	CreatedAt time.Time `json:"createdAt"`
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+
+	// SizePolicy Limits on the dimensions of estates. Zero means unset.
+	SizePolicy SizePolicy `json:"sizePolicy"`
+	Timezone   *string    `json:"timezone,omitempty"`
+}
+
+// Rest defines model for Rest.
+type Rest struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// RestoreEstatesResponse defines model for RestoreEstatesResponse.
+type RestoreEstatesResponse struct {
+	Code    int           `json:"code"`
+	Data    RestoreReport `json:"data"`
+	Errors  *interface{}  `json:"errors"`
+	Message string        `json:"message"`
+}
+
+// RestoreReport defines model for RestoreReport.
+type RestoreReport struct {
+	Conflict  string          `json:"conflict"`
+	Created   int             `json:"created"`
+	Estates   []RestoreResult `json:"estates"`
+	Remapped  int             `json:"remapped"`
+	Skipped   int             `json:"skipped"`
+	Total     int             `json:"total"`
+	Unchanged int             `json:"unchanged"`
+}
+
+// RestoreResult defines model for RestoreResult.
+type RestoreResult struct {
+	SourceUuid string              `json:"sourceUuid"`
+	Status     RestoreResultStatus `json:"status"`
+	Trees      int                 `json:"trees"`
+	Uuid       string              `json:"uuid"`
+}
+
+// RestoreResultStatus defines model for RestoreResult.Status.
+type RestoreResultStatus string
+
+// SizePolicy Limits on the dimensions of estates. Zero means unset.
+type SizePolicy struct {
+	// MaxArea Square metres.
+	MaxArea *int `json:"maxArea,omitempty"`
+
+	// MaxLength Plots.
+	MaxLength *int `json:"maxLength,omitempty"`
+
+	// MaxWidth Plots.
+	MaxWidth *int `json:"maxWidth,omitempty"`
+
+	// PlotSize Metres.
+	PlotSize *int `json:"plotSize,omitempty"`
+}
+
+// TreeStats defines model for TreeStats.
+type TreeStats struct {
+	Count  int `json:"count"`
+	Max    int `json:"max"`
+	Median int `json:"median"`
+	Min    int `json:"min"`
+}
+
+// TreeStatsResponse defines model for TreeStatsResponse.
+type TreeStatsResponse struct {
+	Code    int          `json:"code"`
+	Data    TreeStats    `json:"data"`
+	Errors  *interface{} `json:"errors"`
+	Message string       `json:"message"`
+}
+
+// EstateUuid defines model for EstateUuid.
+type EstateUuid = string
+
+// IdempotencyKey defines model for IdempotencyKey.
+type IdempotencyKey = string
+
+// Subject defines model for Subject.
+type Subject = string
+
+// ErrorApplicationJSON defines model for Error.
+type ErrorApplicationJSON = ErrorResponse
+
+// ErrorApplicationProblemPlusJSON defines model for Error.
+type ErrorApplicationProblemPlusJSON = Problem
+
+// RestoreEstatesParams defines parameters for RestoreEstates.
+type RestoreEstatesParams struct {
+	// Conflict What to do when an archived UUID already holds different data:
+	// `fail` stops the restore, `skip` keeps the existing estate and
+	// `remap` restores the archived one under a UUID derived from its own.
+	Conflict *RestoreEstatesParamsConflict `form:"conflict,omitempty" json:"conflict,omitempty"`
+}
+
+// RestoreEstatesParamsConflict defines parameters for RestoreEstates.
+type RestoreEstatesParamsConflict string
+
+// ListAuditEntriesParams defines parameters for ListAuditEntries.
+type ListAuditEntriesParams struct {
+	EstateId *string `form:"estateId,omitempty" json:"estateId,omitempty"`
+	TreeId   *int64  `form:"treeId,omitempty" json:"treeId,omitempty"`
+
+	// Actor API key id or token subject that made the change.
+	Actor  *string                       `form:"actor,omitempty" json:"actor,omitempty"`
+	Action *ListAuditEntriesParamsAction `form:"action,omitempty" json:"action,omitempty"`
+	From   *time.Time                    `form:"from,omitempty" json:"from,omitempty"`
+	To     *time.Time                    `form:"to,omitempty" json:"to,omitempty"`
+	Cursor *string                       `form:"cursor,omitempty" json:"cursor,omitempty"`
+	Limit  *int                          `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ListAuditEntriesParamsAction defines parameters for ListAuditEntries.
+type ListAuditEntriesParamsAction string
+
+// FindEstateByExternalRefParams defines parameters for FindEstateByExternalRef.
+type FindEstateByExternalRefParams struct {
+	ExternalRef string `form:"externalRef" json:"externalRef"`
+}
+
+// CreateEstateParams defines parameters for CreateEstate.
+type CreateEstateParams struct {
+	// IdempotencyKey Client chosen key making a retried request safe. A repeated key
+	// replays the stored response with an `Idempotent-Replayed: true`
+	// header; reusing it for a different request fails with 422, and
+	// while the first request is still running with 409.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetDroneFlyingDistanceParams defines parameters for GetDroneFlyingDistance.
+type GetDroneFlyingDistanceParams struct {
+	// MaxDistance Battery limit of the drone; the response reports where it has to land.
+	MaxDistance *int `form:"max-distance,omitempty" json:"max-distance,omitempty"`
+}
+
+// PlantPalmTreeParams defines parameters for PlantPalmTree.
+type PlantPalmTreeParams struct {
+	// IdempotencyKey Client chosen key making a retried request safe. A repeated key
+	// replays the stored response with an `Idempotent-Replayed: true`
+	// header; reusing it for a different request fails with 422, and
+	// while the first request is still running with 409.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// ListPalmTreesParams defines parameters for ListPalmTrees.
+type ListPalmTreesParams struct {
+	MinHeight     *int       `form:"minHeight,omitempty" json:"minHeight,omitempty"`
+	MaxHeight     *int       `form:"maxHeight,omitempty" json:"maxHeight,omitempty"`
+	MinX          *int       `form:"minX,omitempty" json:"minX,omitempty"`
+	MaxX          *int       `form:"maxX,omitempty" json:"maxX,omitempty"`
+	MinY          *int       `form:"minY,omitempty" json:"minY,omitempty"`
+	MaxY          *int       `form:"maxY,omitempty" json:"maxY,omitempty"`
+	PlantedAfter  *time.Time `form:"plantedAfter,omitempty" json:"plantedAfter,omitempty"`
+	PlantedBefore *time.Time `form:"plantedBefore,omitempty" json:"plantedBefore,omitempty"`
+
+	// Sort Sort key, prefixed with `-` for descending order. Ties are broken by id.
+	Sort   *ListPalmTreesParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+	Cursor *string                  `form:"cursor,omitempty" json:"cursor,omitempty"`
+	Limit  *int                     `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ListPalmTreesParamsSort defines parameters for ListPalmTrees.
+type ListPalmTreesParamsSort string
+
+// ImportPalmTreesJSONBody defines parameters for ImportPalmTrees.
+type ImportPalmTreesJSONBody = []ImportPalmTree
+
+// ImportPalmTreesParams defines parameters for ImportPalmTrees.
+type ImportPalmTreesParams struct {
+	// Mode `atomic` rejects the whole import when any row is invalid,
+	// `best-effort` plants the valid rows and reports the others.
+	Mode *ImportPalmTreesParamsMode `form:"mode,omitempty" json:"mode,omitempty"`
+
+	// IdempotencyKey Client chosen key making a retried request safe. A repeated key
+	// replays the stored response with an `Idempotent-Replayed: true`
+	// header; reusing it for a different request fails with 422, and
+	// while the first request is still running with 409.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// ImportPalmTreesParamsMode defines parameters for ImportPalmTrees.
+type ImportPalmTreesParamsMode string
+
+// CreateApiKeyJSONRequestBody defines body for CreateApiKey for application/json ContentType.
+type CreateApiKeyJSONRequestBody = CreateApiKey
+
+// PutOrganisationJSONRequestBody defines body for PutOrganisation for application/json ContentType.
+type PutOrganisationJSONRequestBody = PutOrganisation
+
+// CreateEstateJSONRequestBody defines body for CreateEstate for application/json ContentType.
+type CreateEstateJSONRequestBody = Estate
+
+// PutEstateJSONRequestBody defines body for PutEstate for application/json ContentType.
+type PutEstateJSONRequestBody = Estate
+
+// GrantEstatePermissionJSONRequestBody defines body for GrantEstatePermission for application/json ContentType.
+type GrantEstatePermissionJSONRequestBody = GrantEstatePermission
+
+// PlantPalmTreeJSONRequestBody defines body for PlantPalmTree for application/json ContentType.
+type PlantPalmTreeJSONRequestBody = PalmTree
+
+// ImportPalmTreesJSONRequestBody defines body for ImportPalmTrees for application/json ContentType.
+type ImportPalmTreesJSONRequestBody = ImportPalmTreesJSONBody
+
+// AsErrorDetails0 returns the union data inside the ErrorDetails as a ErrorDetails0
+func (t ErrorDetails) AsErrorDetails0() (ErrorDetails0, error) {
+	var body ErrorDetails0
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorDetails0 overwrites any union data inside the ErrorDetails as the provided ErrorDetails0
+func (t *ErrorDetails) FromErrorDetails0(v ErrorDetails0) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorDetails0 performs a merge with any union data inside the ErrorDetails, using the provided ErrorDetails0
+func (t *ErrorDetails) MergeErrorDetails0(v ErrorDetails0) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorDetails1 returns the union data inside the ErrorDetails as a ErrorDetails1
+func (t ErrorDetails) AsErrorDetails1() (ErrorDetails1, error) {
+	var body ErrorDetails1
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorDetails1 overwrites any union data inside the ErrorDetails as the provided ErrorDetails1
+func (t *ErrorDetails) FromErrorDetails1(v ErrorDetails1) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorDetails1 performs a merge with any union data inside the ErrorDetails, using the provided ErrorDetails1
+func (t *ErrorDetails) MergeErrorDetails1(v ErrorDetails1) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorDetails2 returns the union data inside the ErrorDetails as a ErrorDetails2
+func (t ErrorDetails) AsErrorDetails2() (ErrorDetails2, error) {
+	var body ErrorDetails2
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorDetails2 overwrites any union data inside the ErrorDetails as the provided ErrorDetails2
+func (t *ErrorDetails) FromErrorDetails2(v ErrorDetails2) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorDetails2 performs a merge with any union data inside the ErrorDetails, using the provided ErrorDetails2
+func (t *ErrorDetails) MergeErrorDetails2(v ErrorDetails2) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t ErrorDetails) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *ErrorDetails) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// List API keys, including revoked ones.
+	// (GET /admin/api-keys)
+	ListApiKeys(ctx echo.Context) error
+	// Issue an API key.
+	// (POST /admin/api-keys)
+	CreateApiKey(ctx echo.Context) error
+	// Revoke an API key.
+	// (DELETE /admin/api-keys/{keyId})
+	RevokeApiKey(ctx echo.Context, keyId string) error
+	// Stream a backup archive of every estate and its trees.
+	// (GET /admin/backup)
+	BackupEstates(ctx echo.Context) error
+	// List organisations.
+	// (GET /admin/organisations)
+	ListOrganisations(ctx echo.Context) error
+	// Create an organisation or replace its settings.
+	// (PUT /admin/organisations/{organisationId})
+	PutOrganisation(ctx echo.Context, organisationId string) error
+	// Restore the estates of a backup archive.
+	// (POST /admin/restore)
+	RestoreEstates(ctx echo.Context, params RestoreEstatesParams) error
+	// List the audit log of the organisation a page at a time.
+	// (GET /audit)
+	ListAuditEntries(ctx echo.Context, params ListAuditEntriesParams) error
+	// Find the estate with an external reference code.
+	// (GET /estate)
+	FindEstateByExternalRef(ctx echo.Context, params FindEstateByExternalRefParams) error
+	// Create an estate.
+	// (POST /estate)
+	CreateEstate(ctx echo.Context, params CreateEstateParams) error
+	// Create or replace an estate under a client chosen id.
+	// (PUT /estate/{id})
+	PutEstate(ctx echo.Context, id string) error
+	// Get the flying distance of a drone monitoring an estate.
+	// (GET /estate/{id}/drone-plan)
+	GetDroneFlyingDistance(ctx echo.Context, id EstateUuid, params GetDroneFlyingDistanceParams) error
+	// List who holds a role on an estate.
+	// (GET /estate/{id}/permissions)
+	ListEstatePermissions(ctx echo.Context, id EstateUuid) error
+	// Take away the role a principal holds on an estate.
+	// (DELETE /estate/{id}/permissions/{subject})
+	RevokeEstatePermission(ctx echo.Context, id EstateUuid, subject Subject) error
+	// Give a principal a role on an estate.
+	// (PUT /estate/{id}/permissions/{subject})
+	GrantEstatePermission(ctx echo.Context, id EstateUuid, subject Subject) error
+	// Get statistics of the trees in an estate.
+	// (GET /estate/{id}/stats)
+	GetTreeStats(ctx echo.Context, id EstateUuid) error
+	// Plant a palm tree in an estate.
+	// (POST /estate/{id}/tree)
+	PlantPalmTree(ctx echo.Context, id EstateUuid, params PlantPalmTreeParams) error
+	// List the palm trees of an estate a page at a time.
+	// (GET /estate/{id}/trees)
+	ListPalmTrees(ctx echo.Context, id EstateUuid, params ListPalmTreesParams) error
+	// Stream the trees of an estate as CSV.
+	// (GET /estate/{id}/trees.csv)
+	ExportPalmTreesCsv(ctx echo.Context, id EstateUuid) error
+	// Plant many palm trees from a CSV or JSON survey.
+	// (POST /estate/{id}/trees:import)
+	ImportPalmTrees(ctx echo.Context, id EstateUuid, params ImportPalmTreesParams) error
+	// Stream every estate with its trees as newline delimited JSON.
+	// (GET /export.ndjson)
+	ExportEstatesNdjson(ctx echo.Context) error
+	// Report that the process is up.
+	// (GET /healthz)
+	GetLiveness(ctx echo.Context) error
+	// Report palm trees planted outside the bounds of their estate.
+	// (GET /maintenance/out-of-bounds-trees)
+	FindOutOfBoundsPalmTrees(ctx echo.Context) error
+	// Report whether the instance can serve requests.
+	// (GET /readyz)
+	GetReadiness(ctx echo.Context) error
+}
+
+// ServerInterfaceWrapper converts echo contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
+}
+
+// ListApiKeys converts echo context to params.
+func (w *ServerInterfaceWrapper) ListApiKeys(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListApiKeys(ctx)
+	return err
+}
+
+// CreateApiKey converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateApiKey(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CreateApiKey(ctx)
+	return err
+}
+
+// RevokeApiKey converts echo context to params.
+func (w *ServerInterfaceWrapper) RevokeApiKey(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "keyId" -------------
+	var keyId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "keyId", ctx.Param("keyId"), &keyId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter keyId: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RevokeApiKey(ctx, keyId)
+	return err
+}
+
+// BackupEstates converts echo context to params.
+func (w *ServerInterfaceWrapper) BackupEstates(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.BackupEstates(ctx)
+	return err
+}
+
+// ListOrganisations converts echo context to params.
+func (w *ServerInterfaceWrapper) ListOrganisations(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListOrganisations(ctx)
+	return err
+}
+
+// PutOrganisation converts echo context to params.
+func (w *ServerInterfaceWrapper) PutOrganisation(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "organisationId" -------------
+	var organisationId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "organisationId", ctx.Param("organisationId"), &organisationId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter organisationId: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PutOrganisation(ctx, organisationId)
+	return err
+}
+
+// RestoreEstates converts echo context to params.
+func (w *ServerInterfaceWrapper) RestoreEstates(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params RestoreEstatesParams
+	// ------------- Optional query parameter "conflict" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "conflict", ctx.QueryParams(), &params.Conflict)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter conflict: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RestoreEstates(ctx, params)
+	return err
+}
+
+// ListAuditEntries converts echo context to params.
+func (w *ServerInterfaceWrapper) ListAuditEntries(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListAuditEntriesParams
+	// ------------- Optional query parameter "estateId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "estateId", ctx.QueryParams(), &params.EstateId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter estateId: %s", err))
+	}
+
+	// ------------- Optional query parameter "treeId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "treeId", ctx.QueryParams(), &params.TreeId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter treeId: %s", err))
+	}
+
+	// ------------- Optional query parameter "actor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "actor", ctx.QueryParams(), &params.Actor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter actor: %s", err))
+	}
+
+	// ------------- Optional query parameter "action" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "action", ctx.QueryParams(), &params.Action)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter action: %s", err))
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", ctx.QueryParams(), &params.From)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter from: %s", err))
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", ctx.QueryParams(), &params.To)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter to: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListAuditEntries(ctx, params)
+	return err
+}
+
+// FindEstateByExternalRef converts echo context to params.
+func (w *ServerInterfaceWrapper) FindEstateByExternalRef(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params FindEstateByExternalRefParams
+	// ------------- Required query parameter "externalRef" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "externalRef", ctx.QueryParams(), &params.ExternalRef)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter externalRef: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.FindEstateByExternalRef(ctx, params)
+	return err
+}
+
+// CreateEstate converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateEstate(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CreateEstateParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CreateEstate(ctx, params)
+	return err
+}
+
+// PutEstate converts echo context to params.
+func (w *ServerInterfaceWrapper) PutEstate(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PutEstate(ctx, id)
+	return err
+}
+
+// GetDroneFlyingDistance converts echo context to params.
+func (w *ServerInterfaceWrapper) GetDroneFlyingDistance(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id EstateUuid
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDroneFlyingDistanceParams
+	// ------------- Optional query parameter "max-distance" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "max-distance", ctx.QueryParams(), &params.MaxDistance)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter max-distance: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetDroneFlyingDistance(ctx, id, params)
+	return err
+}
+
+// ListEstatePermissions converts echo context to params.
+func (w *ServerInterfaceWrapper) ListEstatePermissions(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id EstateUuid
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListEstatePermissions(ctx, id)
+	return err
+}
+
+// RevokeEstatePermission converts echo context to params.
+func (w *ServerInterfaceWrapper) RevokeEstatePermission(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id EstateUuid
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "subject" -------------
+	var subject Subject
+
+	err = runtime.BindStyledParameterWithOptions("simple", "subject", ctx.Param("subject"), &subject, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter subject: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RevokeEstatePermission(ctx, id, subject)
+	return err
+}
+
+// GrantEstatePermission converts echo context to params.
+func (w *ServerInterfaceWrapper) GrantEstatePermission(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id EstateUuid
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "subject" -------------
+	var subject Subject
+
+	err = runtime.BindStyledParameterWithOptions("simple", "subject", ctx.Param("subject"), &subject, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter subject: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GrantEstatePermission(ctx, id, subject)
+	return err
+}
+
+// GetTreeStats converts echo context to params.
+func (w *ServerInterfaceWrapper) GetTreeStats(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id EstateUuid
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetTreeStats(ctx, id)
+	return err
+}
+
+// PlantPalmTree converts echo context to params.
+func (w *ServerInterfaceWrapper) PlantPalmTree(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id EstateUuid
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PlantPalmTreeParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PlantPalmTree(ctx, id, params)
+	return err
+}
+
+// ListPalmTrees converts echo context to params.
+func (w *ServerInterfaceWrapper) ListPalmTrees(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id EstateUuid
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListPalmTreesParams
+	// ------------- Optional query parameter "minHeight" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minHeight", ctx.QueryParams(), &params.MinHeight)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter minHeight: %s", err))
+	}
+
+	// ------------- Optional query parameter "maxHeight" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "maxHeight", ctx.QueryParams(), &params.MaxHeight)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter maxHeight: %s", err))
+	}
+
+	// ------------- Optional query parameter "minX" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minX", ctx.QueryParams(), &params.MinX)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter minX: %s", err))
+	}
+
+	// ------------- Optional query parameter "maxX" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "maxX", ctx.QueryParams(), &params.MaxX)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter maxX: %s", err))
+	}
+
+	// ------------- Optional query parameter "minY" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minY", ctx.QueryParams(), &params.MinY)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter minY: %s", err))
+	}
+
+	// ------------- Optional query parameter "maxY" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "maxY", ctx.QueryParams(), &params.MaxY)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter maxY: %s", err))
+	}
+
+	// ------------- Optional query parameter "plantedAfter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "plantedAfter", ctx.QueryParams(), &params.PlantedAfter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter plantedAfter: %s", err))
+	}
+
+	// ------------- Optional query parameter "plantedBefore" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "plantedBefore", ctx.QueryParams(), &params.PlantedBefore)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter plantedBefore: %s", err))
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", ctx.QueryParams(), &params.Sort)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sort: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListPalmTrees(ctx, id, params)
+	return err
+}
+
+// ExportPalmTreesCsv converts echo context to params.
+func (w *ServerInterfaceWrapper) ExportPalmTreesCsv(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id EstateUuid
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ExportPalmTreesCsv(ctx, id)
+	return err
+}
+
+// ImportPalmTrees converts echo context to params.
+func (w *ServerInterfaceWrapper) ImportPalmTrees(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id EstateUuid
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ImportPalmTreesParams
+	// ------------- Optional query parameter "mode" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "mode", ctx.QueryParams(), &params.Mode)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter mode: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ImportPalmTrees(ctx, id, params)
+	return err
+}
+
+// ExportEstatesNdjson converts echo context to params.
+func (w *ServerInterfaceWrapper) ExportEstatesNdjson(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ExportEstatesNdjson(ctx)
+	return err
+}
+
+// GetLiveness converts echo context to params.
+func (w *ServerInterfaceWrapper) GetLiveness(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetLiveness(ctx)
+	return err
+}
+
+// FindOutOfBoundsPalmTrees converts echo context to params.
+func (w *ServerInterfaceWrapper) FindOutOfBoundsPalmTrees(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.FindOutOfBoundsPalmTrees(ctx)
+	return err
+}
+
+// GetReadiness converts echo context to params.
+func (w *ServerInterfaceWrapper) GetReadiness(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetReadiness(ctx)
+	return err
+}
+
+// This is a simple interface which specifies echo.Route addition functions which
+// are present on both echo.Echo and echo.Group, since we want to allow using
+// either of them for path registration
+type EchoRouter interface {
+	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterHandlers adds each server route to the EchoRouter.
+func RegisterHandlers(router EchoRouter, si ServerInterface) {
+	RegisterHandlersWithBaseURL(router, si, "")
+}
+
+// Registers handlers, and prepends BaseURL to the paths, so that the paths
+// can be served under a prefix.
+func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL string) {
+
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	router.GET(baseURL+"/admin/api-keys", wrapper.ListApiKeys)
+	router.POST(baseURL+"/admin/api-keys", wrapper.CreateApiKey)
+	router.DELETE(baseURL+"/admin/api-keys/:keyId", wrapper.RevokeApiKey)
+	router.GET(baseURL+"/admin/backup", wrapper.BackupEstates)
+	router.GET(baseURL+"/admin/organisations", wrapper.ListOrganisations)
+	router.PUT(baseURL+"/admin/organisations/:organisationId", wrapper.PutOrganisation)
+	router.POST(baseURL+"/admin/restore", wrapper.RestoreEstates)
+	router.GET(baseURL+"/audit", wrapper.ListAuditEntries)
+	router.GET(baseURL+"/estate", wrapper.FindEstateByExternalRef)
+	router.POST(baseURL+"/estate", wrapper.CreateEstate)
+	router.PUT(baseURL+"/estate/:id", wrapper.PutEstate)
+	router.GET(baseURL+"/estate/:id/drone-plan", wrapper.GetDroneFlyingDistance)
+	router.GET(baseURL+"/estate/:id/permissions", wrapper.ListEstatePermissions)
+	router.DELETE(baseURL+"/estate/:id/permissions/:subject", wrapper.RevokeEstatePermission)
+	router.PUT(baseURL+"/estate/:id/permissions/:subject", wrapper.GrantEstatePermission)
+	router.GET(baseURL+"/estate/:id/stats", wrapper.GetTreeStats)
+	router.POST(baseURL+"/estate/:id/tree", wrapper.PlantPalmTree)
+	router.GET(baseURL+"/estate/:id/trees", wrapper.ListPalmTrees)
+	router.GET(baseURL+"/estate/:id/trees.csv", wrapper.ExportPalmTreesCsv)
+	router.POST(baseURL+"/estate/:id/trees:import", wrapper.ImportPalmTrees)
+	router.GET(baseURL+"/export.ndjson", wrapper.ExportEstatesNdjson)
+	router.GET(baseURL+"/healthz", wrapper.GetLiveness)
+	router.GET(baseURL+"/maintenance/out-of-bounds-trees", wrapper.FindOutOfBoundsPalmTrees)
+	router.GET(baseURL+"/readyz", wrapper.GetReadiness)
+
+}
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+w923bjuJG/gsPNw+4JJbsvk5NxP7l7LvFkMu21O5nstrwRTJYkjEmAA4CWNb3+9z0o",
+	"ACRIgbrYlrs3u3nItEUCKBQKda/ipyQTZSU4cK2Sk09JRSUtQYPEv75Vmmr4a81y81cOKpOs0kzw5MQ9",
+	"I3/969k34yRNmPmtonqRpAmnJSQnCcuTNJHwa80k5MmJljWkicoWUFIznV5V5i2lJePz5P4+Tc5yKCuh",
+	"gWerP8Nqfcl3BQOuSbYQCji5gRUp6Q3jc0KJBC0Z5MQsB0oTRWcwJqdEQgVUQ27ennAJVUFXiugFEKWF",
+	"xAGqElwBWTK9IJSTaQOFHl3g+5CfEAP8dMIXQHOQb4iEWpmFmSYzIQklOZvNQBroPAQzygplZ3398mVK",
+	"KM8nfLlgBeDyMyZV+zJTRGlWFETWnJuJ7bjjr8cT7pFr127RG2BrZNAV4rakdz8Cn+tFcvLyq6/SpGTc",
+	"//0ijWD+sr7+BTK9jvJzyXjGKlog0FIUYGCdCZmSX2tasJlB+vWKLMTSIIPWegFcs4xqUCcTPqUV+8cN",
+	"rE4m9fHxq8wcGcvx3zAlQpLpL0vtnmlxA5yo+to9DnbeJSvlYN1EW/39V1RrkGau//pXB9J//7LU/3Yy",
+	"/v3vknWE3KeJpwt7D6QU0vwjE1wDRzzRqirMPpngR78og6xPAQC/kzBLTpJ/OWqv15F9qo5wtgs3P6I/",
+	"nKuS4rqA8vf7zXluR1nYexfVLDfGddzbZrLTirk7VklRgdTMbjWTeF9OcY8zIUuqk5MkpxpGmpWwjqzU",
+	"D3m7itzqNGF59Gd7mJEHQs4pZwqRcRYfW0mYsbt1Yv0O71S2oJJmGqQiYoZkewOrlGhBNBSF+UMRWlGp",
+	"x7HNSLgVN/vs/z6kw4+W6eHmGjhDDKUBgq+auYSl6PvUHcuPTOmGQNaPSOQh6hjXMAdpRudUI6UwDaXa",
+	"RjJ2KTPMTUSlpPg3GIrB8bwuCnpdgL1h92lSglJ0DnH+HeIBgWzfd7BFt1znTH/LtYxQI83s0UaIgGZa",
+	"yPiTmQa5Th0fFmBog8+R62dC5gTfJLp58IbQawXcMnUJpbilhRqbOa9hJiTsNKl9dWhWPH4muJ32AbcN",
+	"UO6e5RsuWzMR4/oPr5M0QilO8AxMoyXAWa46pLTDpF06il0Me2ipP9ett8GQxrmjty5lANeSQRfEjdTe",
+	"UlmE4jnc6Xe1VFGK6m3Er7wR4Mfc3q3bQIw8+z19h0c1JDc8P+9ej58XVHsW3CgOqs4WhFotLLMqndem",
+	"DEsOZPeL4+MtuktMXnRBeB88b0ChmUZg3pBpDjNaF3pKlgvgRJRMa8h7cPzh9Ta2j9sfxlreoo0WxftZ",
+	"cvJxR+bcx/MNrOJc6PT8zGxtTM40ySjnQpNrcHrxLeSEzinj463yy8y/vo+r/k4ORd9ddD07jX8jBYfz",
+	"gvL1feVMacqzgb1JUHrb3i7MO33Ymmk3gnModLf7fXZUo1r6DWhjJsUpesagyA2boNaagpzc0oLleJVT",
+	"orSsM10bIy6304ScZcILVhqLhBMrMQncZQC5So3dYfgAbpc4SO3t1wuQQKgEwgUHa4F0cZEmgoO7vzvJ",
+	"ne/MJnCvEbnzaQ0ta/i98qh6DAmsnSfu/V13XKBiNISw1YzxB/goGmmhiRIKHt/6tuFOg+S0uIDZOv1c",
+	"AJrjGRCzmjcEHCUwTigX5rCJWikNZUpqzn6tgdBS8HnwKpoQlJNQyERkwxYRVbhnaJeysi5Rspn/4VD3",
+	"S0yjqusB82nJ8ofM2DsJB5ifbhj7Z/k6/qOQrSt9myY9FFtrgH5+roYrn4MsmVLOdOmR7Sb9fS4p39Me",
+	"cEMGrG8pCtwfcEMRH5NbBkuQSZqoWt7CCpVxAx11enleMh5srJ1ItR6izbhq9peGnhoDRghruNVd8Ggs",
+	"4nVcVs3z3Q2BtRPaZriEi+wK6mEJu4eWz07kh9rt95ZEYif2OTZ82EP9DLu6q4TUQ+L1Ia6JrkTeIAfX",
+	"EdW7yj1pLgpQZAFFTpwRZ7lM6u21qPqWpE/FEaxHZA8eg5g9p0X5QQLE5tsu2LeIbpwg7Uvw0KHigR4+",
+	"+gbAtcNfAJsvdPykdvYxVcXewuwuvuRqB4QgOoyz1QgXB38IQwwNgXK+hgK0PqJHNHwVUxs/iz6RdbHD",
+	"7bWrurfbpWLAI3fcrm94DaB7o6ZWEZgSCTRXoXJMeU6YVgSJZ0ymXlGYkpKuCC2UmHDEqn0DA1tk6nWI",
+	"9i2cGSfOpeBAzJixiQgZFcO+lgtyLfQiJRiXy6APRkm5Mc0MNGYXyhpkj1RleghH9AyiNyJ8dnbhRNjK",
+	"AI8NyOJaiAIoXwPTvxl3zfwJaGG5Rm/+BWQ3u7MtO807MyjGs8x+ahVqkxVVKkkTY5xvx7QbHsN1uPDa",
+	"JjZdN+919ACpRa1zseRO7F1TBW1cNKrWPm5PLtCzdWuHUh3cwT+76nBW9uVHl72ckooWJXIIIsXSWdEM",
+	"R70hkvI5EEucKK4lmAeQkwqkeX+cpD00bRJIjxAaPXmxfavqUCdpl7lARHyu83Srr+2shWUnNuImE8sh",
+	"t9dQTNjSB+RxLJZD7ioJZhtDw7TQtNhVfygtxuyYAKBgkeZoNuDRb30bjeymU0ixfHLCN3O26lKPUGI7",
+	"C8MoT2Is7JsXoNhvcC4Klq220d9l+6Y5f1bCb4LDTs6qJnQfrLYtSBli5lkC952j+Ozh+xCaQzHH81r3",
+	"lqmLz8Al39f6/eytqHmuhk0nq7/+uMHUtW/8PGTopU9jfQ1amI+1sZzluWZqdTbe3eWOyFQxuqn5ACb2",
+	"M8ljZ7fN82cX32RHxzZxqEsQRdiz34FdfAZNTOLV1gAHEuPmV1bbXtlfp/ObiKeWbEwGeWpXUA/4YVIL",
+	"YT4Ynw3x8vyk5XIYd9eabNz3CWOYjK8F+WO2YoQZMV3ER9kftmEJn/ppNhqU5/U2l23MnZDuGq5LN7ga",
+	"gsUPKOk/lzu8p2QMpzjtmaD0FIpr17o+O/3plJjHxDzvhKuN2wx5CNrV5h2laVlBTnZJ/hnMYoorYDu7",
+	"wnoa66HcYBcQCxI+2j0QQ4hZSkiwtHowke9W+Vxege7ykb3xWcGiMeE0cqZrGvDuQrQBxOv9fZNHQkmr",
+	"amg5dcOGHw76B9Kk5i61dwdSaZDReg88CgLwwjlbuFqEbDwFf+e6p6BELbOmRmcHB+ceYDWbirlOGz1o",
+	"Z9ujh7IA8saqcOBu0rkvO/y0yxl/ZCXTygcJc1YCx5CicUA6HI/Jf4IUpATKFam5Ar3ubSzp3akEuj7/",
+	"5a+14aslaAnKjGv00uOY6hpIirXCmkLo3WZoDMUHTVAVQhuMrU/wl502cR85AaMiXmqq97PXSjrAiUvI",
+	"GeUDzxjf6fJZQ80sYcc0s15tgv9QfLtF0DPzbHPhIasl06tLAwsEtT6ndYyMXNowYUrVYOJ1UtTzBTnC",
+	"8NkRrdjIFMyMh4rQ/j46PT9z5WeeKTe5u2+BSpB+3Wv86zvvufjh5w9Jv1Tph58/EMXm3NaUUQuYvcuG",
+	"FbE5Znv+8POfL8fEZIhOVX09JVlBWUkMQC7zM6NFYar0euNwS0RlosIUwCn+a0owEUm5p9LYjC7QiKeJ",
+	"2ghC3u5woXVlK60Yn4lIaeQtyJUPZF5DIfhcES36iYQ2dOoqMQzTKlZEAdg9BPmHZhuil8WOKewmg/3E",
+	"PuaACEC86TYbfMJdsutUyLnHlJsSC+4wG7aXA8+BYW6kxahNhD012GFKY3RV2SBv5oofXSplB8RrsxWe",
+	"26x+RZjRPyccIfn7KFQHR2f5lPiySspXZidQKGiGh7WVZhplyjhntYLcF0m+Gk/4hF94PGJAyWAeE4Bd",
+	"UMnVGdA8l6BUir950hfS4mLCXcKaPZgSq3gMPRQrI1HgrgKu2C0Q4HklGNdtwnEb4lYTbgYDmv1qTCwt",
+	"NFWmGZWSgSLTC+MrMwCO8P+n6YQHv11ASRlnfD614fXwiQLtMabeBORzC5LQJu3ZIKGDppdf41SUTC9A",
+	"y9XodKZB+om6GFzQWzB4B5oXjENKDAWbE7ZXMYIIW2qLwye8W8lKNVKAnyysi/3q+DWZ+gf/sPnZkE9d",
+	"razBaGaM8QKaoiqmiWG3BWjI20ISR/PujHOmMsE5ZJrxeTrhbqlXZOog/Ief1dWYFiwDJwQcV/vL2YfA",
+	"qPelzpcgb1kGSZrcgrS5AMmL8fH42LwrKuC0YslJ8gp/wprTBbLfHi81P81Bx3KWkeEbI47Q8L6Nm8wG",
+	"V+OSmDiD5eoq6dWqvjw+frJK1Ug1YqTA1N0jW9H2+vjF0KwNmEdNYPD18as93nZsaucRmLZallSuHMr8",
+	"lVcpYTwramQwruLTMDqUc5rOlZG5LoHE6FBC7XVcKJtQdmkFxYwwx9wl6FpytMWJXlhOZkG2skpZAiM3",
+	"AJUihq0a+K5re4MWVC0swXZpoVOX1VT2vRX56snooLPEfVc5capMjwZfPPHa/bKjCBkapHNYYhGUpa3j",
+	"vSjxC6XbM6VqMDTmC7wiJHqf9pnM0acbWJ3l95ZsC9CwFwFfuDthpiJKi4oshbxx7FzwDNZZkh3SkGHY",
+	"SOLjoNKZD7SOQOD36h5xtUaCr2M7xm0dnFG9Pn69x9uPIg+7pZ3p45pmN3U1KILMHaIyWxjhzpS5Tiiz",
+	"c/C61A+X7386IdSpDQSfooBFpdUPteHJlDg5SV6mTd2z9VuimzKi1rZ6r1WbiRapnR9TEp1HFIcbtyqp",
+	"0A+QmqHcMPAJD5OaSeVATFHvWjFX2yMH0onHEz58JyKc9y0i0661pxy+G/F8nQ9G+lB0z8eu6I9o/Gjq",
+	"udQSaEkoue5MjO6S0IjpJoVupLDwSJ9M13nfmfSAGs9gMkfkMDpA/S9QfkQX3p0P8ehTt8YahUpV76cS",
+	"GR+UtcgUKWCmjSD5DaSwRgknUFZ6NeHNFZ/RokCqxMYdgXK0ZHlr1duCvMjV7Adztgik8F3C8pRQUogl",
+	"yIwqIKqo5wNyqouYjQIraEHzkY5+Ox59feX+O7r6dJz+4dV9rBPN1WEUuj56dtLpDnPLdr1hPkncSu+n",
+	"1DAfBI3zov8zaZpW0+57qYiQHvUoAhRoY1hvYSDSBi2Q8KPW07c+YImOCteM63qF/cRSAjRbGCvJLCiW",
+	"nGhJubJNQxp1YMJtUQA5bUq8lwuhbEsyQgsJNDeNqQpX36BoCcT4TQmzLGjCm6hHSpRoX/JSMKOcXAfg",
+	"YQsFbC1WrNDIC/hVTyXxbks/ldOXzG6RUCF3fG3CO8hu1mJcC+/1oNwL4jE5tROiY9CrVy9S7CyWLaya",
+	"4x14qV0uy6AylEr20m26Ac5t/NP2+RCmogNdiGYFC2geO47Wo2fOw/QJM16hKVoa9rAcHlIyNdGoqTOJ",
+	"rfeJKUOAgWoy4VMMZU39MBXiHi17UnPEv4UmB4kPZlKUnsaCfmO/1iBXLZMPQnstQ2lumq0UaMtS3J8G",
+	"bB9iS64ewdh31hSfj30PBL8jLNO96RL8H8Qsv34+S8rC2q/+7+nGg3zPdOZ5gL7rPMSWEZk75NZOXX3V",
+	"hAelkWnjwUKG01HTDFuzLai8z3e5EKSkOUw406m7Vq7foCZLqvCh7YaQu8dhByvKXYes8YR/a5seIUcp",
+	"mDKmoChyMxU2MnxDKqrsrZu26XNTi7+KzoFQNeHTzP2sBZmBztBqJOZ9vKLejrRMeMYKDdJUoJlbOkXH",
+	"Pzrt0POMXnEtpqarh/0pxsTQTeobP7EYG4vd97CCffDCpfGxtnNWZ+TWXN37tE8trX+kCU4QF5uw7VDw",
+	"4NoOY+MBzuXbbe29jaY/VzvS8zcniKz+06QujJ2KEP5gNQAbyh9jyaD/w9ZVJGHV7xjDcN2frGc2SRNa",
+	"MePZaNZ0fzfPO+05jGVyle66U0Nc8ePa2PNv4PTFk01lr8pDzg6NrLissulqYceQLe1Crg4ZXFjrlRaL",
+	"LVjuYfiIeZ245mv/TGo32uaor+AOCzGPBn09H9WEoiNsSA5BkxzqBFGXJX7HuKtpfbv6NqjX340zdgbs",
+	"1H51a3ucg9JYL1l1IGrgONZDaOrZ/Lzm3ML6aN+u2B+JifYGHY9C6miSy4JQViyEZJG1TgkxYNtXjnpd",
+	"mw/lsnDQPXP0aa1TUazBrj2Rx3gE9lFyX798+fweAX9FYlTVcp2jT2yDd9DOptao2Pw9Z7fAnfHfOhxU",
+	"L43P51askbytL25MQ5OzQi4XknEbs/LwT7gJKyzRoBYztPxcgwiXNI0qtC30dBlGJi9x6hnytchXE84U",
+	"YXPurHQyo7eilv4N4x5EjwTLG5u3Vi6fqQVDzOJpM8H6vRbga+7Nods62KU9DVyaOPdiVS2Aq4d1bw/d",
+	"mcejr+lodvXpj/ej5t+vd/j3i5fP6fPch4EcP6WndbsQcqd0MA/nPkA8Fxt7CsYUuCaby9V4erLO1wJY",
+	"vhPzOsJMrlHlWoBGVajvQWPfzO8KE1H8xjfw3FduBh9XWLcA3+L1WtmIiWcuCNsb7x9D9DjPijJuN5sf",
+	"taCYa1hQng+ZhSW9G+Ut2IHetikR+JB62nqj0wiBftP2kfmiVbXvwerzM6QP4jFtnSF4hqQUnGkhO8Jp",
+	"N/rs9cna7GxCq8LmtYoCesHuqLek37RGPYqsD6/aD7Tdi7kih4L+/0TJIGhKGq+fdbPT5tijRNZpbLiJ",
+	"0I4+OefTbulE+1GdTWDpn+ZjuemWt/33Tv7Ppg19oCZpaElX7XddKKmaj71Y+tmNcNItuQAbyYFchJo+",
+	"voBBsQAUKLxHOqYJx7ugPSf1PL2mGt/TMyuug00+B3jr4bTXvSFxrWW/MCfhc2og7LZ7nx8lCZQv9RrS",
+	"httypy9VWViv+Iq5AiUAMQAxpVmmxs99aKCD1ZsqHeuX4Pvqidr36Ig6/Iyq3TalOCy3fCb/YLObL9BD",
+	"eN40w3PupoMbMF+sSxEpD4MZHiUPo+1h68eElGyg+gZW0Gj7di2X6YOXyrv+7Ef5Kjo35hiHW5ATrhZs",
+	"pp1/Adw3bGaiKMTSvGQiMWpMzjfHvCecKvKQmLdNJsJWiUyjv9F9QMptpaBK4wqpK+mya9jQMMPKEZwc",
+	"WYiJm/vvHqx7F4/H5IJyj7A2vm6KsSo94VOHpLeogE2HguxtQ6JH8pKou4LxP/kmU50Y025NhganpXcH",
+	"mZbxv8edKvvB9gSTMP4fTwHJ4yfxXZjxw2xPFSHvUOaDZu3V9gup7acC7Xf7/CWZjqZY52redjWpQpqa",
+	"SfLBZ8RcS0zRuO5UtPTAVTbjIZZBhq52n1+Bf4xY3nawSpNR868785fvcDVadTpcp8ko1u76/zMNoq2z",
+	"NiYbNNJJfdmuxiZ3oAXYx8JcduSGrIEtAnacqdtBIfue28a+FUgrWRtB5hZug3ZpK34zIWTOuFk1NUlp",
+	"iljCRjHmW/iHNSfNyCXINjQREULdNmvqnbo9sC2i4U4fOQztUcDz7vJvrjb8uc0LV+rT2hRdOlHk3eXf",
+	"OtSBUA4Rx4lL4RrO77Z17+azwMq2evYJ1I5i3cLX2NAw7ab24hI22Gt+t4FSKZaNVTQzGlubHW0LZVmj",
+	"0rnkbPxamB1ntRsF2GXaaJxEMT4vIMwsHxsU4NSKcDDg+g8+E05LXzx2l5IV0qYj3UwUdclVjCh7raOf",
+	"2c5aE3BTqkXJsqlTAq3qulzgN50RUp+73Zwbsyg0vQmuQekRzGZC6qlFsx0f4hi7WdhwVHNsajiv2jVb",
+	"jklEC2kgFZsfAjgelVa9LkH26G0dtnMs6d2ZHfnCfn9rrb/jzqzi+YzWoabmEY5lX31MBvfrL9kSLQ25",
+	"B9ITSwIocgIhsdyV2O9LbJCclp+3afqDErNTnGp79jQ1qpvqMwbEnZ1I/WQXfoL60+2NTMMkiu7+fvoG",
+	"kRUIt6cQV50q1LYQGI+KDhUoD4qxBX4u4bfBM/rRZCGBUsR8hB3wU6qUqyWa5Aq7oPjv1lZSZOZFU5UI",
+	"ivzpw4dzW4nivmrgmzdAoQBdDpT4D1IQUWujk+UC8D0yB00sZCa5yHUgVVjLQuWAvvM9aA/sIcthe1+v",
+	"GMif9MhgitTVuNOFKjn5eNUtskBuov13gXtD23OzCHHnVlJmNmPwciRqPRKzkdUbRptdQe9N1w1fy2GW",
+	"s52hsH0Rfp4m/PjE+i0zOZfRrsuHLI3c1FE6VpRYY3qIRUdgsjyyFAZPKWCL3lcmaq2Yqz1wa1qVjMmI",
+	"Cy84N3eSmBH324bMBZqz3g3sNAx65bx1Zn1/VQzxqEWNZYkTbj7/klpN5lwoPZdw+e8/tpfN3mcsz2Ml",
+	"YLJhY3j4Gzrh9jTMxKbKBT1rvg1ZbhsgZabp0YfmM7L+C7S2AA9DmJauvMbq+AISvqR84E43+//8lzrE",
+	"Lp4aktVXx6/2IqnNbGC5AFTtO6dpqi6RrTa9reJ8oTt9t8vdxyuj/ob95z5eGd0Q53XKdy0L18vt5Oio",
+	"EBktFkLpkz8e//EYDUC34qduWZBCxbpxjrX0HfzsZE7wSxhgC35GKR/+4HZ3f3X/PwMAdlZqwJmHAAA=",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	res := make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	resolvePath := PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		pathToFile := url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
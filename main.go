@@ -1,41 +1,131 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/davidyunus/sawitpro-estate/generated"
 	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/config"
 	"github.com/davidyunus/sawitpro-estate/src/domain"
 	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/davidyunus/sawitpro-estate/src/middleware"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
 	"github.com/labstack/echo/v4"
 	_ "github.com/lib/pq"
 
+	auditsql "github.com/davidyunus/sawitpro-estate/src/audit/repository/sql"
+	audituc "github.com/davidyunus/sawitpro-estate/src/audit/usecase"
+	authsql "github.com/davidyunus/sawitpro-estate/src/auth/repository/sql"
+	authuc "github.com/davidyunus/sawitpro-estate/src/auth/usecase"
 	estatehttp "github.com/davidyunus/sawitpro-estate/src/estate/delivery/http"
 	estatesql "github.com/davidyunus/sawitpro-estate/src/estate/repository/sql"
 	estateuc "github.com/davidyunus/sawitpro-estate/src/estate/usecase"
+	healthsql "github.com/davidyunus/sawitpro-estate/src/health/repository/sql"
+	healthuc "github.com/davidyunus/sawitpro-estate/src/health/usecase"
+	idempotencysql "github.com/davidyunus/sawitpro-estate/src/idempotency/repository/sql"
+	organisationsql "github.com/davidyunus/sawitpro-estate/src/organisation/repository/sql"
+	organisationuc "github.com/davidyunus/sawitpro-estate/src/organisation/usecase"
 	palmtreelocation "github.com/davidyunus/sawitpro-estate/src/palm_tree/repository/sql"
+	permissionsql "github.com/davidyunus/sawitpro-estate/src/permission/repository/sql"
+	ratelimitmemory "github.com/davidyunus/sawitpro-estate/src/ratelimit/repository/memory"
 )
 
+// publicRoutes are served without credentials or an organisation.
+var publicRoutes = []string{"/ping", "/metrics", "/healthz", "/readyz"}
+
+// expensiveRoutes are the endpoints limited by rate_limit.expensive on top
+// of the per caller limit, and allowed http.long_request_timeout. Custom
+// verbs keep the ':' escaped, as echo reports their path.
+var expensiveRoutes = []string{
+	"/estate/:id/drone-plan",
+	`/estate/:id/trees\:import`,
+	"/estate/:id/trees.csv",
+	"/export.ndjson",
+	"/admin/backup",
+	"/admin/restore",
+}
+
 var (
+	cfg *config.Config
+
 	dbConn               *sql.DB
 	estateUsecase        domain.EstateUsecase
+	authUsecase          domain.AuthUsecase
+	organisationUsecase  domain.OrganisationUsecase
+	auditUsecase         domain.AuditUsecase
+	healthUsecase        domain.HealthUsecase
 	estateRepo           domain.EstateRepository
 	palmTreeLocationRepo domain.PalmTreeLocationRepository
+	idempotencyRepo      domain.IdempotencyRepository
+	apiKeyRepo           domain.ApiKeyRepository
+	permissionRepo       domain.EstatePermissionRepository
+	organisationRepo     domain.OrganisationRepository
+	auditRepo            domain.AuditRepository
+	rateLimitRepo        domain.RateLimitRepository
+	healthRepo           domain.HealthRepository
 
 	manager *helper.Manager
+	server  *echo.Echo
+
+	shutdownTracing func(context.Context) error
 )
 
-func initDB() (err error) {
-	psqlInfo := fmt.Sprint("host=localhost port=5432 user=postgres " +
-		"password=postgres dbname=postgres sslmode=disable")
+func initConfig() (err error) {
+	cfg, err = config.Load()
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(helper.NewLogger(os.Stdout, logLevel(cfg.LogLevel)))
+
+	return helper.InitTime(cfg.Timezone)
+}
+
+func logLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	case "off":
+		return helper.LevelOff
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func initTracing() (err error) {
+	shutdownTracing, err = tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.OTLPEndpoint,
+		File:        cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	return err
+}
 
-	dbConn, err = sql.Open("postgres", psqlInfo)
+func initDB() (err error) {
+	dbConn, err = sql.Open("postgres", cfg.Database.DSN)
 	if err != nil {
 		return err
 	}
 
+	dbConn.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	dbConn.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	dbConn.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+
 	err = dbConn.Ping()
 	if err != nil {
 		return err
@@ -43,50 +133,196 @@ func initDB() (err error) {
 
 	manager = helper.NewManager(dbConn, common.TransactionContextKey)
 
-	return nil
+	return metrics.RegisterDB(dbConn, "estate")
 }
 
 func initRepo() error {
 	estateRepo = estatesql.NewEstateRepositorySql(dbConn, manager)
 	palmTreeLocationRepo = palmtreelocation.NewPalmTreeRepositorySql(dbConn, manager)
+	idempotencyRepo = idempotencysql.NewIdempotencyRepositorySql(dbConn)
+	apiKeyRepo = authsql.NewApiKeyRepositorySql(dbConn, manager)
+	permissionRepo = permissionsql.NewPermissionRepositorySql(dbConn, manager)
+	organisationRepo = organisationsql.NewOrganisationRepositorySql(dbConn, manager)
+	auditRepo = auditsql.NewAuditRepositorySql(dbConn, manager)
+	rateLimitRepo = ratelimitmemory.NewRateLimitRepositoryMemory()
+	healthRepo = healthsql.NewHealthRepositorySql(dbConn)
 
 	return nil
 }
 
 func initUsecase() error {
-	estateUsecase = estateuc.NewEstateUsecase(estateRepo, palmTreeLocationRepo)
+	estateUsecase = estateuc.NewEstateUsecase(estateRepo, palmTreeLocationRepo, permissionRepo, organisationRepo, auditRepo, manager, cfg.Estate)
+	organisationUsecase = organisationuc.NewOrganisationUsecase(organisationRepo, auditRepo, manager)
+	auditUsecase = audituc.NewAuditUsecase(auditRepo)
+	healthUsecase = healthuc.NewHealthUsecase(healthRepo, cfg.HTTP.ReadinessTimeout, domain.SchemaVersion)
+
+	tokens := authuc.TokenOptions{
+		Issuer:     cfg.Auth.Issuer,
+		Audience:   cfg.Auth.Audience,
+		Leeway:     cfg.Auth.Leeway,
+		AdminScope: cfg.Auth.AdminScope,
+	}
+	if cfg.Auth.JWKSFile != "" {
+		keys, err := authuc.ReadJWKS(cfg.Auth.JWKSFile)
+		if err != nil {
+			return err
+		}
+		tokens.Keys = keys
+	}
+	authUsecase = authuc.NewAuthUsecase(apiKeyRepo, auditRepo, manager, tokens)
 
 	return nil
 }
 
 func initHTTP() error {
 	e := echo.New()
-	e.Debug = true
+	e.Debug = cfg.Debug
+	e.HideBanner = true
+	e.HidePort = true
+	e.HTTPErrorHandler = helper.HTTPErrorHandler
+	e.Validator = helper.NewValidator()
 
-	estatehttp.NewEstateHandler(e, estateUsecase)
+	spec, err := generated.GetSwagger()
+	if err != nil {
+		return err
+	}
+	openAPIValidator, err := middleware.OpenAPIValidator(spec, cfg.HTTP.ValidateResponses)
+	if err != nil {
+		return err
+	}
+	e.Use(middleware.RequestId())
+	e.Use(middleware.Tracing())
+	e.Use(middleware.Metrics())
+	e.Use(middleware.RequestLog())
+	longRoutes := map[string]time.Duration{}
+	for _, route := range expensiveRoutes {
+		longRoutes[route] = cfg.HTTP.LongRequestTimeout
+	}
+	e.Use(middleware.Deadline(cfg.HTTP.RequestTimeout, longRoutes))
+	if cfg.RateLimit.TrustProxy {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	} else {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
+	if cfg.RateLimit.Enabled {
+		e.Use(middleware.RateLimit(rateLimitRepo, "ip", middleware.ClientIP, cfg.RateLimit.IP))
+	}
+	if cfg.Auth.Enabled {
+		e.Use(middleware.Auth(authUsecase, publicRoutes...))
+	} else {
+		slog.Warn("authentication is disabled: every request acts as an administrator")
+		e.Use(middleware.Anonymous())
+	}
+	if cfg.RateLimit.Enabled {
+		e.Use(middleware.RateLimit(rateLimitRepo, "caller", middleware.Caller, cfg.RateLimit.Caller))
+		e.Use(middleware.RateLimit(rateLimitRepo, "expensive", middleware.Caller, cfg.RateLimit.Expensive, expensiveRoutes...))
+	}
+	e.Use(middleware.Tenant(organisationUsecase, publicRoutes...))
+	e.Use(openAPIValidator)
+	e.Use(middleware.Idempotency(idempotencyRepo, cfg.HTTP.IdempotencyTTL, "/estate", "/estate/:id/tree", `/estate/:id/trees\:import`))
+
+	estatehttp.NewEstateHandler(e, estateUsecase, authUsecase, organisationUsecase, auditUsecase, healthUsecase)
 
 	e.GET("/ping", func(c echo.Context) error {
 		return c.JSON(http.StatusOK, helper.Response(http.StatusOK, "Pong", nil, nil))
 	})
+	if cfg.HTTP.Metrics {
+		e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+	}
+
+	e.Server.ReadTimeout = cfg.HTTP.ReadTimeout
+	e.Server.WriteTimeout = cfg.HTTP.WriteTimeout
+	e.Server.IdleTimeout = cfg.HTTP.IdleTimeout
+	server = e
+	return nil
+}
 
-	return e.Start(":8080")
+// serve runs the server until it fails or the process is asked to stop.
+// On SIGTERM or SIGINT readiness checks start failing while requests are
+// still served for http.drain_delay, long enough for load balancers to
+// notice; then the listener is closed and in-flight requests get
+// http.shutdown_timeout to finish before their connections are closed.
+func serve() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", cfg.HTTP.ListenAddr)
+		errc <- server.Start(cfg.HTTP.ListenAddr)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	slog.Info("shutting down", "drain_delay", cfg.HTTP.DrainDelay, "timeout", cfg.HTTP.ShutdownTimeout)
+	healthUsecase.Drain()
+	time.Sleep(cfg.HTTP.DrainDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	err := server.Shutdown(shutdownCtx)
+	if err != nil {
+		return fmt.Errorf("drain connections: %w", err)
+	}
+	err = <-errc
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// shutdown releases what the service holds once it stopped serving: spans
+// not yet exported are flushed and the database pool closed.
+func shutdown() {
+	err := shutdownTracing(context.Background())
+	if err != nil {
+		slog.Error("flush traces", "error", err)
+	}
+	err = dbConn.Close()
+	if err != nil {
+		slog.Error("close database", "error", err)
+	}
 }
 
 func main() {
-	err := initDB()
+	err := initConfig()
+	if err != nil {
+		fatal(err)
+	}
+	err = initTracing()
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
+	}
+	err = initDB()
+	if err != nil {
+		fatal(err)
 	}
 	err = initRepo()
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
 	err = initUsecase()
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
 	err = initHTTP()
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
+
+	err = serve()
+	shutdown()
+	if err != nil {
+		fatal(err)
+	}
+	slog.Info("stopped")
+}
+
+func fatal(err error) {
+	slog.Error("exiting", "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,155 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+	"github.com/lib/pq"
+)
+
+type auditRepositorySql struct {
+	conn    *sql.DB
+	manager *helper.Manager
+}
+
+func NewAuditRepositorySql(conn *sql.DB, manager *helper.Manager) domain.AuditRepository {
+	return &auditRepositorySql{
+		conn:    conn,
+		manager: manager,
+	}
+}
+
+func (a *auditRepositorySql) AppendAuditEntry(ctx context.Context, param *domain.AuditEntry) error {
+	ctx, span := tracing.StartQuery(ctx, "audit", "AppendAuditEntry")
+	defer span.End()
+	defer metrics.ObserveQuery("audit", "AppendAuditEntry")()
+
+	var dbConn interface {
+		QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	} = a.conn
+
+	tx, _ := ctx.Value(a.manager.GetKey()).(*sql.Tx)
+	if tx != nil {
+		dbConn = tx
+	}
+
+	param.CreatedAt = helper.TenantNow(ctx)
+	return dbConn.QueryRowContext(ctx, QueryAppendAuditEntry,
+		domain.TenantId(ctx),
+		param.Actor,
+		param.Action,
+		param.EstateId,
+		pq.Array(param.TreeIds),
+		jsonValue(param.Before),
+		jsonValue(param.After),
+		param.RequestId,
+		param.CreatedAt,
+	).Scan(&param.Id)
+}
+
+// jsonValue stores an empty value as NULL.
+func jsonValue(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+func (a *auditRepositorySql) ListAuditEntries(ctx context.Context, filter *domain.AuditFilter, after int64, limit int) ([]domain.AuditEntry, error) {
+	ctx, span := tracing.StartQuery(ctx, "audit", "ListAuditEntries")
+	defer span.End()
+	defer metrics.ObserveQuery("audit", "ListAuditEntries")()
+
+	query, args := listAuditEntriesQuery(domain.TenantId(ctx), filter, after, limit)
+
+	rows, err := a.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = rows.Close()
+		if err != nil {
+			slog.ErrorContext(ctx, "close rows", "error", err)
+		}
+	}()
+
+	result := []domain.AuditEntry{}
+	for rows.Next() {
+		entry := domain.AuditEntry{}
+		var (
+			treeIds       pq.Int64Array
+			before, after []byte
+		)
+
+		err = rows.Scan(
+			&entry.Id,
+			&entry.Actor,
+			&entry.Action,
+			&entry.EstateId,
+			&treeIds,
+			&before,
+			&after,
+			&entry.RequestId,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(treeIds) > 0 {
+			entry.TreeIds = treeIds
+		}
+		if len(before) > 0 {
+			entry.Before = before
+		}
+		if len(after) > 0 {
+			entry.After = after
+		}
+
+		result = append(result, entry)
+	}
+
+	return result, rows.Err()
+}
+
+func listAuditEntriesQuery(organisationId string, filter *domain.AuditFilter, after int64, limit int) (string, []interface{}) {
+	query := QueryListAuditEntries
+	args := []interface{}{organisationId}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		query += fmt.Sprintf("\n\t\tAND "+condition, len(args))
+	}
+
+	if filter.EstateId != "" {
+		where("estateUuid = $%d", filter.EstateId)
+	}
+	if filter.TreeId != nil {
+		where("$%d = ANY(treeIds)", *filter.TreeId)
+	}
+	if filter.Actor != "" {
+		where("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		where("action = $%d", filter.Action)
+	}
+	if filter.From != nil {
+		where("createdAt >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		where("createdAt < $%d", *filter.To)
+	}
+	if after > 0 {
+		where("id > $%d", after)
+	}
+
+	args = append(args, limit)
+	query += fmt.Sprintf("\n\tORDER BY\n\t\tid\n\tLIMIT $%d", len(args))
+	return query, args
+}
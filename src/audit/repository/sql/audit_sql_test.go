@@ -0,0 +1,199 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+const utTenant = "acme"
+
+var (
+	tenantCtx = domain.WithTenant(context.Background(), &domain.Organisation{Id: utTenant})
+	columns   = []string{"id", "actor", "action", "estateUuid", "treeIds", "before", "after", "requestId", "createdAt"}
+)
+
+func init() {
+	err := helper.InitTime("Asia/Jakarta")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func TestNewAuditRepositorySql(t *testing.T) {
+	assert.NotNil(t, NewAuditRepositorySql(nil, nil))
+}
+
+func TestAppendAuditEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &auditRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	tests := []struct {
+		name    string
+		param   *domain.AuditEntry
+		wantId  int64
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "success",
+			param: &domain.AuditEntry{
+				Actor:     "alice",
+				Action:    domain.AuditPlantTree,
+				EstateId:  common.UtUuid,
+				TreeIds:   []int64{7, 8},
+				After:     json.RawMessage(`{"x":2}`),
+				RequestId: "req-1",
+			},
+			wantId: 3,
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO auditLog").
+					WithArgs(utTenant, "alice", domain.AuditPlantTree, common.UtUuid, "{7,8}", nil, `{"x":2}`, "req-1", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+			},
+		},
+		{
+			name:   "success without estate or trees",
+			param:  &domain.AuditEntry{Actor: "alice", Action: domain.AuditRevokeApiKey, Before: json.RawMessage(`{"id":"k"}`)},
+			wantId: 4,
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO auditLog").
+					WithArgs(utTenant, "alice", domain.AuditRevokeApiKey, "", nil, `{"id":"k"}`, nil, "", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+			},
+		},
+		{
+			name:    "error",
+			param:   &domain.AuditEntry{Actor: "alice", Action: domain.AuditCreateEstate},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO auditLog").WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := repo.AppendAuditEntry(tenantCtx, test.param)
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantId, test.param.Id)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListAuditEntries(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &auditRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	treeId := int64(7)
+
+	type args struct {
+		filter *domain.AuditFilter
+		after  int64
+		limit  int
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult []domain.AuditEntry
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name: "success first page",
+			args: args{filter: &domain.AuditFilter{}, limit: 2},
+			wantResult: []domain.AuditEntry{
+				{Id: 1, Actor: "alice", Action: domain.AuditCreateEstate, EstateId: common.UtUuid, After: json.RawMessage(`{"length":6}`), RequestId: "req-1", CreatedAt: createdAt},
+				{Id: 2, Actor: "alice", Action: domain.AuditRevokeApiKey, CreatedAt: createdAt},
+			},
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "alice", domain.AuditCreateEstate, common.UtUuid, nil, nil, []byte(`{"length":6}`), "req-1", createdAt).
+					AddRow(2, "alice", domain.AuditRevokeApiKey, "", nil, nil, nil, "", createdAt)
+				mock.ExpectQuery(QueryListAuditEntries+"\n\tORDER BY\n\t\tid\n\tLIMIT $2").
+					WithArgs(utTenant, 2).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name: "success filtered page after cursor",
+			args: args{
+				filter: &domain.AuditFilter{
+					EstateId: common.UtUuid,
+					TreeId:   &treeId,
+					Actor:    "bob",
+					Action:   domain.AuditPlantTree,
+					From:     &createdAt,
+					To:       &createdAt,
+				},
+				after: 5,
+				limit: 10,
+			},
+			wantResult: []domain.AuditEntry{
+				{Id: 6, Actor: "bob", Action: domain.AuditPlantTree, EstateId: common.UtUuid, TreeIds: []int64{7}, After: json.RawMessage(`{"x":1}`), CreatedAt: createdAt},
+			},
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(6, "bob", domain.AuditPlantTree, common.UtUuid, "{7}", nil, []byte(`{"x":1}`), "", createdAt)
+				mock.ExpectQuery(QueryListAuditEntries+
+					"\n\t\tAND estateUuid = $2"+
+					"\n\t\tAND $3 = ANY(treeIds)"+
+					"\n\t\tAND actor = $4"+
+					"\n\t\tAND action = $5"+
+					"\n\t\tAND createdAt >= $6"+
+					"\n\t\tAND createdAt < $7"+
+					"\n\t\tAND id > $8"+
+					"\n\tORDER BY\n\t\tid\n\tLIMIT $9").
+					WithArgs(utTenant, common.UtUuid, treeId, "bob", domain.AuditPlantTree, createdAt, createdAt, int64(5), 10).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name:    "error",
+			args:    args{filter: &domain.AuditFilter{}, limit: 2},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(QueryListAuditEntries + "\n\tORDER BY\n\t\tid\n\tLIMIT $2").
+					WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.ListAuditEntries(tenantCtx, test.args.filter, test.args.after, test.args.limit)
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package sql
+
+const (
+	QueryAppendAuditEntry = `INSERT INTO auditLog
+	(organisationId, actor, action, estateUuid, treeIds, before, after, requestId, createdAt)
+	VALUES($1, $2, $3, NULLIF($4, ''), $5, $6, $7, NULLIF($8, ''), $9)
+	RETURNING
+		id`
+
+	QueryListAuditEntries = `SELECT
+		id,
+		actor,
+		action,
+		COALESCE(estateUuid, ''),
+		treeIds,
+		before,
+		after,
+		COALESCE(requestId, ''),
+		createdAt
+	FROM
+		auditLog
+	WHERE
+		organisationId = $1`
+)
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+)
+
+type auditUsecase struct {
+	auditRepo domain.AuditRepository
+}
+
+func NewAuditUsecase(auditRepo domain.AuditRepository) domain.AuditUsecase {
+	return &auditUsecase{
+		auditRepo: auditRepo,
+	}
+}
+
+// ListAuditEntries returns one page of the audit log of the organisation
+// the request acts for. One entry more than the page holds is fetched to
+// tell whether another page follows. Only administrators may read it.
+func (a *auditUsecase) ListAuditEntries(ctx context.Context, param *domain.ListAuditEntriesRequest) (*domain.ListAuditEntriesResponse, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	after, limit, err := validateListAuditEntries(param)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := a.auditRepo.ListAuditEntries(ctx, &param.Filter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &domain.ListAuditEntriesResponse{Entries: entries}
+	if len(entries) > limit {
+		resp.Entries = entries[:limit]
+		resp.NextCursor = strconv.FormatInt(entries[limit-1].Id, 10)
+	}
+	return resp, nil
+}
+
+// validateListAuditEntries checks an audit listing and returns the id the
+// page starts after and the page size, with defaults filled in.
+func validateListAuditEntries(param *domain.ListAuditEntriesRequest) (int64, int, error) {
+	details := []domain.FieldError{}
+
+	var after int64
+	if param.Cursor != "" {
+		var err error
+		after, err = strconv.ParseInt(param.Cursor, 10, 64)
+		if err != nil || after < 1 {
+			details = append(details, domain.FieldError{
+				Field:   "cursor",
+				Rule:    "cursor",
+				Message: "cursor must be the nextCursor of a previous page",
+			})
+		}
+	}
+
+	limit := param.Limit
+	if limit == 0 {
+		limit = domain.DefaultAuditPageSize
+	}
+	if limit < 1 || limit > domain.MaxAuditPageSize {
+		details = append(details, domain.FieldError{
+			Field:   "limit",
+			Rule:    "max",
+			Param:   strconv.Itoa(domain.MaxAuditPageSize),
+			Message: fmt.Sprintf("limit must be between 1 and %d", domain.MaxAuditPageSize),
+		})
+	}
+
+	filter := param.Filter
+	if filter.Action != "" && !isAuditAction(filter.Action) {
+		details = append(details, domain.FieldError{
+			Field:   "action",
+			Rule:    "oneof",
+			Param:   strings.Join(domain.AuditActions, " "),
+			Message: "action must be one of: " + strings.Join(domain.AuditActions, ", "),
+		})
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		details = append(details, domain.FieldError{
+			Field:   "to",
+			Rule:    "gtfield",
+			Param:   "from",
+			Message: "to must be after from",
+		})
+	}
+
+	if len(details) > 0 {
+		return 0, 0, domain.ErrInvalidInput.WithDetails(details)
+	}
+	return after, limit, nil
+}
+
+func isAuditAction(action string) bool {
+	for _, a := range domain.AuditActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func requireAdmin(ctx context.Context) error {
+	principal := domain.PrincipalFromContext(ctx)
+	if principal == nil {
+		return domain.ErrUnauthorized
+	}
+	if !principal.Admin {
+		return domain.ErrForbidden
+	}
+	return nil
+}
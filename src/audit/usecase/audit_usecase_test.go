@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var adminCtx = domain.WithPrincipal(context.Background(), &domain.Principal{Subject: "alice", Admin: true})
+
+func TestNewAuditUsecase(t *testing.T) {
+	assert.NotNil(t, NewAuditUsecase(nil))
+}
+
+func TestListAuditEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	uc := NewAuditUsecase(auditRepoMock)
+
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	entries := []domain.AuditEntry{
+		{Id: 1, Actor: "bob", Action: domain.AuditPlantTree},
+		{Id: 2, Actor: "bob", Action: domain.AuditPlantTree},
+		{Id: 3, Actor: "bob", Action: domain.AuditPlantTree},
+	}
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		param      *domain.ListAuditEntriesRequest
+		wantResult *domain.ListAuditEntriesResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success last page with defaults",
+			ctx:        adminCtx,
+			param:      &domain.ListAuditEntriesRequest{},
+			wantResult: &domain.ListAuditEntriesResponse{Entries: entries},
+			mock: func() {
+				auditRepoMock.EXPECT().ListAuditEntries(gomock.Any(), &domain.AuditFilter{}, int64(0), domain.DefaultAuditPageSize+1).
+					Return(entries, nil)
+			},
+		},
+		{
+			name: "success page with next cursor",
+			ctx:  adminCtx,
+			param: &domain.ListAuditEntriesRequest{
+				Filter: domain.AuditFilter{EstateId: common.UtUuid, Action: domain.AuditPlantTree, From: &from, To: &to},
+				Cursor: "4",
+				Limit:  2,
+			},
+			wantResult: &domain.ListAuditEntriesResponse{Entries: entries[:2], NextCursor: "2"},
+			mock: func() {
+				auditRepoMock.EXPECT().ListAuditEntries(gomock.Any(),
+					&domain.AuditFilter{EstateId: common.UtUuid, Action: domain.AuditPlantTree, From: &from, To: &to}, int64(4), 3).
+					Return(entries, nil)
+			},
+		},
+		{
+			name: "error invalid request",
+			ctx:  adminCtx,
+			param: &domain.ListAuditEntriesRequest{
+				Filter: domain.AuditFilter{Action: "tree.fell", From: &to, To: &from},
+				Cursor: "abc",
+				Limit:  domain.MaxAuditPageSize + 1,
+			},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:    "error not admin",
+			ctx:     domain.WithPrincipal(context.Background(), &domain.Principal{Subject: "bob"}),
+			param:   &domain.ListAuditEntriesRequest{},
+			wantErr: domain.ErrForbidden,
+			mock:    func() {},
+		},
+		{
+			name:    "error unauthenticated",
+			ctx:     context.Background(),
+			param:   &domain.ListAuditEntriesRequest{},
+			wantErr: domain.ErrUnauthorized,
+			mock:    func() {},
+		},
+		{
+			name:    "error repository",
+			ctx:     adminCtx,
+			param:   &domain.ListAuditEntriesRequest{},
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				auditRepoMock.EXPECT().ListAuditEntries(gomock.Any(), gomock.Any(), int64(0), gomock.Any()).
+					Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.ListAuditEntries(test.ctx, test.param)
+			if test.wantErr != nil {
+				assert.Error(t, err)
+				if errors.Is(test.wantErr, domain.ErrInvalidInput) {
+					assert.Len(t, err.(*domain.Error).Details, 4)
+				}
+				if _, ok := test.wantErr.(*domain.Error); ok {
+					assert.True(t, errors.Is(err, test.wantErr), err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
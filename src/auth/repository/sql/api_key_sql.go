@@ -0,0 +1,153 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+	"github.com/lib/pq"
+)
+
+type apiKeyRepositorySql struct {
+	conn    *sql.DB
+	manager *helper.Manager
+}
+
+func NewApiKeyRepositorySql(conn *sql.DB, manager *helper.Manager) domain.ApiKeyRepository {
+	return &apiKeyRepositorySql{
+		conn:    conn,
+		manager: manager,
+	}
+}
+
+// querier is a *sql.DB or *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// db returns the transaction ctx carries, so a key is only issued or
+// revoked together with its audit entry.
+func (r *apiKeyRepositorySql) db(ctx context.Context) querier {
+	tx, _ := ctx.Value(r.manager.GetKey()).(*sql.Tx)
+	if tx != nil {
+		return tx
+	}
+	return r.conn
+}
+
+func (r *apiKeyRepositorySql) CreateApiKey(ctx context.Context, param *domain.ApiKey) error {
+	ctx, span := tracing.StartQuery(ctx, "api_key", "CreateApiKey", tracing.Statement(QueryCreateApiKey))
+	defer span.End()
+	defer metrics.ObserveQuery("api_key", "CreateApiKey")()
+
+	_, err := r.db(ctx).ExecContext(ctx, QueryCreateApiKey,
+		param.Id,
+		param.Name,
+		param.Prefix,
+		param.Hash,
+		param.OrganisationId,
+		param.CreatedBy,
+		param.CreatedAt,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation && pqErr.Constraint == organisationForeignKey {
+		return domain.ErrOrganisationNotFound.Wrap(err)
+	}
+	return err
+}
+
+func (r *apiKeyRepositorySql) GetApiKeyByHash(ctx context.Context, hash string) (*domain.ApiKey, error) {
+	ctx, span := tracing.StartQuery(ctx, "api_key", "GetApiKeyByHash")
+	defer span.End()
+	defer metrics.ObserveQuery("api_key", "GetApiKeyByHash")()
+
+	key := &domain.ApiKey{}
+	err := scanApiKey(r.db(ctx).QueryRowContext(ctx, QueryGetApiKeyByHash, hash), key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepositorySql) ListApiKeys(ctx context.Context) ([]domain.ApiKey, error) {
+	ctx, span := tracing.StartQuery(ctx, "api_key", "ListApiKeys")
+	defer span.End()
+	defer metrics.ObserveQuery("api_key", "ListApiKeys")()
+
+	rows, err := r.db(ctx).QueryContext(ctx, QueryListApiKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = rows.Close()
+		if err != nil {
+			slog.ErrorContext(ctx, "close rows", "error", err)
+		}
+	}()
+
+	result := []domain.ApiKey{}
+	for rows.Next() {
+		key := domain.ApiKey{}
+		err = scanApiKey(rows, &key)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, key)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *apiKeyRepositorySql) RevokeApiKey(ctx context.Context, id string, at time.Time) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "api_key", "RevokeApiKey", tracing.Statement(QueryRevokeApiKey))
+	defer span.End()
+	defer metrics.ObserveQuery("api_key", "RevokeApiKey")()
+
+	res, err := r.db(ctx).ExecContext(ctx, QueryRevokeApiKey, id, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// scanner is a *sql.Row or *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanApiKey(row scanner, key *domain.ApiKey) error {
+	var revokedAt sql.NullTime
+	err := row.Scan(
+		&key.Id,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		&key.OrganisationId,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []string{"uuid", "name", "prefix", "hash", "organisationId", "createdBy", "createdAt", "revokedAt"}
+
+func TestCreateApiKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &apiKeyRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	key := &domain.ApiKey{Id: common.UtUuid, Name: "ci", Prefix: "spk_abcd", Hash: "hash", OrganisationId: "acme", CreatedBy: "admin", CreatedAt: now}
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QueryCreateApiKey)).
+					WithArgs(common.UtUuid, "ci", "spk_abcd", "hash", "acme", "admin", now).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "error unknown organisation",
+			wantErr: domain.ErrOrganisationNotFound,
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QueryCreateApiKey)).
+					WillReturnError(&pq.Error{Code: foreignKeyViolation, Constraint: organisationForeignKey})
+			},
+		},
+		{
+			name:    "error",
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QueryCreateApiKey)).WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := repo.CreateApiKey(context.Background(), key)
+			if test.wantErr != nil {
+				assert.ErrorContains(t, err, test.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetApiKeyByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &apiKeyRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		wantResult *domain.ApiKey
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:       "success",
+			wantResult: &domain.ApiKey{Id: common.UtUuid, Name: "ci", Prefix: "spk_abcd", Hash: "hash", OrganisationId: "acme", CreatedBy: "admin", CreatedAt: now},
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetApiKeyByHash)).WithArgs("hash").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(common.UtUuid, "ci", "spk_abcd", "hash", "acme", "admin", now, nil))
+			},
+		},
+		{
+			name: "success not found",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetApiKeyByHash)).WithArgs("hash").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetApiKeyByHash)).WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.GetApiKeyByHash(context.Background(), "hash")
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListApiKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &apiKeyRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	revokedAt := now.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		wantResult []domain.ApiKey
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: []domain.ApiKey{
+				{Id: "a", Name: "ci", Prefix: "spk_abcd", Hash: "hash-a", CreatedBy: "admin", CreatedAt: now},
+				{Id: "b", Name: "old", Prefix: "spk_efgh", Hash: "hash-b", CreatedBy: "admin", CreatedAt: now, RevokedAt: &revokedAt},
+			},
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryListApiKeys)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("a", "ci", "spk_abcd", "hash-a", "", "admin", now, nil).
+						AddRow("b", "old", "spk_efgh", "hash-b", "", "admin", now, revokedAt))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryListApiKeys)).WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.ListApiKeys(context.Background())
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &apiKeyRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		wantResult bool
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:       "success revoked",
+			wantResult: true,
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QueryRevokeApiKey)).WithArgs(common.UtUuid, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "success not found",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QueryRevokeApiKey)).WithArgs(common.UtUuid, now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QueryRevokeApiKey)).WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.RevokeApiKey(context.Background(), common.UtUuid, now)
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package sql
+
+const (
+	QueryCreateApiKey = `INSERT INTO apiKey
+	(uuid, name, prefix, hash, organisationId, createdBy, createdAt)
+	VALUES($1, $2, $3, $4, NULLIF($5, ''), $6, $7)`
+
+	QueryGetApiKeyByHash = `SELECT
+		uuid,
+		name,
+		prefix,
+		hash,
+		COALESCE(organisationId, ''),
+		createdBy,
+		createdAt,
+		revokedAt
+	FROM
+		apiKey
+	WHERE
+		hash = $1
+		AND revokedAt IS NULL`
+
+	QueryListApiKeys = `SELECT
+		uuid,
+		name,
+		prefix,
+		hash,
+		COALESCE(organisationId, ''),
+		createdBy,
+		createdAt,
+		revokedAt
+	FROM
+		apiKey
+	ORDER BY
+		createdAt, uuid`
+
+	QueryRevokeApiKey = `UPDATE apiKey
+	SET
+		revokedAt = $2
+	WHERE
+		uuid = $1
+		AND revokedAt IS NULL`
+)
+
+// organisationForeignKey is the foreign key from apiKey.organisationId, as
+// PostgreSQL names it in constraint violations.
+const organisationForeignKey = "apikey_organisationid_fkey"
+
+// foreignKeyViolation is the SQLSTATE of a foreign key violation.
+const foreignKeyViolation = "23503"
@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// ApiKeyPrefix starts every API key, so leaked keys are easy to spot.
+	ApiKeyPrefix = "spk_"
+
+	// apiKeyShownLength is how much of a key is kept in the clear.
+	apiKeyShownLength = len(ApiKeyPrefix) + 8
+)
+
+// tokenMethods are the signing algorithms accepted on bearer tokens. The
+// symmetric ones are left out: the key set only holds public keys.
+var tokenMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type (
+	// TokenOptions configures how bearer tokens are verified. Issuer and
+	// Audience are only checked when set. A token carrying AdminScope in its
+	// scope claim may manage API keys.
+	TokenOptions struct {
+		Keys       KeySet
+		Issuer     string
+		Audience   string
+		Leeway     time.Duration
+		AdminScope string
+	}
+
+	authUsecase struct {
+		apiKeyRepo domain.ApiKeyRepository
+		auditRepo  domain.AuditRepository
+		transactor domain.Transactor
+		tokens     TokenOptions
+		parser     *jwt.Parser
+	}
+
+	// revokedApiKey is how a revocation is recorded in the audit log.
+	revokedApiKey struct {
+		Id        string    `json:"id"`
+		RevokedAt time.Time `json:"revokedAt"`
+	}
+
+	tokenClaims struct {
+		jwt.RegisteredClaims
+		Scope        string `json:"scope"`
+		Organisation string `json:"org"`
+	}
+)
+
+func NewAuthUsecase(apiKeyRepo domain.ApiKeyRepository, auditRepo domain.AuditRepository, transactor domain.Transactor, tokens TokenOptions) domain.AuthUsecase {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(tokenMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(tokens.Leeway),
+		jwt.WithTimeFunc(func() time.Time { return helper.Now() }),
+	}
+	if tokens.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(tokens.Issuer))
+	}
+	if tokens.Audience != "" {
+		opts = append(opts, jwt.WithAudience(tokens.Audience))
+	}
+
+	return &authUsecase{
+		apiKeyRepo: apiKeyRepo,
+		auditRepo:  auditRepo,
+		transactor: transactor,
+		tokens:     tokens,
+		parser:     jwt.NewParser(opts...),
+	}
+}
+
+func (a *authUsecase) AuthenticateApiKey(ctx context.Context, key string) (*domain.Principal, error) {
+	if !strings.HasPrefix(key, ApiKeyPrefix) {
+		return nil, domain.ErrUnauthorized
+	}
+
+	stored, err := a.apiKeyRepo.GetApiKeyByHash(ctx, hashApiKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, domain.ErrUnauthorized
+	}
+
+	return &domain.Principal{
+		Subject:      stored.Id,
+		Method:       domain.AuthMethodApiKey,
+		Organisation: stored.OrganisationId,
+	}, nil
+}
+
+func (a *authUsecase) AuthenticateToken(ctx context.Context, token string) (*domain.Principal, error) {
+	if len(a.tokens.Keys) == 0 {
+		return nil, domain.ErrUnauthorized
+	}
+
+	claims := &tokenClaims{}
+	_, err := a.parser.ParseWithClaims(token, claims, a.key)
+	if err != nil {
+		return nil, domain.ErrUnauthorized.Wrap(err)
+	}
+	if claims.Subject == "" {
+		return nil, domain.ErrUnauthorized.Wrap(errors.New("token has no subject"))
+	}
+
+	principal := &domain.Principal{
+		Subject:      claims.Subject,
+		Method:       domain.AuthMethodJwt,
+		Organisation: claims.Organisation,
+	}
+	if a.tokens.AdminScope != "" {
+		for _, scope := range strings.Fields(claims.Scope) {
+			if scope == a.tokens.AdminScope {
+				principal.Admin = true
+			}
+		}
+	}
+	return principal, nil
+}
+
+// key picks the verification key named by the token's kid header. A set
+// with a single key also verifies tokens without a kid.
+func (a *authUsecase) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := a.tokens.Keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(a.tokens.Keys) == 1 {
+		for _, key := range a.tokens.Keys {
+			return key, nil
+		}
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+func (a *authUsecase) CreateApiKey(ctx context.Context, param *domain.CreateApiKeyRequest) (*domain.CreateApiKeyResponse, error) {
+	principal, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := generateApiKey()
+	if err != nil {
+		return nil, err
+	}
+	key := domain.ApiKey{
+		Id:             generateUUID(),
+		Name:           param.Name,
+		Prefix:         raw[:apiKeyShownLength],
+		Hash:           hashApiKey(raw),
+		OrganisationId: param.OrganisationId,
+		CreatedBy:      principal.Subject,
+		CreatedAt:      helper.Now(),
+	}
+	err = a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := a.apiKeyRepo.CreateApiKey(ctx, &key)
+		if err != nil {
+			return err
+		}
+		// The key shows in the audit log of the organisation it acts for,
+		// not in that of the administrator issuing it.
+		organisationId := key.OrganisationId
+		if organisationId == "" {
+			organisationId = domain.DefaultOrganisationId
+		}
+		return a.audit(domain.WithTenant(ctx, &domain.Organisation{Id: organisationId}), domain.AuditCreateApiKey, nil, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.CreateApiKeyResponse{
+		ApiKey: key,
+		Key:    raw,
+	}, nil
+}
+
+func (a *authUsecase) ListApiKeys(ctx context.Context) ([]domain.ApiKey, error) {
+	_, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return a.apiKeyRepo.ListApiKeys(ctx)
+}
+
+func (a *authUsecase) RevokeApiKey(ctx context.Context, id string) error {
+	_, err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		at := helper.Now()
+		revoked, err := a.apiKeyRepo.RevokeApiKey(ctx, id, at)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return domain.ErrApiKeyNotFound
+		}
+		return a.audit(ctx, domain.AuditRevokeApiKey, nil, revokedApiKey{Id: id, RevokedAt: at})
+	})
+}
+
+// audit appends an audit entry for a change made within the transaction
+// ctx carries.
+func (a *authUsecase) audit(ctx context.Context, action string, before, after interface{}) error {
+	entry, err := domain.NewAuditEntry(ctx, action, "", before, after)
+	if err != nil {
+		return err
+	}
+	return a.auditRepo.AppendAuditEntry(ctx, entry)
+}
+
+func requireAdmin(ctx context.Context) (*domain.Principal, error) {
+	principal := domain.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, domain.ErrUnauthorized
+	}
+	if !principal.Admin {
+		return nil, domain.ErrForbidden
+	}
+	return principal, nil
+}
+
+var generateUUID = func() string {
+	return uuid.NewString()
+}
+
+// generateApiKey returns a new key carrying 256 random bits.
+var generateApiKey = func() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return ApiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashApiKey is how keys are stored and looked up. The keys are random
+// enough that a plain SHA-256 cannot be brute forced.
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
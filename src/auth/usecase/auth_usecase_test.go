@@ -0,0 +1,468 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const utApiKey = ApiKeyPrefix + "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFG"
+
+func TestAuthenticateApiKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiKeyRepoMock := mock_domain.NewMockApiKeyRepository(ctrl)
+	uc := NewAuthUsecase(apiKeyRepoMock, nil, nil, TokenOptions{})
+
+	tests := []struct {
+		name       string
+		key        string
+		wantResult *domain.Principal
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success",
+			key:        utApiKey,
+			wantResult: &domain.Principal{Subject: common.UtUuid, Method: domain.AuthMethodApiKey, Organisation: "acme"},
+			mock: func() {
+				apiKeyRepoMock.EXPECT().GetApiKeyByHash(gomock.Any(), hashApiKey(utApiKey)).
+					Return(&domain.ApiKey{Id: common.UtUuid, OrganisationId: "acme"}, nil)
+			},
+		},
+		{
+			name:    "error unknown key",
+			key:     utApiKey,
+			wantErr: domain.ErrUnauthorized,
+			mock: func() {
+				apiKeyRepoMock.EXPECT().GetApiKeyByHash(gomock.Any(), hashApiKey(utApiKey)).Return(nil, nil)
+			},
+		},
+		{
+			name:    "error malformed key",
+			key:     "not-a-key",
+			wantErr: domain.ErrUnauthorized,
+			mock:    func() {},
+		},
+		{
+			name:    "error repository",
+			key:     utApiKey,
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				apiKeyRepoMock.EXPECT().GetApiKeyByHash(gomock.Any(), gomock.Any()).Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.AuthenticateApiKey(context.Background(), test.key)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time {
+		return now
+	}
+	defer func() {
+		helper.Now = tempNow
+	}()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uc := NewAuthUsecase(nil, nil, nil, TokenOptions{
+		Keys: KeySet{
+			"rsa": &rsaKey.PublicKey,
+			"ec":  &ecKey.PublicKey,
+			"ed":  edPublic,
+		},
+		Issuer:     "https://idp.example.com",
+		Audience:   "estate-api",
+		Leeway:     time.Minute,
+		AdminScope: "admin",
+	})
+
+	claims := func(edit func(c *tokenClaims)) *tokenClaims {
+		c := &tokenClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "alice",
+				Issuer:    "https://idp.example.com",
+				Audience:  jwt.ClaimStrings{"estate-api"},
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+		}
+		if edit != nil {
+			edit(c)
+		}
+		return c
+	}
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, c *tokenClaims) string {
+		token := jwt.NewWithClaims(method, c)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantResult *domain.Principal
+		wantErr    error
+	}{
+		{
+			name:       "success rsa",
+			token:      sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims(nil)),
+			wantResult: &domain.Principal{Subject: "alice", Method: domain.AuthMethodJwt},
+		},
+		{
+			name:       "success ecdsa",
+			token:      sign(jwt.SigningMethodES256, "ec", ecKey, claims(nil)),
+			wantResult: &domain.Principal{Subject: "alice", Method: domain.AuthMethodJwt},
+		},
+		{
+			name: "success ed25519 admin",
+			token: sign(jwt.SigningMethodEdDSA, "ed", edKey, claims(func(c *tokenClaims) {
+				c.Scope = "read admin"
+			})),
+			wantResult: &domain.Principal{Subject: "alice", Method: domain.AuthMethodJwt, Admin: true},
+		},
+		{
+			name: "success organisation",
+			token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims(func(c *tokenClaims) {
+				c.Organisation = "acme"
+			})),
+			wantResult: &domain.Principal{Subject: "alice", Method: domain.AuthMethodJwt, Organisation: "acme"},
+		},
+		{
+			name: "success expired within leeway",
+			token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims(func(c *tokenClaims) {
+				c.ExpiresAt = jwt.NewNumericDate(now.Add(-30 * time.Second))
+			})),
+			wantResult: &domain.Principal{Subject: "alice", Method: domain.AuthMethodJwt},
+		},
+		{
+			name: "error expired",
+			token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims(func(c *tokenClaims) {
+				c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Hour))
+			})),
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name: "error no expiry",
+			token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims(func(c *tokenClaims) {
+				c.ExpiresAt = nil
+			})),
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name: "error wrong issuer",
+			token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims(func(c *tokenClaims) {
+				c.Issuer = "https://evil.example.com"
+			})),
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name: "error wrong audience",
+			token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims(func(c *tokenClaims) {
+				c.Audience = jwt.ClaimStrings{"other-api"}
+			})),
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name: "error no subject",
+			token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims(func(c *tokenClaims) {
+				c.Subject = ""
+			})),
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name:    "error unknown kid",
+			token:   sign(jwt.SigningMethodRS256, "other", otherKey, claims(nil)),
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name:    "error bad signature",
+			token:   sign(jwt.SigningMethodRS256, "rsa", otherKey, claims(nil)),
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name:    "error symmetric algorithm",
+			token:   sign(jwt.SigningMethodHS256, "rsa", []byte("secret"), claims(nil)),
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name:    "error malformed",
+			token:   "not.a.token",
+			wantErr: domain.ErrUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := uc.AuthenticateToken(context.Background(), test.token)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestAuthenticateTokenSingleKey(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time {
+		return now
+	}
+	defer func() {
+		helper.Now = tempNow
+	}()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Subject:   "alice",
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewAuthUsecase(nil, nil, nil, TokenOptions{Keys: KeySet{"only": &key.PublicKey}}).
+		AuthenticateToken(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.Principal{Subject: "alice", Method: domain.AuthMethodJwt}, got)
+
+	_, err = NewAuthUsecase(nil, nil, nil, TokenOptions{}).AuthenticateToken(context.Background(), token)
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+}
+
+func TestCreateApiKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiKeyRepoMock := mock_domain.NewMockApiKeyRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	uc := NewAuthUsecase(apiKeyRepoMock, auditRepoMock, transactorMock, TokenOptions{})
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow, tempGenerateUUID, tempGenerateApiKey := helper.Now, generateUUID, generateApiKey
+	helper.Now = func() time.Time { return now }
+	generateUUID = func() string { return common.UtUuid }
+	generateApiKey = func() (string, error) { return utApiKey, nil }
+	defer func() {
+		helper.Now, generateUUID, generateApiKey = tempNow, tempGenerateUUID, tempGenerateApiKey
+	}()
+
+	admin := domain.WithPrincipal(context.Background(), &domain.Principal{Subject: "alice", Method: domain.AuthMethodJwt, Admin: true})
+	stored := domain.ApiKey{
+		Id:             common.UtUuid,
+		Name:           "ci",
+		Prefix:         "spk_abcdefgh",
+		Hash:           hashApiKey(utApiKey),
+		OrganisationId: "acme",
+		CreatedBy:      "alice",
+		CreatedAt:      now,
+	}
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantResult *domain.CreateApiKeyResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success",
+			ctx:        admin,
+			wantResult: &domain.CreateApiKeyResponse{ApiKey: stored, Key: utApiKey},
+			mock: func() {
+				apiKeyRepoMock.EXPECT().CreateApiKey(gomock.Any(), &stored).Return(nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, "acme", domain.TenantId(ctx))
+						assert.Equal(t, "alice", entry.Actor)
+						assert.Equal(t, domain.AuditCreateApiKey, entry.Action)
+						assert.NotContains(t, string(entry.After), hashApiKey(utApiKey))
+						return nil
+					})
+			},
+		},
+		{
+			name:    "error not admin",
+			ctx:     domain.WithPrincipal(context.Background(), &domain.Principal{Subject: common.UtUuid, Method: domain.AuthMethodApiKey}),
+			wantErr: domain.ErrForbidden,
+			mock:    func() {},
+		},
+		{
+			name:    "error unauthenticated",
+			ctx:     context.Background(),
+			wantErr: domain.ErrUnauthorized,
+			mock:    func() {},
+		},
+		{
+			name:    "error repository",
+			ctx:     admin,
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				apiKeyRepoMock.EXPECT().CreateApiKey(gomock.Any(), gomock.Any()).Return(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.CreateApiKey(test.ctx, &domain.CreateApiKeyRequest{Name: "ci", OrganisationId: "acme"})
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestListApiKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiKeyRepoMock := mock_domain.NewMockApiKeyRepository(ctrl)
+	uc := NewAuthUsecase(apiKeyRepoMock, nil, nil, TokenOptions{})
+	admin := domain.WithPrincipal(context.Background(), &domain.Principal{Subject: "alice", Admin: true})
+
+	apiKeyRepoMock.EXPECT().ListApiKeys(gomock.Any()).Return([]domain.ApiKey{{Id: common.UtUuid}}, nil)
+	got, err := uc.ListApiKeys(admin)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.ApiKey{{Id: common.UtUuid}}, got)
+
+	_, err = uc.ListApiKeys(context.Background())
+	assert.Equal(t, domain.ErrUnauthorized, err)
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiKeyRepoMock := mock_domain.NewMockApiKeyRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	uc := NewAuthUsecase(apiKeyRepoMock, auditRepoMock, transactorMock, TokenOptions{})
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time { return now }
+	defer func() {
+		helper.Now = tempNow
+	}()
+
+	admin := domain.WithPrincipal(context.Background(), &domain.Principal{Subject: "alice", Admin: true})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "success",
+			ctx:  admin,
+			mock: func() {
+				apiKeyRepoMock.EXPECT().RevokeApiKey(gomock.Any(), common.UtUuid, now).Return(true, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, domain.AuditRevokeApiKey, entry.Action)
+						assert.JSONEq(t, `{"id":"uuid","revokedAt":"2024-01-02T03:04:05Z"}`, string(entry.After))
+						return nil
+					})
+			},
+		},
+		{
+			name:    "error not found",
+			ctx:     admin,
+			wantErr: domain.ErrApiKeyNotFound,
+			mock: func() {
+				apiKeyRepoMock.EXPECT().RevokeApiKey(gomock.Any(), common.UtUuid, now).Return(false, nil)
+			},
+		},
+		{
+			name:    "error not admin",
+			ctx:     domain.WithPrincipal(context.Background(), &domain.Principal{Subject: "bob"}),
+			wantErr: domain.ErrForbidden,
+			mock:    func() {},
+		},
+		{
+			name:    "error repository",
+			ctx:     admin,
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				apiKeyRepoMock.EXPECT().RevokeApiKey(gomock.Any(), common.UtUuid, now).Return(false, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := uc.RevokeApiKey(test.ctx, common.UtUuid)
+			assert.Equal(t, test.wantErr, err)
+		})
+	}
+}
+
+func TestGenerateApiKey(t *testing.T) {
+	a, err := generateApiKey()
+	assert.NoError(t, err)
+	b, err := generateApiKey()
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, a, b)
+	assert.Len(t, a, len(ApiKeyPrefix)+43)
+	assert.Equal(t, ApiKeyPrefix, a[:len(ApiKeyPrefix)])
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	rsaJwk := fmt.Sprintf(`{"kty":"RSA","kid":"rsa","use":"sig","n":%q,"e":%q}`,
+		b64(rsaKey.N.Bytes()), b64(big.NewInt(int64(rsaKey.E)).Bytes()))
+	ecJwk := fmt.Sprintf(`{"kty":"EC","kid":"ec","crv":"P-384","x":%q,"y":%q}`,
+		b64(ecKey.X.Bytes()), b64(ecKey.Y.Bytes()))
+	edJwk := fmt.Sprintf(`{"kty":"OKP","kid":"ed","crv":"Ed25519","x":%q}`, b64(edPublic))
+
+	tests := []struct {
+		name       string
+		args       string
+		wantResult KeySet
+		wantErr    bool
+	}{
+		{
+			name: "success",
+			args: `{"keys":[` + rsaJwk + `,` + ecJwk + `,` + edJwk + `,{"kty":"RSA","kid":"enc","use":"enc"}]}`,
+			wantResult: KeySet{
+				"rsa": &rsaKey.PublicKey,
+				"ec":  &ecKey.PublicKey,
+				"ed":  edPublic,
+			},
+		},
+		{
+			name:    "error duplicate kid",
+			args:    `{"keys":[` + rsaJwk + `,` + rsaJwk + `]}`,
+			wantErr: true,
+		},
+		{
+			name:    "error point not on curve",
+			args:    fmt.Sprintf(`{"keys":[{"kty":"EC","kid":"ec","crv":"P-384","x":%q,"y":%q}]}`, b64(ecKey.X.Bytes()), b64(ecKey.X.Bytes())),
+			wantErr: true,
+		},
+		{
+			name:    "error unsupported key type",
+			args:    `{"keys":[{"kty":"oct","kid":"hmac","k":"c2VjcmV0"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "error no keys",
+			args:    `{"keys":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "error malformed",
+			args:    `{"keys":`,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseJWKS([]byte(test.args))
+			assert.Equal(t, test.wantErr, err != nil, err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestReadJWKS(t *testing.T) {
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(path, []byte(fmt.Sprintf(`{"keys":[{"kty":"OKP","crv":"Ed25519","x":%q}]}`,
+		base64.RawURLEncoding.EncodeToString(edPublic))), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadJWKS(path)
+	assert.NoError(t, err)
+	assert.Equal(t, KeySet{"": edPublic}, got)
+
+	_, err = ReadJWKS(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
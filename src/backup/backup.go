@@ -0,0 +1,129 @@
+// Package backup reads and writes estate backup archives.
+//
+// An archive is newline delimited JSON: a Header line followed by one
+// domain.ExportEstate per line, each carrying the estate's trees and roles.
+// The header also carries the settings of the organisation the estates
+// belong to. It can be written and read as a stream, and gzip or any other
+// transport encoding can be layered on top.
+//
+// Version 1 archives carry neither roles nor organisation settings; they
+// are still read.
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+)
+
+const (
+	Format  = "sawitpro-estate-backup"
+	Version = 2
+)
+
+type (
+	Header struct {
+		Format       string               `json:"format"`
+		Version      int                  `json:"version"`
+		CreatedAt    time.Time            `json:"createdAt"`
+		Organisation *domain.Organisation `json:"organisation,omitempty"`
+	}
+
+	Writer struct {
+		w      io.Writer
+		enc    *json.Encoder
+		header Header
+		begun  bool
+	}
+
+	Reader struct {
+		Header Header
+		dec    *json.Decoder
+		line   int
+	}
+)
+
+// NewWriter returns a Writer that starts the archive with its header on
+// the first call to Write or Close. organisation is the one the estates
+// belong to.
+func NewWriter(w io.Writer, createdAt time.Time, organisation *domain.Organisation) *Writer {
+	return &Writer{
+		w:      w,
+		enc:    json.NewEncoder(w),
+		header: Header{Format: Format, Version: Version, CreatedAt: createdAt, Organisation: organisation},
+	}
+}
+
+func (w *Writer) Write(estate domain.ExportEstate) error {
+	err := w.begin()
+	if err != nil {
+		return err
+	}
+	return w.enc.Encode(estate)
+}
+
+// Close writes the header of an archive without estates. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	return w.begin()
+}
+
+func (w *Writer) begin() error {
+	if w.begun {
+		return nil
+	}
+	w.begun = true
+	return w.enc.Encode(w.header)
+}
+
+// NewReader reads the archive header and rejects unknown formats and
+// versions.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{dec: json.NewDecoder(r)}
+
+	err := reader.decode(&reader.Header)
+	if errors.Is(err, io.EOF) {
+		return nil, invalid(1, "archive is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reader.Header.Format != Format {
+		return nil, invalid(1, fmt.Sprintf("unknown archive format %q", reader.Header.Format))
+	}
+	if reader.Header.Version < 1 || reader.Header.Version > Version {
+		return nil, invalid(1, fmt.Sprintf("unsupported archive version %d", reader.Header.Version))
+	}
+	return reader, nil
+}
+
+// Next returns the next estate of the archive, or io.EOF after the last.
+func (r *Reader) Next() (*domain.ExportEstate, error) {
+	estate := &domain.ExportEstate{}
+	err := r.decode(estate)
+	if err != nil {
+		return nil, err
+	}
+	return estate, nil
+}
+
+func (r *Reader) decode(v interface{}) error {
+	r.line++
+	err := r.dec.Decode(v)
+	if err == nil || errors.Is(err, io.EOF) {
+		return err
+	}
+	return invalid(r.line, err.Error())
+}
+
+func invalid(line int, message string) error {
+	return domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+		Field:   fmt.Sprintf("archive[%d]", line),
+		Rule:    "archive",
+		Message: message,
+	}})
+}
@@ -0,0 +1,127 @@
+package backup
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	organisation := &domain.Organisation{
+		Id:         "acme",
+		Name:       "Acme",
+		Timezone:   "Asia/Makassar",
+		SizePolicy: domain.SizePolicy{MaxLength: 200},
+		CreatedAt:  createdAt,
+	}
+	estates := []domain.ExportEstate{
+		{
+			Uuid:      common.UtUuid,
+			Length:    6,
+			Width:     3,
+			CreatedAt: createdAt,
+			Trees:     []domain.ExportPalmTree{{Id: 1, X: 2, Y: 1, Height: 10, PlantedAt: createdAt}},
+			Permissions: []domain.EstatePermission{
+				{EstateId: common.UtUuid, Subject: "jwt:alice", Role: domain.RoleAdmin, GrantedBy: "jwt:root", GrantedAt: createdAt},
+			},
+		},
+		{Uuid: "empty", Length: 2, Width: 2, CreatedAt: createdAt, Trees: []domain.ExportPalmTree{}},
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, createdAt, organisation)
+	for _, estate := range estates {
+		assert.NoError(t, w.Write(estate))
+	}
+	assert.NoError(t, w.Close())
+	assert.Equal(t, `{"format":"sawitpro-estate-backup","version":2,"createdAt":"2024-01-02T03:04:05Z",`+
+		`"organisation":{"id":"acme","name":"Acme","timezone":"Asia/Makassar","sizePolicy":{"maxArea":0,"maxLength":200,"maxWidth":0,"plotSize":0},"createdAt":"2024-01-02T03:04:05Z"}}`,
+		strings.SplitN(buf.String(), "\n", 2)[0])
+
+	r, err := NewReader(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, Header{Format: Format, Version: Version, CreatedAt: createdAt, Organisation: organisation}, r.Header)
+
+	got := []domain.ExportEstate{}
+	for {
+		estate, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		got = append(got, *estate)
+	}
+	assert.Equal(t, estates, got)
+}
+
+func TestWriterEmpty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, NewWriter(buf, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), nil).Close())
+	assert.Equal(t, `{"format":"sawitpro-estate-backup","version":2,"createdAt":"2024-01-02T03:04:05Z"}`+"\n", buf.String())
+}
+
+func TestNewReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive string
+		wantErr string
+	}{
+		{
+			name:    "success",
+			archive: `{"format":"sawitpro-estate-backup","version":2,"organisation":{"id":"acme","name":"Acme"}}`,
+		},
+		{
+			name:    "success version 1",
+			archive: `{"format":"sawitpro-estate-backup","version":1}`,
+		},
+		{
+			name:    "error empty",
+			archive: "",
+			wantErr: "archive is empty",
+		},
+		{
+			name:    "error format",
+			archive: `{"format":"tarball","version":1}`,
+			wantErr: `unknown archive format "tarball"`,
+		},
+		{
+			name:    "error version",
+			archive: `{"format":"sawitpro-estate-backup","version":3}`,
+			wantErr: "unsupported archive version 3",
+		},
+		{
+			name:    "error not json",
+			archive: "estates",
+			wantErr: "invalid character",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(test.archive))
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, domain.ErrInvalidInput)
+			details := err.(*domain.Error).Details.([]domain.FieldError)
+			assert.Equal(t, "archive[1]", details[0].Field)
+			assert.Contains(t, details[0].Message, test.wantErr)
+		})
+	}
+}
+
+func TestReaderNextInvalidLine(t *testing.T) {
+	r, err := NewReader(strings.NewReader(`{"format":"sawitpro-estate-backup","version":1}` + "\n" + `{"uuid":"uuid","length":"six"}`))
+	assert.NoError(t, err)
+
+	_, err = r.Next()
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	assert.Equal(t, "archive[2]", err.(*domain.Error).Details.([]domain.FieldError)[0].Field)
+}
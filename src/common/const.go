@@ -4,8 +4,21 @@ const (
 	TransactionContextKey = "TransactionContextKey"
 	PayloadByte           = "PayloadJsonString"
 	ContentTypeJson       = "application/json"
+	ContentTypeProblem    = "application/problem+json"
+	ContentTypeCSV        = "text/csv"
+	ContentTypeNDJSON     = "application/x-ndjson"
 	UtContentType         = "Content-Type"
+	HeaderOrganisationId  = "X-Organisation-Id"
+	HeaderApiKey          = "X-API-Key"
+
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
 
 	UtUuid      = "uuid"
+	UtUuidV4    = "7c9e6679-7425-40de-944b-e07fc1f77ccd"
 	UtSomeError = "some error"
 )
@@ -0,0 +1,432 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EnvConfigFile      = "CONFIG_FILE"
+	EnvDatabaseURL     = "DATABASE_URL"
+	EnvMaxOpenConns    = "DB_MAX_OPEN_CONNS"
+	EnvMaxIdleConns    = "DB_MAX_IDLE_CONNS"
+	EnvConnMaxLifetime = "DB_CONN_MAX_LIFETIME"
+	EnvListenAddr      = "LISTEN_ADDR"
+	EnvValidateResp    = "HTTP_VALIDATE_RESPONSES"
+	EnvMetrics         = "HTTP_METRICS"
+	EnvShutdownTimeout = "HTTP_SHUTDOWN_TIMEOUT"
+	EnvDrainDelay      = "HTTP_DRAIN_DELAY"
+	EnvReadyTimeout    = "HTTP_READINESS_TIMEOUT"
+	EnvReadTimeout     = "HTTP_READ_TIMEOUT"
+	EnvWriteTimeout    = "HTTP_WRITE_TIMEOUT"
+	EnvIdleTimeout     = "HTTP_IDLE_TIMEOUT"
+	EnvRequestTimeout  = "HTTP_REQUEST_TIMEOUT"
+	EnvLongTimeout     = "HTTP_LONG_REQUEST_TIMEOUT"
+	EnvIdempotencyTTL  = "IDEMPOTENCY_TTL"
+	EnvTimezone        = "TIMEZONE"
+	EnvLogLevel        = "LOG_LEVEL"
+	EnvEstateMaxArea   = "ESTATE_MAX_AREA"
+	EnvEstateMaxLength = "ESTATE_MAX_LENGTH"
+	EnvEstateMaxWidth  = "ESTATE_MAX_WIDTH"
+	EnvEstatePlotSize  = "ESTATE_PLOT_SIZE"
+	EnvDebug           = "DEBUG"
+	EnvAuthEnabled     = "AUTH_ENABLED"
+	EnvAuthJWKSFile    = "AUTH_JWKS_FILE"
+	EnvAuthIssuer      = "AUTH_ISSUER"
+	EnvAuthAudience    = "AUTH_AUDIENCE"
+	EnvAuthAdminScope  = "AUTH_ADMIN_SCOPE"
+	EnvAuthLeeway      = "AUTH_LEEWAY"
+
+	EnvRateLimitEnabled        = "RATE_LIMIT_ENABLED"
+	EnvRateLimitTrustProxy     = "RATE_LIMIT_TRUST_PROXY"
+	EnvRateLimitIPRate         = "RATE_LIMIT_IP_RATE"
+	EnvRateLimitIPBurst        = "RATE_LIMIT_IP_BURST"
+	EnvRateLimitCallerRate     = "RATE_LIMIT_CALLER_RATE"
+	EnvRateLimitCallerBurst    = "RATE_LIMIT_CALLER_BURST"
+	EnvRateLimitExpensiveRate  = "RATE_LIMIT_EXPENSIVE_RATE"
+	EnvRateLimitExpensiveBurst = "RATE_LIMIT_EXPENSIVE_BURST"
+
+	EnvTracingExporter    = "TRACING_EXPORTER"
+	EnvTracingEndpoint    = "TRACING_OTLP_ENDPOINT"
+	EnvTracingFile        = "TRACING_FILE"
+	EnvTracingSampleRatio = "TRACING_SAMPLE_RATIO"
+)
+
+var (
+	logLevels        = []string{"debug", "info", "warn", "error", "off"}
+	tracingExporters = []string{"none", "otlp", "stdout", "file"}
+)
+
+type (
+	Config struct {
+		Database  Database  `yaml:"database"`
+		HTTP      HTTP      `yaml:"http"`
+		Auth      Auth      `yaml:"auth"`
+		RateLimit RateLimit `yaml:"rate_limit"`
+		Tracing   Tracing   `yaml:"tracing"`
+		// Estate is the global size policy; organisations override it with
+		// their own limits.
+		Estate   domain.SizePolicy `yaml:"estate"`
+		Timezone string            `yaml:"timezone"`
+		LogLevel string            `yaml:"log_level"`
+		Debug    bool              `yaml:"debug"`
+	}
+
+	Database struct {
+		DSN             string        `yaml:"dsn"`
+		MaxOpenConns    int           `yaml:"max_open_conns"`
+		MaxIdleConns    int           `yaml:"max_idle_conns"`
+		ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	}
+
+	HTTP struct {
+		ListenAddr        string        `yaml:"listen_addr"`
+		ValidateResponses bool          `yaml:"validate_responses"`
+		IdempotencyTTL    time.Duration `yaml:"idempotency_ttl"`
+		// Metrics serves Prometheus metrics on /metrics, without
+		// credentials.
+		Metrics bool `yaml:"metrics"`
+		// ShutdownTimeout is how long in-flight requests may run after
+		// SIGTERM before their connections are closed.
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+		// DrainDelay is how long /readyz fails after SIGTERM before the
+		// listener is closed, so load balancers stop sending traffic first.
+		DrainDelay time.Duration `yaml:"drain_delay"`
+		// ReadinessTimeout bounds the database checks of /readyz.
+		ReadinessTimeout time.Duration `yaml:"readiness_timeout"`
+		// ReadTimeout, WriteTimeout and IdleTimeout are those of the
+		// server: for reading a request, writing its response and keeping
+		// an idle connection open. 0 means no limit.
+		ReadTimeout  time.Duration `yaml:"read_timeout"`
+		WriteTimeout time.Duration `yaml:"write_timeout"`
+		IdleTimeout  time.Duration `yaml:"idle_timeout"`
+		// RequestTimeout is the deadline of a request, after which it fails
+		// with 504. Expensive routes, such as drone plans, imports and
+		// exports, get LongRequestTimeout instead.
+		RequestTimeout     time.Duration `yaml:"request_timeout"`
+		LongRequestTimeout time.Duration `yaml:"long_request_timeout"`
+	}
+
+	// Auth configures authentication. API keys always work while it is
+	// enabled; bearer tokens need a JWKS file to be verified against.
+	Auth struct {
+		Enabled    bool          `yaml:"enabled"`
+		JWKSFile   string        `yaml:"jwks_file"`
+		Issuer     string        `yaml:"issuer"`
+		Audience   string        `yaml:"audience"`
+		AdminScope string        `yaml:"admin_scope"`
+		Leeway     time.Duration `yaml:"leeway"`
+	}
+
+	// RateLimit configures the token buckets requests are limited by: one
+	// per client address, one per API key or token subject, and one per
+	// caller for each expensive endpoint, such as drone plans. TrustProxy
+	// takes the client address from X-Forwarded-For, which is only safe
+	// behind a proxy that sets it.
+	RateLimit struct {
+		Enabled    bool             `yaml:"enabled"`
+		TrustProxy bool             `yaml:"trust_proxy"`
+		IP         domain.RateLimit `yaml:"ip"`
+		Caller     domain.RateLimit `yaml:"caller"`
+		Expensive  domain.RateLimit `yaml:"expensive"`
+	}
+
+	// Tracing configures where OpenTelemetry spans go: nowhere, to an
+	// OTLP/HTTP collector, or as JSON lines to stdout or a file. Root spans
+	// are sampled at SampleRatio, between 0 and 1.
+	Tracing struct {
+		Exporter     string  `yaml:"exporter"`
+		OTLPEndpoint string  `yaml:"otlp_endpoint"`
+		File         string  `yaml:"file"`
+		SampleRatio  float64 `yaml:"sample_ratio"`
+	}
+)
+
+func Default() *Config {
+	return &Config{
+		Database: Database{
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		HTTP: HTTP{
+			ListenAddr:       ":8080",
+			IdempotencyTTL:   24 * time.Hour,
+			Metrics:          true,
+			ShutdownTimeout:  30 * time.Second,
+			DrainDelay:       5 * time.Second,
+			ReadinessTimeout: 2 * time.Second,
+
+			ReadTimeout:        30 * time.Second,
+			WriteTimeout:       60 * time.Second,
+			IdleTimeout:        2 * time.Minute,
+			RequestTimeout:     30 * time.Second,
+			LongRequestTimeout: 10 * time.Minute,
+		},
+		Auth: Auth{
+			Enabled:    true,
+			AdminScope: "admin",
+			Leeway:     time.Minute,
+		},
+		RateLimit: RateLimit{
+			Enabled:   true,
+			IP:        domain.RateLimit{Rate: 50, Burst: 100},
+			Caller:    domain.RateLimit{Rate: 20, Burst: 40},
+			Expensive: domain.RateLimit{Rate: 0.2, Burst: 5},
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+		Estate:   domain.DefaultSizePolicy(),
+		Timezone: "Asia/Jakarta",
+		LogLevel: "info",
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file named by
+// CONFIG_FILE (if any) and finally the environment, then validates it.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path, ok := os.LookupEnv(EnvConfigFile); ok && path != "" {
+		err := cfg.loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := cfg.loadEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: read %s: %w", path, err)
+	}
+
+	err = yaml.Unmarshal(b, c)
+	if err != nil {
+		return fmt.Errorf("config: parse %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	lookupString(EnvDatabaseURL, &c.Database.DSN)
+	lookupString(EnvListenAddr, &c.HTTP.ListenAddr)
+	lookupString(EnvTimezone, &c.Timezone)
+	lookupString(EnvLogLevel, &c.LogLevel)
+	lookupString(EnvAuthJWKSFile, &c.Auth.JWKSFile)
+	lookupString(EnvAuthIssuer, &c.Auth.Issuer)
+	lookupString(EnvAuthAudience, &c.Auth.Audience)
+	lookupString(EnvAuthAdminScope, &c.Auth.AdminScope)
+	lookupString(EnvTracingExporter, &c.Tracing.Exporter)
+	lookupString(EnvTracingEndpoint, &c.Tracing.OTLPEndpoint)
+	lookupString(EnvTracingFile, &c.Tracing.File)
+
+	return errors.Join(
+		lookupInt(EnvMaxOpenConns, &c.Database.MaxOpenConns),
+		lookupInt(EnvMaxIdleConns, &c.Database.MaxIdleConns),
+		lookupDuration(EnvConnMaxLifetime, &c.Database.ConnMaxLifetime),
+		lookupDuration(EnvIdempotencyTTL, &c.HTTP.IdempotencyTTL),
+		lookupDuration(EnvShutdownTimeout, &c.HTTP.ShutdownTimeout),
+		lookupDuration(EnvDrainDelay, &c.HTTP.DrainDelay),
+		lookupDuration(EnvReadyTimeout, &c.HTTP.ReadinessTimeout),
+		lookupDuration(EnvReadTimeout, &c.HTTP.ReadTimeout),
+		lookupDuration(EnvWriteTimeout, &c.HTTP.WriteTimeout),
+		lookupDuration(EnvIdleTimeout, &c.HTTP.IdleTimeout),
+		lookupDuration(EnvRequestTimeout, &c.HTTP.RequestTimeout),
+		lookupDuration(EnvLongTimeout, &c.HTTP.LongRequestTimeout),
+		lookupInt(EnvEstateMaxArea, &c.Estate.MaxArea),
+		lookupInt(EnvEstateMaxLength, &c.Estate.MaxLength),
+		lookupInt(EnvEstateMaxWidth, &c.Estate.MaxWidth),
+		lookupInt(EnvEstatePlotSize, &c.Estate.PlotSize),
+		lookupBool(EnvValidateResp, &c.HTTP.ValidateResponses),
+		lookupBool(EnvMetrics, &c.HTTP.Metrics),
+		lookupBool(EnvDebug, &c.Debug),
+		lookupBool(EnvAuthEnabled, &c.Auth.Enabled),
+		lookupDuration(EnvAuthLeeway, &c.Auth.Leeway),
+		lookupBool(EnvRateLimitEnabled, &c.RateLimit.Enabled),
+		lookupBool(EnvRateLimitTrustProxy, &c.RateLimit.TrustProxy),
+		lookupFloat(EnvRateLimitIPRate, &c.RateLimit.IP.Rate),
+		lookupInt(EnvRateLimitIPBurst, &c.RateLimit.IP.Burst),
+		lookupFloat(EnvRateLimitCallerRate, &c.RateLimit.Caller.Rate),
+		lookupInt(EnvRateLimitCallerBurst, &c.RateLimit.Caller.Burst),
+		lookupFloat(EnvRateLimitExpensiveRate, &c.RateLimit.Expensive.Rate),
+		lookupInt(EnvRateLimitExpensiveBurst, &c.RateLimit.Expensive.Burst),
+		lookupFloat(EnvTracingSampleRatio, &c.Tracing.SampleRatio),
+	)
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Database.DSN == "" {
+		errs = append(errs, fmt.Errorf("config: database.dsn is required (set %s)", EnvDatabaseURL))
+	}
+	if c.Database.MaxOpenConns < 0 {
+		errs = append(errs, fmt.Errorf("config: database.max_open_conns must not be negative, got %d", c.Database.MaxOpenConns))
+	}
+	if c.Database.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("config: database.max_idle_conns must not be negative, got %d", c.Database.MaxIdleConns))
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("config: database.max_idle_conns (%d) must not exceed database.max_open_conns (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns))
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		errs = append(errs, fmt.Errorf("config: database.conn_max_lifetime must not be negative, got %s", c.Database.ConnMaxLifetime))
+	}
+	if c.HTTP.ListenAddr == "" {
+		errs = append(errs, fmt.Errorf("config: http.listen_addr is required (set %s)", EnvListenAddr))
+	}
+	if c.HTTP.IdempotencyTTL <= 0 {
+		errs = append(errs, fmt.Errorf("config: http.idempotency_ttl must be positive, got %s", c.HTTP.IdempotencyTTL))
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("config: http.shutdown_timeout must be positive, got %s", c.HTTP.ShutdownTimeout))
+	}
+	if c.HTTP.DrainDelay < 0 {
+		errs = append(errs, fmt.Errorf("config: http.drain_delay must not be negative, got %s", c.HTTP.DrainDelay))
+	}
+	if c.HTTP.ReadinessTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("config: http.readiness_timeout must be positive, got %s", c.HTTP.ReadinessTimeout))
+	}
+	if c.HTTP.ReadTimeout < 0 {
+		errs = append(errs, fmt.Errorf("config: http.read_timeout must not be negative, got %s", c.HTTP.ReadTimeout))
+	}
+	if c.HTTP.WriteTimeout < 0 {
+		errs = append(errs, fmt.Errorf("config: http.write_timeout must not be negative, got %s", c.HTTP.WriteTimeout))
+	}
+	if c.HTTP.IdleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("config: http.idle_timeout must not be negative, got %s", c.HTTP.IdleTimeout))
+	}
+	if c.HTTP.RequestTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("config: http.request_timeout must be positive, got %s", c.HTTP.RequestTimeout))
+	}
+	if c.HTTP.WriteTimeout > 0 && c.HTTP.RequestTimeout >= c.HTTP.WriteTimeout {
+		errs = append(errs, fmt.Errorf("config: http.request_timeout (%s) must be shorter than http.write_timeout (%s), or the 504 cannot be written", c.HTTP.RequestTimeout, c.HTTP.WriteTimeout))
+	}
+	if c.HTTP.LongRequestTimeout < c.HTTP.RequestTimeout {
+		errs = append(errs, fmt.Errorf("config: http.long_request_timeout (%s) must not be shorter than http.request_timeout (%s)", c.HTTP.LongRequestTimeout, c.HTTP.RequestTimeout))
+	}
+	if c.Auth.Leeway < 0 {
+		errs = append(errs, fmt.Errorf("config: auth.leeway must not be negative, got %s", c.Auth.Leeway))
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		errs = append(errs, fmt.Errorf("config: timezone %q is invalid: %w", c.Timezone, err))
+	}
+	if !contains(logLevels, c.LogLevel) {
+		errs = append(errs, fmt.Errorf("config: log_level %q is invalid, must be one of %s", c.LogLevel, strings.Join(logLevels, ", ")))
+	}
+	if !contains(tracingExporters, c.Tracing.Exporter) {
+		errs = append(errs, fmt.Errorf("config: tracing.exporter %q is invalid, must be one of %s", c.Tracing.Exporter, strings.Join(tracingExporters, ", ")))
+	}
+	if c.Tracing.Exporter == "file" && c.Tracing.File == "" {
+		errs = append(errs, fmt.Errorf("config: tracing.file is required by the file exporter (set %s)", EnvTracingFile))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("config: tracing.sample_ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio))
+	}
+	if err := c.Estate.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("config: estate: %w", err))
+	}
+	if c.RateLimit.Enabled {
+		if err := c.RateLimit.IP.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("config: rate_limit.ip: %w", err))
+		}
+		if err := c.RateLimit.Caller.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("config: rate_limit.caller: %w", err))
+		}
+		if err := c.RateLimit.Expensive.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("config: rate_limit.expensive: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupString(key string, dst *string) {
+	if v, ok := os.LookupEnv(key); ok {
+		*dst = v
+	}
+}
+
+func lookupInt(key string, dst *int) error {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("config: %s must be an integer, got %q", key, v)
+	}
+	*dst = i
+	return nil
+}
+
+func lookupFloat(key string, dst *float64) error {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("config: %s must be a number, got %q", key, v)
+	}
+	*dst = f
+	return nil
+}
+
+func lookupBool(key string, dst *bool) error {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("config: %s must be a boolean, got %q", key, v)
+	}
+	*dst = b
+	return nil
+}
+
+func lookupDuration(key string, dst *time.Duration) error {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("config: %s must be a duration such as 30m, got %q", key, v)
+	}
+	*dst = d
+	return nil
+}
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yml")
+	err := os.WriteFile(file, []byte(`
+database:
+  dsn: postgres://file
+  max_open_conns: 20
+  conn_max_lifetime: 1h
+http:
+  listen_addr: ":1323"
+auth:
+  issuer: https://idp.example.com
+log_level: debug
+estate:
+  plot_size: 10
+  max_length: 20
+rate_limit:
+  expensive:
+    rate: 0.5
+    burst: 2
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantResult func() *Config
+		wantErr    bool
+	}{
+		{
+			name: "success env only",
+			env: map[string]string{
+				EnvDatabaseURL: "postgres://env",
+			},
+			wantResult: func() *Config {
+				cfg := Default()
+				cfg.Database.DSN = "postgres://env"
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "success file with env override",
+			env: map[string]string{
+				EnvConfigFile:      file,
+				EnvMaxIdleConns:    "2",
+				EnvEstateMaxArea:   "1000",
+				EnvDebug:           "true",
+				EnvIdempotencyTTL:  "1h",
+				EnvAuthJWKSFile:    "/etc/estate/jwks.json",
+				EnvAuthEnabled:     "false",
+				EnvMetrics:         "false",
+				EnvShutdownTimeout: "10s",
+				EnvDrainDelay:      "0s",
+				EnvRequestTimeout:  "15s",
+				EnvLongTimeout:     "1m",
+
+				EnvRateLimitExpensiveBurst: "3",
+				EnvRateLimitTrustProxy:     "true",
+				EnvTracingExporter:         "file",
+				EnvTracingFile:             "/tmp/spans.json",
+				EnvTracingSampleRatio:      "0.25",
+			},
+			wantResult: func() *Config {
+				cfg := Default()
+				cfg.Database.DSN = "postgres://file"
+				cfg.Database.MaxOpenConns = 20
+				cfg.Database.MaxIdleConns = 2
+				cfg.Database.ConnMaxLifetime = time.Hour
+				cfg.HTTP.ListenAddr = ":1323"
+				cfg.HTTP.IdempotencyTTL = time.Hour
+				cfg.HTTP.Metrics = false
+				cfg.HTTP.ShutdownTimeout = 10 * time.Second
+				cfg.HTTP.DrainDelay = 0
+				cfg.HTTP.RequestTimeout = 15 * time.Second
+				cfg.HTTP.LongRequestTimeout = time.Minute
+				cfg.Auth.Enabled = false
+				cfg.Auth.JWKSFile = "/etc/estate/jwks.json"
+				cfg.Auth.Issuer = "https://idp.example.com"
+				cfg.Estate.MaxArea = 1000
+				cfg.Estate.MaxLength = 20
+				cfg.RateLimit.TrustProxy = true
+				cfg.RateLimit.Expensive = domain.RateLimit{Rate: 0.5, Burst: 3}
+				cfg.Tracing = Tracing{Exporter: "file", File: "/tmp/spans.json", SampleRatio: 0.25}
+				cfg.LogLevel = "debug"
+				cfg.Debug = true
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "error missing file",
+			env: map[string]string{
+				EnvConfigFile: filepath.Join(dir, "missing.yml"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "error invalid env value",
+			env: map[string]string{
+				EnvDatabaseURL:  "postgres://env",
+				EnvMaxOpenConns: "ten",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "error missing dsn",
+			env:     map[string]string{},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for k, v := range test.env {
+				t.Setenv(k, v)
+			}
+
+			got, err := Load()
+			assert.Equal(t, test.wantErr, err != nil)
+			if !test.wantErr {
+				assert.Equal(t, test.wantResult(), got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "success",
+			mutate: func(cfg *Config) {},
+		},
+		{
+			name: "error idle exceeds open",
+			mutate: func(cfg *Config) {
+				cfg.Database.MaxIdleConns = 20
+			},
+			wantErr: "config: database.max_idle_conns (20) must not exceed database.max_open_conns (10)",
+		},
+		{
+			name: "error idempotency ttl not positive",
+			mutate: func(cfg *Config) {
+				cfg.HTTP.IdempotencyTTL = 0
+			},
+			wantErr: "config: http.idempotency_ttl must be positive, got 0s",
+		},
+		{
+			name: "error shutdown timeout not positive",
+			mutate: func(cfg *Config) {
+				cfg.HTTP.ShutdownTimeout = 0
+			},
+			wantErr: "config: http.shutdown_timeout must be positive, got 0s",
+		},
+		{
+			name: "error drain delay negative",
+			mutate: func(cfg *Config) {
+				cfg.HTTP.DrainDelay = -time.Second
+			},
+			wantErr: "config: http.drain_delay must not be negative, got -1s",
+		},
+		{
+			name: "error readiness timeout not positive",
+			mutate: func(cfg *Config) {
+				cfg.HTTP.ReadinessTimeout = -time.Second
+			},
+			wantErr: "config: http.readiness_timeout must be positive, got -1s",
+		},
+		{
+			name: "success server timeouts disabled",
+			mutate: func(cfg *Config) {
+				cfg.HTTP.ReadTimeout = 0
+				cfg.HTTP.WriteTimeout = 0
+				cfg.HTTP.IdleTimeout = 0
+			},
+		},
+		{
+			name: "error negative write timeout",
+			mutate: func(cfg *Config) {
+				cfg.HTTP.WriteTimeout = -time.Second
+			},
+			wantErr: "config: http.write_timeout must not be negative, got -1s",
+		},
+		{
+			name: "error request timeout not positive",
+			mutate: func(cfg *Config) {
+				cfg.HTTP.RequestTimeout = 0
+			},
+			wantErr: "config: http.request_timeout must be positive, got 0s",
+		},
+		{
+			name: "error request timeout exceeds write timeout",
+			mutate: func(cfg *Config) {
+				cfg.HTTP.RequestTimeout = 2 * time.Minute
+				cfg.HTTP.LongRequestTimeout = 2 * time.Minute
+			},
+			wantErr: "config: http.request_timeout (2m0s) must be shorter than http.write_timeout (1m0s), or the 504 cannot be written",
+		},
+		{
+			name: "error long request timeout shorter",
+			mutate: func(cfg *Config) {
+				cfg.HTTP.LongRequestTimeout = time.Second
+			},
+			wantErr: "config: http.long_request_timeout (1s) must not be shorter than http.request_timeout (30s)",
+		},
+		{
+			name: "error negative auth leeway",
+			mutate: func(cfg *Config) {
+				cfg.Auth.Leeway = -time.Second
+			},
+			wantErr: "config: auth.leeway must not be negative, got -1s",
+		},
+		{
+			name: "error invalid timezone",
+			mutate: func(cfg *Config) {
+				cfg.Timezone = "Mars/Olympus"
+			},
+			wantErr: `config: timezone "Mars/Olympus" is invalid: unknown time zone Mars/Olympus`,
+		},
+		{
+			name: "error invalid log level",
+			mutate: func(cfg *Config) {
+				cfg.LogLevel = "verbose"
+			},
+			wantErr: `config: log_level "verbose" is invalid, must be one of debug, info, warn, error, off`,
+		},
+		{
+			name: "error invalid estate max area",
+			mutate: func(cfg *Config) {
+				cfg.Estate.MaxArea = 0
+			},
+			wantErr: "config: estate: max_area must be greater than 0, got 0",
+		},
+		{
+			name: "error negative estate max width",
+			mutate: func(cfg *Config) {
+				cfg.Estate.MaxWidth = -1
+			},
+			wantErr: "config: estate: max_width must not be negative, got -1",
+		},
+		{
+			name: "error invalid rate limit",
+			mutate: func(cfg *Config) {
+				cfg.RateLimit.Expensive = domain.RateLimit{}
+			},
+			wantErr: "config: rate_limit.expensive: rate must be greater than 0, got 0\nburst must be at least 1, got 0",
+		},
+		{
+			name: "error invalid tracing exporter",
+			mutate: func(cfg *Config) {
+				cfg.Tracing.Exporter = "jaeger"
+			},
+			wantErr: `config: tracing.exporter "jaeger" is invalid, must be one of none, otlp, stdout, file`,
+		},
+		{
+			name: "error tracing file missing",
+			mutate: func(cfg *Config) {
+				cfg.Tracing.Exporter = "file"
+			},
+			wantErr: "config: tracing.file is required by the file exporter (set TRACING_FILE)",
+		},
+		{
+			name: "error tracing sample ratio",
+			mutate: func(cfg *Config) {
+				cfg.Tracing.SampleRatio = 1.5
+			},
+			wantErr: "config: tracing.sample_ratio must be between 0 and 1, got 1.5",
+		},
+		{
+			name: "success rate limit disabled",
+			mutate: func(cfg *Config) {
+				cfg.RateLimit.Enabled = false
+				cfg.RateLimit.Caller = domain.RateLimit{}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Database.DSN = "postgres://test"
+			test.mutate(cfg)
+
+			err := cfg.Validate()
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	// DefaultAuditPageSize is the page size of an audit listing without a
+	// limit.
+	DefaultAuditPageSize = 100
+	// MaxAuditPageSize bounds the entries a single page may hold.
+	MaxAuditPageSize = 1000
+)
+
+// Actions recorded in the audit log, one for each mutating usecase call.
+const (
+	AuditCreateEstate     = "estate.create"
+	AuditReplaceEstate    = "estate.replace"
+	AuditRestoreEstate    = "estate.restore"
+	AuditPlantTree        = "tree.plant"
+	AuditImportTrees      = "tree.import"
+	AuditGrantPermission  = "permission.grant"
+	AuditRevokePermission = "permission.revoke"
+	AuditCreateApiKey     = "apikey.create"
+	AuditRevokeApiKey     = "apikey.revoke"
+	AuditPutOrganisation  = "organisation.put"
+)
+
+// AuditActions lists every action an audit listing can filter on.
+var AuditActions = []string{
+	AuditCreateEstate,
+	AuditReplaceEstate,
+	AuditRestoreEstate,
+	AuditPlantTree,
+	AuditImportTrees,
+	AuditGrantPermission,
+	AuditRevokePermission,
+	AuditCreateApiKey,
+	AuditRevokeApiKey,
+	AuditPutOrganisation,
+}
+
+type (
+	AuditUsecase interface {
+		ListAuditEntries(ctx context.Context, param *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error)
+	}
+
+	// AuditRepository only sees and writes the entries of the organisation
+	// TenantId(ctx) names. Entries are never updated or deleted.
+	AuditRepository interface {
+		// AppendAuditEntry stores the entry in the transaction carried by
+		// ctx, so it is only kept if the change it records is. It fills in
+		// the id and creation time.
+		AppendAuditEntry(ctx context.Context, param *AuditEntry) error
+		// ListAuditEntries returns up to limit entries matching filter,
+		// oldest first, starting after the entry with id after. A zero
+		// after starts from the beginning.
+		ListAuditEntries(ctx context.Context, filter *AuditFilter, after int64, limit int) ([]AuditEntry, error)
+	}
+
+	// AuditEntry records one change: who made it, in which request, and
+	// what the changed record held before and after. Before is empty for a
+	// creation, After for a removal.
+	AuditEntry struct {
+		Id        int64           `json:"id"`
+		Actor     string          `json:"actor"`
+		Action    string          `json:"action"`
+		EstateId  string          `json:"estateId,omitempty"`
+		TreeIds   []int64         `json:"treeIds,omitempty"`
+		Before    json.RawMessage `json:"before,omitempty"`
+		After     json.RawMessage `json:"after,omitempty"`
+		RequestId string          `json:"requestId,omitempty"`
+		CreatedAt time.Time       `json:"createdAt"`
+	}
+
+	// AuditFilter narrows an audit listing. Empty fields do not filter;
+	// From is inclusive and To exclusive.
+	AuditFilter struct {
+		EstateId string
+		TreeId   *int64
+		Actor    string
+		Action   string
+		From     *time.Time
+		To       *time.Time
+	}
+
+	ListAuditEntriesRequest struct {
+		Filter AuditFilter
+		Cursor string
+		Limit  int
+	}
+
+	// ListAuditEntriesResponse is one page of entries. NextCursor is empty
+	// on the last page.
+	ListAuditEntriesResponse struct {
+		Entries    []AuditEntry `json:"entries"`
+		NextCursor string       `json:"nextCursor,omitempty"`
+	}
+)
+
+// NewAuditEntry records action by the principal of ctx within the request
+// of ctx. before and after are stored as JSON; nil leaves them empty.
+func NewAuditEntry(ctx context.Context, action, estateId string, before, after interface{}) (*AuditEntry, error) {
+	entry := &AuditEntry{
+		Action:    action,
+		EstateId:  estateId,
+		RequestId: RequestIdFromContext(ctx),
+	}
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		entry.Actor = principal.Subject
+	}
+
+	var err error
+	entry.Before, err = auditValue(before)
+	if err != nil {
+		return nil, err
+	}
+	entry.After, err = auditValue(after)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func auditValue(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil, err
+	}
+	return b, nil
+}
+
+type requestIdKey struct{}
+
+func WithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+// RequestIdFromContext returns the id of the request being served, or ""
+// outside of one.
+func RequestIdFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
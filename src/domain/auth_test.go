@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleSubject(t *testing.T) {
+	key := &Principal{Subject: "7d0c", Method: AuthMethodApiKey}
+	token := &Principal{Subject: "7d0c", Method: AuthMethodJwt}
+
+	assert.Equal(t, "api_key:7d0c", key.RoleSubject())
+	assert.Equal(t, "jwt:7d0c", token.RoleSubject())
+	assert.NotEqual(t, key.RoleSubject(), token.RoleSubject())
+}
+
+func TestValidRoleSubject(t *testing.T) {
+	tests := []struct {
+		subject    string
+		wantResult bool
+	}{
+		{subject: "api_key:7d0c", wantResult: true},
+		{subject: "jwt:alice", wantResult: true},
+		{subject: "jwt:", wantResult: false},
+		{subject: "alice", wantResult: false},
+		{subject: "none:anonymous", wantResult: false},
+	}
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			assert.Equal(t, test.wantResult, ValidRoleSubject(test.subject))
+		})
+	}
+}
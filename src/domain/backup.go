@@ -0,0 +1,54 @@
+package domain
+
+const (
+	// RestoreConflictFail stops the restore at the first estate whose UUID
+	// already holds different data.
+	RestoreConflictFail = "fail"
+	// RestoreConflictSkip keeps the existing estate and moves on.
+	RestoreConflictSkip = "skip"
+	// RestoreConflictRemap restores the estate under a UUID derived from
+	// the archived one, so restoring the same archive again is a no-op.
+	RestoreConflictRemap = "remap"
+
+	RestoreStatusCreated   = "created"
+	RestoreStatusRemapped  = "remapped"
+	RestoreStatusUnchanged = "unchanged"
+	RestoreStatusSkipped   = "skipped"
+	RestoreStatusConflict  = "conflict"
+)
+
+type (
+	// EstateReader yields the estates of a backup archive. Next returns
+	// io.EOF after the last one.
+	EstateReader interface {
+		Next() (*ExportEstate, error)
+	}
+
+	// RestoreEstatesRequest restores Estates into the organisation of the
+	// request. Organisation holds the settings of the organisation the
+	// archive was taken from; it is nil for archives that do not carry them.
+	RestoreEstatesRequest struct {
+		Conflict     string
+		Organisation *Organisation
+		Estates      EstateReader
+	}
+
+	RestoreEstatesResponse struct {
+		Conflict  string          `json:"conflict"`
+		Total     int             `json:"total"`
+		Created   int             `json:"created"`
+		Remapped  int             `json:"remapped"`
+		Unchanged int             `json:"unchanged"`
+		Skipped   int             `json:"skipped"`
+		Estates   []RestoreResult `json:"estates"`
+	}
+
+	// RestoreResult reports what happened to one archived estate. Uuid is
+	// the estate holding its data after the restore.
+	RestoreResult struct {
+		SourceUuid string `json:"sourceUuid"`
+		Uuid       string `json:"uuid"`
+		Status     string `json:"status"`
+		Trees      int    `json:"trees"`
+	}
+)
@@ -1,10 +1,109 @@
 package domain
 
-import "errors"
+import (
+	"fmt"
+	"net/http"
+)
 
 var (
-	ErrInvalidInput   = errors.New("invalid input")
-	ErrMaxSizeEstate  = errors.New("max size of state exceed 50000")
-	ErrLocationFilled = errors.New("location already filled")
-	ErrEstateNotFound = errors.New("estate not found")
+	ErrInvalidInput     = NewError("invalid_input", http.StatusBadRequest, "invalid input")
+	ErrMaxSizeEstate    = NewError("estate_size_exceeded", http.StatusBadRequest, "max size of estate exceeded")
+	ErrLocationFilled   = NewError("location_filled", http.StatusConflict, "location already filled")
+	ErrOutOfBounds      = NewError("location_out_of_bounds", http.StatusUnprocessableEntity, "location is outside the estate")
+	ErrEstateNotFound   = NewError("estate_not_found", http.StatusNotFound, "estate not found")
+	ErrImportRejected   = NewError("import_rejected", http.StatusUnprocessableEntity, "import rejected")
+	ErrRestoreConflict  = NewError("restore_conflict", http.StatusConflict, "estate already exists with different data")
+	ErrExternalRefTaken = NewError("external_ref_taken", http.StatusConflict, "external reference already belongs to another estate")
+	ErrTreesOutside     = NewError("trees_outside_estate", http.StatusConflict, "estate would leave planted trees outside its bounds")
+	ErrEstateIdTaken    = NewError("estate_id_taken", http.StatusConflict, "estate id is already in use")
+
+	ErrOrganisationNotFound = NewError("organisation_not_found", http.StatusNotFound, "organisation not found")
+
+	ErrUnauthorized   = NewError("unauthorized", http.StatusUnauthorized, "missing or invalid credentials")
+	ErrForbidden      = NewError("forbidden", http.StatusForbidden, "not allowed to perform this action")
+	ErrApiKeyNotFound = NewError("api_key_not_found", http.StatusNotFound, "api key not found")
+
+	ErrPermissionNotFound = NewError("permission_not_found", http.StatusNotFound, "subject holds no role on this estate")
+
+	ErrIdempotencyKeyReused = NewError("idempotency_key_reused", http.StatusUnprocessableEntity, "idempotency key was used for a different request")
+	ErrIdempotencyKeyInUse  = NewError("idempotency_key_in_use", http.StatusConflict, "a request with this idempotency key is still in progress")
+
+	ErrRateLimited = NewError("rate_limited", http.StatusTooManyRequests, "too many requests, retry later")
+
+	ErrNotReady = NewError("not_ready", http.StatusServiceUnavailable, "service is not ready")
+
+	ErrDeadlineExceeded = NewError("deadline_exceeded", http.StatusGatewayTimeout, "request took too long to complete")
+	ErrRequestCanceled  = NewError("request_canceled", http.StatusServiceUnavailable, "request was canceled before it completed")
+)
+
+type (
+	// Error is an error the API exposes to clients. Code is stable and meant
+	// for machines, Message for humans and Details carries structured
+	// context such as the offending fields.
+	Error struct {
+		Code    string
+		Status  int
+		Message string
+		Details interface{}
+		err     error
+	}
+
+	FieldError struct {
+		Field   string `json:"field"`
+		Rule    string `json:"rule"`
+		Param   string `json:"param,omitempty"`
+		Message string `json:"message"`
+	}
+
+	// SizeLimitError reports which limit of a SizePolicy an estate violates.
+	SizeLimitError struct {
+		Limit  string `json:"limit"`
+		Max    int    `json:"max"`
+		Actual int    `json:"actual"`
+	}
 )
+
+func NewError(code string, status int, message string) *Error {
+	return &Error{
+		Code:    code,
+		Status:  status,
+		Message: message,
+	}
+}
+
+// Error returns the client facing message only; the wrapped cause stays
+// reachable through errors.Unwrap.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Is matches any *Error with the same code, so errors.Is keeps working on
+// copies returned by WithDetails and Wrap.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func (e *Error) WithDetails(details interface{}) *Error {
+	err := *e
+	err.Details = details
+	return &err
+}
+
+func (e *Error) Wrap(cause error) *Error {
+	err := *e
+	err.err = cause
+	return &err
+}
+
+func (e *SizeLimitError) Error() string {
+	return fmt.Sprintf("%s: estate %s %d exceeds maximum %d", ErrMaxSizeEstate.Message, e.Limit, e.Actual, e.Max)
+}
+
+func (e *SizeLimitError) Unwrap() error {
+	return ErrMaxSizeEstate.WithDetails(e)
+}
@@ -5,26 +5,65 @@ import "context"
 type (
 	EstateUsecase interface {
 		CreateEstate(ctx context.Context, param *Estate) (*CreateEstateResponse, error)
+		PutEstate(ctx context.Context, id string, param *Estate) (*PutEstateResponse, error)
+		GetEstateByExternalRef(ctx context.Context, ref string) (*Estate, error)
 		PlantPalmTree(ctx context.Context, id string, param *PalmTree) (*PlantPalmTreeResponse, error)
 		GetTreeStats(ctx context.Context, id string) (*GetTreeStatsResponse, error)
+		ListPalmTrees(ctx context.Context, id string, param *ListPalmTreesRequest) (*ListPalmTreesResponse, error)
 		GetDroneFlyingDistance(ctx context.Context, id string, maxDistance int) (*GetDroneFlyingDistanceResponse, error)
+		FindOutOfBoundsPalmTrees(ctx context.Context) (*FindOutOfBoundsPalmTreesResponse, error)
+		ImportPalmTrees(ctx context.Context, id string, param *ImportPalmTreesRequest) (*ImportPalmTreesResponse, error)
+		ExportPalmTrees(ctx context.Context, id string, fn func(EstateExportRow) error) error
+		ExportEstates(ctx context.Context, fn func(ExportEstate) error) error
+		RestoreEstates(ctx context.Context, param *RestoreEstatesRequest) (*RestoreEstatesResponse, error)
+		ListEstatePermissions(ctx context.Context, id string) (*ListEstatePermissionsResponse, error)
+		GrantEstatePermission(ctx context.Context, id, subject string, param *GrantEstatePermissionRequest) (*GrantEstatePermissionResponse, error)
+		RevokeEstatePermission(ctx context.Context, id, subject string) error
 	}
 
+	// EstateRepository only sees and writes the estates of the organisation
+	// TenantId(ctx) names.
 	EstateRepository interface {
 		CreateEstate(ctx context.Context, param *Estate) error
+		// UpsertEstate inserts the estate or replaces the dimensions and
+		// external reference of the one with the same uuid, and reports
+		// whether it was inserted. It fails with ErrEstateIdTaken when
+		// another organisation owns the uuid.
+		UpsertEstate(ctx context.Context, param *Estate) (bool, error)
 		GetEstateByUuid(ctx context.Context, id string) (*Estate, error)
+		// LockEstate reads the estate like GetEstateByUuid and locks it until
+		// the transaction ctx carries ends, serialising the changes to an
+		// estate and its trees.
+		LockEstate(ctx context.Context, id string) (*Estate, error)
+		GetEstateByExternalRef(ctx context.Context, ref string) (*Estate, error)
+		// ExportEstates streams the estates joined with their trees, ordered
+		// by estate and tree, to fn. An empty id exports every estate.
+		ExportEstates(ctx context.Context, id string, fn func(EstateExportRow) error) error
+		// RestoreEstate inserts an archived estate keeping its creation time.
+		RestoreEstate(ctx context.Context, param *ExportEstate) error
 	}
 
 	Estate struct {
-		Uuid   string `json:"uuid"`
-		Length int    `json:"length" validate:"gt=0"`
-		Width  int    `json:"width" validate:"gt=0"`
+		Uuid string `json:"uuid"`
+		// Length and width are capped well above any size policy, only so
+		// that the area of an estate cannot overflow.
+		Length int `json:"length" validate:"gt=0,lte=1000000"`
+		Width  int `json:"width" validate:"gt=0,lte=1000000"`
+		// ExternalRef is the code another system, such as the ERP, knows
+		// the estate by. It is unique among the estates of an organisation;
+		// empty means none.
+		ExternalRef string `json:"externalRef,omitempty" validate:"max=64"`
 	}
 
 	CreateEstateResponse struct {
 		Id string `json:"id"`
 	}
 
+	PutEstateResponse struct {
+		Id      string `json:"id"`
+		Created bool   `json:"created"`
+	}
+
 	PlantPalmTreeResponse struct {
 		Id string `json:"id"`
 	}
@@ -45,4 +84,14 @@ type (
 		X int `json:"x"`
 		Y int `json:"y"`
 	}
+
+	FindOutOfBoundsPalmTreesResponse struct {
+		Count int                   `json:"count"`
+		Trees []OutOfBoundsPalmTree `json:"trees"`
+	}
 )
+
+// Contains reports whether the plot at x, y lies inside the estate.
+func (e *Estate) Contains(x, y int) bool {
+	return x >= 1 && x <= e.Length && y >= 1 && y <= e.Width
+}
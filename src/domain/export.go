@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+type (
+	// EstateExportRow is an estate joined with one of its trees, as streamed
+	// by the repository. Tree is nil for an estate without trees.
+	EstateExportRow struct {
+		Uuid        string
+		Length      int
+		Width       int
+		CreatedAt   time.Time
+		ExternalRef string
+		Tree        *ExportPalmTree
+	}
+
+	ExportPalmTree struct {
+		Id        int64     `json:"id"`
+		X         int       `json:"x"`
+		Y         int       `json:"y"`
+		Height    int       `json:"height"`
+		PlantedAt time.Time `json:"plantedAt"`
+	}
+
+	// ExportEstate is one line of the NDJSON export. Permissions are the
+	// roles held on the estate, so that a restore keeps who may access it.
+	ExportEstate struct {
+		Uuid        string             `json:"uuid"`
+		Length      int                `json:"length"`
+		Width       int                `json:"width"`
+		CreatedAt   time.Time          `json:"createdAt"`
+		ExternalRef string             `json:"externalRef,omitempty"`
+		Trees       []ExportPalmTree   `json:"trees"`
+		Permissions []EstatePermission `json:"permissions,omitempty"`
+	}
+)
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+)
+
+// SchemaVersion is the version of database.sql this code runs against. Bump
+// it together with the schemaVersion row whenever the schema changes, so
+// instances are only ready once the database has been migrated.
+const SchemaVersion = 5
+
+// Outcomes of a readiness check.
+const (
+	HealthStatusPass = "pass"
+	HealthStatusFail = "fail"
+)
+
+// Checks reported by a readiness probe.
+const (
+	HealthCheckShutdown = "shutdown"
+	HealthCheckDatabase = "database"
+	HealthCheckSchema   = "schema"
+)
+
+type (
+	HealthUsecase interface {
+		// GetReadiness checks that the instance can serve requests: it is not
+		// shutting down, PostgreSQL answers and the schema is migrated. It
+		// fails with ErrNotReady, detailing the report, when any check fails.
+		GetReadiness(ctx context.Context) (*Readiness, error)
+		// Drain makes every readiness check fail from now on, so load
+		// balancers stop routing to an instance that is shutting down.
+		Drain()
+	}
+
+	HealthRepository interface {
+		PingDatabase(ctx context.Context) error
+		// GetSchemaVersion returns the highest schema version applied, or 0
+		// when the database predates versioning.
+		GetSchemaVersion(ctx context.Context) (int, error)
+	}
+
+	Readiness struct {
+		Status string        `json:"status"`
+		Checks []HealthCheck `json:"checks,omitempty"`
+	}
+
+	HealthCheck struct {
+		Name    string `json:"name"`
+		Status  string `json:"status"`
+		Message string `json:"message,omitempty"`
+	}
+)
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrIdempotencyReservationLost reports a request that outlived its
+// reservation, which a retry has since taken over.
+var ErrIdempotencyReservationLost = errors.New("idempotency reservation taken over by another request")
+
+type (
+	IdempotencyRepository interface {
+		// ReserveIdempotencyKey claims record.Key within record.Scope for a
+		// request, replacing an expired record. When an unexpired record
+		// already holds the key it is returned and nothing is claimed; a nil
+		// record means the key is now reserved for the caller.
+		ReserveIdempotencyKey(ctx context.Context, record *IdempotencyRecord, now time.Time) (*IdempotencyRecord, error)
+		// SaveIdempotentResponse stores the response of a reserved key. It
+		// fails with ErrIdempotencyReservationLost when record.Token no
+		// longer holds the reservation.
+		SaveIdempotentResponse(ctx context.Context, record *IdempotencyRecord) error
+		// ReleaseIdempotencyKey drops a reservation so the request can be
+		// retried under the same key. A reservation record.Token no longer
+		// holds is left alone.
+		ReleaseIdempotencyKey(ctx context.Context, record *IdempotencyRecord) error
+	}
+
+	// IdempotencyRecord is the stored outcome of a request sent with an
+	// Idempotency-Key. Keys are scoped to the caller and the organisation
+	// it acts for, and Token tells the reservations of one key apart once
+	// an expired one is taken over. StatusCode is zero while the request
+	// is in progress.
+	IdempotencyRecord struct {
+		Scope       string
+		Key         string
+		Token       string
+		RequestHash string
+		StatusCode  int
+		ContentType string
+		Body        []byte
+		ExpiresAt   time.Time
+	}
+)
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultOrganisationId is the organisation of requests whose credentials
+// name none, and the owner of the estates created before organisations
+// existed.
+const DefaultOrganisationId = "default"
+
+type (
+	OrganisationUsecase interface {
+		// ResolveTenant returns the organisation a request of principal acts
+		// for: its own, or requested when set. Only administrators may act
+		// for an organisation other than their own.
+		ResolveTenant(ctx context.Context, principal *Principal, requested string) (*Organisation, error)
+		ListOrganisations(ctx context.Context) ([]Organisation, error)
+		PutOrganisation(ctx context.Context, id string, param *Organisation) (*PutOrganisationResponse, error)
+	}
+
+	OrganisationRepository interface {
+		// GetOrganisation returns the organisation, or nil when there is
+		// none with that id.
+		GetOrganisation(ctx context.Context, id string) (*Organisation, error)
+		ListOrganisations(ctx context.Context) ([]Organisation, error)
+		// UpsertOrganisation inserts the organisation or replaces the
+		// settings of the one with the same id, and reports whether it was
+		// inserted.
+		UpsertOrganisation(ctx context.Context, param *Organisation) (bool, error)
+	}
+
+	// Organisation is a tenant of the service. It owns estates, and its
+	// settings take precedence over the service wide ones: zero size limits
+	// fall back to the global policy and an empty Timezone to TIMEZONE.
+	Organisation struct {
+		Id         string     `json:"id"`
+		Name       string     `json:"name" validate:"required,max=100"`
+		Timezone   string     `json:"timezone,omitempty" validate:"omitempty,timezone"`
+		SizePolicy SizePolicy `json:"sizePolicy"`
+		CreatedAt  time.Time  `json:"createdAt"`
+	}
+
+	PutOrganisationResponse struct {
+		Organisation
+		Created bool `json:"created"`
+	}
+)
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, organisation *Organisation) context.Context {
+	return context.WithValue(ctx, tenantKey{}, organisation)
+}
+
+// TenantFromContext returns the organisation the request acts for, or nil
+// when none was resolved.
+func TenantFromContext(ctx context.Context) *Organisation {
+	organisation, _ := ctx.Value(tenantKey{}).(*Organisation)
+	return organisation
+}
+
+// TenantId returns the id of the organisation the request acts for. The
+// repositories scope every query by it.
+func TenantId(ctx context.Context) string {
+	if organisation := TenantFromContext(ctx); organisation != nil {
+		return organisation.Id
+	}
+	return DefaultOrganisationId
+}
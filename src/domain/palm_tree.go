@@ -3,9 +3,22 @@ package domain
 import "context"
 
 type (
+	// PalmTreeLocationRepository only sees and writes the trees of the
+	// organisation TenantId(ctx) names.
 	PalmTreeLocationRepository interface {
 		GetPalmTreesByUuid(ctx context.Context, id string) ([]PalmTree, error)
+		// ListPalmTrees returns up to limit trees of an estate matching
+		// filter, ordered by sort and then id, starting after the tree with
+		// id after. A zero after starts from the beginning.
+		ListPalmTrees(ctx context.Context, id string, filter *PalmTreeFilter, sort string, after int64, limit int) ([]ExportPalmTree, error)
+		// PlantPalmTree inserts the tree and fills in its id.
 		PlantPalmTree(ctx context.Context, id string, param *PalmTree) error
+		// PlantPalmTrees inserts the trees and fills in their ids.
+		PlantPalmTrees(ctx context.Context, id string, trees []PalmTree) error
+		// RestorePalmTrees inserts archived trees keeping their planting
+		// time, and fills in their new ids.
+		RestorePalmTrees(ctx context.Context, id string, trees []ExportPalmTree) error
+		GetOutOfBoundsPalmTrees(ctx context.Context) ([]OutOfBoundsPalmTree, error)
 	}
 
 	PalmTree struct {
@@ -15,4 +28,10 @@ type (
 		Y      int    `json:"y" validate:"gt=0"`
 		Height int    `json:"height" validate:"gte=1,lte=30"`
 	}
+
+	OutOfBoundsPalmTree struct {
+		PalmTree
+		EstateLength int `json:"estateLength"`
+		EstateWidth  int `json:"estateWidth"`
+	}
 )
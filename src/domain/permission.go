@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Roles a principal can hold on an estate. Each role grants the
+// permissions listed in rolePermissions.
+const (
+	RoleViewer   = "viewer"
+	RoleSurveyor = "surveyor"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+// Permissions checked by the estate usecase.
+const (
+	// PermissionReadEstate covers the estate itself, its trees and stats.
+	PermissionReadEstate = "estate:read"
+	// PermissionPlantTrees covers planting and importing trees.
+	PermissionPlantTrees = "trees:plant"
+	// PermissionReadDronePlan covers the drone flying distance.
+	PermissionReadDronePlan = "drone:read"
+	// PermissionManageEstate covers replacing the estate and managing who
+	// may access it.
+	PermissionManageEstate = "estate:manage"
+)
+
+var rolePermissions = map[string][]string{
+	RoleViewer:   {PermissionReadEstate},
+	RoleSurveyor: {PermissionReadEstate, PermissionPlantTrees},
+	RoleOperator: {PermissionReadEstate, PermissionReadDronePlan},
+	RoleAdmin:    {PermissionReadEstate, PermissionPlantTrees, PermissionReadDronePlan, PermissionManageEstate},
+}
+
+type (
+	EstatePermissionRepository interface {
+		// GetEstateRole returns the role subject holds on the estate, or ""
+		// when it holds none.
+		GetEstateRole(ctx context.Context, id, subject string) (string, error)
+		ListEstatePermissions(ctx context.Context, id string) ([]EstatePermission, error)
+		// ListSubjectPermissions returns the roles subject holds across
+		// every estate.
+		ListSubjectPermissions(ctx context.Context, subject string) ([]EstatePermission, error)
+		// UpsertEstatePermission grants the role, replacing any role the
+		// subject already holds on the estate, and reports whether the
+		// grant is new.
+		UpsertEstatePermission(ctx context.Context, param *EstatePermission) (bool, error)
+		// DeleteEstatePermission reports whether the subject held a role.
+		DeleteEstatePermission(ctx context.Context, id, subject string) (bool, error)
+	}
+
+	// EstatePermission is the role a principal, named by its subject, holds
+	// on one estate.
+	EstatePermission struct {
+		EstateId  string    `json:"estateId"`
+		Subject   string    `json:"subject"`
+		Role      string    `json:"role"`
+		GrantedBy string    `json:"grantedBy"`
+		GrantedAt time.Time `json:"grantedAt"`
+	}
+
+	GrantEstatePermissionRequest struct {
+		Role string `json:"role" validate:"required,oneof=viewer surveyor operator admin"`
+	}
+
+	GrantEstatePermissionResponse struct {
+		EstatePermission
+		Created bool `json:"created"`
+	}
+
+	ListEstatePermissionsResponse struct {
+		Permissions []EstatePermission `json:"permissions"`
+	}
+)
+
+// ValidRole reports whether role is one of the roles above.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleAllows reports whether role grants permission. Unknown roles grant
+// nothing.
+func RoleAllows(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// RateLimitRepository holds the token buckets requests are limited by.
+	// The in-memory one limits each instance on its own; a shared one lets
+	// every instance enforce the same limit.
+	RateLimitRepository interface {
+		// TakeRateLimitToken takes a token from the bucket named key, which
+		// holds up to limit.Burst tokens and regains limit.Rate of them a
+		// second, and reports whether there was one to take. A bucket not
+		// seen before starts full.
+		TakeRateLimitToken(ctx context.Context, key string, limit RateLimit, now time.Time) (*RateLimitResult, error)
+	}
+
+	// RateLimit lets a burst of Burst requests through at once and Rate
+	// requests a second after that.
+	RateLimit struct {
+		Rate  float64 `yaml:"rate"`
+		Burst int     `yaml:"burst"`
+	}
+
+	// RateLimitResult is the state of a bucket after a take. RetryAfter is
+	// how long until a denied request would be let through, Reset how long
+	// until the bucket is full again.
+	RateLimitResult struct {
+		Allowed    bool
+		Remaining  int
+		RetryAfter time.Duration
+		Reset      time.Duration
+	}
+)
+
+func (l RateLimit) Validate() error {
+	var errs []error
+	if l.Rate <= 0 {
+		errs = append(errs, fmt.Errorf("rate must be greater than 0, got %g", l.Rate))
+	}
+	if l.Burst < 1 {
+		errs = append(errs, fmt.Errorf("burst must be at least 1, got %d", l.Burst))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+const (
+	SizeLimitArea   = "area"
+	SizeLimitLength = "length"
+	SizeLimitWidth  = "width"
+)
+
+// SizePolicy bounds the dimensions of an estate. Length and width are
+// counted in plots, each plot being PlotSize metres square. A zero
+// MaxLength or MaxWidth means the dimension is only bound by MaxArea.
+type SizePolicy struct {
+	MaxArea   int `yaml:"max_area" json:"maxArea" validate:"gte=0"`
+	MaxLength int `yaml:"max_length" json:"maxLength" validate:"gte=0"`
+	MaxWidth  int `yaml:"max_width" json:"maxWidth" validate:"gte=0"`
+	PlotSize  int `yaml:"plot_size" json:"plotSize" validate:"gte=0"`
+}
+
+func DefaultSizePolicy() SizePolicy {
+	return SizePolicy{
+		MaxArea:  50000,
+		PlotSize: 10,
+	}
+}
+
+func (p SizePolicy) Validate() error {
+	var errs []error
+	if p.MaxArea <= 0 {
+		errs = append(errs, fmt.Errorf("max_area must be greater than 0, got %d", p.MaxArea))
+	}
+	if p.MaxLength < 0 {
+		errs = append(errs, fmt.Errorf("max_length must not be negative, got %d", p.MaxLength))
+	}
+	if p.MaxWidth < 0 {
+		errs = append(errs, fmt.Errorf("max_width must not be negative, got %d", p.MaxWidth))
+	}
+	if p.PlotSize <= 0 {
+		errs = append(errs, fmt.Errorf("plot_size must be greater than 0, got %d", p.PlotSize))
+	}
+	return errors.Join(errs...)
+}
+
+// Area returns the surface of the estate in square metres. It saturates at
+// math.MaxInt rather than wrapping around, so absurd dimensions cannot slip
+// under MaxArea.
+func (p SizePolicy) Area(estate *Estate) int {
+	area := 1
+	for _, factor := range []int{estate.Length, p.PlotSize, estate.Width, p.PlotSize} {
+		if factor <= 0 {
+			return 0
+		}
+		hi, lo := bits.Mul64(uint64(area), uint64(factor))
+		if hi != 0 || lo > math.MaxInt {
+			return math.MaxInt
+		}
+		area = int(lo)
+	}
+	return area
+}
+
+// Check returns a *SizeLimitError describing the first limit the estate
+// violates, or nil when it fits the policy.
+func (p SizePolicy) Check(estate *Estate) error {
+	if p.MaxLength > 0 && estate.Length > p.MaxLength {
+		return &SizeLimitError{Limit: SizeLimitLength, Max: p.MaxLength, Actual: estate.Length}
+	}
+	if p.MaxWidth > 0 && estate.Width > p.MaxWidth {
+		return &SizeLimitError{Limit: SizeLimitWidth, Max: p.MaxWidth, Actual: estate.Width}
+	}
+	if area := p.Area(estate); area > p.MaxArea {
+		return &SizeLimitError{Limit: SizeLimitArea, Max: p.MaxArea, Actual: area}
+	}
+	return nil
+}
+
+// Override returns the policy with the non-zero fields of override, such
+// as the limits of an organisation, taking precedence.
+func (p SizePolicy) Override(override SizePolicy) SizePolicy {
+	if override.MaxArea != 0 {
+		p.MaxArea = override.MaxArea
+	}
+	if override.MaxLength != 0 {
+		p.MaxLength = override.MaxLength
+	}
+	if override.MaxWidth != 0 {
+		p.MaxWidth = override.MaxWidth
+	}
+	if override.PlotSize != 0 {
+		p.PlotSize = override.PlotSize
+	}
+	return p
+}
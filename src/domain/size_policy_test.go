@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizePolicyCheck(t *testing.T) {
+	policy := DefaultSizePolicy()
+
+	tests := []struct {
+		name       string
+		policy     SizePolicy
+		estate     *Estate
+		wantResult error
+	}{
+		{
+			name:   "success within area",
+			policy: policy,
+			estate: &Estate{Length: 25, Width: 20},
+		},
+		{
+			name:       "error area exceeded",
+			policy:     policy,
+			estate:     &Estate{Length: 26, Width: 20},
+			wantResult: &SizeLimitError{Limit: SizeLimitArea, Max: 50000, Actual: 52000},
+		},
+		{
+			name:       "error length exceeded",
+			policy:     SizePolicy{MaxArea: 50000, MaxLength: 10, PlotSize: 10},
+			estate:     &Estate{Length: 11, Width: 1},
+			wantResult: &SizeLimitError{Limit: SizeLimitLength, Max: 10, Actual: 11},
+		},
+		{
+			name:       "error area overflowing int",
+			policy:     policy,
+			estate:     &Estate{Length: 350000000, Width: 350000000},
+			wantResult: &SizeLimitError{Limit: SizeLimitArea, Max: 50000, Actual: math.MaxInt},
+		},
+		{
+			name:       "error dimensions near max int",
+			policy:     SizePolicy{MaxArea: math.MaxInt - 1, PlotSize: 1},
+			estate:     &Estate{Length: math.MaxInt/2 + 1, Width: 2},
+			wantResult: &SizeLimitError{Limit: SizeLimitArea, Max: math.MaxInt - 1, Actual: math.MaxInt},
+		},
+		{
+			name:   "success area of max int",
+			policy: SizePolicy{MaxArea: math.MaxInt, PlotSize: 1},
+			estate: &Estate{Length: math.MaxInt, Width: 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.wantResult, test.policy.Check(test.estate))
+		})
+	}
+}
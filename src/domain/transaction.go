@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// Transactor runs fn in a transaction carried by the context it is given,
+// so every repository call made with that context takes part in it.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,37 @@
+package domain
+
+const (
+	// ImportModeAtomic plants every row or none of them.
+	ImportModeAtomic = "atomic"
+	// ImportModeBestEffort plants the valid rows and reports the rest.
+	ImportModeBestEffort = "best-effort"
+
+	// MaxImportRows bounds the number of trees a single import may carry.
+	MaxImportRows = 10000
+)
+
+type (
+	ImportPalmTreesRequest struct {
+		Mode  string
+		Trees []PalmTree
+	}
+
+	// ImportPalmTreesResponse is the per-row report of an import. Rows are
+	// numbered from 1 in the order they appear in the file.
+	ImportPalmTreesResponse struct {
+		Id       string           `json:"id"`
+		Mode     string           `json:"mode"`
+		Total    int              `json:"total"`
+		Imported int              `json:"imported"`
+		Rejected int              `json:"rejected"`
+		Errors   []ImportRowError `json:"errors"`
+	}
+
+	ImportRowError struct {
+		Row     int    `json:"row"`
+		X       int    `json:"x"`
+		Y       int    `json:"y"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+)
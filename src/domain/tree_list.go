@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+const (
+	// DefaultPalmTreePageSize is the page size of a listing without a limit.
+	DefaultPalmTreePageSize = 100
+	// MaxPalmTreePageSize bounds the trees a single page may hold.
+	MaxPalmTreePageSize = 1000
+)
+
+// PalmTreeSortKeys are the columns a tree listing can be sorted on. A key
+// prefixed with "-" sorts in descending order.
+var PalmTreeSortKeys = []string{"id", "height", "x", "y", "plantedAt"}
+
+// InvalidCursor reports a cursor that is not the nextCursor of a previous
+// page of the same estate.
+var InvalidCursor = FieldError{
+	Field:   "cursor",
+	Rule:    "cursor",
+	Message: "cursor must be the nextCursor of a previous page",
+}
+
+type (
+	// PalmTreeFilter narrows a tree listing. Nil fields do not filter; the
+	// ranges are inclusive except PlantedBefore.
+	PalmTreeFilter struct {
+		MinHeight     *int
+		MaxHeight     *int
+		MinX          *int
+		MaxX          *int
+		MinY          *int
+		MaxY          *int
+		PlantedAfter  *time.Time
+		PlantedBefore *time.Time
+	}
+
+	ListPalmTreesRequest struct {
+		Filter PalmTreeFilter
+		Sort   string
+		Cursor string
+		Limit  int
+	}
+
+	// ListPalmTreesResponse is one page of trees. NextCursor is empty on the
+	// last page.
+	ListPalmTreesResponse struct {
+		Trees      []ExportPalmTree `json:"trees"`
+		NextCursor string           `json:"nextCursor,omitempty"`
+	}
+)
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+)
+
+func (e *estateHandler) ListApiKeys(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	keys, err := e.authUsecase.ListApiKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	response := helper.Response(http.StatusOK, "Success list api keys", keys, nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (e *estateHandler) CreateApiKey(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	payload := &domain.CreateApiKeyRequest{}
+	err := json.NewDecoder(c.Request().Body).Decode(&payload)
+	if err != nil {
+		return helper.DecodeError(err)
+	}
+	err = c.Validate(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.authUsecase.CreateApiKey(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	response := helper.Response(http.StatusCreated, "Success create api key", resp, nil)
+	return c.JSON(http.StatusCreated, response)
+}
+
+func (e *estateHandler) RevokeApiKey(c echo.Context, keyId string) error {
+	ctx := c.Request().Context()
+
+	err := e.authUsecase.RevokeApiKey(ctx, keyId)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
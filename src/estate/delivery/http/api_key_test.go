@@ -0,0 +1,203 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListApiKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_domain.NewMockAuthUsecase(ctrl)
+	handler := &estateHandler{
+		authUsecase: authMock,
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	revokedAt := createdAt.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		wantResult string
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: `{"code":200,"message":"Success list api keys","data":[{"id":"uuid","name":"ci","prefix":"spk_abcdefgh","createdBy":"alice","createdAt":"2024-01-02T03:04:05Z","revokedAt":"2024-01-02T04:04:05Z"}],"errors":null}
+`,
+			mock: func() {
+				authMock.EXPECT().ListApiKeys(gomock.Any()).Return([]domain.ApiKey{{
+					Id:        common.UtUuid,
+					Name:      "ci",
+					Prefix:    "spk_abcdefgh",
+					Hash:      "hash",
+					CreatedBy: "alice",
+					CreatedAt: createdAt,
+					RevokedAt: &revokedAt,
+				}}, nil)
+			},
+		},
+		{
+			name: "error forbidden",
+			wantResult: `{"code":403,"message":"not allowed to perform this action","data":null,"errors":"not allowed to perform this action","errorCode":"forbidden"}
+`,
+			mock: func() {
+				authMock.EXPECT().ListApiKeys(gomock.Any()).Return(nil, domain.ErrForbidden)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/api-keys", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.ListApiKeys(c)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestCreateApiKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_domain.NewMockAuthUsecase(ctrl)
+	handler := &estateHandler{
+		authUsecase: authMock,
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		args       string
+		wantResult string
+		mock       func()
+	}{
+		{
+			name: "success",
+			args: `{"name":"ci"}`,
+			wantResult: `{"code":201,"message":"Success create api key","data":{"id":"uuid","name":"ci","prefix":"spk_abcdefgh","createdBy":"alice","createdAt":"2024-01-02T03:04:05Z","key":"spk_abcdefghijk"},"errors":null}
+`,
+			mock: func() {
+				authMock.EXPECT().CreateApiKey(gomock.Any(), &domain.CreateApiKeyRequest{Name: "ci"}).
+					Return(&domain.CreateApiKeyResponse{
+						ApiKey: domain.ApiKey{
+							Id:        common.UtUuid,
+							Name:      "ci",
+							Prefix:    "spk_abcdefgh",
+							Hash:      "hash",
+							CreatedBy: "alice",
+							CreatedAt: createdAt,
+						},
+						Key: "spk_abcdefghijk",
+					}, nil)
+			},
+		},
+		{
+			name: "error usecase",
+			args: `{"name":"ci"}`,
+			wantResult: `{"code":500,"message":"internal server error","data":null,"errors":"internal server error","errorCode":"internal_error"}
+`,
+			mock: func() {
+				authMock.EXPECT().CreateApiKey(gomock.Any(), gomock.Any()).Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+		{
+			name: "error invalid input",
+			args: `{"name":""}`,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"name","rule":"required","message":"is required"}],"errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = helper.NewValidator()
+			req := httptest.NewRequest(http.MethodPost, "/admin/api-keys", strings.NewReader(test.args))
+			req.Header.Set(common.UtContentType, common.ContentTypeJson)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.CreateApiKey(c)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_domain.NewMockAuthUsecase(ctrl)
+	handler := &estateHandler{
+		authUsecase: authMock,
+	}
+
+	tests := []struct {
+		name       string
+		wantCode   int
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:     "success",
+			wantCode: http.StatusNoContent,
+			mock: func() {
+				authMock.EXPECT().RevokeApiKey(gomock.Any(), common.UtUuid).Return(nil)
+			},
+		},
+		{
+			name:     "error not found",
+			wantCode: http.StatusNotFound,
+			wantResult: `{"code":404,"message":"api key not found","data":null,"errors":"api key not found","errorCode":"api_key_not_found"}
+`,
+			mock: func() {
+				authMock.EXPECT().RevokeApiKey(gomock.Any(), common.UtUuid).Return(domain.ErrApiKeyNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodDelete, "/admin/api-keys/"+common.UtUuid, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.RevokeApiKey(c, common.UtUuid)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/davidyunus/sawitpro-estate/generated"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+)
+
+func (e *estateHandler) ListAuditEntries(c echo.Context, params generated.ListAuditEntriesParams) error {
+	ctx := c.Request().Context()
+
+	param := &domain.ListAuditEntriesRequest{
+		Filter: domain.AuditFilter{
+			TreeId: params.TreeId,
+			From:   params.From,
+			To:     params.To,
+		},
+	}
+	if params.EstateId != nil {
+		param.Filter.EstateId = *params.EstateId
+	}
+	if params.Actor != nil {
+		param.Filter.Actor = *params.Actor
+	}
+	if params.Action != nil {
+		param.Filter.Action = string(*params.Action)
+	}
+	if params.Cursor != nil {
+		param.Cursor = *params.Cursor
+	}
+	if params.Limit != nil {
+		param.Limit = *params.Limit
+	}
+
+	entries, err := e.auditUsecase.ListAuditEntries(ctx, param)
+	if err != nil {
+		return err
+	}
+
+	response := helper.Response(http.StatusOK, "Success list audit entries", entries, nil)
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/generated"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListAuditEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditMock := mock_domain.NewMockAuditUsecase(ctrl)
+	handler := &estateHandler{
+		auditUsecase: auditMock,
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	treeId := int64(9)
+	action := generated.ListAuditEntriesParamsAction(domain.AuditPlantTree)
+	limit := 1
+
+	tests := []struct {
+		name       string
+		params     generated.ListAuditEntriesParams
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:   "success",
+			params: generated.ListAuditEntriesParams{TreeId: &treeId, Action: &action, Limit: &limit},
+			wantResult: `{"code":200,"message":"Success list audit entries","data":{"entries":[{"id":1,"actor":"alice","action":"tree.plant","estateId":"uuid","treeIds":[9],"after":{"x":1},"requestId":"req-1","createdAt":"2024-01-02T03:04:05Z"}],"nextCursor":"1"},"errors":null}
+`,
+			mock: func() {
+				auditMock.EXPECT().ListAuditEntries(gomock.Any(), &domain.ListAuditEntriesRequest{
+					Filter: domain.AuditFilter{TreeId: &treeId, Action: domain.AuditPlantTree},
+					Limit:  1,
+				}).Return(&domain.ListAuditEntriesResponse{
+					Entries: []domain.AuditEntry{{
+						Id:        1,
+						Actor:     "alice",
+						Action:    domain.AuditPlantTree,
+						EstateId:  "uuid",
+						TreeIds:   []int64{9},
+						After:     []byte(`{"x":1}`),
+						RequestId: "req-1",
+						CreatedAt: createdAt,
+					}},
+					NextCursor: "1",
+				}, nil)
+			},
+		},
+		{
+			name: "error forbidden",
+			wantResult: `{"code":403,"message":"not allowed to perform this action","data":null,"errors":"not allowed to perform this action","errorCode":"forbidden"}
+`,
+			mock: func() {
+				auditMock.EXPECT().ListAuditEntries(gomock.Any(), &domain.ListAuditEntriesRequest{}).Return(nil, domain.ErrForbidden)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/audit", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.ListAuditEntries(c, test.params)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
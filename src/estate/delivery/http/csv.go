@@ -0,0 +1,110 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+)
+
+// exportFlushRows is how many CSV rows are buffered before they are
+// flushed to the client.
+const exportFlushRows = 500
+
+var (
+	csvColumns = []string{"x", "y", "height"}
+
+	csvExportHeader = []string{
+		"estate_id", "estate_length", "estate_width", "estate_created_at",
+		"tree_id", "x", "y", "height", "planted_at",
+	}
+)
+
+// decodePalmTreesCSV reads palm trees from a CSV file whose header names
+// the x, y and height columns in any order. Cells that are not integers
+// are reported together as field errors of the 1-based data row.
+func decodePalmTreesCSV(r io.Reader) ([]domain.PalmTree, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return []domain.PalmTree{}, nil
+	}
+	if err != nil {
+		return nil, domain.ErrInvalidInput.Wrap(err)
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	fieldErrors := []domain.FieldError{}
+	for _, column := range csvColumns {
+		if _, ok := index[column]; !ok {
+			fieldErrors = append(fieldErrors, domain.FieldError{
+				Field:   column,
+				Rule:    "required",
+				Message: "csv header is missing the " + column + " column",
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return nil, domain.ErrInvalidInput.WithDetails(fieldErrors)
+	}
+
+	trees := []domain.PalmTree{}
+	for row := 1; len(trees) <= domain.MaxImportRows; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+				Field:   fmt.Sprintf("rows[%d]", row),
+				Rule:    "csv",
+				Message: err.Error(),
+			}}).Wrap(err)
+		}
+
+		values := make([]int, len(csvColumns))
+		for i, column := range csvColumns {
+			values[i], err = strconv.Atoi(strings.TrimSpace(record[index[column]]))
+			if err != nil {
+				fieldErrors = append(fieldErrors, domain.FieldError{
+					Field:   fmt.Sprintf("rows[%d].%s", row, column),
+					Rule:    "type",
+					Param:   "integer",
+					Message: column + " must be an integer",
+				})
+			}
+		}
+		trees = append(trees, domain.PalmTree{X: values[0], Y: values[1], Height: values[2]})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, domain.ErrInvalidInput.WithDetails(fieldErrors)
+	}
+
+	return trees, nil
+}
+
+// csvExportRecord formats a row of the tree export in csvExportHeader
+// order. Only rows holding a tree are exported.
+func csvExportRecord(row domain.EstateExportRow) []string {
+	return []string{
+		row.Uuid,
+		strconv.Itoa(row.Length),
+		strconv.Itoa(row.Width),
+		row.CreatedAt.Format(time.RFC3339),
+		strconv.FormatInt(row.Tree.Id, 10),
+		strconv.Itoa(row.Tree.X),
+		strconv.Itoa(row.Tree.Y),
+		strconv.Itoa(row.Tree.Height),
+		row.Tree.PlantedAt.Format(time.RFC3339),
+	}
+}
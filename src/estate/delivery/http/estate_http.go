@@ -1,160 +1,368 @@
 package http
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"log/slog"
+	"mime"
 	"net/http"
-	"strconv"
+	"time"
 
+	"github.com/davidyunus/sawitpro-estate/generated"
+	"github.com/davidyunus/sawitpro-estate/src/backup"
+	"github.com/davidyunus/sawitpro-estate/src/common"
 	"github.com/davidyunus/sawitpro-estate/src/domain"
 	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
 	"github.com/labstack/echo/v4"
 )
 
+var _ generated.ServerInterface = (*estateHandler)(nil)
+
 type estateHandler struct {
-	estateUsecase domain.EstateUsecase
+	estateUsecase       domain.EstateUsecase
+	authUsecase         domain.AuthUsecase
+	organisationUsecase domain.OrganisationUsecase
+	auditUsecase        domain.AuditUsecase
+	healthUsecase       domain.HealthUsecase
 }
 
-func NewEstateHandler(e *echo.Echo, estateUsecase domain.EstateUsecase) {
-	handler := estateHandler{
-		estateUsecase: estateUsecase,
+func NewEstateHandler(e *echo.Echo, estateUsecase domain.EstateUsecase, authUsecase domain.AuthUsecase, organisationUsecase domain.OrganisationUsecase, auditUsecase domain.AuditUsecase, healthUsecase domain.HealthUsecase) {
+	handler := &estateHandler{
+		estateUsecase:       estateUsecase,
+		authUsecase:         authUsecase,
+		organisationUsecase: organisationUsecase,
+		auditUsecase:        auditUsecase,
+		healthUsecase:       healthUsecase,
 	}
 
-	e.POST("/estate", handler.CreateEstate)
-	e.POST(`/estate/:id/tree`, handler.PlantPalmTree)
-	e.GET("/estate/:id/stats", handler.GetTreeStats)
-	e.GET("/estate/:id/drone-plan", handler.GetDroneFlyingDistance)
+	generated.RegisterHandlers(router{e}, handler)
 }
 
-// @Summary Create Estate
-// @Description Create Estate
-// @Tags estates
-// @Accept  json
-// @Produce  json
-// @Param   estate body domain.Estate true "Estate Payload"
-// @Success 201 {object} domain.Estate
-// @Failure 400 {object} helper.HttpResponse
-// @Router /estate [post]
-func (e *estateHandler) CreateEstate(c echo.Context) error {
+func (e *estateHandler) CreateEstate(c echo.Context, _ generated.CreateEstateParams) error {
 	ctx := c.Request().Context()
 
 	payload := &domain.Estate{}
 	err := json.NewDecoder(c.Request().Body).Decode(&payload)
 	if err != nil {
-		err = domain.ErrInvalidInput
-		code := helper.GetStatusCode(err)
-		return c.JSON(code, helper.Response(code, err.Error(), nil, err.Error()))
+		return helper.DecodeError(err)
 	}
-	c.Echo().Validator = helper.NewValidator()
-	err = c.Echo().Validator.Validate(payload)
+	err = c.Validate(payload)
 	if err != nil {
-		err = domain.ErrInvalidInput
-		code := helper.GetStatusCode(err)
-		return c.JSON(code, helper.Response(code, err.Error(), nil, err.Error()))
+		return err
 	}
 
 	resp, err := e.estateUsecase.CreateEstate(ctx, payload)
 	if err != nil {
-		response := helper.Response(http.StatusBadRequest, err.Error(), nil, err.Error())
-		return c.JSON(response.Code, response)
+		return err
 	}
+	metrics.EstatesCreated.Inc()
 
 	response := helper.Response(http.StatusCreated, "Success create estate", resp, nil)
 	return c.JSON(http.StatusCreated, response)
 }
 
-// @Summary Plant Palm Tree
-// @Description Plant a palm tree in an estate
-// @Tags estates
-// @Accept  json
-// @Produce  json
-// @Param   id    path  string           true "Estate ID"
-// @Param   tree  body  domain.PalmTree  true "Palm Tree Payload"
-// @Success 201 {object} nil
-// @Failure 400 {object} helper.HttpResponse
-// @Router /estate/{id}/tree [post]
-func (e *estateHandler) PlantPalmTree(c echo.Context) error {
+func (e *estateHandler) PutEstate(c echo.Context, id string) error {
+	ctx := c.Request().Context()
+
+	payload := &domain.Estate{}
+	err := json.NewDecoder(c.Request().Body).Decode(&payload)
+	if err != nil {
+		return helper.DecodeError(err)
+	}
+	err = c.Validate(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.estateUsecase.PutEstate(ctx, id, payload)
+	if err != nil {
+		return err
+	}
+
+	if resp.Created {
+		metrics.EstatesCreated.Inc()
+		response := helper.Response(http.StatusCreated, "Success create estate", resp, nil)
+		return c.JSON(http.StatusCreated, response)
+	}
+	response := helper.Response(http.StatusOK, "Success replace estate", resp, nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (e *estateHandler) FindEstateByExternalRef(c echo.Context, params generated.FindEstateByExternalRefParams) error {
+	ctx := c.Request().Context()
+
+	estate, err := e.estateUsecase.GetEstateByExternalRef(ctx, params.ExternalRef)
+	if err != nil {
+		return err
+	}
+
+	response := helper.Response(http.StatusOK, "Success find estate", estate, nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (e *estateHandler) PlantPalmTree(c echo.Context, id generated.EstateUuid, _ generated.PlantPalmTreeParams) error {
 	ctx := c.Request().Context()
-	id := c.Param("id")
 
 	payload := &domain.PalmTree{}
 	err := json.NewDecoder(c.Request().Body).Decode(&payload)
 	if err != nil {
-		err = domain.ErrInvalidInput
-		code := helper.GetStatusCode(err)
-		return c.JSON(code, helper.Response(code, err.Error(), nil, err.Error()))
+		return helper.DecodeError(err)
 	}
-	c.Echo().Validator = helper.NewValidator()
-	err = c.Echo().Validator.Validate(payload)
+	err = c.Validate(payload)
 	if err != nil {
-		err = domain.ErrInvalidInput
-		code := helper.GetStatusCode(err)
-		return c.JSON(code, helper.Response(code, err.Error(), nil, err.Error()))
+		return err
 	}
 	resp, err := e.estateUsecase.PlantPalmTree(ctx, id, payload)
 	if err != nil {
-		code := helper.GetStatusCode(err)
-		response := helper.Response(code, err.Error(), nil, err.Error())
-		return c.JSON(response.Code, response)
+		return err
 	}
+	metrics.PalmTreesPlanted.Inc()
 
 	response := helper.Response(http.StatusCreated, "Success plant palm tree", resp, nil)
 	return c.JSON(http.StatusCreated, response)
 }
 
-// @Summary Get Tree Stats
-// @Description Get statistics of trees in an estate
-// @Tags estates
-// @Accept  json
-// @Produce  json
-// @Param   id    path  string  true "Estate ID"
-// @Success 200 {object} domain.GetTreeStatsResponse
-// @Failure 400 {object} helper.HttpResponse
-// @Router /estate/{id}/stats [get]
-func (e *estateHandler) GetTreeStats(c echo.Context) error {
+func (e *estateHandler) GetTreeStats(c echo.Context, id generated.EstateUuid) error {
 	ctx := c.Request().Context()
-	id := c.Param("id")
 
 	treeStats, err := e.estateUsecase.GetTreeStats(ctx, id)
 	if err != nil {
-		code := helper.GetStatusCode(err)
-		response := helper.Response(code, err.Error(), nil, err.Error())
-		return c.JSON(response.Code, response)
+		return err
 	}
 
 	response := helper.Response(http.StatusOK, "Success get tree stats", treeStats, nil)
-	return c.JSON(http.StatusCreated, response)
+	return c.JSON(http.StatusOK, response)
 }
 
-// @Summary Get Drone Flying Distance
-// @Description Get the flying distance plan for a drone in an estate
-// @Tags estates
-// @Accept  json
-// @Produce  json
-// @Param   id            path    string  true  "Estate ID"
-// @Param   max-distance  query   string  false "Maximum Distance (optional)"
-// @Success 200 {object} domain.GetDroneFlyingDistanceResponse
-// @Failure 400 {object} helper.HttpResponse
-// @Router /estate/{id}/drone-plan [get]
-func (e *estateHandler) GetDroneFlyingDistance(c echo.Context) error {
+func (e *estateHandler) ListPalmTrees(c echo.Context, id generated.EstateUuid, params generated.ListPalmTreesParams) error {
 	ctx := c.Request().Context()
-	id := c.Param("id")
-	maxDistance := c.Request().URL.Query().Get("max-distance")
-	if maxDistance == "" {
-		maxDistance = "0"
+
+	param := &domain.ListPalmTreesRequest{
+		Filter: domain.PalmTreeFilter{
+			MinHeight:     params.MinHeight,
+			MaxHeight:     params.MaxHeight,
+			MinX:          params.MinX,
+			MaxX:          params.MaxX,
+			MinY:          params.MinY,
+			MaxY:          params.MaxY,
+			PlantedAfter:  params.PlantedAfter,
+			PlantedBefore: params.PlantedBefore,
+		},
 	}
-	md, err := strconv.Atoi(maxDistance)
+	if params.Sort != nil {
+		param.Sort = string(*params.Sort)
+	}
+	if params.Cursor != nil {
+		param.Cursor = *params.Cursor
+	}
+	if params.Limit != nil {
+		param.Limit = *params.Limit
+	}
+
+	trees, err := e.estateUsecase.ListPalmTrees(ctx, id, param)
 	if err != nil {
-		response := helper.Response(http.StatusBadRequest, err.Error(), nil, err.Error())
-		return c.JSON(response.Code, response)
+		return err
 	}
 
-	distance, err := e.estateUsecase.GetDroneFlyingDistance(ctx, id, md)
+	response := helper.Response(http.StatusOK, "Success list palm trees", trees, nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (e *estateHandler) GetDroneFlyingDistance(c echo.Context, id generated.EstateUuid, params generated.GetDroneFlyingDistanceParams) error {
+	ctx := c.Request().Context()
+	maxDistance := 0
+	if params.MaxDistance != nil {
+		maxDistance = *params.MaxDistance
+	}
+
+	start := time.Now()
+	distance, err := e.estateUsecase.GetDroneFlyingDistance(ctx, id, maxDistance)
 	if err != nil {
-		code := helper.GetStatusCode(err)
-		response := helper.Response(code, err.Error(), nil, err.Error())
-		return c.JSON(response.Code, response)
+		return err
 	}
+	metrics.DronePlanDuration.Observe(time.Since(start).Seconds())
 
 	response := helper.Response(http.StatusOK, "Success get drone flying distance", distance, nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (e *estateHandler) FindOutOfBoundsPalmTrees(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	trees, err := e.estateUsecase.FindOutOfBoundsPalmTrees(ctx)
+	if err != nil {
+		return err
+	}
+
+	response := helper.Response(http.StatusOK, "Success find out of bounds trees", trees, nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (e *estateHandler) ImportPalmTrees(c echo.Context, id generated.EstateUuid, params generated.ImportPalmTreesParams) error {
+	ctx := c.Request().Context()
+
+	param := &domain.ImportPalmTreesRequest{}
+	if params.Mode != nil {
+		param.Mode = string(*params.Mode)
+	}
+
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType == common.ContentTypeCSV {
+		param.Trees, err = decodePalmTreesCSV(c.Request().Body)
+	} else {
+		err = json.NewDecoder(c.Request().Body).Decode(&param.Trees)
+		if err != nil {
+			err = helper.DecodeError(err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.estateUsecase.ImportPalmTrees(ctx, id, param)
+	if err != nil {
+		return err
+	}
+	metrics.PalmTreesPlanted.Add(float64(resp.Imported))
+
+	response := helper.Response(http.StatusCreated, "Success import palm trees", resp, nil)
 	return c.JSON(http.StatusCreated, response)
 }
+
+func (e *estateHandler) ExportPalmTreesCsv(c echo.Context, id generated.EstateUuid) error {
+	ctx := c.Request().Context()
+
+	w := csv.NewWriter(c.Response())
+	rows := 0
+	start := func() error {
+		c.Response().Header().Set(echo.HeaderContentType, common.ContentTypeCSV)
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="trees.csv"`)
+		c.Response().WriteHeader(http.StatusOK)
+		return w.Write(csvExportHeader)
+	}
+
+	err := e.estateUsecase.ExportPalmTrees(ctx, id, func(row domain.EstateExportRow) error {
+		if !c.Response().Committed {
+			err := start()
+			if err != nil {
+				return err
+			}
+		}
+		err := w.Write(csvExportRecord(row))
+		if err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			w.Flush()
+			c.Response().Flush()
+		}
+		return w.Error()
+	})
+	if err == nil && !c.Response().Committed {
+		err = start()
+	}
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func (e *estateHandler) ExportEstatesNdjson(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	enc := json.NewEncoder(c.Response())
+	start := func() {
+		c.Response().Header().Set(echo.HeaderContentType, common.ContentTypeNDJSON)
+		c.Response().WriteHeader(http.StatusOK)
+	}
+
+	err := e.estateUsecase.ExportEstates(ctx, func(estate domain.ExportEstate) error {
+		if !c.Response().Committed {
+			start()
+		}
+		err := enc.Encode(estate)
+		if err != nil {
+			return err
+		}
+		c.Response().Flush()
+		return nil
+	})
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	if !c.Response().Committed {
+		start()
+	}
+	return nil
+}
+
+// exportError hands errors raised before the first row to the central
+// error handler. Once streaming has started the status is already sent, so
+// the error is logged and the connection aborted: a client must not mistake
+// a truncated export for a complete one.
+func exportError(c echo.Context, err error) error {
+	if !c.Response().Committed {
+		return err
+	}
+	slog.ErrorContext(c.Request().Context(), "export aborted", "path", c.Request().URL.Path, "error", err)
+	panic(http.ErrAbortHandler)
+}
+
+func (e *estateHandler) BackupEstates(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	archive := backup.NewWriter(c.Response(), helper.Now(), domain.TenantFromContext(ctx))
+	start := func() {
+		c.Response().Header().Set(echo.HeaderContentType, common.ContentTypeNDJSON)
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="estates-backup.ndjson"`)
+		c.Response().WriteHeader(http.StatusOK)
+	}
+
+	err := e.estateUsecase.ExportEstates(ctx, func(estate domain.ExportEstate) error {
+		if !c.Response().Committed {
+			start()
+		}
+		err := archive.Write(estate)
+		if err != nil {
+			return err
+		}
+		c.Response().Flush()
+		return nil
+	})
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	if !c.Response().Committed {
+		start()
+	}
+	return archive.Close()
+}
+
+func (e *estateHandler) RestoreEstates(c echo.Context, params generated.RestoreEstatesParams) error {
+	ctx := c.Request().Context()
+
+	archive, err := backup.NewReader(c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	param := &domain.RestoreEstatesRequest{Organisation: archive.Header.Organisation, Estates: archive}
+	if params.Conflict != nil {
+		param.Conflict = string(*params.Conflict)
+	}
+
+	resp, err := e.estateUsecase.RestoreEstates(ctx, param)
+	if err != nil {
+		return err
+	}
+
+	response := helper.Response(http.StatusOK, "Success restore estates", resp, nil)
+	return c.JSON(http.StatusOK, response)
+}
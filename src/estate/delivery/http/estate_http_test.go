@@ -1,23 +1,30 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/davidyunus/sawitpro-estate/generated"
 	"github.com/davidyunus/sawitpro-estate/src/common"
 	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
 	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
 	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
 func TestNewEstateHandler(t *testing.T) {
-	NewEstateHandler(echo.New(), nil)
+	NewEstateHandler(echo.New(), nil, nil, nil, nil, nil)
 }
 
 func TestCreateEstate(t *testing.T) {
@@ -30,16 +37,18 @@ func TestCreateEstate(t *testing.T) {
 	}
 
 	tests := []struct {
-		name       string
-		args       string
-		wantResult string
-		mock       func()
+		name        string
+		args        string
+		wantResult  string
+		wantCreated float64
+		mock        func()
 	}{
 		{
 			name: "success",
 			args: `{"length":6,"width":3}`,
 			wantResult: `{"code":201,"message":"Success create estate","data":{"id":"uuid"},"errors":null}
 `,
+			wantCreated: 1,
 			mock: func() {
 				estateMock.EXPECT().CreateEstate(gomock.Any(), &domain.Estate{
 					Length: 6,
@@ -52,7 +61,7 @@ func TestCreateEstate(t *testing.T) {
 		{
 			name: "error create estate",
 			args: `{"length":6,"width":3}`,
-			wantResult: `{"code":400,"message":"some error","data":null,"errors":"some error"}
+			wantResult: `{"code":500,"message":"internal server error","data":null,"errors":"internal server error","errorCode":"internal_error"}
 `,
 			mock: func() {
 				estateMock.EXPECT().CreateEstate(gomock.Any(), &domain.Estate{
@@ -61,17 +70,33 @@ func TestCreateEstate(t *testing.T) {
 				}).Return(nil, errors.New(common.UtSomeError))
 			},
 		},
+		{
+			name: "error exceed size estate",
+			args: `{"length":600,"width":3}`,
+			wantResult: `{"code":400,"message":"max size of estate exceeded: estate area 18000000 exceeds maximum 50000","data":null,"errors":{"limit":"area","max":50000,"actual":18000000},"errorCode":"estate_size_exceeded"}
+`,
+			mock: func() {
+				estateMock.EXPECT().CreateEstate(gomock.Any(), &domain.Estate{
+					Length: 600,
+					Width:  3,
+				}).Return(nil, &domain.SizeLimitError{
+					Limit:  domain.SizeLimitArea,
+					Max:    50000,
+					Actual: 18000000,
+				})
+			},
+		},
 		{
 			name: "error json decode",
 			args: `{"length":"aaa","width":3}`,
-			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":"invalid input"}
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"length","rule":"type","param":"int","message":"must be of type int"}],"errorCode":"invalid_input"}
 `,
 			mock: func() {},
 		},
 		{
 			name: "error invalid input",
 			args: `{"length":0,"width":3}`,
-			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":"invalid input"}
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"length","rule":"gt","param":"0","message":"must be greater than 0"}],"errorCode":"invalid_input"}
 `,
 			mock: func() {},
 		},
@@ -79,16 +104,157 @@ func TestCreateEstate(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			e := echo.New()
+			e.Validator = helper.NewValidator()
 			req := httptest.NewRequest(http.MethodPost, "/estate", strings.NewReader(test.args))
 			req.Header.Set(common.UtContentType, common.ContentTypeJson)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 
+			test.mock()
+			created := testutil.ToFloat64(metrics.EstatesCreated)
+
+			err := handler.CreateEstate(c, generated.CreateEstateParams{})
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+			assert.Equal(t, created+test.wantCreated, testutil.ToFloat64(metrics.EstatesCreated))
+		})
+	}
+}
+
+func TestPutEstate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	tests := []struct {
+		name       string
+		args       string
+		wantStatus int
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:       "success created",
+			args:       `{"length":6,"width":3,"externalRef":"ERP-1"}`,
+			wantStatus: http.StatusCreated,
+			wantResult: `{"code":201,"message":"Success create estate","data":{"id":"` + common.UtUuidV4 + `","created":true},"errors":null}
+`,
+			mock: func() {
+				estateMock.EXPECT().PutEstate(gomock.Any(), common.UtUuidV4, &domain.Estate{
+					Length:      6,
+					Width:       3,
+					ExternalRef: "ERP-1",
+				}).Return(&domain.PutEstateResponse{Id: common.UtUuidV4, Created: true}, nil)
+			},
+		},
+		{
+			name:       "success replaced",
+			args:       `{"length":6,"width":3}`,
+			wantStatus: http.StatusOK,
+			wantResult: `{"code":200,"message":"Success replace estate","data":{"id":"` + common.UtUuidV4 + `","created":false},"errors":null}
+`,
+			mock: func() {
+				estateMock.EXPECT().PutEstate(gomock.Any(), common.UtUuidV4, &domain.Estate{
+					Length: 6,
+					Width:  3,
+				}).Return(&domain.PutEstateResponse{Id: common.UtUuidV4}, nil)
+			},
+		},
+		{
+			name:       "error trees outside",
+			args:       `{"length":1,"width":1}`,
+			wantStatus: http.StatusConflict,
+			wantResult: `{"code":409,"message":"estate would leave planted trees outside its bounds","data":null,"errors":"estate would leave planted trees outside its bounds","errorCode":"trees_outside_estate"}
+`,
+			mock: func() {
+				estateMock.EXPECT().PutEstate(gomock.Any(), common.UtUuidV4, &domain.Estate{
+					Length: 1,
+					Width:  1,
+				}).Return(nil, domain.ErrTreesOutside)
+			},
+		},
+		{
+			name:       "error external ref too long",
+			args:       `{"length":6,"width":3,"externalRef":"` + strings.Repeat("x", 65) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"externalRef","rule":"max","param":"64","message":"must be at most 64 characters long"}],"errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = helper.NewValidator()
+			req := httptest.NewRequest(http.MethodPut, "/estate/"+common.UtUuidV4, strings.NewReader(test.args))
+			req.Header.Set(common.UtContentType, common.ContentTypeJson)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
 			test.mock()
 
-			if assert.NoError(t, handler.CreateEstate(c)) {
-				assert.Equal(t, test.wantResult, rec.Body.String())
+			err := handler.PutEstate(c, common.UtUuidV4)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
 			}
+			assert.Equal(t, test.wantStatus, rec.Code)
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestFindEstateByExternalRef(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	tests := []struct {
+		name       string
+		wantResult string
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: `{"code":200,"message":"Success find estate","data":{"uuid":"uuid","length":6,"width":3,"externalRef":"ERP-1"},"errors":null}
+`,
+			mock: func() {
+				estateMock.EXPECT().GetEstateByExternalRef(gomock.Any(), "ERP-1").
+					Return(&domain.Estate{Uuid: common.UtUuid, Length: 6, Width: 3, ExternalRef: "ERP-1"}, nil)
+			},
+		},
+		{
+			name: "error estate not found",
+			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found","errorCode":"estate_not_found"}
+`,
+			mock: func() {
+				estateMock.EXPECT().GetEstateByExternalRef(gomock.Any(), "ERP-1").Return(nil, domain.ErrEstateNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/estate?externalRef=ERP-1", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.FindEstateByExternalRef(c, generated.FindEstateByExternalRefParams{ExternalRef: "ERP-1"})
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
 		})
 	}
 }
@@ -126,7 +292,7 @@ func TestPlantPalmTree(t *testing.T) {
 		{
 			name: "error plant palm tree",
 			args: `{"x":3,"y":1,"height":10}`,
-			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found"}
+			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found","errorCode":"estate_not_found"}
 `,
 			mock: func() {
 				estateMock.EXPECT().PlantPalmTree(gomock.Any(), common.UtUuid, &domain.PalmTree{
@@ -136,18 +302,38 @@ func TestPlantPalmTree(t *testing.T) {
 				}).Return(nil, domain.ErrEstateNotFound)
 			},
 		},
+		{
+			name: "error out of bounds",
+			args: `{"x":30,"y":1,"height":10}`,
+			wantResult: `{"code":422,"message":"location is outside the estate","data":null,"errors":"location is outside the estate","errorCode":"location_out_of_bounds"}
+`,
+			mock: func() {
+				estateMock.EXPECT().PlantPalmTree(gomock.Any(), common.UtUuid, &domain.PalmTree{
+					X:      30,
+					Y:      1,
+					Height: 10,
+				}).Return(nil, domain.ErrOutOfBounds)
+			},
+		},
 		{
 			name: "error json decoder",
 			args: `{"x":"aaa","y":1,"height":10}`,
-			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":"invalid input"}
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"x","rule":"type","param":"int","message":"must be of type int"}],"errorCode":"invalid_input"}
 `,
 			mock: func() {},
 		},
 		{
-			name: "error json decoder",
+			name: "error invalid input",
 			args: `{"x":0,"y":1,"height":10}
 `,
-			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":"invalid input"}
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"x","rule":"gt","param":"0","message":"must be greater than 0"}],"errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+		{
+			name: "error invalid height",
+			args: `{"x":1,"y":1,"height":40}`,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"height","rule":"lte","param":"30","message":"must be less than or equal to 30"}],"errorCode":"invalid_input"}
 `,
 			mock: func() {},
 		},
@@ -155,18 +341,19 @@ func TestPlantPalmTree(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			e := echo.New()
+			e.Validator = helper.NewValidator()
 			req := httptest.NewRequest(http.MethodPost, "/estate/uuid/tree", strings.NewReader(test.args))
 			req.Header.Set(common.UtContentType, common.ContentTypeJson)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
-			c.SetParamNames("id")
-			c.SetParamValues("uuid")
 
 			test.mock()
 
-			if assert.NoError(t, handler.PlantPalmTree(c)) {
-				assert.Equal(t, test.wantResult, rec.Body.String())
+			err := handler.PlantPalmTree(c, common.UtUuid, generated.PlantPalmTreeParams{})
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
 			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
 		})
 	}
 }
@@ -203,7 +390,7 @@ func TestGetTreeStats(t *testing.T) {
 		{
 			name: "error get tree stats",
 			args: common.UtUuid,
-			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found"}
+			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found","errorCode":"estate_not_found"}
 `,
 			mock: func() {
 				estateMock.EXPECT().GetTreeStats(gomock.Any(), common.UtUuid).Return(nil, domain.ErrEstateNotFound)
@@ -213,18 +400,83 @@ func TestGetTreeStats(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			e := echo.New()
+			e.Validator = helper.NewValidator()
 			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/stats", test.args), nil)
 			req.Header.Set(common.UtContentType, common.ContentTypeJson)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
-			c.SetParamNames("id")
-			c.SetParamValues(common.UtUuid)
 
 			test.mock()
 
-			if assert.NoError(t, handler.GetTreeStats(c)) {
-				assert.Equal(t, test.wantResult, rec.Body.String())
+			err := handler.GetTreeStats(c, common.UtUuid)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
 			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestListPalmTrees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	plantedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	minHeight, limit := 5, 1
+	sort := generated.ListPalmTreesParamsSort("-height")
+	cursor := "9"
+
+	tests := []struct {
+		name       string
+		params     generated.ListPalmTreesParams
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:   "success",
+			params: generated.ListPalmTreesParams{MinHeight: &minHeight, PlantedBefore: &plantedAt, Sort: &sort, Cursor: &cursor, Limit: &limit},
+			wantResult: `{"code":200,"message":"Success list palm trees","data":{"trees":[{"id":3,"x":2,"y":1,"height":8,"plantedAt":"2024-01-02T03:04:05Z"}],"nextCursor":"3"},"errors":null}
+`,
+			mock: func() {
+				estateMock.EXPECT().ListPalmTrees(gomock.Any(), common.UtUuid, &domain.ListPalmTreesRequest{
+					Filter: domain.PalmTreeFilter{MinHeight: &minHeight, PlantedBefore: &plantedAt},
+					Sort:   "-height",
+					Cursor: "9",
+					Limit:  1,
+				}).Return(&domain.ListPalmTreesResponse{
+					Trees:      []domain.ExportPalmTree{{Id: 3, X: 2, Y: 1, Height: 8, PlantedAt: plantedAt}},
+					NextCursor: "3",
+				}, nil)
+			},
+		},
+		{
+			name: "error list palm trees",
+			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found","errorCode":"estate_not_found"}
+`,
+			mock: func() {
+				estateMock.EXPECT().ListPalmTrees(gomock.Any(), common.UtUuid, &domain.ListPalmTreesRequest{}).Return(nil, domain.ErrEstateNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/trees", common.UtUuid), nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.ListPalmTrees(c, common.UtUuid, test.params)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
 		})
 	}
 }
@@ -237,9 +489,10 @@ func TestGetDroneFlyingDistance(t *testing.T) {
 	handler := &estateHandler{
 		estateUsecase: estateMock,
 	}
+	maxDistance := 100
 	type args struct {
 		id          string
-		maxDistance string
+		maxDistance *int
 	}
 
 	tests := []struct {
@@ -266,40 +519,572 @@ func TestGetDroneFlyingDistance(t *testing.T) {
 			args: args{
 				id: common.UtUuid,
 			},
-			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found"}
+			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found","errorCode":"estate_not_found"}
 `,
 			mock: func() {
 				estateMock.EXPECT().GetDroneFlyingDistance(gomock.Any(), common.UtUuid, 0).Return(nil, domain.ErrEstateNotFound)
 			},
 		},
 		{
-			name: "error max distance param",
+			name: "success with max distance",
 			args: args{
 				id:          common.UtUuid,
-				maxDistance: "aaa",
+				maxDistance: &maxDistance,
 			},
-			wantResult: `{"code":400,"message":"strconv.Atoi: parsing \"aaa\": invalid syntax","data":null,"errors":"strconv.Atoi: parsing \"aaa\": invalid syntax"}
+			wantResult: `{"code":200,"message":"Success get drone flying distance","data":{"distance":100,"rest":{"x":4,"y":2}},"errors":null}
 `,
 			mock: func() {
-				// estateMock.EXPECT().GetDroneFlyingDistance(gomock.Any(), common.UtUuid, 0).Return(nil, domain.ErrEstateNotFound)
+				estateMock.EXPECT().GetDroneFlyingDistance(gomock.Any(), common.UtUuid, 100).Return(&domain.GetDroneFlyingDistanceResponse{
+					Distance: 100,
+					Rest: &domain.Rest{
+						X: 4,
+						Y: 2,
+					},
+				}, nil)
 			},
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			e := echo.New()
-			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/drone-plan?max-distance=%v", test.args.id, test.args.maxDistance), nil)
+			e.Validator = helper.NewValidator()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/drone-plan", test.args.id), nil)
 			req.Header.Set(common.UtContentType, common.ContentTypeJson)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
-			c.SetParamNames("id")
-			c.SetParamValues(common.UtUuid)
 
 			test.mock()
 
-			if assert.NoError(t, handler.GetDroneFlyingDistance(c)) {
-				assert.Equal(t, test.wantResult, rec.Body.String())
+			err := handler.GetDroneFlyingDistance(c, common.UtUuid, generated.GetDroneFlyingDistanceParams{MaxDistance: test.args.maxDistance})
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestFindOutOfBoundsPalmTrees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	tests := []struct {
+		name       string
+		wantResult string
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: `{"code":200,"message":"Success find out of bounds trees","data":{"count":1,"trees":[{"id":1,"uuid":"uuid","x":7,"y":1,"height":10,"estateLength":6,"estateWidth":3}]},"errors":null}
+`,
+			mock: func() {
+				estateMock.EXPECT().FindOutOfBoundsPalmTrees(gomock.Any()).Return(&domain.FindOutOfBoundsPalmTreesResponse{
+					Count: 1,
+					Trees: []domain.OutOfBoundsPalmTree{
+						{
+							PalmTree: domain.PalmTree{
+								Id:     1,
+								Uuid:   common.UtUuid,
+								X:      7,
+								Y:      1,
+								Height: 10,
+							},
+							EstateLength: 6,
+							EstateWidth:  3,
+						},
+					},
+				}, nil)
+			},
+		},
+		{
+			name: "error find out of bounds trees",
+			wantResult: `{"code":500,"message":"internal server error","data":null,"errors":"internal server error","errorCode":"internal_error"}
+`,
+			mock: func() {
+				estateMock.EXPECT().FindOutOfBoundsPalmTrees(gomock.Any()).Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = helper.NewValidator()
+			req := httptest.NewRequest(http.MethodGet, "/maintenance/out-of-bounds-trees", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.FindOutOfBoundsPalmTrees(c)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestImportPalmTrees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	bestEffort := generated.BestEffort
+	report := &domain.ImportPalmTreesResponse{
+		Id:       common.UtUuid,
+		Mode:     domain.ImportModeBestEffort,
+		Total:    2,
+		Imported: 1,
+		Rejected: 1,
+		Errors: []domain.ImportRowError{
+			{Row: 2, X: 9, Y: 1, Code: "location_out_of_bounds", Message: "location is outside the estate"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		params      generated.ImportPalmTreesParams
+		args        string
+		wantResult  string
+		wantPlanted float64
+		mock        func()
+	}{
+		{
+			name:        "success csv",
+			contentType: common.ContentTypeCSV,
+			params:      generated.ImportPalmTreesParams{Mode: &bestEffort},
+			args:        "height,x,y\n10,1,1\n5, 9, 1\n",
+			wantResult: `{"code":201,"message":"Success import palm trees","data":{"id":"uuid","mode":"best-effort","total":2,"imported":1,"rejected":1,"errors":[{"row":2,"x":9,"y":1,"code":"location_out_of_bounds","message":"location is outside the estate"}]},"errors":null}
+`,
+			wantPlanted: 1,
+			mock: func() {
+				estateMock.EXPECT().ImportPalmTrees(gomock.Any(), common.UtUuid, &domain.ImportPalmTreesRequest{
+					Mode: domain.ImportModeBestEffort,
+					Trees: []domain.PalmTree{
+						{X: 1, Y: 1, Height: 10},
+						{X: 9, Y: 1, Height: 5},
+					},
+				}).Return(report, nil)
+			},
+		},
+		{
+			name:        "error atomic json",
+			contentType: common.ContentTypeJson,
+			args:        `[{"x":1,"y":1,"height":10},{"x":9,"y":1,"height":5}]`,
+			wantResult: `{"code":422,"message":"import rejected","data":null,"errors":{"id":"uuid","mode":"best-effort","total":2,"imported":1,"rejected":1,"errors":[{"row":2,"x":9,"y":1,"code":"location_out_of_bounds","message":"location is outside the estate"}]},"errorCode":"import_rejected"}
+`,
+			mock: func() {
+				estateMock.EXPECT().ImportPalmTrees(gomock.Any(), common.UtUuid, &domain.ImportPalmTreesRequest{
+					Trees: []domain.PalmTree{
+						{X: 1, Y: 1, Height: 10},
+						{X: 9, Y: 1, Height: 5},
+					},
+				}).Return(nil, domain.ErrImportRejected.WithDetails(report))
+			},
+		},
+		{
+			name:        "error csv missing column",
+			contentType: common.ContentTypeCSV,
+			args:        "x,y\n1,1\n",
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"height","rule":"required","message":"csv header is missing the height column"}],"errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+		{
+			name:        "error csv not an integer",
+			contentType: common.ContentTypeCSV,
+			args:        "x,y,height\n1,1,10\na,1,tall\n",
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"rows[2].x","rule":"type","param":"integer","message":"x must be an integer"},{"field":"rows[2].height","rule":"type","param":"integer","message":"height must be an integer"}],"errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+		{
+			name:        "error csv field count",
+			contentType: common.ContentTypeCSV,
+			args:        "x,y,height\n1,1\n",
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"rows[1]","rule":"csv","message":"record on line 2: wrong number of fields"}],"errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+		{
+			name:        "error json decoder",
+			contentType: common.ContentTypeJson,
+			args:        `{"x":1}`,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":"invalid input","errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/estate/uuid/trees:import", strings.NewReader(test.args))
+			req.Header.Set(common.UtContentType, test.contentType)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+			planted := testutil.ToFloat64(metrics.PalmTreesPlanted)
+
+			err := handler.ImportPalmTrees(c, common.UtUuid, test.params)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+			assert.Equal(t, planted+test.wantPlanted, testutil.ToFloat64(metrics.PalmTreesPlanted))
+		})
+	}
+}
+
+func TestExportPalmTreesCsv(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	row := domain.EstateExportRow{
+		Uuid:      common.UtUuid,
+		Length:    6,
+		Width:     3,
+		CreatedAt: createdAt,
+		Tree:      &domain.ExportPalmTree{Id: 1, X: 2, Y: 1, Height: 10, PlantedAt: createdAt},
+	}
+	stream := func(err error) func(context.Context, string, func(domain.EstateExportRow) error) error {
+		return func(_ context.Context, _ string, fn func(domain.EstateExportRow) error) error {
+			if fnErr := fn(row); fnErr != nil {
+				return fnErr
+			}
+			return err
+		}
+	}
+
+	tests := []struct {
+		name            string
+		wantCode        int
+		wantContentType string
+		wantResult      string
+		mock            func()
+	}{
+		{
+			name:            "success",
+			wantCode:        http.StatusOK,
+			wantContentType: common.ContentTypeCSV,
+			wantResult: "estate_id,estate_length,estate_width,estate_created_at,tree_id,x,y,height,planted_at\n" +
+				"uuid,6,3,2024-01-02T03:04:05Z,1,2,1,10,2024-01-02T03:04:05Z\n",
+			mock: func() {
+				estateMock.EXPECT().ExportPalmTrees(gomock.Any(), common.UtUuid, gomock.Any()).DoAndReturn(stream(nil))
+			},
+		},
+		{
+			name:            "success no trees",
+			wantCode:        http.StatusOK,
+			wantContentType: common.ContentTypeCSV,
+			wantResult:      "estate_id,estate_length,estate_width,estate_created_at,tree_id,x,y,height,planted_at\n",
+			mock: func() {
+				estateMock.EXPECT().ExportPalmTrees(gomock.Any(), common.UtUuid, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:            "error estate not found",
+			wantCode:        http.StatusNotFound,
+			wantContentType: echo.MIMEApplicationJSON,
+			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found","errorCode":"estate_not_found"}
+`,
+			mock: func() {
+				estateMock.EXPECT().ExportPalmTrees(gomock.Any(), common.UtUuid, gomock.Any()).Return(domain.ErrEstateNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.Logger.SetOutput(io.Discard)
+			req := httptest.NewRequest(http.MethodGet, "/estate/uuid/trees.csv", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.ExportPalmTreesCsv(c, common.UtUuid)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.True(t, strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), test.wantContentType))
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+
+	t.Run("error after streaming started aborts", func(t *testing.T) {
+		e := echo.New()
+		e.Logger.SetOutput(io.Discard)
+		req := httptest.NewRequest(http.MethodGet, "/estate/uuid/trees.csv", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		estateMock.EXPECT().ExportPalmTrees(gomock.Any(), common.UtUuid, gomock.Any()).
+			DoAndReturn(stream(errors.New(common.UtSomeError)))
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			_ = handler.ExportPalmTreesCsv(c, common.UtUuid)
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestExportEstatesNdjson(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	estates := []domain.ExportEstate{
+		{
+			Uuid:      common.UtUuid,
+			Length:    6,
+			Width:     3,
+			CreatedAt: createdAt,
+			Trees:     []domain.ExportPalmTree{{Id: 1, X: 2, Y: 1, Height: 10, PlantedAt: createdAt}},
+		},
+		{Uuid: "empty", Length: 2, Width: 2, CreatedAt: createdAt, Trees: []domain.ExportPalmTree{}},
+	}
+
+	tests := []struct {
+		name       string
+		wantCode   int
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:     "success",
+			wantCode: http.StatusOK,
+			wantResult: `{"uuid":"uuid","length":6,"width":3,"createdAt":"2024-01-02T03:04:05Z","trees":[{"id":1,"x":2,"y":1,"height":10,"plantedAt":"2024-01-02T03:04:05Z"}]}
+{"uuid":"empty","length":2,"width":2,"createdAt":"2024-01-02T03:04:05Z","trees":[]}
+`,
+			mock: func() {
+				estateMock.EXPECT().ExportEstates(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, fn func(domain.ExportEstate) error) error {
+						for _, estate := range estates {
+							if err := fn(estate); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			},
+		},
+		{
+			name:       "success no estates",
+			wantCode:   http.StatusOK,
+			wantResult: "",
+			mock: func() {
+				estateMock.EXPECT().ExportEstates(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:     "error before streaming",
+			wantCode: http.StatusInternalServerError,
+			wantResult: `{"code":500,"message":"internal server error","data":null,"errors":"internal server error","errorCode":"internal_error"}
+`,
+			mock: func() {
+				estateMock.EXPECT().ExportEstates(gomock.Any(), gomock.Any()).Return(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.Logger.SetOutput(io.Discard)
+			req := httptest.NewRequest(http.MethodGet, "/export.ndjson", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.ExportEstatesNdjson(c)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestBackupEstates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time {
+		return createdAt
+	}
+	defer func() {
+		helper.Now = tempNow
+	}()
+
+	header := `{"format":"sawitpro-estate-backup","version":2,"createdAt":"2024-01-02T03:04:05Z",` +
+		`"organisation":{"id":"acme","name":"Acme","timezone":"Asia/Makassar","sizePolicy":{"maxArea":0,"maxLength":200,"maxWidth":0,"plotSize":0},"createdAt":"2024-01-02T03:04:05Z"}}
+`
+	acme := &domain.Organisation{Id: "acme", Name: "Acme", Timezone: "Asia/Makassar", SizePolicy: domain.SizePolicy{MaxLength: 200}, CreatedAt: createdAt}
+	tests := []struct {
+		name       string
+		wantCode   int
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:     "success",
+			wantCode: http.StatusOK,
+			wantResult: header + `{"uuid":"uuid","length":6,"width":3,"createdAt":"2024-01-02T03:04:05Z","trees":[]}
+`,
+			mock: func() {
+				estateMock.EXPECT().ExportEstates(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, fn func(domain.ExportEstate) error) error {
+						return fn(domain.ExportEstate{Uuid: common.UtUuid, Length: 6, Width: 3, CreatedAt: createdAt, Trees: []domain.ExportPalmTree{}})
+					})
+			},
+		},
+		{
+			name:       "success no estates",
+			wantCode:   http.StatusOK,
+			wantResult: header,
+			mock: func() {
+				estateMock.EXPECT().ExportEstates(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:     "error before streaming",
+			wantCode: http.StatusInternalServerError,
+			wantResult: `{"code":500,"message":"internal server error","data":null,"errors":"internal server error","errorCode":"internal_error"}
+`,
+			mock: func() {
+				estateMock.EXPECT().ExportEstates(gomock.Any(), gomock.Any()).Return(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.Logger.SetOutput(io.Discard)
+			req := httptest.NewRequest(http.MethodGet, "/admin/backup", nil)
+			req = req.WithContext(domain.WithTenant(req.Context(), acme))
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.BackupEstates(c)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestRestoreEstates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	remap := generated.Remap
+	archive := `{"format":"sawitpro-estate-backup","version":2,"createdAt":"2024-01-02T03:04:05Z","organisation":{"id":"acme","name":"Acme","timezone":"Asia/Makassar"}}
+{"uuid":"uuid","length":6,"width":3,"createdAt":"2024-01-02T03:04:05Z","trees":[]}
+`
+	report := &domain.RestoreEstatesResponse{
+		Conflict: domain.RestoreConflictRemap,
+		Total:    1,
+		Created:  1,
+		Estates:  []domain.RestoreResult{{SourceUuid: common.UtUuid, Uuid: common.UtUuid, Status: domain.RestoreStatusCreated}},
+	}
+
+	tests := []struct {
+		name       string
+		params     generated.RestoreEstatesParams
+		args       string
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:   "success",
+			params: generated.RestoreEstatesParams{Conflict: &remap},
+			args:   archive,
+			wantResult: `{"code":200,"message":"Success restore estates","data":{"conflict":"remap","total":1,"created":1,"remapped":0,"unchanged":0,"skipped":0,"estates":[{"sourceUuid":"uuid","uuid":"uuid","status":"created","trees":0}]},"errors":null}
+`,
+			mock: func() {
+				estateMock.EXPECT().RestoreEstates(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, param *domain.RestoreEstatesRequest) (*domain.RestoreEstatesResponse, error) {
+						assert.Equal(t, domain.RestoreConflictRemap, param.Conflict)
+						assert.Equal(t, &domain.Organisation{Id: "acme", Name: "Acme", Timezone: "Asia/Makassar"}, param.Organisation)
+						estate, err := param.Estates.Next()
+						assert.NoError(t, err)
+						assert.Equal(t, common.UtUuid, estate.Uuid)
+						return report, nil
+					})
+			},
+		},
+		{
+			name: "error conflict",
+			args: archive,
+			wantResult: `{"code":409,"message":"estate already exists with different data","data":null,"errors":{"conflict":"remap","total":1,"created":1,"remapped":0,"unchanged":0,"skipped":0,"estates":[{"sourceUuid":"uuid","uuid":"uuid","status":"created","trees":0}]},"errorCode":"restore_conflict"}
+`,
+			mock: func() {
+				estateMock.EXPECT().RestoreEstates(gomock.Any(), gomock.Any()).Return(nil, domain.ErrRestoreConflict.WithDetails(report))
+			},
+		},
+		{
+			name: "error archive format",
+			args: `{"format":"tarball","version":1}`,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"archive[1]","rule":"archive","message":"unknown archive format \"tarball\""}],"errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/admin/restore", strings.NewReader(test.args))
+			req.Header.Set(common.UtContentType, common.ContentTypeNDJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.RestoreEstates(c, test.params)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
 			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
 		})
 	}
 }
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+)
+
+func (e *estateHandler) GetLiveness(c echo.Context) error {
+	response := helper.Response(http.StatusOK, "Alive", &domain.Readiness{Status: domain.HealthStatusPass}, nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (e *estateHandler) GetReadiness(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	readiness, err := e.healthUsecase.GetReadiness(ctx)
+	if err != nil {
+		return err
+	}
+
+	response := helper.Response(http.StatusOK, "Ready", readiness, nil)
+	return c.JSON(http.StatusOK, response)
+}
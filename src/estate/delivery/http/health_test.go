@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetLiveness(t *testing.T) {
+	handler := &estateHandler{}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	assert.NoError(t, handler.GetLiveness(c))
+	assert.Equal(t, `{"code":200,"message":"Alive","data":{"status":"pass"},"errors":null}
+`, rec.Body.String())
+}
+
+func TestGetReadiness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	healthMock := mock_domain.NewMockHealthUsecase(ctrl)
+	handler := &estateHandler{
+		healthUsecase: healthMock,
+	}
+
+	tests := []struct {
+		name       string
+		wantCode   int
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:     "success",
+			wantCode: http.StatusOK,
+			wantResult: `{"code":200,"message":"Ready","data":{"status":"pass","checks":[{"name":"database","status":"pass"}]},"errors":null}
+`,
+			mock: func() {
+				healthMock.EXPECT().GetReadiness(gomock.Any()).Return(&domain.Readiness{
+					Status: domain.HealthStatusPass,
+					Checks: []domain.HealthCheck{{Name: domain.HealthCheckDatabase, Status: domain.HealthStatusPass}},
+				}, nil)
+			},
+		},
+		{
+			name:     "error not ready",
+			wantCode: http.StatusServiceUnavailable,
+			wantResult: `{"code":503,"message":"service is not ready","data":null,"errors":{"status":"fail","checks":[{"name":"database","status":"fail","message":"database is unavailable"}]},"errorCode":"not_ready"}
+`,
+			mock: func() {
+				healthMock.EXPECT().GetReadiness(gomock.Any()).Return(nil, domain.ErrNotReady.WithDetails(&domain.Readiness{
+					Status: domain.HealthStatusFail,
+					Checks: []domain.HealthCheck{{Name: domain.HealthCheckDatabase, Status: domain.HealthStatusFail, Message: "database is unavailable"}},
+				}))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.GetReadiness(c)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
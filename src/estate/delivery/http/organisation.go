@@ -0,0 +1,48 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+)
+
+func (e *estateHandler) ListOrganisations(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organisations, err := e.organisationUsecase.ListOrganisations(ctx)
+	if err != nil {
+		return err
+	}
+
+	response := helper.Response(http.StatusOK, "Success list organisations", organisations, nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (e *estateHandler) PutOrganisation(c echo.Context, organisationId string) error {
+	ctx := c.Request().Context()
+
+	payload := &domain.Organisation{}
+	err := json.NewDecoder(c.Request().Body).Decode(&payload)
+	if err != nil {
+		return helper.DecodeError(err)
+	}
+	err = c.Validate(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.organisationUsecase.PutOrganisation(ctx, organisationId, payload)
+	if err != nil {
+		return err
+	}
+
+	if resp.Created {
+		response := helper.Response(http.StatusCreated, "Success create organisation", resp, nil)
+		return c.JSON(http.StatusCreated, response)
+	}
+	response := helper.Response(http.StatusOK, "Success replace organisation", resp, nil)
+	return c.JSON(http.StatusOK, response)
+}
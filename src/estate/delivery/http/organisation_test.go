@@ -0,0 +1,156 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListOrganisations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	organisationMock := mock_domain.NewMockOrganisationUsecase(ctrl)
+	handler := &estateHandler{
+		organisationUsecase: organisationMock,
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		wantResult string
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: `{"code":200,"message":"Success list organisations","data":[{"id":"acme","name":"Acme","timezone":"Asia/Makassar","sizePolicy":{"maxArea":0,"maxLength":20,"maxWidth":0,"plotSize":0},"createdAt":"2024-01-02T03:04:05Z"}],"errors":null}
+`,
+			mock: func() {
+				organisationMock.EXPECT().ListOrganisations(gomock.Any()).Return([]domain.Organisation{{
+					Id:         "acme",
+					Name:       "Acme",
+					Timezone:   "Asia/Makassar",
+					SizePolicy: domain.SizePolicy{MaxLength: 20},
+					CreatedAt:  createdAt,
+				}}, nil)
+			},
+		},
+		{
+			name: "error forbidden",
+			wantResult: `{"code":403,"message":"not allowed to perform this action","data":null,"errors":"not allowed to perform this action","errorCode":"forbidden"}
+`,
+			mock: func() {
+				organisationMock.EXPECT().ListOrganisations(gomock.Any()).Return(nil, domain.ErrForbidden)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/organisations", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.ListOrganisations(c)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestPutOrganisation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	organisationMock := mock_domain.NewMockOrganisationUsecase(ctrl)
+	handler := &estateHandler{
+		organisationUsecase: organisationMock,
+	}
+
+	organisation := domain.Organisation{
+		Id:        "acme",
+		Name:      "Acme",
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name       string
+		args       string
+		wantCode   int
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:     "success created",
+			args:     `{"name":"Acme"}`,
+			wantCode: http.StatusCreated,
+			wantResult: `{"code":201,"message":"Success create organisation","data":{"id":"acme","name":"Acme","sizePolicy":{"maxArea":0,"maxLength":0,"maxWidth":0,"plotSize":0},"createdAt":"2024-01-02T03:04:05Z","created":true},"errors":null}
+`,
+			mock: func() {
+				organisationMock.EXPECT().PutOrganisation(gomock.Any(), "acme", &domain.Organisation{Name: "Acme"}).
+					Return(&domain.PutOrganisationResponse{Organisation: organisation, Created: true}, nil)
+			},
+		},
+		{
+			name:     "success replaced",
+			args:     `{"name":"Acme"}`,
+			wantCode: http.StatusOK,
+			wantResult: `{"code":200,"message":"Success replace organisation","data":{"id":"acme","name":"Acme","sizePolicy":{"maxArea":0,"maxLength":0,"maxWidth":0,"plotSize":0},"createdAt":"2024-01-02T03:04:05Z","created":false},"errors":null}
+`,
+			mock: func() {
+				organisationMock.EXPECT().PutOrganisation(gomock.Any(), "acme", gomock.Any()).
+					Return(&domain.PutOrganisationResponse{Organisation: organisation}, nil)
+			},
+		},
+		{
+			name:     "error unknown timezone",
+			args:     `{"name":"Acme","timezone":"Mars/Olympus"}`,
+			wantCode: http.StatusBadRequest,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"timezone","rule":"timezone","message":"must be an IANA time zone name"}],"errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+		{
+			name:     "error negative size limit",
+			args:     `{"name":"Acme","sizePolicy":{"maxWidth":-1}}`,
+			wantCode: http.StatusBadRequest,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"maxWidth","rule":"gte","param":"0","message":"must be greater than or equal to 0"}],"errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = helper.NewValidator()
+			req := httptest.NewRequest(http.MethodPut, "/admin/organisations/acme", strings.NewReader(test.args))
+			req.Header.Set(common.UtContentType, common.ContentTypeJson)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.PutOrganisation(c, "acme")
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidyunus/sawitpro-estate/generated"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+)
+
+func (e *estateHandler) ListEstatePermissions(c echo.Context, id generated.EstateUuid) error {
+	ctx := c.Request().Context()
+
+	resp, err := e.estateUsecase.ListEstatePermissions(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response := helper.Response(http.StatusOK, "Success list permissions", resp, nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (e *estateHandler) GrantEstatePermission(c echo.Context, id generated.EstateUuid, subject generated.Subject) error {
+	ctx := c.Request().Context()
+
+	payload := &domain.GrantEstatePermissionRequest{}
+	err := json.NewDecoder(c.Request().Body).Decode(&payload)
+	if err != nil {
+		return helper.DecodeError(err)
+	}
+	err = c.Validate(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.estateUsecase.GrantEstatePermission(ctx, id, subject, payload)
+	if err != nil {
+		return err
+	}
+
+	if resp.Created {
+		response := helper.Response(http.StatusCreated, "Success grant permission", resp, nil)
+		return c.JSON(http.StatusCreated, response)
+	}
+	response := helper.Response(http.StatusOK, "Success replace permission", resp, nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (e *estateHandler) RevokeEstatePermission(c echo.Context, id generated.EstateUuid, subject generated.Subject) error {
+	ctx := c.Request().Context()
+
+	err := e.estateUsecase.RevokeEstatePermission(ctx, id, subject)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
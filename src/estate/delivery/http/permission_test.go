@@ -0,0 +1,213 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListEstatePermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	grantedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		wantResult string
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: `{"code":200,"message":"Success list permissions","data":{"permissions":[{"estateId":"uuid","subject":"alice","role":"admin","grantedBy":"alice","grantedAt":"2024-01-02T03:04:05Z"}]},"errors":null}
+`,
+			mock: func() {
+				estateMock.EXPECT().ListEstatePermissions(gomock.Any(), common.UtUuid).Return(&domain.ListEstatePermissionsResponse{
+					Permissions: []domain.EstatePermission{{
+						EstateId:  common.UtUuid,
+						Subject:   "alice",
+						Role:      domain.RoleAdmin,
+						GrantedBy: "alice",
+						GrantedAt: grantedAt,
+					}},
+				}, nil)
+			},
+		},
+		{
+			name: "error forbidden",
+			wantResult: `{"code":403,"message":"not allowed to perform this action","data":null,"errors":"not allowed to perform this action","errorCode":"forbidden"}
+`,
+			mock: func() {
+				estateMock.EXPECT().ListEstatePermissions(gomock.Any(), common.UtUuid).Return(nil, domain.ErrForbidden)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/estate/"+common.UtUuid+"/permissions", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.ListEstatePermissions(c, common.UtUuid)
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestGrantEstatePermission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	permission := domain.EstatePermission{
+		EstateId:  common.UtUuid,
+		Subject:   "bob",
+		Role:      domain.RoleSurveyor,
+		GrantedBy: "alice",
+		GrantedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name       string
+		args       string
+		wantCode   int
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:     "success granted",
+			args:     `{"role":"surveyor"}`,
+			wantCode: http.StatusCreated,
+			wantResult: `{"code":201,"message":"Success grant permission","data":{"estateId":"uuid","subject":"bob","role":"surveyor","grantedBy":"alice","grantedAt":"2024-01-02T03:04:05Z","created":true},"errors":null}
+`,
+			mock: func() {
+				estateMock.EXPECT().GrantEstatePermission(gomock.Any(), common.UtUuid, "bob", &domain.GrantEstatePermissionRequest{Role: domain.RoleSurveyor}).
+					Return(&domain.GrantEstatePermissionResponse{EstatePermission: permission, Created: true}, nil)
+			},
+		},
+		{
+			name:     "success replaced",
+			args:     `{"role":"surveyor"}`,
+			wantCode: http.StatusOK,
+			wantResult: `{"code":200,"message":"Success replace permission","data":{"estateId":"uuid","subject":"bob","role":"surveyor","grantedBy":"alice","grantedAt":"2024-01-02T03:04:05Z","created":false},"errors":null}
+`,
+			mock: func() {
+				estateMock.EXPECT().GrantEstatePermission(gomock.Any(), common.UtUuid, "bob", gomock.Any()).
+					Return(&domain.GrantEstatePermissionResponse{EstatePermission: permission}, nil)
+			},
+		},
+		{
+			name:     "error unknown role",
+			args:     `{"role":"owner"}`,
+			wantCode: http.StatusBadRequest,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"role","rule":"oneof","param":"viewer surveyor operator admin","message":"must be one of viewer surveyor operator admin"}],"errorCode":"invalid_input"}
+`,
+			mock: func() {},
+		},
+		{
+			name:     "error estate not found",
+			args:     `{"role":"viewer"}`,
+			wantCode: http.StatusNotFound,
+			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found","errorCode":"estate_not_found"}
+`,
+			mock: func() {
+				estateMock.EXPECT().GrantEstatePermission(gomock.Any(), common.UtUuid, "bob", gomock.Any()).Return(nil, domain.ErrEstateNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = helper.NewValidator()
+			req := httptest.NewRequest(http.MethodPut, "/estate/"+common.UtUuid+"/permissions/bob", strings.NewReader(test.args))
+			req.Header.Set(common.UtContentType, common.ContentTypeJson)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.GrantEstatePermission(c, common.UtUuid, "bob")
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestRevokeEstatePermission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	handler := &estateHandler{
+		estateUsecase: estateMock,
+	}
+
+	tests := []struct {
+		name       string
+		wantCode   int
+		wantResult string
+		mock       func()
+	}{
+		{
+			name:     "success",
+			wantCode: http.StatusNoContent,
+			mock: func() {
+				estateMock.EXPECT().RevokeEstatePermission(gomock.Any(), common.UtUuid, "bob").Return(nil)
+			},
+		},
+		{
+			name:     "error not found",
+			wantCode: http.StatusNotFound,
+			wantResult: `{"code":404,"message":"subject holds no role on this estate","data":null,"errors":"subject holds no role on this estate","errorCode":"permission_not_found"}
+`,
+			mock: func() {
+				estateMock.EXPECT().RevokeEstatePermission(gomock.Any(), common.UtUuid, "bob").Return(domain.ErrPermissionNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodDelete, "/estate/"+common.UtUuid+"/permissions/bob", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			test.mock()
+
+			err := handler.RevokeEstatePermission(c, common.UtUuid, "bob")
+			if err != nil {
+				helper.HTTPErrorHandler(err, c)
+			}
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// router registers the generated routes on echo. It escapes the ':' of
+// custom verbs such as /trees:import, which echo would otherwise read as
+// the start of a path parameter matching /trees<anything>.
+type router struct {
+	*echo.Echo
+}
+
+func (r router) CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Echo.CONNECT(escapeVerb(path), h, m...)
+}
+
+func (r router) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Echo.DELETE(escapeVerb(path), h, m...)
+}
+
+func (r router) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Echo.GET(escapeVerb(path), h, m...)
+}
+
+func (r router) HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Echo.HEAD(escapeVerb(path), h, m...)
+}
+
+func (r router) OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Echo.OPTIONS(escapeVerb(path), h, m...)
+}
+
+func (r router) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Echo.PATCH(escapeVerb(path), h, m...)
+}
+
+func (r router) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Echo.POST(escapeVerb(path), h, m...)
+}
+
+func (r router) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Echo.PUT(escapeVerb(path), h, m...)
+}
+
+func (r router) TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Echo.TRACE(escapeVerb(path), h, m...)
+}
+
+// escapeVerb escapes every ':' that does not open a path segment.
+func escapeVerb(path string) string {
+	b := strings.Builder{}
+	for i := 0; i < len(path); i++ {
+		if path[i] == ':' && i > 0 && path[i-1] != '/' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEscapeVerb(t *testing.T) {
+	assert.Equal(t, `/estate/:id/trees\:import`, escapeVerb("/estate/:id/trees:import"))
+	assert.Equal(t, "/estate/:id/tree", escapeVerb("/estate/:id/tree"))
+}
+
+func TestRouterCustomVerb(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateMock := mock_domain.NewMockEstateUsecase(ctrl)
+	e := echo.New()
+	e.HTTPErrorHandler = helper.HTTPErrorHandler
+	NewEstateHandler(e, estateMock, nil, nil, nil, nil)
+
+	estateMock.EXPECT().ImportPalmTrees(gomock.Any(), common.UtUuid, gomock.Any()).
+		Return(&domain.ImportPalmTreesResponse{Id: common.UtUuid}, nil)
+
+	tests := []struct {
+		path     string
+		wantCode int
+	}{
+		{path: "/estate/uuid/trees:import", wantCode: http.StatusCreated},
+		{path: "/estate/uuid/treesimport", wantCode: http.StatusNotFound},
+		{path: "/estate/uuid/trees:export", wantCode: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(`[{"x":1,"y":1,"height":1}]`))
+			req.Header.Set(common.UtContentType, common.ContentTypeJson)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, test.wantCode, rec.Code)
+		})
+	}
+}
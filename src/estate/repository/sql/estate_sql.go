@@ -3,10 +3,14 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"log/slog"
 
 	"github.com/davidyunus/sawitpro-estate/src/domain"
 	"github.com/davidyunus/sawitpro-estate/src/helper"
-	"github.com/labstack/gommon/log"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+	"github.com/lib/pq"
 )
 
 type estateRepositorySql struct {
@@ -22,7 +26,17 @@ func NewEstateRepositorySql(conn *sql.DB, manager *helper.Manager) domain.Estate
 }
 
 func (m *estateRepositorySql) fetch(ctx context.Context, query string, args ...interface{}) ([]domain.Estate, error) {
-	rows, err := m.conn.QueryContext(ctx, query, args...)
+	var dbConn interface {
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	} = m.conn
+
+	// Reads within a transaction see, and lock, what it is about to write.
+	tx, _ := ctx.Value(m.manager.GetKey()).(*sql.Tx)
+	if tx != nil {
+		dbConn = tx
+	}
+
+	rows, err := dbConn.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +44,7 @@ func (m *estateRepositorySql) fetch(ctx context.Context, query string, args ...i
 	defer func() {
 		err = rows.Close()
 		if err != nil {
-			log.Error(err)
+			slog.ErrorContext(ctx, "close rows", "error", err)
 		}
 	}()
 
@@ -42,6 +56,7 @@ func (m *estateRepositorySql) fetch(ctx context.Context, query string, args ...i
 			&estate.Uuid,
 			&estate.Length,
 			&estate.Width,
+			&estate.ExternalRef,
 		)
 		if err != nil {
 			return nil, err
@@ -53,6 +68,10 @@ func (m *estateRepositorySql) fetch(ctx context.Context, query string, args ...i
 }
 
 func (e *estateRepositorySql) CreateEstate(ctx context.Context, param *domain.Estate) error {
+	ctx, span := tracing.StartQuery(ctx, "estate", "CreateEstate", tracing.Statement(QueryCreateEstate))
+	defer span.End()
+	defer metrics.ObserveQuery("estate", "CreateEstate")()
+
 	var dbConn interface {
 		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	} = e.conn
@@ -63,20 +82,135 @@ func (e *estateRepositorySql) CreateEstate(ctx context.Context, param *domain.Es
 	}
 
 	_, err := dbConn.ExecContext(ctx, QueryCreateEstate,
+		domain.TenantId(ctx),
 		param.Uuid,
 		param.Length,
 		param.Width,
-		helper.Now(),
+		helper.TenantNow(ctx),
+		param.ExternalRef,
 	)
 	if err != nil {
+		return writeError(err)
+	}
+
+	return nil
+}
+
+func (e *estateRepositorySql) UpsertEstate(ctx context.Context, param *domain.Estate) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "estate", "UpsertEstate")
+	defer span.End()
+	defer metrics.ObserveQuery("estate", "UpsertEstate")()
+
+	var dbConn interface {
+		QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	} = e.conn
+
+	tx, _ := ctx.Value(e.manager.GetKey()).(*sql.Tx)
+	if tx != nil {
+		dbConn = tx
+	}
+
+	var created bool
+	err := dbConn.QueryRowContext(ctx, QueryUpsertEstate,
+		domain.TenantId(ctx),
+		param.Uuid,
+		param.Length,
+		param.Width,
+		helper.TenantNow(ctx),
+		param.ExternalRef,
+	).Scan(&created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, domain.ErrEstateIdTaken
+	}
+	if err != nil {
+		return false, writeError(err)
+	}
+
+	return created, nil
+}
+
+// writeError turns a clash on the external reference into
+// domain.ErrExternalRefTaken, and one on the uuid, which can only be with an
+// estate of another organisation, into domain.ErrEstateIdTaken.
+func writeError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != uniqueViolation {
 		return err
 	}
+	switch pqErr.Constraint {
+	case externalRefIndex:
+		return domain.ErrExternalRefTaken.Wrap(err)
+	case uuidIndex:
+		return domain.ErrEstateIdTaken.Wrap(err)
+	}
+	return err
+}
+
+func (e *estateRepositorySql) RestoreEstate(ctx context.Context, param *domain.ExportEstate) error {
+	ctx, span := tracing.StartQuery(ctx, "estate", "RestoreEstate", tracing.Statement(QueryCreateEstate))
+	defer span.End()
+	defer metrics.ObserveQuery("estate", "RestoreEstate")()
+
+	var dbConn interface {
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	} = e.conn
+
+	tx, _ := ctx.Value(e.manager.GetKey()).(*sql.Tx)
+	if tx != nil {
+		dbConn = tx
+	}
+
+	_, err := dbConn.ExecContext(ctx, QueryCreateEstate,
+		domain.TenantId(ctx),
+		param.Uuid,
+		param.Length,
+		param.Width,
+		param.CreatedAt,
+		param.ExternalRef,
+	)
+	if err != nil {
+		return writeError(err)
+	}
 
 	return nil
 }
 
 func (e *estateRepositorySql) GetEstateByUuid(ctx context.Context, id string) (*domain.Estate, error) {
-	result, err := e.fetch(ctx, QueryGetByUuid, id)
+	ctx, span := tracing.StartQuery(ctx, "estate", "GetEstateByUuid", tracing.EstateId(id), tracing.Statement(QueryGetByUuid))
+	defer span.End()
+	defer metrics.ObserveQuery("estate", "GetEstateByUuid")()
+
+	result, err := e.fetch(ctx, QueryGetByUuid, domain.TenantId(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return &result[0], nil
+}
+
+func (e *estateRepositorySql) LockEstate(ctx context.Context, id string) (*domain.Estate, error) {
+	ctx, span := tracing.StartQuery(ctx, "estate", "LockEstate", tracing.EstateId(id), tracing.Statement(QueryLockByUuid))
+	defer span.End()
+	defer metrics.ObserveQuery("estate", "LockEstate")()
+
+	result, err := e.fetch(ctx, QueryLockByUuid, domain.TenantId(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return &result[0], nil
+}
+
+func (e *estateRepositorySql) GetEstateByExternalRef(ctx context.Context, ref string) (*domain.Estate, error) {
+	ctx, span := tracing.StartQuery(ctx, "estate", "GetEstateByExternalRef", tracing.Statement(QueryGetByExternalRef))
+	defer span.End()
+	defer metrics.ObserveQuery("estate", "GetEstateByExternalRef")()
+
+	result, err := e.fetch(ctx, QueryGetByExternalRef, domain.TenantId(ctx), ref)
 	if err != nil {
 		return nil, err
 	}
@@ -85,3 +219,107 @@ func (e *estateRepositorySql) GetEstateByUuid(ctx context.Context, id string) (*
 	}
 	return &result[0], nil
 }
+
+// ExportEstates reads the export through a server side cursor, so only one
+// batch of rows is held in memory however many estates there are. The
+// cursor lives in the transaction carried by ctx or in a read-only one of
+// its own.
+func (e *estateRepositorySql) ExportEstates(ctx context.Context, id string, fn func(domain.EstateExportRow) error) (err error) {
+	ctx, span := tracing.StartQuery(ctx, "estate", "ExportEstates", tracing.EstateId(id))
+	defer span.End()
+	defer metrics.ObserveQuery("estate", "ExportEstates")()
+
+	tx, _ := ctx.Value(e.manager.GetKey()).(*sql.Tx)
+	if tx == nil {
+		tx, err = e.conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				rollbackErr := tx.Rollback()
+				if rollbackErr != nil {
+					slog.ErrorContext(ctx, "roll back transaction", "error", rollbackErr)
+				}
+				return
+			}
+			err = tx.Commit()
+		}()
+	}
+
+	_, err = tx.ExecContext(ctx, QueryDeclareExportCursor, domain.TenantId(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	for {
+		n, err := e.fetchExportBatch(ctx, tx, fn)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, QueryCloseExportCursor)
+	return err
+}
+
+// fetchExportBatch passes the next batch of the export cursor to fn and
+// returns how many rows it held.
+func (e *estateRepositorySql) fetchExportBatch(ctx context.Context, tx *sql.Tx, fn func(domain.EstateExportRow) error) (n int, err error) {
+	rows, err := tx.QueryContext(ctx, QueryFetchExportCursor)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		closeErr := rows.Close()
+		if closeErr != nil {
+			slog.ErrorContext(ctx, "close rows", "error", closeErr)
+		}
+	}()
+
+	for rows.Next() {
+		row := domain.EstateExportRow{}
+		var (
+			treeId               sql.NullInt64
+			treeX, treeY, height sql.NullInt64
+			plantedAt            sql.NullTime
+		)
+
+		err = rows.Scan(
+			&row.Uuid,
+			&row.Length,
+			&row.Width,
+			&row.CreatedAt,
+			&row.ExternalRef,
+			&treeId,
+			&treeX,
+			&treeY,
+			&height,
+			&plantedAt,
+		)
+		if err != nil {
+			return n, err
+		}
+		if treeId.Valid {
+			row.Tree = &domain.ExportPalmTree{
+				Id:        treeId.Int64,
+				X:         int(treeX.Int64),
+				Y:         int(treeY.Int64),
+				Height:    int(height.Int64),
+				PlantedAt: plantedAt.Time,
+			}
+		}
+
+		err = fn(row)
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, rows.Err()
+}
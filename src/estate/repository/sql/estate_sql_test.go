@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log"
+	"regexp"
 	"testing"
 	"time"
 
@@ -11,11 +12,16 @@ import (
 	"github.com/davidyunus/sawitpro-estate/src/common"
 	"github.com/davidyunus/sawitpro-estate/src/domain"
 	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
+const utTenant = "acme"
+
+var tenantCtx = domain.WithTenant(context.Background(), &domain.Organisation{Id: utTenant})
+
 func init() {
-	err := helper.InitTime()
+	err := helper.InitTime("Asia/Jakarta")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -32,7 +38,7 @@ func TestCreateEstate(t *testing.T) {
 	}
 	defer db.Close()
 
-	ctx := context.Background()
+	ctx := tenantCtx
 	now := helper.Now()
 	tempNow := helper.Now
 	helper.Now = func() time.Time {
@@ -51,10 +57,11 @@ func TestCreateEstate(t *testing.T) {
 		param *domain.Estate
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-		mock    func()
+		name        string
+		args        args
+		wantErr     bool
+		wantErrType error
+		mock        func()
 	}{
 		{
 			name: "success",
@@ -69,7 +76,7 @@ func TestCreateEstate(t *testing.T) {
 			wantErr: false,
 			mock: func() {
 				mock.ExpectExec("INSERT").
-					WithArgs("uuid", 6, 3, now).
+					WithArgs(utTenant, "uuid", 6, 3, now, "").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
@@ -86,10 +93,82 @@ func TestCreateEstate(t *testing.T) {
 			wantErr: true,
 			mock: func() {
 				mock.ExpectExec("INSERT").
-					WithArgs("uuid", 6, 3, now).
+					WithArgs(utTenant, "uuid", 6, 3, now, "").
 					WillReturnError(errors.New(common.UtSomeError))
 			},
 		},
+		{
+			name: "error external ref taken",
+			args: args{
+				ctx: ctx,
+				param: &domain.Estate{
+					Uuid:        common.UtUuid,
+					Length:      6,
+					Width:       3,
+					ExternalRef: "ERP-1",
+				},
+			},
+			wantErr:     true,
+			wantErrType: domain.ErrExternalRefTaken,
+			mock: func() {
+				mock.ExpectExec("INSERT").
+					WithArgs(utTenant, "uuid", 6, 3, now, "ERP-1").
+					WillReturnError(&pq.Error{Code: uniqueViolation, Constraint: externalRefIndex})
+			},
+		},
+		{
+			name: "error estate id taken",
+			args: args{
+				ctx: ctx,
+				param: &domain.Estate{
+					Uuid:   common.UtUuid,
+					Length: 6,
+					Width:  3,
+				},
+			},
+			wantErr:     true,
+			wantErrType: domain.ErrEstateIdTaken,
+			mock: func() {
+				mock.ExpectExec("INSERT").
+					WithArgs(utTenant, "uuid", 6, 3, now, "").
+					WillReturnError(&pq.Error{Code: uniqueViolation, Constraint: uuidIndex})
+			},
+		},
+		{
+			name: "success default organisation",
+			args: args{
+				ctx: context.Background(),
+				param: &domain.Estate{
+					Uuid:   common.UtUuid,
+					Length: 6,
+					Width:  3,
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("INSERT").
+					WithArgs(domain.DefaultOrganisationId, "uuid", 6, 3, now, "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "success organisation timezone",
+			args: args{
+				ctx: domain.WithTenant(context.Background(), &domain.Organisation{Id: utTenant, Timezone: "Asia/Makassar"}),
+				param: &domain.Estate{
+					Uuid:   common.UtUuid,
+					Length: 6,
+					Width:  3,
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				makassar, _ := time.LoadLocation("Asia/Makassar")
+				mock.ExpectExec("INSERT").
+					WithArgs(utTenant, "uuid", 6, 3, now.In(makassar), "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -97,6 +176,138 @@ func TestCreateEstate(t *testing.T) {
 
 			err := repo.CreateEstate(test.args.ctx, test.args.param)
 			assert.Equal(t, test.wantErr, err != nil)
+			if test.wantErrType != nil {
+				assert.ErrorIs(t, err, test.wantErrType)
+			}
+		})
+	}
+}
+
+func TestUpsertEstate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := tenantCtx
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time {
+		return now
+	}
+	defer func() {
+		helper.Now = tempNow
+	}()
+	repo := estateRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+	param := &domain.Estate{Uuid: common.UtUuid, Length: 6, Width: 3, ExternalRef: "ERP-1"}
+
+	tests := []struct {
+		name       string
+		wantResult bool
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success inserted",
+			wantResult: true,
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO estate").
+					WithArgs(utTenant, common.UtUuid, 6, 3, now, "ERP-1").
+					WillReturnRows(sqlmock.NewRows([]string{"created"}).AddRow(true))
+			},
+		},
+		{
+			name:       "success updated",
+			wantResult: false,
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO estate").
+					WithArgs(utTenant, common.UtUuid, 6, 3, now, "ERP-1").
+					WillReturnRows(sqlmock.NewRows([]string{"created"}).AddRow(false))
+			},
+		},
+		{
+			name:    "error external ref taken",
+			wantErr: domain.ErrExternalRefTaken,
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO estate").
+					WillReturnError(&pq.Error{Code: uniqueViolation, Constraint: externalRefIndex})
+			},
+		},
+		{
+			name:    "error estate id taken by another organisation",
+			wantErr: domain.ErrEstateIdTaken,
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO estate").
+					WithArgs(utTenant, common.UtUuid, 6, 3, now, "ERP-1").
+					WillReturnRows(sqlmock.NewRows([]string{"created"}))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.UpsertEstate(ctx, param)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetEstateByExternalRef(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &estateRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	tests := []struct {
+		name       string
+		wantResult *domain.Estate
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:       "success",
+			wantResult: &domain.Estate{Uuid: common.UtUuid, Length: 6, Width: 3, ExternalRef: "ERP-1"},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"uuid", "length", "width", "externalRef"}).
+					AddRow(common.UtUuid, 6, 3, "ERP-1")
+				mock.ExpectQuery("SELECT").WithArgs(utTenant, "ERP-1").WillReturnRows(rows)
+			},
+		},
+		{
+			name: "success no rows",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"uuid", "length", "width", "externalRef"})
+				mock.ExpectQuery("SELECT").WithArgs(utTenant, "ERP-1").WillReturnRows(rows)
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery("SELECT").WithArgs(utTenant, "ERP-1").WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.GetEstateByExternalRef(tenantCtx, "ERP-1")
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
 		})
 	}
 }
@@ -108,7 +319,7 @@ func TestGetEstateByUuid(t *testing.T) {
 	}
 	defer db.Close()
 
-	ctx := context.Background()
+	ctx := tenantCtx
 	repo := &estateRepositorySql{
 		conn:    db,
 		manager: helper.NewManager(db, common.TransactionContextKey),
@@ -138,10 +349,10 @@ func TestGetEstateByUuid(t *testing.T) {
 			},
 			wantErr: false,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"uuid", "length", "widty"}).
-					AddRow(common.UtUuid, 6, 3)
+				rows := sqlmock.NewRows([]string{"uuid", "length", "widty", "externalRef"}).
+					AddRow(common.UtUuid, 6, 3, "")
 
-				mock.ExpectQuery("SELECT").WithArgs(common.UtUuid).WillReturnRows(rows)
+				mock.ExpectQuery("SELECT").WithArgs(utTenant, common.UtUuid).WillReturnRows(rows)
 			},
 		},
 		{
@@ -153,7 +364,7 @@ func TestGetEstateByUuid(t *testing.T) {
 			wantResult: nil,
 			wantErr:    true,
 			mock: func() {
-				mock.ExpectQuery("SELECT").WithArgs(common.UtUuid).WillReturnError(errors.New(common.UtSomeError))
+				mock.ExpectQuery("SELECT").WithArgs(utTenant, common.UtUuid).WillReturnError(errors.New(common.UtSomeError))
 			},
 		},
 		{
@@ -167,7 +378,21 @@ func TestGetEstateByUuid(t *testing.T) {
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"uuid", "length", "widty"})
 
-				mock.ExpectQuery("SELECT").WithArgs(common.UtUuid).WillReturnRows(rows)
+				mock.ExpectQuery("SELECT").WithArgs(utTenant, common.UtUuid).WillReturnRows(rows)
+			},
+		},
+		{
+			name: "success estate of another organisation",
+			args: args{
+				ctx: domain.WithTenant(context.Background(), &domain.Organisation{Id: "other"}),
+				id:  common.UtUuid,
+			},
+			wantResult: nil,
+			wantErr:    false,
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"uuid", "length", "widty"})
+
+				mock.ExpectQuery("SELECT").WithArgs("other", common.UtUuid).WillReturnRows(rows)
 			},
 		},
 	}
@@ -181,3 +406,222 @@ func TestGetEstateByUuid(t *testing.T) {
 		})
 	}
 }
+
+func TestLockEstate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	manager := helper.NewManager(db, common.TransactionContextKey)
+	repo := &estateRepositorySql{
+		conn:    db,
+		manager: manager,
+	}
+
+	tests := []struct {
+		name       string
+		wantResult *domain.Estate
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:       "success",
+			wantResult: &domain.Estate{Uuid: common.UtUuid, Length: 6, Width: 3},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"uuid", "length", "width", "externalRef"}).
+					AddRow(common.UtUuid, 6, 3, "")
+
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(QueryLockByUuid)).WithArgs(utTenant, common.UtUuid).WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "success no rows",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(QueryLockByUuid)).WithArgs(utTenant, common.UtUuid).
+					WillReturnRows(sqlmock.NewRows([]string{"uuid", "length", "width", "externalRef"}))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(QueryLockByUuid)).WithArgs(utTenant, common.UtUuid).WillReturnError(errors.New(common.UtSomeError))
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			var got *domain.Estate
+			err := manager.WithinTransaction(tenantCtx, func(ctx context.Context) error {
+				var err error
+				got, err = repo.LockEstate(ctx, common.UtUuid)
+				return err
+			})
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExportEstates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := tenantCtx
+	repo := &estateRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	columns := []string{"uuid", "length", "width", "createdAt", "externalRef", "id", "x", "y", "height", "createdAt"}
+
+	tests := []struct {
+		name       string
+		id         string
+		wantResult []domain.EstateExportRow
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name: "success",
+			id:   "",
+			wantResult: []domain.EstateExportRow{
+				{
+					Uuid:        common.UtUuid,
+					Length:      6,
+					Width:       3,
+					CreatedAt:   createdAt,
+					ExternalRef: "ERP-1",
+					Tree:        &domain.ExportPalmTree{Id: 1, X: 2, Y: 1, Height: 10, PlantedAt: createdAt},
+				},
+				{
+					Uuid:      "empty",
+					Length:    2,
+					Width:     2,
+					CreatedAt: createdAt,
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DECLARE estate_export").WithArgs(utTenant, "").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("FETCH").WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(common.UtUuid, 6, 3, createdAt, "ERP-1", 1, 2, 1, 10, createdAt))
+				mock.ExpectQuery("FETCH").WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("empty", 2, 2, createdAt, "", nil, nil, nil, nil, nil))
+				mock.ExpectQuery("FETCH").WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectExec("CLOSE estate_export").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:       "error fetch",
+			id:         common.UtUuid,
+			wantResult: nil,
+			wantErr:    true,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DECLARE estate_export").WithArgs(utTenant, common.UtUuid).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("FETCH").WillReturnError(errors.New(common.UtSomeError))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name:       "error declare",
+			id:         common.UtUuid,
+			wantResult: nil,
+			wantErr:    true,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DECLARE estate_export").WithArgs(utTenant, common.UtUuid).WillReturnError(errors.New(common.UtSomeError))
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			var got []domain.EstateExportRow
+			err := repo.ExportEstates(ctx, test.id, func(row domain.EstateExportRow) error {
+				got = append(got, row)
+				return nil
+			})
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRestoreEstate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := tenantCtx
+	manager := helper.NewManager(db, common.TransactionContextKey)
+	repo := estateRepositorySql{
+		conn:    db,
+		manager: manager,
+	}
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	param := &domain.ExportEstate{Uuid: common.UtUuid, Length: 6, Width: 3, CreatedAt: createdAt}
+
+	tests := []struct {
+		name    string
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "success",
+			wantErr: false,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT").
+					WithArgs(utTenant, common.UtUuid, 6, 3, createdAt, "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT").
+					WithArgs(utTenant, common.UtUuid, 6, 3, createdAt, "").
+					WillReturnError(errors.New(common.UtSomeError))
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := manager.WithinTransaction(ctx, func(ctx context.Context) error {
+				return repo.RestoreEstate(ctx, param)
+			})
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
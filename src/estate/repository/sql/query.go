@@ -4,15 +4,75 @@ const (
 	SelectTemplate = `SELECT
 		uuid,
 		length,
-		width
+		width,
+		COALESCE(externalRef, '')
 	FROM
 		estate`
 
 	QueryGetByUuid = SelectTemplate + `
 	WHERE
-		uuid = $1`
+		organisationId = $1
+		AND uuid = $2`
+
+	QueryLockByUuid = QueryGetByUuid + `
+	FOR UPDATE`
+
+	QueryGetByExternalRef = SelectTemplate + `
+	WHERE
+		organisationId = $1
+		AND externalRef = $2`
 
 	QueryCreateEstate = `INSERT INTO estate
-	(uuid, length, width, createdAt)
-	VALUES($1, $2, $3, $4)`
+	(organisationId, uuid, length, width, createdAt, externalRef)
+	VALUES($1, $2, $3, $4, $5, NULLIF($6, ''))`
+
+	// QueryUpsertEstate reports through xmax whether the row was inserted
+	// rather than updated. It returns no row when the uuid belongs to an
+	// estate of another organisation.
+	QueryUpsertEstate = `INSERT INTO estate
+	(organisationId, uuid, length, width, createdAt, externalRef)
+	VALUES($1, $2, $3, $4, $5, NULLIF($6, ''))
+	ON CONFLICT (uuid) DO UPDATE SET
+		length = EXCLUDED.length,
+		width = EXCLUDED.width,
+		externalRef = EXCLUDED.externalRef
+	WHERE
+		estate.organisationId = EXCLUDED.organisationId
+	RETURNING
+		xmax = 0`
+
+	QueryDeclareExportCursor = `DECLARE estate_export NO SCROLL CURSOR FOR
+	SELECT
+		e.uuid,
+		e.length,
+		e.width,
+		e.createdAt,
+		COALESCE(e.externalRef, ''),
+		p.id,
+		p.x,
+		p.y,
+		p.height,
+		p.createdAt
+	FROM
+		estate e
+		LEFT JOIN palmTreeLocation p ON p.uuid = e.uuid
+	WHERE
+		e.organisationId = $1
+		AND ($2 = '' OR e.uuid = $2)
+	ORDER BY
+		e.id, p.id`
+
+	QueryFetchExportCursor = `FETCH 1000 FROM estate_export`
+
+	QueryCloseExportCursor = `CLOSE estate_export`
 )
+
+// Unique indexes on estate, as PostgreSQL names them in constraint
+// violations.
+const (
+	uuidIndex        = "estate_uuid_idx"
+	externalRefIndex = "estate_organisationid_externalref_idx"
+)
+
+// uniqueViolation is the SQLSTATE of a unique constraint violation.
+const uniqueViolation = "23505"
@@ -2,48 +2,191 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
 
 	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+	"github.com/google/uuid"
 )
 
 type estateUsecase struct {
 	estateRepo           domain.EstateRepository
 	palmTreeLocationRepo domain.PalmTreeLocationRepository
+	permissionRepo       domain.EstatePermissionRepository
+	organisationRepo     domain.OrganisationRepository
+	auditRepo            domain.AuditRepository
+	transactor           domain.Transactor
+	sizePolicy           domain.SizePolicy
 }
 
-func NewEstateUsecase(estateRepo domain.EstateRepository, palmTreeLocationRepo domain.PalmTreeLocationRepository) domain.EstateUsecase {
+func NewEstateUsecase(estateRepo domain.EstateRepository, palmTreeLocationRepo domain.PalmTreeLocationRepository, permissionRepo domain.EstatePermissionRepository, organisationRepo domain.OrganisationRepository, auditRepo domain.AuditRepository, transactor domain.Transactor, sizePolicy domain.SizePolicy) domain.EstateUsecase {
 	return &estateUsecase{
 		estateRepo:           estateRepo,
 		palmTreeLocationRepo: palmTreeLocationRepo,
+		permissionRepo:       permissionRepo,
+		organisationRepo:     organisationRepo,
+		auditRepo:            auditRepo,
+		transactor:           transactor,
+		sizePolicy:           sizePolicy,
 	}
 }
 
+// tenantSizePolicy returns the size policy of the organisation the request
+// acts for.
+func (e *estateUsecase) tenantSizePolicy(ctx context.Context) domain.SizePolicy {
+	tenant := domain.TenantFromContext(ctx)
+	if tenant == nil {
+		return e.sizePolicy
+	}
+	return e.sizePolicy.Override(tenant.SizePolicy)
+}
+
+// CreateEstate lets any authenticated caller create an estate, and makes
+// the caller the admin of it.
 func (e *estateUsecase) CreateEstate(ctx context.Context, param *domain.Estate) (*domain.CreateEstateResponse, error) {
-	plots := 100
-	maxEstateSize := 50000
-	estateSize := param.Width * param.Length * plots
+	ctx, span := tracing.Start(ctx, "estateUsecase.CreateEstate")
+	defer span.End()
 
-	if estateSize > maxEstateSize {
-		return nil, domain.ErrMaxSizeEstate
+	principal := domain.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, domain.ErrUnauthorized
 	}
 
-	id := generateUUID()
-	err := e.estateRepo.CreateEstate(ctx, &domain.Estate{
-		Uuid:   id,
-		Length: param.Length,
-		Width:  param.Width,
+	err := e.tenantSizePolicy(ctx).Check(param)
+	if err != nil {
+		return nil, err
+	}
+
+	estate := &domain.Estate{
+		Uuid:        generateUUID(),
+		Length:      param.Length,
+		Width:       param.Width,
+		ExternalRef: param.ExternalRef,
+	}
+	err = e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := e.estateRepo.CreateEstate(ctx, estate)
+		if err != nil {
+			return err
+		}
+		err = e.grantCreator(ctx, estate.Uuid, principal)
+		if err != nil {
+			return err
+		}
+		return e.audit(ctx, domain.AuditCreateEstate, estate.Uuid, nil, nil, estate)
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &domain.CreateEstateResponse{
-		Id: id,
+		Id: estate.Uuid,
 	}, nil
 }
 
-func (e *estateUsecase) PlantPalmTree(ctx context.Context, id string, param *domain.PalmTree) (*domain.PlantPalmTreeResponse, error) {
-	estate, err := e.estateRepo.GetEstateByUuid(ctx, id)
+// PutEstate creates the estate under a client-chosen id, or replaces the
+// dimensions and external reference of the one already there. An estate
+// cannot shrink past its planted trees. Like CreateEstate, creating makes
+// the caller its admin; replacing needs PermissionManageEstate.
+func (e *estateUsecase) PutEstate(ctx context.Context, id string, param *domain.Estate) (*domain.PutEstateResponse, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.PutEstate", tracing.EstateId(id))
+	defer span.End()
+
+	principal := domain.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, domain.ErrUnauthorized
+	}
+
+	parsed, err := uuid.Parse(id)
+	if err != nil || parsed.String() != id {
+		return nil, domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+			Field:   "id",
+			Rule:    "uuid",
+			Message: "id must be a lowercase hyphenated UUID",
+		}})
+	}
+
+	err = e.tenantSizePolicy(ctx).Check(param)
+	if err != nil {
+		return nil, err
+	}
+
+	estate := &domain.Estate{
+		Uuid:        id,
+		Length:      param.Length,
+		Width:       param.Width,
+		ExternalRef: param.ExternalRef,
+	}
+	var created bool
+	err = e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The lock keeps trees from being planted outside the new bounds
+		// between the check below and the replace.
+		existing, err := e.estateRepo.LockEstate(ctx, id)
+		if err != nil {
+			return err
+		}
+		mustCreate := false
+		if !principal.Admin {
+			if existing != nil {
+				err = e.authorize(ctx, id, domain.PermissionManageEstate)
+				if err != nil {
+					return err
+				}
+			}
+			mustCreate = existing == nil
+		}
+
+		trees, err := e.palmTreeLocationRepo.GetPalmTreesByUuid(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, tree := range trees {
+			if !estate.Contains(tree.X, tree.Y) {
+				return domain.ErrTreesOutside
+			}
+		}
+
+		created, err = e.estateRepo.UpsertEstate(ctx, estate)
+		if err != nil {
+			return err
+		}
+		if !created {
+			// Someone else created the estate since it was looked up; the
+			// rollback undoes the replace.
+			if mustCreate {
+				return domain.ErrForbidden
+			}
+			return e.audit(ctx, domain.AuditReplaceEstate, id, nil, existing, estate)
+		}
+		err = e.grantCreator(ctx, id, principal)
+		if err != nil {
+			return err
+		}
+		return e.audit(ctx, domain.AuditCreateEstate, id, nil, nil, estate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PutEstateResponse{
+		Id:      id,
+		Created: created,
+	}, nil
+}
+
+// GetEstateByExternalRef reports an estate the caller may not read as not
+// found, so references of other estates cannot be probed.
+func (e *estateUsecase) GetEstateByExternalRef(ctx context.Context, ref string) (*domain.Estate, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.GetEstateByExternalRef")
+	defer span.End()
+
+	if domain.PrincipalFromContext(ctx) == nil {
+		return nil, domain.ErrUnauthorized
+	}
+
+	estate, err := e.estateRepo.GetEstateByExternalRef(ctx, ref)
 	if err != nil {
 		return nil, err
 	}
@@ -51,28 +194,74 @@ func (e *estateUsecase) PlantPalmTree(ctx context.Context, id string, param *dom
 		return nil, domain.ErrEstateNotFound
 	}
 
-	trees, err := e.palmTreeLocationRepo.GetPalmTreesByUuid(ctx, id)
+	err = e.authorize(ctx, estate.Uuid, domain.PermissionReadEstate)
+	if errors.Is(err, domain.ErrForbidden) {
+		return nil, domain.ErrEstateNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
+	return estate, nil
+}
 
-	for _, tree := range trees {
-		if tree.X == param.X && tree.Y == param.Y {
-			return nil, domain.ErrLocationFilled
-		}
+func (e *estateUsecase) PlantPalmTree(ctx context.Context, id string, param *domain.PalmTree) (*domain.PlantPalmTreeResponse, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.PlantPalmTree", tracing.EstateId(id), tracing.TreeCount(1))
+	defer span.End()
+
+	err := e.authorize(ctx, id, domain.PermissionPlantTrees)
+	if err != nil {
+		return nil, err
 	}
 
-	err = e.palmTreeLocationRepo.PlantPalmTree(ctx, id, param)
+	tree := &domain.PalmTree{Uuid: id, X: param.X, Y: param.Y, Height: param.Height}
+	err = e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Bounds and filled plots are checked under the lock of the estate,
+		// so a concurrent resize or planting cannot invalidate them.
+		estate, err := e.estateRepo.LockEstate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if estate == nil {
+			return domain.ErrEstateNotFound
+		}
+		if !estate.Contains(param.X, param.Y) {
+			return domain.ErrOutOfBounds
+		}
+
+		trees, err := e.palmTreeLocationRepo.GetPalmTreesByUuid(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, tree := range trees {
+			if tree.X == param.X && tree.Y == param.Y {
+				return domain.ErrLocationFilled
+			}
+		}
+
+		err = e.palmTreeLocationRepo.PlantPalmTree(ctx, id, tree)
+		if err != nil {
+			return err
+		}
+		return e.audit(ctx, domain.AuditPlantTree, id, []int64{tree.Id}, nil, tree)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &domain.PlantPalmTreeResponse{
-		Id: estate.Uuid,
+		Id: id,
 	}, nil
 }
 
 func (e *estateUsecase) GetTreeStats(ctx context.Context, id string) (*domain.GetTreeStatsResponse, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.GetTreeStats", tracing.EstateId(id))
+	defer span.End()
+
+	err := e.authorize(ctx, id, domain.PermissionReadEstate)
+	if err != nil {
+		return nil, err
+	}
+
 	estate, err := e.estateRepo.GetEstateByUuid(ctx, id)
 	if err != nil {
 		return nil, err
@@ -103,7 +292,52 @@ func (e *estateUsecase) GetTreeStats(ctx context.Context, id string) (*domain.Ge
 	return treeStatsResp, nil
 }
 
+// ListPalmTrees returns one page of the trees of an estate. One tree more
+// than the page holds is fetched to tell whether another page follows.
+func (e *estateUsecase) ListPalmTrees(ctx context.Context, id string, param *domain.ListPalmTreesRequest) (*domain.ListPalmTreesResponse, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.ListPalmTrees", tracing.EstateId(id))
+	defer span.End()
+
+	err := e.authorize(ctx, id, domain.PermissionReadEstate)
+	if err != nil {
+		return nil, err
+	}
+
+	sort, after, limit, err := validateListPalmTrees(param)
+	if err != nil {
+		return nil, err
+	}
+
+	estate, err := e.estateRepo.GetEstateByUuid(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if estate == nil {
+		return nil, domain.ErrEstateNotFound
+	}
+
+	trees, err := e.palmTreeLocationRepo.ListPalmTrees(ctx, id, &param.Filter, sort, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &domain.ListPalmTreesResponse{Trees: trees}
+	if len(trees) > limit {
+		resp.Trees = trees[:limit]
+		resp.NextCursor = strconv.FormatInt(trees[limit-1].Id, 10)
+	}
+	return resp, nil
+}
+
 func (e *estateUsecase) GetDroneFlyingDistance(ctx context.Context, id string, maxDistance int) (*domain.GetDroneFlyingDistanceResponse, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.GetDroneFlyingDistance", tracing.EstateId(id))
+	defer span.End()
+
+	err := e.authorize(ctx, id, domain.PermissionReadDronePlan)
+	if err != nil {
+		return nil, err
+	}
+
 	estate, err := e.estateRepo.GetEstateByUuid(ctx, id)
 	if err != nil {
 		return nil, err
@@ -117,14 +351,23 @@ func (e *estateUsecase) GetDroneFlyingDistance(ctx context.Context, id string, m
 		return nil, err
 	}
 
-	palmTreeArr := generateCoordinates(estate.Length, estate.Width)
-	mergedCoordinates := mergePalmTrees(palmTreeArr, palmTrees)
+	palmTreeArr, err := generateCoordinates(ctx, estate.Length, estate.Width)
+	if err != nil {
+		return nil, err
+	}
+	mergedCoordinates, err := mergePalmTrees(ctx, palmTreeArr, palmTrees)
+	if err != nil {
+		return nil, err
+	}
 	finalCoordinates := removeTrailingZeroHeightCoordinates(mergedCoordinates)
 
 	horizontalDistance := 0
 	verticalDistance := 0
 	lastHeight := 0
 	for i, coordinate := range finalCoordinates {
+		if i%checkEvery == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		if coordinate.Height != 0 {
 			if lastHeight == 0 {
 				verticalDistance++
@@ -157,3 +400,477 @@ func (e *estateUsecase) GetDroneFlyingDistance(ctx context.Context, id string, m
 		Distance: int(totalDistance),
 	}, nil
 }
+
+// FindOutOfBoundsPalmTrees only reports the trees of estates the caller may
+// read.
+func (e *estateUsecase) FindOutOfBoundsPalmTrees(ctx context.Context) (*domain.FindOutOfBoundsPalmTreesResponse, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.FindOutOfBoundsPalmTrees")
+	defer span.End()
+
+	principal := domain.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, domain.ErrUnauthorized
+	}
+
+	trees, err := e.palmTreeLocationRepo.GetOutOfBoundsPalmTrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !principal.Admin {
+		permissions, err := e.permissionRepo.ListSubjectPermissions(ctx, principal.RoleSubject())
+		if err != nil {
+			return nil, err
+		}
+		readable := map[string]bool{}
+		for _, permission := range permissions {
+			readable[permission.EstateId] = domain.RoleAllows(permission.Role, domain.PermissionReadEstate)
+		}
+		visible := make([]domain.OutOfBoundsPalmTree, 0, len(trees))
+		for _, tree := range trees {
+			if readable[tree.Uuid] {
+				visible = append(visible, tree)
+			}
+		}
+		trees = visible
+	}
+
+	return &domain.FindOutOfBoundsPalmTreesResponse{
+		Count: len(trees),
+		Trees: trees,
+	}, nil
+}
+
+// ImportPalmTrees validates every row before planting anything. In atomic
+// mode a single rejected row fails the import with the full report; in
+// best-effort mode the valid rows are planted and the rest reported.
+func (e *estateUsecase) ImportPalmTrees(ctx context.Context, id string, param *domain.ImportPalmTreesRequest) (*domain.ImportPalmTreesResponse, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.ImportPalmTrees", tracing.EstateId(id), tracing.TreeCount(len(param.Trees)))
+	defer span.End()
+
+	err := e.authorize(ctx, id, domain.PermissionPlantTrees)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := param.Mode
+	if mode == "" {
+		mode = domain.ImportModeAtomic
+	}
+	if mode != domain.ImportModeAtomic && mode != domain.ImportModeBestEffort {
+		return nil, domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+			Field:   "mode",
+			Rule:    "oneof",
+			Param:   domain.ImportModeAtomic + " " + domain.ImportModeBestEffort,
+			Message: "mode must be one of: " + domain.ImportModeAtomic + ", " + domain.ImportModeBestEffort,
+		}})
+	}
+	if len(param.Trees) == 0 {
+		return nil, domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+			Field:   "trees",
+			Rule:    "required",
+			Message: "trees is required",
+		}})
+	}
+	if len(param.Trees) > domain.MaxImportRows {
+		return nil, domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+			Field:   "trees",
+			Rule:    "max",
+			Param:   strconv.Itoa(domain.MaxImportRows),
+			Message: fmt.Sprintf("trees must contain at most %d rows", domain.MaxImportRows),
+		}})
+	}
+
+	result := &domain.ImportPalmTreesResponse{
+		Id:     id,
+		Mode:   mode,
+		Total:  len(param.Trees),
+		Errors: []domain.ImportRowError{},
+	}
+	var valid []domain.PalmTree
+	// Rows are checked against the trees of the estate under its lock, so
+	// no concurrent planting or resize lands between the check and the
+	// insert.
+	err = e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		estate, err := e.estateRepo.LockEstate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if estate == nil {
+			return domain.ErrEstateNotFound
+		}
+
+		existing, err := e.palmTreeLocationRepo.GetPalmTreesByUuid(ctx, id)
+		if err != nil {
+			return err
+		}
+		valid, err = validateImportRows(ctx, estate, existing, param.Trees, result)
+		if err != nil {
+			return err
+		}
+		if mode == domain.ImportModeAtomic && result.Rejected > 0 {
+			return domain.ErrImportRejected.WithDetails(result)
+		}
+		if len(valid) == 0 {
+			return nil
+		}
+
+		err = e.palmTreeLocationRepo.PlantPalmTrees(ctx, id, valid)
+		if err != nil {
+			return err
+		}
+		treeIds := make([]int64, len(valid))
+		for i, tree := range valid {
+			treeIds[i] = tree.Id
+		}
+		return e.audit(ctx, domain.AuditImportTrees, id, treeIds, nil, valid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(valid)
+
+	return result, nil
+}
+
+// ExportPalmTrees streams the trees of one estate, each with the estate
+// dimensions, to fn. The estate is looked up first so a missing one fails
+// before anything is streamed.
+func (e *estateUsecase) ExportPalmTrees(ctx context.Context, id string, fn func(domain.EstateExportRow) error) error {
+	ctx, span := tracing.Start(ctx, "estateUsecase.ExportPalmTrees", tracing.EstateId(id))
+	defer span.End()
+
+	err := e.authorize(ctx, id, domain.PermissionReadEstate)
+	if err != nil {
+		return err
+	}
+
+	estate, err := e.estateRepo.GetEstateByUuid(ctx, id)
+	if err != nil {
+		return err
+	}
+	if estate == nil {
+		return domain.ErrEstateNotFound
+	}
+
+	return e.estateRepo.ExportEstates(ctx, id, func(row domain.EstateExportRow) error {
+		if row.Tree == nil {
+			return nil
+		}
+		return fn(row)
+	})
+}
+
+// ExportEstates streams every estate with its trees and roles to fn. The
+// repository orders rows by estate, so an estate is complete once the next
+// one starts. Only administrators may export every estate.
+func (e *estateUsecase) ExportEstates(ctx context.Context, fn func(domain.ExportEstate) error) error {
+	ctx, span := tracing.Start(ctx, "estateUsecase.ExportEstates")
+	defer span.End()
+
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	emit := func(estate domain.ExportEstate) error {
+		permissions, err := e.permissionRepo.ListEstatePermissions(ctx, estate.Uuid)
+		if err != nil {
+			return err
+		}
+		estate.Permissions = permissions
+		return fn(estate)
+	}
+
+	var current *domain.ExportEstate
+	err = e.estateRepo.ExportEstates(ctx, "", func(row domain.EstateExportRow) error {
+		if current != nil && current.Uuid != row.Uuid {
+			err := emit(*current)
+			if err != nil {
+				return err
+			}
+			current = nil
+		}
+		if current == nil {
+			current = &domain.ExportEstate{
+				Uuid:        row.Uuid,
+				Length:      row.Length,
+				Width:       row.Width,
+				CreatedAt:   row.CreatedAt,
+				ExternalRef: row.ExternalRef,
+				Trees:       []domain.ExportPalmTree{},
+			}
+		}
+		if row.Tree != nil {
+			current.Trees = append(current.Trees, *row.Tree)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if current != nil {
+		return emit(*current)
+	}
+	return nil
+}
+
+// RestoreEstates restores the estates of a backup archive one by one, each
+// in its own transaction with its roles. An estate whose UUID already holds
+// the same dimensions and trees is left unchanged, so a restore can be
+// repeated or resumed after a failure. Differing data is a conflict,
+// handled as param.Conflict says. The timezone and size policy of the
+// archived organisation are applied to the organisation restored into
+// before any estate, so the estates are checked against them. Only
+// administrators may restore.
+func (e *estateUsecase) RestoreEstates(ctx context.Context, param *domain.RestoreEstatesRequest) (*domain.RestoreEstatesResponse, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.RestoreEstates")
+	defer span.End()
+
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conflict := param.Conflict
+	if conflict == "" {
+		conflict = domain.RestoreConflictFail
+	}
+	if conflict != domain.RestoreConflictFail && conflict != domain.RestoreConflictSkip && conflict != domain.RestoreConflictRemap {
+		return nil, domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+			Field:   "conflict",
+			Rule:    "oneof",
+			Param:   domain.RestoreConflictFail + " " + domain.RestoreConflictSkip + " " + domain.RestoreConflictRemap,
+			Message: "conflict must be one of: " + domain.RestoreConflictFail + ", " + domain.RestoreConflictSkip + ", " + domain.RestoreConflictRemap,
+		}})
+	}
+
+	if param.Organisation != nil {
+		ctx, err = e.restoreOrganisation(ctx, param.Organisation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &domain.RestoreEstatesResponse{
+		Conflict: conflict,
+		Estates:  []domain.RestoreResult{},
+	}
+	policy := e.tenantSizePolicy(ctx)
+	for i := 0; ; i++ {
+		// A restore runs as long as the archive is large; stop between
+		// estates once the request is cancelled, keeping those restored.
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		estate, err := param.Estates.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = validateArchivedEstate(i, estate, policy)
+		if err != nil {
+			return nil, err
+		}
+
+		restored, err := e.restoreEstate(ctx, estate, conflict)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Total++
+		result.Estates = append(result.Estates, restored)
+		switch restored.Status {
+		case domain.RestoreStatusCreated:
+			result.Created++
+		case domain.RestoreStatusRemapped:
+			result.Remapped++
+		case domain.RestoreStatusUnchanged:
+			result.Unchanged++
+		case domain.RestoreStatusSkipped:
+			result.Skipped++
+		case domain.RestoreStatusConflict:
+			return nil, domain.ErrRestoreConflict.WithDetails(result)
+		}
+	}
+
+	return result, nil
+}
+
+// restoreOrganisation gives the organisation of the request the timezone
+// and size policy of the archived one, keeping its id and name, and returns
+// ctx carrying the updated organisation. Settings already equal are left
+// alone, so repeating a restore changes nothing.
+func (e *estateUsecase) restoreOrganisation(ctx context.Context, archived *domain.Organisation) (context.Context, error) {
+	err := validateArchivedOrganisation(archived)
+	if err != nil {
+		return ctx, err
+	}
+
+	tenant := domain.TenantFromContext(ctx)
+	if tenant == nil {
+		return ctx, nil
+	}
+	if tenant.Timezone == archived.Timezone && tenant.SizePolicy == archived.SizePolicy {
+		return ctx, nil
+	}
+
+	organisation := *tenant
+	organisation.Timezone = archived.Timezone
+	organisation.SizePolicy = archived.SizePolicy
+	err = e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := e.organisationRepo.UpsertOrganisation(ctx, &organisation)
+		if err != nil {
+			return err
+		}
+		entry, err := domain.NewAuditEntry(ctx, domain.AuditPutOrganisation, "", tenant, organisation)
+		if err != nil {
+			return err
+		}
+		return e.auditRepo.AppendAuditEntry(ctx, entry)
+	})
+	if err != nil {
+		return ctx, err
+	}
+	return domain.WithTenant(ctx, &organisation), nil
+}
+
+func (e *estateUsecase) restoreEstate(ctx context.Context, estate *domain.ExportEstate, conflict string) (domain.RestoreResult, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.restoreEstate", tracing.EstateId(estate.Uuid), tracing.TreeCount(len(estate.Trees)))
+	defer span.End()
+
+	restored := domain.RestoreResult{
+		SourceUuid: estate.Uuid,
+		Uuid:       estate.Uuid,
+		Status:     domain.RestoreStatusCreated,
+		Trees:      len(estate.Trees),
+	}
+
+	same, exists, err := e.compareArchivedEstate(ctx, estate.Uuid, estate)
+	if err != nil {
+		return restored, err
+	}
+	if exists && same {
+		restored.Status = domain.RestoreStatusUnchanged
+		return restored, nil
+	}
+	if !exists {
+		err = e.insertArchivedEstate(ctx, estate.Uuid, estate, estate.ExternalRef)
+		if !errors.Is(err, domain.ErrEstateIdTaken) {
+			return restored, err
+		}
+		// The uuid belongs to an estate of another organisation, which is a
+		// conflict like any other, reported without saying whose.
+	}
+
+	switch conflict {
+	case domain.RestoreConflictSkip:
+		restored.Status = domain.RestoreStatusSkipped
+		return restored, nil
+	case domain.RestoreConflictFail:
+		restored.Status = domain.RestoreStatusConflict
+		return restored, nil
+	}
+
+	restored.Uuid = remapUUID(estate.Uuid)
+	restored.Status = domain.RestoreStatusRemapped
+	same, exists, err = e.compareArchivedEstate(ctx, restored.Uuid, estate)
+	if err != nil {
+		return restored, err
+	}
+	if exists && same {
+		restored.Status = domain.RestoreStatusUnchanged
+		return restored, nil
+	}
+	if exists {
+		restored.Status = domain.RestoreStatusConflict
+		return restored, nil
+	}
+
+	// The external reference stays with the estate already holding it, so
+	// a remapped copy is restored without one.
+	err = e.insertArchivedEstate(ctx, restored.Uuid, estate, "")
+	if errors.Is(err, domain.ErrEstateIdTaken) {
+		restored.Status = domain.RestoreStatusConflict
+		return restored, nil
+	}
+	return restored, err
+}
+
+// insertArchivedEstate inserts the archived estate, its trees and its roles
+// under id in a transaction of its own, failing with
+// domain.ErrEstateIdTaken when id belongs to an estate of another
+// organisation.
+func (e *estateUsecase) insertArchivedEstate(ctx context.Context, id string, estate *domain.ExportEstate, externalRef string) error {
+	record := &domain.ExportEstate{
+		Uuid:        id,
+		Length:      estate.Length,
+		Width:       estate.Width,
+		CreatedAt:   estate.CreatedAt,
+		ExternalRef: externalRef,
+		Trees:       append([]domain.ExportPalmTree(nil), estate.Trees...),
+	}
+	for _, permission := range estate.Permissions {
+		permission.EstateId = id
+		record.Permissions = append(record.Permissions, permission)
+	}
+	return e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := e.estateRepo.RestoreEstate(ctx, record)
+		if err != nil {
+			return err
+		}
+		var treeIds []int64
+		if len(record.Trees) > 0 {
+			err = e.palmTreeLocationRepo.RestorePalmTrees(ctx, id, record.Trees)
+			if err != nil {
+				return err
+			}
+			treeIds = make([]int64, len(record.Trees))
+			for i, tree := range record.Trees {
+				treeIds[i] = tree.Id
+			}
+		}
+		for i := range record.Permissions {
+			_, err = e.permissionRepo.UpsertEstatePermission(ctx, &record.Permissions[i])
+			if err != nil {
+				return err
+			}
+		}
+		return e.audit(ctx, domain.AuditRestoreEstate, id, treeIds, nil, record)
+	})
+}
+
+// compareArchivedEstate reports whether an estate exists under id and, if
+// so, whether it holds the dimensions and trees of the archived one.
+func (e *estateUsecase) compareArchivedEstate(ctx context.Context, id string, archived *domain.ExportEstate) (same, exists bool, err error) {
+	estate, err := e.estateRepo.GetEstateByUuid(ctx, id)
+	if err != nil || estate == nil {
+		return false, false, err
+	}
+	if estate.Length != archived.Length || estate.Width != archived.Width {
+		return false, true, nil
+	}
+
+	trees, err := e.palmTreeLocationRepo.GetPalmTreesByUuid(ctx, id)
+	if err != nil {
+		return false, true, err
+	}
+	if len(trees) != len(archived.Trees) {
+		return false, true, nil
+	}
+	type plot struct{ x, y int }
+	heights := make(map[plot]int, len(trees))
+	for _, tree := range trees {
+		heights[plot{tree.X, tree.Y}] = tree.Height
+	}
+	for _, tree := range archived.Trees {
+		height, ok := heights[plot{tree.X, tree.Y}]
+		if !ok || height != tree.Height {
+			return false, true, nil
+		}
+	}
+	return true, true, nil
+}
@@ -2,8 +2,12 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/davidyunus/sawitpro-estate/src/common"
 	"github.com/davidyunus/sawitpro-estate/src/domain"
@@ -13,20 +17,29 @@ import (
 )
 
 func TestNewEstateUsecase(t *testing.T) {
-	assert.NotNil(t, NewEstateUsecase(nil, nil))
+	assert.NotNil(t, NewEstateUsecase(nil, nil, nil, nil, nil, nil, domain.SizePolicy{}))
 }
 
 func TestCreateEstate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := context.Background()
+	ctx := adminCtx
 	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
 	palmTreeLocationRepoMock := mock_domain.NewMockPalmTreeLocationRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
 
 	uc := &estateUsecase{
 		estateRepo:           estateRepoMock,
 		palmTreeLocationRepo: palmTreeLocationRepoMock,
+		auditRepo:            auditRepoMock,
+		transactor:           transactorMock,
+		sizePolicy:           domain.DefaultSizePolicy(),
 	}
 
 	type args struct {
@@ -37,7 +50,7 @@ func TestCreateEstate(t *testing.T) {
 		name       string
 		args       args
 		wantResult *domain.CreateEstateResponse
-		wantErr    bool
+		wantErr    error
 		mock       func() func()
 	}{
 		{
@@ -53,7 +66,7 @@ func TestCreateEstate(t *testing.T) {
 			wantResult: &domain.CreateEstateResponse{
 				Id: common.UtUuid,
 			},
-			wantErr: false,
+			wantErr: nil,
 			mock: func() func() {
 				tempGenerateUUID := generateUUID
 				generateUUID = func() string {
@@ -65,6 +78,16 @@ func TestCreateEstate(t *testing.T) {
 					Length: 5,
 					Width:  5,
 				}).Return(nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, &domain.AuditEntry{
+							Actor:    "root",
+							Action:   domain.AuditCreateEstate,
+							EstateId: common.UtUuid,
+							After:    json.RawMessage(`{"uuid":"uuid","length":5,"width":5}`),
+						}, entry)
+						return nil
+					})
 				return func() {
 					generateUUID = tempGenerateUUID
 				}
@@ -81,7 +104,23 @@ func TestCreateEstate(t *testing.T) {
 				},
 			},
 			wantResult: nil,
-			wantErr:    true,
+			wantErr:    &domain.SizeLimitError{Limit: domain.SizeLimitArea, Max: 50000, Actual: 25000000},
+			mock: func() func() {
+				return func() {}
+			},
+		},
+		{
+			name: "error exceed organisation length",
+			args: args{
+				ctx: domain.WithTenant(ctx, &domain.Organisation{Id: "org", SizePolicy: domain.SizePolicy{MaxLength: 4}}),
+				param: &domain.Estate{
+					Uuid:   common.UtUuid,
+					Length: 5,
+					Width:  5,
+				},
+			},
+			wantResult: nil,
+			wantErr:    &domain.SizeLimitError{Limit: domain.SizeLimitLength, Max: 4, Actual: 5},
 			mock: func() func() {
 				return func() {}
 			},
@@ -97,7 +136,7 @@ func TestCreateEstate(t *testing.T) {
 				},
 			},
 			wantResult: nil,
-			wantErr:    true,
+			wantErr:    errors.New(common.UtSomeError),
 			mock: func() func() {
 				tempGenerateUUID := generateUUID
 				generateUUID = func() string {
@@ -120,7 +159,178 @@ func TestCreateEstate(t *testing.T) {
 			test.mock()
 
 			got, err := uc.CreateEstate(test.args.ctx, test.args.param)
-			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestPutEstate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := adminCtx
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	palmTreeLocationRepoMock := mock_domain.NewMockPalmTreeLocationRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+
+	uc := &estateUsecase{
+		estateRepo:           estateRepoMock,
+		palmTreeLocationRepo: palmTreeLocationRepoMock,
+		auditRepo:            auditRepoMock,
+		transactor:           transactorMock,
+		sizePolicy:           domain.DefaultSizePolicy(),
+	}
+
+	inTransaction := func() {
+		transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+	}
+	estate := &domain.Estate{Uuid: common.UtUuidV4, Length: 5, Width: 5, ExternalRef: "ERP-1"}
+
+	type args struct {
+		id    string
+		param *domain.Estate
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult *domain.PutEstateResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success created",
+			args:       args{id: common.UtUuidV4, param: &domain.Estate{Length: 5, Width: 5, ExternalRef: "ERP-1"}},
+			wantResult: &domain.PutEstateResponse{Id: common.UtUuidV4, Created: true},
+			mock: func() {
+				inTransaction()
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuidV4).Return(nil, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuidV4).Return([]domain.PalmTree{}, nil)
+				estateRepoMock.EXPECT().UpsertEstate(gomock.Any(), estate).Return(true, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), auditEntry("root", domain.AuditCreateEstate, common.UtUuidV4)).Return(nil)
+			},
+		},
+		{
+			name:       "success replaced",
+			args:       args{id: common.UtUuidV4, param: &domain.Estate{Length: 5, Width: 5, ExternalRef: "ERP-1"}},
+			wantResult: &domain.PutEstateResponse{Id: common.UtUuidV4, Created: false},
+			mock: func() {
+				inTransaction()
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuidV4).
+					Return(&domain.Estate{Uuid: common.UtUuidV4, Length: 6, Width: 6}, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuidV4).
+					Return([]domain.PalmTree{{X: 5, Y: 5, Height: 10}}, nil)
+				estateRepoMock.EXPECT().UpsertEstate(gomock.Any(), estate).Return(false, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, domain.AuditReplaceEstate, entry.Action)
+						assert.JSONEq(t, `{"uuid":"`+common.UtUuidV4+`","length":6,"width":6}`, string(entry.Before))
+						assert.JSONEq(t, `{"uuid":"`+common.UtUuidV4+`","length":5,"width":5,"externalRef":"ERP-1"}`, string(entry.After))
+						return nil
+					})
+			},
+		},
+		{
+			name:    "error invalid id",
+			args:    args{id: "not-a-uuid", param: &domain.Estate{Length: 5, Width: 5}},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:    "error uppercase id",
+			args:    args{id: strings.ToUpper(common.UtUuidV4), param: &domain.Estate{Length: 5, Width: 5}},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:    "error exceed size estate",
+			args:    args{id: common.UtUuidV4, param: &domain.Estate{Length: 500, Width: 500}},
+			wantErr: domain.ErrMaxSizeEstate,
+			mock:    func() {},
+		},
+		{
+			name:    "error trees outside",
+			args:    args{id: common.UtUuidV4, param: &domain.Estate{Length: 5, Width: 5, ExternalRef: "ERP-1"}},
+			wantErr: domain.ErrTreesOutside,
+			mock: func() {
+				inTransaction()
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuidV4).
+					Return(&domain.Estate{Uuid: common.UtUuidV4, Length: 6, Width: 6}, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuidV4).
+					Return([]domain.PalmTree{{X: 6, Y: 1, Height: 10}}, nil)
+			},
+		},
+		{
+			name:    "error external ref taken",
+			args:    args{id: common.UtUuidV4, param: &domain.Estate{Length: 5, Width: 5, ExternalRef: "ERP-1"}},
+			wantErr: domain.ErrExternalRefTaken,
+			mock: func() {
+				inTransaction()
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuidV4).Return(nil, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuidV4).Return([]domain.PalmTree{}, nil)
+				estateRepoMock.EXPECT().UpsertEstate(gomock.Any(), estate).Return(false, domain.ErrExternalRefTaken)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.PutEstate(ctx, test.args.id, test.args.param)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestGetEstateByExternalRef(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	uc := &estateUsecase{
+		estateRepo: estateRepoMock,
+	}
+
+	estate := &domain.Estate{Uuid: common.UtUuid, Length: 5, Width: 5, ExternalRef: "ERP-1"}
+	tests := []struct {
+		name       string
+		wantResult *domain.Estate
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success",
+			wantResult: estate,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByExternalRef(gomock.Any(), "ERP-1").Return(estate, nil)
+			},
+		},
+		{
+			name:    "error not found",
+			wantErr: domain.ErrEstateNotFound,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByExternalRef(gomock.Any(), "ERP-1").Return(nil, nil)
+			},
+		},
+		{
+			name:    "error repository",
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByExternalRef(gomock.Any(), "ERP-1").Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.GetEstateByExternalRef(adminCtx, "ERP-1")
+			assert.Equal(t, test.wantErr, err)
 			assert.Equal(t, test.wantResult, got)
 		})
 	}
@@ -130,13 +340,21 @@ func TestPlantPalmTree(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := context.Background()
+	ctx := domain.WithRequestId(adminCtx, "req-1")
 	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
 	palmTreeLocationRepoMock := mock_domain.NewMockPalmTreeLocationRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
 
 	uc := &estateUsecase{
 		estateRepo:           estateRepoMock,
 		palmTreeLocationRepo: palmTreeLocationRepoMock,
+		auditRepo:            auditRepoMock,
+		transactor:           transactorMock,
 	}
 
 	type args struct {
@@ -167,7 +385,7 @@ func TestPlantPalmTree(t *testing.T) {
 			},
 			wantErr: false,
 			mock: func() {
-				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(&domain.Estate{
 					Uuid:   common.UtUuid,
 					Length: 6,
 					Width:  3,
@@ -185,7 +403,22 @@ func TestPlantPalmTree(t *testing.T) {
 					Uuid: common.UtUuid,
 					X:    3,
 					Y:    1,
-				}).Return(nil)
+				}).DoAndReturn(func(_ context.Context, _ string, tree *domain.PalmTree) error {
+					tree.Id = 9
+					return nil
+				})
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, &domain.AuditEntry{
+							Actor:     "root",
+							Action:    domain.AuditPlantTree,
+							EstateId:  common.UtUuid,
+							TreeIds:   []int64{9},
+							After:     json.RawMessage(`{"id":9,"uuid":"uuid","x":3,"y":1,"height":0}`),
+							RequestId: "req-1",
+						}, entry)
+						return nil
+					})
 			},
 		},
 		{
@@ -202,7 +435,7 @@ func TestPlantPalmTree(t *testing.T) {
 			wantResult: nil,
 			wantErr:    true,
 			mock: func() {
-				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(nil, errors.New(common.UtSomeError))
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(nil, errors.New(common.UtSomeError))
 			},
 		},
 		{
@@ -219,7 +452,28 @@ func TestPlantPalmTree(t *testing.T) {
 			wantResult: nil,
 			wantErr:    true,
 			mock: func() {
-				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(nil, nil)
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(nil, nil)
+			},
+		},
+		{
+			name: "error location out of bounds",
+			args: args{
+				ctx: ctx,
+				id:  common.UtUuid,
+				param: &domain.PalmTree{
+					Uuid: common.UtUuid,
+					X:    7,
+					Y:    1,
+				},
+			},
+			wantResult: nil,
+			wantErr:    true,
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(&domain.Estate{
+					Uuid:   common.UtUuid,
+					Length: 6,
+					Width:  3,
+				}, nil)
 			},
 		},
 		{
@@ -236,7 +490,7 @@ func TestPlantPalmTree(t *testing.T) {
 			wantResult: nil,
 			wantErr:    true,
 			mock: func() {
-				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(&domain.Estate{
 					Uuid:   common.UtUuid,
 					Length: 6,
 					Width:  3,
@@ -259,7 +513,7 @@ func TestPlantPalmTree(t *testing.T) {
 			wantResult: nil,
 			wantErr:    true,
 			mock: func() {
-				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(&domain.Estate{
 					Uuid:   common.UtUuid,
 					Length: 6,
 					Width:  3,
@@ -288,7 +542,7 @@ func TestPlantPalmTree(t *testing.T) {
 			wantResult: nil,
 			wantErr:    true,
 			mock: func() {
-				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(&domain.Estate{
 					Uuid:   common.UtUuid,
 					Length: 6,
 					Width:  3,
@@ -325,7 +579,7 @@ func TestGetTreeStats(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := context.Background()
+	ctx := adminCtx
 	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
 	palmTreeLocationRepoMock := mock_domain.NewMockPalmTreeLocationRepository(ctrl)
 
@@ -489,11 +743,123 @@ func TestGetTreeStats(t *testing.T) {
 	}
 }
 
+func TestListPalmTrees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := adminCtx
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	palmTreeLocationRepoMock := mock_domain.NewMockPalmTreeLocationRepository(ctrl)
+
+	uc := &estateUsecase{
+		estateRepo:           estateRepoMock,
+		palmTreeLocationRepo: palmTreeLocationRepoMock,
+	}
+
+	plantedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	trees := []domain.ExportPalmTree{
+		{Id: 1, X: 1, Y: 1, Height: 10, PlantedAt: plantedAt},
+		{Id: 2, X: 2, Y: 1, Height: 5, PlantedAt: plantedAt},
+		{Id: 3, X: 3, Y: 1, Height: 7, PlantedAt: plantedAt},
+	}
+	minHeight, maxHeight := 10, 5
+	someErr := errors.New(common.UtSomeError)
+
+	tests := []struct {
+		name       string
+		args       *domain.ListPalmTreesRequest
+		wantResult *domain.ListPalmTreesResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success last page with defaults",
+			args:       &domain.ListPalmTreesRequest{},
+			wantResult: &domain.ListPalmTreesResponse{Trees: trees},
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				palmTreeLocationRepoMock.EXPECT().ListPalmTrees(gomock.Any(), common.UtUuid, &domain.PalmTreeFilter{}, "id", int64(0), domain.DefaultPalmTreePageSize+1).
+					Return(trees, nil)
+			},
+		},
+		{
+			name:       "success page followed by another",
+			args:       &domain.ListPalmTreesRequest{Sort: "-height", Cursor: "9", Limit: 2},
+			wantResult: &domain.ListPalmTreesResponse{Trees: trees[:2], NextCursor: "2"},
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				palmTreeLocationRepoMock.EXPECT().ListPalmTrees(gomock.Any(), common.UtUuid, &domain.PalmTreeFilter{}, "-height", int64(9), 3).
+					Return(trees, nil)
+			},
+		},
+		{
+			name:    "error invalid input",
+			args:    &domain.ListPalmTreesRequest{Sort: "age", Cursor: "abc", Limit: domain.MaxPalmTreePageSize + 1, Filter: domain.PalmTreeFilter{MinHeight: &minHeight, MaxHeight: &maxHeight}},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:    "error estate nil",
+			args:    &domain.ListPalmTreesRequest{},
+			wantErr: domain.ErrEstateNotFound,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(nil, nil)
+			},
+		},
+		{
+			name:    "error list palm trees",
+			args:    &domain.ListPalmTreesRequest{},
+			wantErr: someErr,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				palmTreeLocationRepoMock.EXPECT().ListPalmTrees(gomock.Any(), common.UtUuid, gomock.Any(), "id", int64(0), gomock.Any()).
+					Return(nil, someErr)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.ListPalmTrees(ctx, common.UtUuid, test.args)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestValidateListPalmTrees(t *testing.T) {
+	minHeight, maxHeight := 10, 5
+	plantedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	_, _, _, err := validateListPalmTrees(&domain.ListPalmTreesRequest{
+		Sort:   "age",
+		Cursor: "0",
+		Limit:  -1,
+		Filter: domain.PalmTreeFilter{
+			MinHeight:     &minHeight,
+			MaxHeight:     &maxHeight,
+			PlantedAfter:  &plantedAt,
+			PlantedBefore: &plantedAt,
+		},
+	})
+
+	domainErr := &domain.Error{}
+	assert.ErrorAs(t, err, &domainErr)
+	fields := []string{}
+	for _, detail := range domainErr.Details.([]domain.FieldError) {
+		fields = append(fields, detail.Field)
+	}
+	assert.Equal(t, []string{"sort", "cursor", "limit", "maxHeight", "plantedBefore"}, fields)
+}
+
 func TestGetDroneFlyingDistance(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := context.Background()
+	ctx := adminCtx
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
 	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
 	palmTreeLocationRepoMock := mock_domain.NewMockPalmTreeLocationRepository(ctrl)
 
@@ -647,6 +1013,31 @@ func TestGetDroneFlyingDistance(t *testing.T) {
 				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(nil, nil)
 			},
 		},
+		{
+			name: "error canceled",
+			args: args{
+				ctx: canceledCtx,
+				id:  common.UtUuid,
+			},
+			wantResult: nil,
+			wantErr:    true,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{
+					Uuid:   common.UtUuid,
+					Length: 6,
+					Width:  3,
+				}, nil)
+
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuid).Return([]domain.PalmTree{
+					{
+						Uuid:   common.UtUuid,
+						X:      3,
+						Y:      1,
+						Height: 10,
+					},
+				}, nil)
+			},
+		},
 		{
 			name: "error get palm trees",
 			args: args{
@@ -676,3 +1067,830 @@ func TestGetDroneFlyingDistance(t *testing.T) {
 		})
 	}
 }
+
+func TestFindOutOfBoundsPalmTrees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := adminCtx
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	palmTreeLocationRepoMock := mock_domain.NewMockPalmTreeLocationRepository(ctrl)
+
+	uc := &estateUsecase{
+		estateRepo:           estateRepoMock,
+		palmTreeLocationRepo: palmTreeLocationRepoMock,
+	}
+
+	tests := []struct {
+		name       string
+		wantResult *domain.FindOutOfBoundsPalmTreesResponse
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: &domain.FindOutOfBoundsPalmTreesResponse{
+				Count: 1,
+				Trees: []domain.OutOfBoundsPalmTree{
+					{
+						PalmTree: domain.PalmTree{
+							Id:     1,
+							Uuid:   common.UtUuid,
+							X:      7,
+							Y:      1,
+							Height: 10,
+						},
+						EstateLength: 6,
+						EstateWidth:  3,
+					},
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				palmTreeLocationRepoMock.EXPECT().GetOutOfBoundsPalmTrees(gomock.Any()).Return([]domain.OutOfBoundsPalmTree{
+					{
+						PalmTree: domain.PalmTree{
+							Id:     1,
+							Uuid:   common.UtUuid,
+							X:      7,
+							Y:      1,
+							Height: 10,
+						},
+						EstateLength: 6,
+						EstateWidth:  3,
+					},
+				}, nil)
+			},
+		},
+		{
+			name:       "error get out of bounds palm trees",
+			wantResult: nil,
+			wantErr:    true,
+			mock: func() {
+				palmTreeLocationRepoMock.EXPECT().GetOutOfBoundsPalmTrees(gomock.Any()).Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.FindOutOfBoundsPalmTrees(ctx)
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestImportPalmTrees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := adminCtx
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	palmTreeLocationRepoMock := mock_domain.NewMockPalmTreeLocationRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+
+	uc := &estateUsecase{
+		estateRepo:           estateRepoMock,
+		palmTreeLocationRepo: palmTreeLocationRepoMock,
+		auditRepo:            auditRepoMock,
+		transactor:           transactorMock,
+	}
+
+	someErr := errors.New(common.UtSomeError)
+	estate := &domain.Estate{Uuid: common.UtUuid, Length: 6, Width: 3}
+	existing := []domain.PalmTree{{Uuid: common.UtUuid, X: 2, Y: 1, Height: 10}}
+	trees := []domain.PalmTree{
+		{X: 3, Y: 1, Height: 10},
+		{X: 2, Y: 1, Height: 5},
+		{X: 7, Y: 1, Height: 5},
+		{X: 4, Y: 2, Height: 31},
+		{X: 3, Y: 1, Height: 8},
+		{X: 4, Y: 1, Height: 8},
+	}
+	report := []domain.ImportRowError{
+		{Row: 2, X: 2, Y: 1, Code: "location_filled", Message: "location already filled"},
+		{Row: 3, X: 7, Y: 1, Code: "location_out_of_bounds", Message: "location is outside the estate"},
+		{Row: 4, X: 4, Y: 2, Code: "invalid_input", Message: "height must be between 1 and 30"},
+		{Row: 5, X: 3, Y: 1, Code: "location_filled", Message: "location already filled by row 1"},
+	}
+
+	type args struct {
+		id    string
+		param *domain.ImportPalmTreesRequest
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult *domain.ImportPalmTreesResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name: "success atomic",
+			args: args{
+				id:    common.UtUuid,
+				param: &domain.ImportPalmTreesRequest{Trees: trees[:1]},
+			},
+			wantResult: &domain.ImportPalmTreesResponse{
+				Id:       common.UtUuid,
+				Mode:     domain.ImportModeAtomic,
+				Total:    1,
+				Imported: 1,
+				Errors:   []domain.ImportRowError{},
+			},
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(estate, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuid).Return(existing, nil)
+				palmTreeLocationRepoMock.EXPECT().PlantPalmTrees(gomock.Any(), common.UtUuid, trees[:1]).Return(nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), auditEntry("root", domain.AuditImportTrees, common.UtUuid)).Return(nil)
+			},
+		},
+		{
+			name: "atomic rejects every row",
+			args: args{
+				id:    common.UtUuid,
+				param: &domain.ImportPalmTreesRequest{Mode: domain.ImportModeAtomic, Trees: trees},
+			},
+			wantErr: domain.ErrImportRejected.WithDetails(&domain.ImportPalmTreesResponse{
+				Id:       common.UtUuid,
+				Mode:     domain.ImportModeAtomic,
+				Total:    6,
+				Rejected: 4,
+				Errors:   report,
+			}),
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(estate, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuid).Return(existing, nil)
+			},
+		},
+		{
+			name: "best effort plants valid rows",
+			args: args{
+				id:    common.UtUuid,
+				param: &domain.ImportPalmTreesRequest{Mode: domain.ImportModeBestEffort, Trees: trees},
+			},
+			wantResult: &domain.ImportPalmTreesResponse{
+				Id:       common.UtUuid,
+				Mode:     domain.ImportModeBestEffort,
+				Total:    6,
+				Imported: 2,
+				Rejected: 4,
+				Errors:   report,
+			},
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(estate, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuid).Return(existing, nil)
+				palmTreeLocationRepoMock.EXPECT().PlantPalmTrees(gomock.Any(), common.UtUuid, []domain.PalmTree{
+					{X: 3, Y: 1, Height: 10},
+					{X: 4, Y: 1, Height: 8},
+				}).DoAndReturn(func(_ context.Context, _ string, trees []domain.PalmTree) error {
+					trees[0].Id, trees[1].Id = 4, 5
+					return nil
+				})
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, domain.AuditImportTrees, entry.Action)
+						assert.Equal(t, []int64{4, 5}, entry.TreeIds)
+						return nil
+					})
+			},
+		},
+		{
+			name: "error unknown mode",
+			args: args{
+				id:    common.UtUuid,
+				param: &domain.ImportPalmTreesRequest{Mode: "partial", Trees: trees},
+			},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name: "error no trees",
+			args: args{
+				id:    common.UtUuid,
+				param: &domain.ImportPalmTreesRequest{},
+			},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name: "error estate nil",
+			args: args{
+				id:    common.UtUuid,
+				param: &domain.ImportPalmTreesRequest{Trees: trees},
+			},
+			wantErr: domain.ErrEstateNotFound,
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(nil, nil)
+			},
+		},
+		{
+			name: "error plant palm trees",
+			args: args{
+				id:    common.UtUuid,
+				param: &domain.ImportPalmTreesRequest{Trees: trees[:1]},
+			},
+			wantErr: someErr,
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(estate, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuid).Return(existing, nil)
+				palmTreeLocationRepoMock.EXPECT().PlantPalmTrees(gomock.Any(), common.UtUuid, trees[:1]).Return(someErr)
+			},
+		},
+		{
+			name: "error audit",
+			args: args{
+				id:    common.UtUuid,
+				param: &domain.ImportPalmTreesRequest{Trees: trees[:1]},
+			},
+			wantErr: someErr,
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuid).Return(estate, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuid).Return(existing, nil)
+				palmTreeLocationRepoMock.EXPECT().PlantPalmTrees(gomock.Any(), common.UtUuid, trees[:1]).Return(nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).Return(someErr)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.ImportPalmTrees(ctx, test.args.id, test.args.param)
+			assert.ErrorIs(t, err, test.wantErr)
+			if wantErr, ok := test.wantErr.(*domain.Error); ok && wantErr.Details != nil {
+				assert.Equal(t, wantErr.Details, err.(*domain.Error).Details)
+			}
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestExportPalmTrees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := adminCtx
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+
+	uc := &estateUsecase{
+		estateRepo: estateRepoMock,
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []domain.EstateExportRow{
+		{Uuid: common.UtUuid, Length: 6, Width: 3, CreatedAt: createdAt, Tree: &domain.ExportPalmTree{Id: 1, X: 2, Y: 1, Height: 10, PlantedAt: createdAt}},
+		{Uuid: common.UtUuid, Length: 6, Width: 3, CreatedAt: createdAt, Tree: &domain.ExportPalmTree{Id: 2, X: 3, Y: 1, Height: 5, PlantedAt: createdAt}},
+	}
+	stream := func(rows []domain.EstateExportRow) func(context.Context, string, func(domain.EstateExportRow) error) error {
+		return func(_ context.Context, _ string, fn func(domain.EstateExportRow) error) error {
+			for _, row := range rows {
+				if err := fn(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name       string
+		wantResult []domain.EstateExportRow
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success",
+			wantResult: rows,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				estateRepoMock.EXPECT().ExportEstates(gomock.Any(), common.UtUuid, gomock.Any()).DoAndReturn(stream(rows))
+			},
+		},
+		{
+			name:       "success estate without trees",
+			wantResult: nil,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				estateRepoMock.EXPECT().ExportEstates(gomock.Any(), common.UtUuid, gomock.Any()).
+					DoAndReturn(stream([]domain.EstateExportRow{{Uuid: common.UtUuid, Length: 6, Width: 3}}))
+			},
+		},
+		{
+			name:    "error estate nil",
+			wantErr: domain.ErrEstateNotFound,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(nil, nil)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			var got []domain.EstateExportRow
+			err := uc.ExportPalmTrees(ctx, common.UtUuid, func(row domain.EstateExportRow) error {
+				got = append(got, row)
+				return nil
+			})
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestExportEstates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := adminCtx
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	permissionRepoMock := mock_domain.NewMockEstatePermissionRepository(ctrl)
+
+	uc := &estateUsecase{
+		estateRepo:     estateRepoMock,
+		permissionRepo: permissionRepoMock,
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := &domain.ExportPalmTree{Id: 1, X: 2, Y: 1, Height: 10, PlantedAt: createdAt}
+	second := &domain.ExportPalmTree{Id: 2, X: 3, Y: 1, Height: 5, PlantedAt: createdAt}
+	rows := []domain.EstateExportRow{
+		{Uuid: common.UtUuid, Length: 6, Width: 3, CreatedAt: createdAt, Tree: first},
+		{Uuid: common.UtUuid, Length: 6, Width: 3, CreatedAt: createdAt, Tree: second},
+		{Uuid: "empty", Length: 2, Width: 2, CreatedAt: createdAt},
+	}
+	permissions := []domain.EstatePermission{
+		{EstateId: common.UtUuid, Subject: "jwt:alice", Role: domain.RoleAdmin, GrantedBy: "jwt:alice", GrantedAt: createdAt},
+	}
+	someErr := errors.New(common.UtSomeError)
+
+	tests := []struct {
+		name       string
+		wantResult []domain.ExportEstate
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: []domain.ExportEstate{
+				{Uuid: common.UtUuid, Length: 6, Width: 3, CreatedAt: createdAt, Trees: []domain.ExportPalmTree{*first, *second}, Permissions: permissions},
+				{Uuid: "empty", Length: 2, Width: 2, CreatedAt: createdAt, Trees: []domain.ExportPalmTree{}},
+			},
+			mock: func() {
+				estateRepoMock.EXPECT().ExportEstates(gomock.Any(), "", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, fn func(domain.EstateExportRow) error) error {
+						for _, row := range rows {
+							if err := fn(row); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+				permissionRepoMock.EXPECT().ListEstatePermissions(gomock.Any(), common.UtUuid).Return(permissions, nil)
+				permissionRepoMock.EXPECT().ListEstatePermissions(gomock.Any(), "empty").Return(nil, nil)
+			},
+		},
+		{
+			name:       "error list permissions",
+			wantResult: nil,
+			wantErr:    someErr,
+			mock: func() {
+				estateRepoMock.EXPECT().ExportEstates(gomock.Any(), "", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, fn func(domain.EstateExportRow) error) error {
+						for _, row := range rows {
+							if err := fn(row); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+				permissionRepoMock.EXPECT().ListEstatePermissions(gomock.Any(), common.UtUuid).Return(nil, someErr)
+			},
+		},
+		{
+			name:       "success no estates",
+			wantResult: nil,
+			mock: func() {
+				estateRepoMock.EXPECT().ExportEstates(gomock.Any(), "", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:       "error export",
+			wantResult: nil,
+			wantErr:    someErr,
+			mock: func() {
+				estateRepoMock.EXPECT().ExportEstates(gomock.Any(), "", gomock.Any()).Return(someErr)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			var got []domain.ExportEstate
+			err := uc.ExportEstates(ctx, func(estate domain.ExportEstate) error {
+				got = append(got, estate)
+				return nil
+			})
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+type estateSlice []domain.ExportEstate
+
+func (s *estateSlice) Next() (*domain.ExportEstate, error) {
+	if len(*s) == 0 {
+		return nil, io.EOF
+	}
+	estate := (*s)[0]
+	*s = (*s)[1:]
+	return &estate, nil
+}
+
+func TestRestoreEstates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := adminCtx
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	palmTreeLocationRepoMock := mock_domain.NewMockPalmTreeLocationRepository(ctrl)
+	permissionRepoMock := mock_domain.NewMockEstatePermissionRepository(ctrl)
+	organisationRepoMock := mock_domain.NewMockOrganisationRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+
+	uc := &estateUsecase{
+		estateRepo:           estateRepoMock,
+		palmTreeLocationRepo: palmTreeLocationRepoMock,
+		permissionRepo:       permissionRepoMock,
+		organisationRepo:     organisationRepoMock,
+		auditRepo:            auditRepoMock,
+		transactor:           transactorMock,
+		sizePolicy:           domain.DefaultSizePolicy(),
+	}
+
+	const source = "3f1e2d4c-5b6a-4789-8abc-def012345678"
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	trees := []domain.ExportPalmTree{{Id: 7, X: 2, Y: 1, Height: 10, PlantedAt: createdAt}}
+	archived := domain.ExportEstate{Uuid: source, Length: 6, Width: 3, CreatedAt: createdAt, ExternalRef: "ERP-1", Trees: trees}
+	remapped := remapUUID(source)
+	role := domain.EstatePermission{EstateId: source, Subject: "jwt:alice", Role: domain.RoleSurveyor, GrantedBy: "jwt:root", GrantedAt: createdAt}
+	withRoles := archived
+	withRoles.Permissions = []domain.EstatePermission{role}
+	remappedRole := role
+	remappedRole.EstateId = remapped
+	acme := &domain.Organisation{Id: "acme", Name: "Acme", CreatedAt: createdAt}
+	acmeCtx := domain.WithTenant(ctx, acme)
+	someErr := errors.New(common.UtSomeError)
+	same := []domain.PalmTree{{Id: 1, Uuid: source, X: 2, Y: 1, Height: 10}}
+	different := []domain.PalmTree{{Id: 1, Uuid: source, X: 2, Y: 1, Height: 12}}
+
+	inTransaction := func() {
+		transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+	}
+	existing := func(id string, trees []domain.PalmTree) {
+		estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), id).Return(&domain.Estate{Uuid: id, Length: 6, Width: 3}, nil)
+		palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), id).Return(trees, nil)
+	}
+	// idTaken is an archived uuid held by an estate of another
+	// organisation, which the tenant scoped lookup does not find.
+	idTaken := func() {
+		estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), source).Return(nil, nil)
+		inTransaction()
+		estateRepoMock.EXPECT().RestoreEstate(gomock.Any(), &domain.ExportEstate{
+			Uuid: source, Length: 6, Width: 3, CreatedAt: createdAt, ExternalRef: "ERP-1", Trees: trees,
+		}).Return(domain.ErrEstateIdTaken)
+	}
+	result := func(conflict, status, id string) *domain.RestoreEstatesResponse {
+		resp := &domain.RestoreEstatesResponse{
+			Conflict: conflict,
+			Total:    1,
+			Estates:  []domain.RestoreResult{{SourceUuid: source, Uuid: id, Status: status, Trees: 1}},
+		}
+		switch status {
+		case domain.RestoreStatusCreated:
+			resp.Created = 1
+		case domain.RestoreStatusRemapped:
+			resp.Remapped = 1
+		case domain.RestoreStatusUnchanged:
+			resp.Unchanged = 1
+		case domain.RestoreStatusSkipped:
+			resp.Skipped = 1
+		}
+		return resp
+	}
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		conflict     string
+		organisation *domain.Organisation
+		estates      estateSlice
+		wantResult *domain.RestoreEstatesResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success created",
+			estates:    estateSlice{archived},
+			wantResult: result(domain.RestoreConflictFail, domain.RestoreStatusCreated, source),
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), source).Return(nil, nil)
+				inTransaction()
+				estateRepoMock.EXPECT().RestoreEstate(gomock.Any(), &domain.ExportEstate{
+					Uuid: source, Length: 6, Width: 3, CreatedAt: createdAt, ExternalRef: "ERP-1", Trees: trees,
+				}).Return(nil)
+				palmTreeLocationRepoMock.EXPECT().RestorePalmTrees(gomock.Any(), source, trees).
+					DoAndReturn(func(_ context.Context, _ string, trees []domain.ExportPalmTree) error {
+						trees[0].Id = 12
+						return nil
+					})
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, domain.AuditRestoreEstate, entry.Action)
+						assert.Equal(t, source, entry.EstateId)
+						assert.Equal(t, []int64{12}, entry.TreeIds)
+						return nil
+					})
+			},
+		},
+		{
+			name:       "success created with roles",
+			estates:    estateSlice{withRoles},
+			wantResult: result(domain.RestoreConflictFail, domain.RestoreStatusCreated, source),
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), source).Return(nil, nil)
+				inTransaction()
+				estateRepoMock.EXPECT().RestoreEstate(gomock.Any(), gomock.Any()).Return(nil)
+				palmTreeLocationRepoMock.EXPECT().RestorePalmTrees(gomock.Any(), source, trees).Return(nil)
+				permissionRepoMock.EXPECT().UpsertEstatePermission(gomock.Any(), &role).Return(true, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), auditEntry("root", domain.AuditRestoreEstate, source)).Return(nil)
+			},
+		},
+		{
+			name:       "success remapped with roles",
+			conflict:   domain.RestoreConflictRemap,
+			estates:    estateSlice{withRoles},
+			wantResult: result(domain.RestoreConflictRemap, domain.RestoreStatusRemapped, remapped),
+			mock: func() {
+				existing(source, different)
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), remapped).Return(nil, nil)
+				inTransaction()
+				estateRepoMock.EXPECT().RestoreEstate(gomock.Any(), gomock.Any()).Return(nil)
+				palmTreeLocationRepoMock.EXPECT().RestorePalmTrees(gomock.Any(), remapped, trees).Return(nil)
+				permissionRepoMock.EXPECT().UpsertEstatePermission(gomock.Any(), &remappedRole).Return(true, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), auditEntry("root", domain.AuditRestoreEstate, remapped)).Return(nil)
+			},
+		},
+		{
+			name:    "error restore roles",
+			estates: estateSlice{withRoles},
+			wantErr: someErr,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), source).Return(nil, nil)
+				inTransaction()
+				estateRepoMock.EXPECT().RestoreEstate(gomock.Any(), gomock.Any()).Return(nil)
+				palmTreeLocationRepoMock.EXPECT().RestorePalmTrees(gomock.Any(), source, trees).Return(nil)
+				permissionRepoMock.EXPECT().UpsertEstatePermission(gomock.Any(), &role).Return(false, someErr)
+			},
+		},
+		{
+			name:         "success organisation settings restored",
+			ctx:          acmeCtx,
+			organisation: &domain.Organisation{Id: "old-acme", Name: "Old", Timezone: "Asia/Makassar", SizePolicy: domain.SizePolicy{MaxLength: 200}},
+			wantResult:   &domain.RestoreEstatesResponse{Conflict: domain.RestoreConflictFail, Estates: []domain.RestoreResult{}},
+			mock: func() {
+				inTransaction()
+				organisationRepoMock.EXPECT().UpsertOrganisation(gomock.Any(), &domain.Organisation{
+					Id: "acme", Name: "Acme", Timezone: "Asia/Makassar", SizePolicy: domain.SizePolicy{MaxLength: 200}, CreatedAt: createdAt,
+				}).Return(false, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, domain.AuditPutOrganisation, entry.Action)
+						assert.Equal(t, "acme", domain.TenantId(ctx))
+						return nil
+					})
+			},
+		},
+		{
+			name:         "success organisation settings unchanged",
+			ctx:          acmeCtx,
+			organisation: &domain.Organisation{Id: "acme", Name: "Acme"},
+			wantResult:   &domain.RestoreEstatesResponse{Conflict: domain.RestoreConflictFail, Estates: []domain.RestoreResult{}},
+			mock:         func() {},
+		},
+		{
+			name:         "error estate above the archived size policy",
+			ctx:          acmeCtx,
+			organisation: &domain.Organisation{Id: "acme", SizePolicy: domain.SizePolicy{MaxLength: 4}},
+			estates:      estateSlice{archived},
+			wantErr:      domain.ErrInvalidInput,
+			mock: func() {
+				inTransaction()
+				organisationRepoMock.EXPECT().UpsertOrganisation(gomock.Any(), gomock.Any()).Return(false, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:         "error archived timezone",
+			ctx:          acmeCtx,
+			organisation: &domain.Organisation{Id: "acme", Timezone: "Mars/Olympus"},
+			wantErr:      domain.ErrInvalidInput,
+			mock:         func() {},
+		},
+		{
+			name:         "error restore organisation",
+			ctx:          acmeCtx,
+			organisation: &domain.Organisation{Id: "acme", Timezone: "Asia/Makassar"},
+			wantErr:      someErr,
+			mock: func() {
+				inTransaction()
+				organisationRepoMock.EXPECT().UpsertOrganisation(gomock.Any(), gomock.Any()).Return(false, someErr)
+			},
+		},
+		{
+			name: "error unknown role",
+			estates: estateSlice{{Uuid: source, Length: 6, Width: 3, Trees: trees, Permissions: []domain.EstatePermission{
+				{Subject: "jwt:alice", Role: "owner"},
+			}}},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name: "error unqualified role subject",
+			estates: estateSlice{{Uuid: source, Length: 6, Width: 3, Trees: trees, Permissions: []domain.EstatePermission{
+				{Subject: "alice", Role: domain.RoleViewer},
+			}}},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:       "success unchanged",
+			estates:    estateSlice{archived},
+			wantResult: result(domain.RestoreConflictFail, domain.RestoreStatusUnchanged, source),
+			mock: func() {
+				existing(source, same)
+			},
+		},
+		{
+			name:       "success skipped",
+			conflict:   domain.RestoreConflictSkip,
+			estates:    estateSlice{archived},
+			wantResult: result(domain.RestoreConflictSkip, domain.RestoreStatusSkipped, source),
+			mock: func() {
+				existing(source, different)
+			},
+		},
+		{
+			name:       "success remapped",
+			conflict:   domain.RestoreConflictRemap,
+			estates:    estateSlice{archived},
+			wantResult: result(domain.RestoreConflictRemap, domain.RestoreStatusRemapped, remapped),
+			mock: func() {
+				existing(source, different)
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), remapped).Return(nil, nil)
+				inTransaction()
+				estateRepoMock.EXPECT().RestoreEstate(gomock.Any(), &domain.ExportEstate{
+					Uuid: remapped, Length: 6, Width: 3, CreatedAt: createdAt, Trees: trees,
+				}).Return(nil)
+				palmTreeLocationRepoMock.EXPECT().RestorePalmTrees(gomock.Any(), remapped, trees).Return(nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), auditEntry("root", domain.AuditRestoreEstate, remapped)).Return(nil)
+			},
+		},
+		{
+			name:       "success remapped from other organisation",
+			conflict:   domain.RestoreConflictRemap,
+			estates:    estateSlice{archived},
+			wantResult: result(domain.RestoreConflictRemap, domain.RestoreStatusRemapped, remapped),
+			mock: func() {
+				idTaken()
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), remapped).Return(nil, nil)
+				inTransaction()
+				estateRepoMock.EXPECT().RestoreEstate(gomock.Any(), &domain.ExportEstate{
+					Uuid: remapped, Length: 6, Width: 3, CreatedAt: createdAt, Trees: trees,
+				}).Return(nil)
+				palmTreeLocationRepoMock.EXPECT().RestorePalmTrees(gomock.Any(), remapped, trees).Return(nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), auditEntry("root", domain.AuditRestoreEstate, remapped)).Return(nil)
+			},
+		},
+		{
+			name:       "success skipped other organisation",
+			conflict:   domain.RestoreConflictSkip,
+			estates:    estateSlice{archived},
+			wantResult: result(domain.RestoreConflictSkip, domain.RestoreStatusSkipped, source),
+			mock: func() {
+				idTaken()
+			},
+		},
+		{
+			name:     "error remapped taken by other organisation",
+			conflict: domain.RestoreConflictRemap,
+			estates:  estateSlice{archived},
+			wantErr:  domain.ErrRestoreConflict,
+			mock: func() {
+				idTaken()
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), remapped).Return(nil, nil)
+				inTransaction()
+				estateRepoMock.EXPECT().RestoreEstate(gomock.Any(), gomock.Any()).Return(domain.ErrEstateIdTaken)
+			},
+		},
+		{
+			name:    "error conflict other organisation",
+			estates: estateSlice{archived},
+			wantErr: domain.ErrRestoreConflict,
+			mock: func() {
+				idTaken()
+			},
+		},
+		{
+			name:       "success remapped before",
+			conflict:   domain.RestoreConflictRemap,
+			estates:    estateSlice{archived},
+			wantResult: result(domain.RestoreConflictRemap, domain.RestoreStatusUnchanged, remapped),
+			mock: func() {
+				existing(source, different)
+				existing(remapped, same)
+			},
+		},
+		{
+			name:    "error conflict",
+			estates: estateSlice{archived},
+			wantErr: domain.ErrRestoreConflict,
+			mock: func() {
+				existing(source, different)
+			},
+		},
+		{
+			name:    "error invalid archive",
+			estates: estateSlice{{Uuid: source, Length: 1, Width: 1, Trees: trees}},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:    "error malformed uuid",
+			estates: estateSlice{{Uuid: strings.Repeat("a", 40), Length: 6, Width: 3, Trees: trees}},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:    "error external reference too long",
+			estates: estateSlice{{Uuid: source, Length: 6, Width: 3, ExternalRef: strings.Repeat("a", 65), Trees: trees}},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:    "error estate above the tenant size policy",
+			ctx:     domain.WithTenant(ctx, &domain.Organisation{Id: "org", SizePolicy: domain.SizePolicy{MaxLength: 4}}),
+			estates: estateSlice{archived},
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:     "error unknown conflict mode",
+			conflict: "overwrite",
+			wantErr:  domain.ErrInvalidInput,
+			mock:     func() {},
+		},
+		{
+			name:    "error canceled",
+			ctx:     canceledCtx,
+			estates: estateSlice{archived},
+			wantErr: context.Canceled,
+			mock:    func() {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			ctx := ctx
+			if test.ctx != nil {
+				ctx = test.ctx
+			}
+			got, err := uc.RestoreEstates(ctx, &domain.RestoreEstatesRequest{
+				Conflict:     test.conflict,
+				Organisation: test.organisation,
+				Estates:      &test.estates,
+			})
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
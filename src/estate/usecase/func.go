@@ -1,7 +1,13 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/davidyunus/sawitpro-estate/src/domain"
 	"github.com/google/uuid"
@@ -20,6 +26,10 @@ func calculateMedian(arr []int) float64 {
 	}
 }
 
+// checkEvery is how many iterations the long loops run between checks of
+// their context, so giving up on a cancelled request costs next to nothing.
+const checkEvery = 1 << 10
+
 func abs(x int) int {
 	if x < 0 {
 		return -x
@@ -27,9 +37,15 @@ func abs(x int) int {
 	return x
 }
 
-func generateCoordinates(length, width int) []domain.PalmTree {
-	var coordinates []domain.PalmTree
+// generateCoordinates lays out the plots in the order the drone visits
+// them. Large estates take long to lay out, so it checks ctx once a row and
+// gives up with the context error once ctx is done.
+func generateCoordinates(ctx context.Context, length, width int) ([]domain.PalmTree, error) {
+	coordinates := make([]domain.PalmTree, 0, length*width)
 	for y := 1; y <= width; y++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		if y%2 != 0 {
 			for x := 1; x <= length; x++ {
 				coordinates = append(coordinates, domain.PalmTree{X: x, Y: y, Height: 0})
@@ -40,11 +56,16 @@ func generateCoordinates(length, width int) []domain.PalmTree {
 			}
 		}
 	}
-	return coordinates
+	return coordinates, nil
 }
 
-func mergePalmTrees(coordinates []domain.PalmTree, palmTrees []domain.PalmTree) []domain.PalmTree {
+// mergePalmTrees scans every tree for every plot, which takes long on large
+// estates, so it gives up with the context error once ctx is done.
+func mergePalmTrees(ctx context.Context, coordinates []domain.PalmTree, palmTrees []domain.PalmTree) ([]domain.PalmTree, error) {
 	for i := range coordinates {
+		if i%checkEvery == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		for _, palmTree := range palmTrees {
 			if coordinates[i].X == palmTree.X && coordinates[i].Y == palmTree.Y {
 				coordinates[i].Height = palmTree.Height
@@ -52,7 +73,7 @@ func mergePalmTrees(coordinates []domain.PalmTree, palmTrees []domain.PalmTree)
 			}
 		}
 	}
-	return coordinates
+	return coordinates, nil
 }
 
 func removeTrailingZeroHeightCoordinates(coordinates []domain.PalmTree) []domain.PalmTree {
@@ -68,3 +89,228 @@ func removeTrailingZeroHeightCoordinates(coordinates []domain.PalmTree) []domain
 var generateUUID = func() string {
 	return uuid.NewString()
 }
+
+// restoreNamespace seeds the UUIDs estates are remapped to on restore.
+var restoreNamespace = uuid.MustParse("5b0c7e5e-8a57-4c1e-9a3f-3f6c2f1d9e40")
+
+// remapUUID derives the UUID an archived estate is restored under when its
+// own is taken. It is deterministic so that repeating a restore finds the
+// estate it remapped before.
+var remapUUID = func(id string) string {
+	return uuid.NewSHA1(restoreNamespace, []byte(id)).String()
+}
+
+// validateArchivedEstate rejects archive entries that could never have been
+// produced by this service: the i-th estate must have a canonical UUID,
+// dimensions within policy and trees of valid height on distinct plots
+// inside it. Restoring is held to the limits PutEstate enforces.
+func validateArchivedEstate(i int, estate *domain.ExportEstate, policy domain.SizePolicy) error {
+	invalid := func(message string) error {
+		return domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+			Field:   fmt.Sprintf("estates[%d]", i),
+			Rule:    "archive",
+			Message: message,
+		}})
+	}
+
+	if estate.Uuid == "" {
+		return invalid("uuid is required")
+	}
+	parsed, err := uuid.Parse(estate.Uuid)
+	if err != nil || parsed.String() != estate.Uuid {
+		return invalid("uuid must be a lowercase hyphenated UUID")
+	}
+	if len(estate.ExternalRef) > 64 {
+		return invalid("externalRef must be at most 64 characters")
+	}
+	if estate.Length < 1 || estate.Width < 1 {
+		return invalid("length and width must be greater than 0")
+	}
+
+	archived := &domain.Estate{Length: estate.Length, Width: estate.Width}
+	err = policy.Check(archived)
+	var limit *domain.SizeLimitError
+	if errors.As(err, &limit) {
+		return invalid(fmt.Sprintf("estate %s %d exceeds maximum %d", limit.Limit, limit.Actual, limit.Max))
+	}
+	if err != nil {
+		return err
+	}
+	type plot struct{ x, y int }
+	filled := make(map[plot]bool, len(estate.Trees))
+	for _, tree := range estate.Trees {
+		switch {
+		case !archived.Contains(tree.X, tree.Y):
+			return invalid(fmt.Sprintf("tree (%d,%d) is outside the estate", tree.X, tree.Y))
+		case tree.Height < 1 || tree.Height > 30:
+			return invalid(fmt.Sprintf("tree (%d,%d) height must be between 1 and 30", tree.X, tree.Y))
+		case filled[plot{tree.X, tree.Y}]:
+			return invalid(fmt.Sprintf("tree (%d,%d) appears more than once", tree.X, tree.Y))
+		}
+		filled[plot{tree.X, tree.Y}] = true
+	}
+
+	granted := make(map[string]bool, len(estate.Permissions))
+	for _, permission := range estate.Permissions {
+		switch {
+		case !domain.ValidRoleSubject(permission.Subject):
+			return invalid(fmt.Sprintf("role subject %q must be api_key:<key id> or jwt:<token sub>", permission.Subject))
+		case !domain.ValidRole(permission.Role):
+			return invalid(fmt.Sprintf("role %q of %s is unknown", permission.Role, permission.Subject))
+		case granted[permission.Subject]:
+			return invalid(fmt.Sprintf("role subject %s appears more than once", permission.Subject))
+		}
+		granted[permission.Subject] = true
+	}
+	return nil
+}
+
+// validateArchivedOrganisation checks the organisation settings of an
+// archive header before they are applied.
+func validateArchivedOrganisation(organisation *domain.Organisation) error {
+	invalid := func(message string) error {
+		return domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+			Field:   "organisation",
+			Rule:    "archive",
+			Message: message,
+		}})
+	}
+
+	if organisation.Timezone != "" {
+		_, err := time.LoadLocation(organisation.Timezone)
+		if err != nil {
+			return invalid(fmt.Sprintf("timezone %q is invalid", organisation.Timezone))
+		}
+	}
+	policy := organisation.SizePolicy
+	if policy.MaxArea < 0 || policy.MaxLength < 0 || policy.MaxWidth < 0 || policy.PlotSize < 0 {
+		return invalid("sizePolicy limits must not be negative")
+	}
+	return nil
+}
+
+// validateImportRows checks the rows of an import against the estate and
+// the trees already planted on it, recording every rejected row in result.
+// It returns the trees of the valid rows.
+func validateImportRows(ctx context.Context, estate *domain.Estate, existing []domain.PalmTree, trees []domain.PalmTree, result *domain.ImportPalmTreesResponse) ([]domain.PalmTree, error) {
+	// filled maps a plot to the import row planting it, 0 for existing trees.
+	type plot struct{ x, y int }
+	filled := make(map[plot]int, len(existing)+len(trees))
+	for _, tree := range existing {
+		filled[plot{tree.X, tree.Y}] = 0
+	}
+
+	valid := make([]domain.PalmTree, 0, len(trees))
+	for i, tree := range trees {
+		if i%checkEvery == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		row := i + 1
+		rowErr := domain.ImportRowError{Row: row, X: tree.X, Y: tree.Y}
+		location := plot{tree.X, tree.Y}
+
+		filledBy, isFilled := filled[location]
+		switch {
+		case tree.Height < 1 || tree.Height > 30:
+			rowErr.Code = domain.ErrInvalidInput.Code
+			rowErr.Message = "height must be between 1 and 30"
+		case !estate.Contains(tree.X, tree.Y):
+			rowErr.Code = domain.ErrOutOfBounds.Code
+			rowErr.Message = domain.ErrOutOfBounds.Message
+		case isFilled && filledBy == 0:
+			rowErr.Code = domain.ErrLocationFilled.Code
+			rowErr.Message = domain.ErrLocationFilled.Message
+		case isFilled:
+			rowErr.Code = domain.ErrLocationFilled.Code
+			rowErr.Message = fmt.Sprintf("location already filled by row %d", filledBy)
+		default:
+			filled[location] = row
+			valid = append(valid, domain.PalmTree{X: tree.X, Y: tree.Y, Height: tree.Height})
+			continue
+		}
+		result.Errors = append(result.Errors, rowErr)
+	}
+	result.Rejected = len(result.Errors)
+	return valid, nil
+}
+
+// validateListPalmTrees checks a tree listing and returns its sort key, the
+// id the page starts after and the page size, with defaults filled in.
+func validateListPalmTrees(param *domain.ListPalmTreesRequest) (string, int64, int, error) {
+	details := []domain.FieldError{}
+
+	sortKey := param.Sort
+	if sortKey == "" {
+		sortKey = domain.PalmTreeSortKeys[0]
+	}
+	valid := false
+	for _, key := range domain.PalmTreeSortKeys {
+		if sortKey == key || sortKey == "-"+key {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		details = append(details, domain.FieldError{
+			Field:   "sort",
+			Rule:    "oneof",
+			Param:   strings.Join(domain.PalmTreeSortKeys, " "),
+			Message: "sort must be one of: " + strings.Join(domain.PalmTreeSortKeys, ", ") + ", optionally prefixed with -",
+		})
+	}
+
+	var after int64
+	if param.Cursor != "" {
+		var err error
+		after, err = strconv.ParseInt(param.Cursor, 10, 64)
+		if err != nil || after < 1 {
+			details = append(details, domain.InvalidCursor)
+		}
+	}
+
+	limit := param.Limit
+	if limit == 0 {
+		limit = domain.DefaultPalmTreePageSize
+	}
+	if limit < 1 || limit > domain.MaxPalmTreePageSize {
+		details = append(details, domain.FieldError{
+			Field:   "limit",
+			Rule:    "max",
+			Param:   strconv.Itoa(domain.MaxPalmTreePageSize),
+			Message: fmt.Sprintf("limit must be between 1 and %d", domain.MaxPalmTreePageSize),
+		})
+	}
+
+	filter := param.Filter
+	if filter.MinHeight != nil && filter.MaxHeight != nil && *filter.MinHeight > *filter.MaxHeight {
+		details = append(details, rangeError("maxHeight", "minHeight"))
+	}
+	if filter.MinX != nil && filter.MaxX != nil && *filter.MinX > *filter.MaxX {
+		details = append(details, rangeError("maxX", "minX"))
+	}
+	if filter.MinY != nil && filter.MaxY != nil && *filter.MinY > *filter.MaxY {
+		details = append(details, rangeError("maxY", "minY"))
+	}
+	if filter.PlantedAfter != nil && filter.PlantedBefore != nil && !filter.PlantedAfter.Before(*filter.PlantedBefore) {
+		details = append(details, domain.FieldError{
+			Field:   "plantedBefore",
+			Rule:    "gtfield",
+			Param:   "plantedAfter",
+			Message: "plantedBefore must be after plantedAfter",
+		})
+	}
+
+	if len(details) > 0 {
+		return "", 0, 0, domain.ErrInvalidInput.WithDetails(details)
+	}
+	return sortKey, after, limit, nil
+}
+
+func rangeError(field, min string) domain.FieldError {
+	return domain.FieldError{
+		Field:   field,
+		Rule:    "gtefield",
+		Param:   min,
+		Message: field + " must be greater than or equal to " + min,
+	}
+}
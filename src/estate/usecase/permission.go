@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+)
+
+// authorize checks that the caller holds a role on the estate granting
+// permission; administrators hold every permission. It runs before the
+// estate is looked up, so a caller without a role cannot tell a missing
+// estate from someone else's.
+func (e *estateUsecase) authorize(ctx context.Context, id, permission string) error {
+	principal := domain.PrincipalFromContext(ctx)
+	if principal == nil {
+		return domain.ErrUnauthorized
+	}
+	if principal.Admin {
+		return nil
+	}
+
+	role, err := e.permissionRepo.GetEstateRole(ctx, id, principal.RoleSubject())
+	if err != nil {
+		return err
+	}
+	if !domain.RoleAllows(role, permission) {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+func requireAdmin(ctx context.Context) error {
+	principal := domain.PrincipalFromContext(ctx)
+	if principal == nil {
+		return domain.ErrUnauthorized
+	}
+	if !principal.Admin {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// audit appends an audit entry for a change made within the transaction
+// ctx carries, so the entry is kept exactly when the change is.
+func (e *estateUsecase) audit(ctx context.Context, action, id string, treeIds []int64, before, after interface{}) error {
+	entry, err := domain.NewAuditEntry(ctx, action, id, before, after)
+	if err != nil {
+		return err
+	}
+	entry.TreeIds = treeIds
+	return e.auditRepo.AppendAuditEntry(ctx, entry)
+}
+
+func validateRoleSubject(subject string) error {
+	if domain.ValidRoleSubject(subject) {
+		return nil
+	}
+	return domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+		Field:   "subject",
+		Rule:    "subject",
+		Message: "subject must be api_key:<key id> or jwt:<token sub>",
+	}})
+}
+
+// auditedRole is how a role on an estate is recorded in the audit log.
+type auditedRole struct {
+	Subject string `json:"subject"`
+	Role    string `json:"role"`
+}
+
+// grantCreator makes the principal that created an estate its admin.
+// Administrators already hold every permission and get no grant.
+func (e *estateUsecase) grantCreator(ctx context.Context, id string, principal *domain.Principal) error {
+	if principal.Admin {
+		return nil
+	}
+	_, err := e.permissionRepo.UpsertEstatePermission(ctx, &domain.EstatePermission{
+		EstateId:  id,
+		Subject:   principal.RoleSubject(),
+		Role:      domain.RoleAdmin,
+		GrantedBy: principal.RoleSubject(),
+		GrantedAt: helper.Now(),
+	})
+	return err
+}
+
+func (e *estateUsecase) ListEstatePermissions(ctx context.Context, id string) (*domain.ListEstatePermissionsResponse, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.ListEstatePermissions", tracing.EstateId(id))
+	defer span.End()
+
+	err := e.authorize(ctx, id, domain.PermissionManageEstate)
+	if err != nil {
+		return nil, err
+	}
+
+	estate, err := e.estateRepo.GetEstateByUuid(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if estate == nil {
+		return nil, domain.ErrEstateNotFound
+	}
+
+	permissions, err := e.permissionRepo.ListEstatePermissions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ListEstatePermissionsResponse{
+		Permissions: permissions,
+	}, nil
+}
+
+// GrantEstatePermission gives subject the role on the estate, replacing
+// the role it held before.
+func (e *estateUsecase) GrantEstatePermission(ctx context.Context, id, subject string, param *domain.GrantEstatePermissionRequest) (*domain.GrantEstatePermissionResponse, error) {
+	ctx, span := tracing.Start(ctx, "estateUsecase.GrantEstatePermission", tracing.EstateId(id))
+	defer span.End()
+
+	err := e.authorize(ctx, id, domain.PermissionManageEstate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateRoleSubject(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	estate, err := e.estateRepo.GetEstateByUuid(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if estate == nil {
+		return nil, domain.ErrEstateNotFound
+	}
+
+	permission := domain.EstatePermission{
+		EstateId:  id,
+		Subject:   subject,
+		Role:      param.Role,
+		GrantedBy: domain.PrincipalFromContext(ctx).RoleSubject(),
+		GrantedAt: helper.Now(),
+	}
+	var created bool
+	err = e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		role, err := e.permissionRepo.GetEstateRole(ctx, id, subject)
+		if err != nil {
+			return err
+		}
+		created, err = e.permissionRepo.UpsertEstatePermission(ctx, &permission)
+		if err != nil {
+			return err
+		}
+
+		var before interface{}
+		if role != "" {
+			before = auditedRole{Subject: subject, Role: role}
+		}
+		return e.audit(ctx, domain.AuditGrantPermission, id, nil, before, auditedRole{Subject: subject, Role: param.Role})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.GrantEstatePermissionResponse{
+		EstatePermission: permission,
+		Created:          created,
+	}, nil
+}
+
+func (e *estateUsecase) RevokeEstatePermission(ctx context.Context, id, subject string) error {
+	ctx, span := tracing.Start(ctx, "estateUsecase.RevokeEstatePermission", tracing.EstateId(id))
+	defer span.End()
+
+	err := e.authorize(ctx, id, domain.PermissionManageEstate)
+	if err != nil {
+		return err
+	}
+
+	err = validateRoleSubject(subject)
+	if err != nil {
+		return err
+	}
+
+	// Roles are not scoped to an organisation, so the estate is looked up
+	// in the caller's to keep other organisations' estates out of reach.
+	estate, err := e.estateRepo.GetEstateByUuid(ctx, id)
+	if err != nil {
+		return err
+	}
+	if estate == nil {
+		return domain.ErrEstateNotFound
+	}
+
+	return e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		role, err := e.permissionRepo.GetEstateRole(ctx, id, subject)
+		if err != nil {
+			return err
+		}
+		deleted, err := e.permissionRepo.DeleteEstatePermission(ctx, id, subject)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return domain.ErrPermissionNotFound
+		}
+		return e.audit(ctx, domain.AuditRevokePermission, id, nil, auditedRole{Subject: subject, Role: role}, nil)
+	})
+}
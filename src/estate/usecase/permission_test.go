@@ -0,0 +1,656 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// adminCtx carries an administrator, who passes every permission check.
+var adminCtx = domain.WithPrincipal(context.Background(), &domain.Principal{
+	Subject: "root",
+	Method:  domain.AuthMethodJwt,
+	Admin:   true,
+})
+
+func principalCtx(subject string) context.Context {
+	return domain.WithPrincipal(context.Background(), &domain.Principal{
+		Subject: subject,
+		Method:  domain.AuthMethodApiKey,
+	})
+}
+
+// auditEntry matches the audit entry of actor's action on the estate id.
+func auditEntry(actor, action, id string) gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		entry, ok := x.(*domain.AuditEntry)
+		return ok && entry.Actor == actor && entry.Action == action && entry.EstateId == id
+	})
+}
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{role: domain.RoleViewer, permission: domain.PermissionReadEstate, want: true},
+		{role: domain.RoleViewer, permission: domain.PermissionPlantTrees, want: false},
+		{role: domain.RoleViewer, permission: domain.PermissionReadDronePlan, want: false},
+		{role: domain.RoleSurveyor, permission: domain.PermissionPlantTrees, want: true},
+		{role: domain.RoleSurveyor, permission: domain.PermissionReadDronePlan, want: false},
+		{role: domain.RoleOperator, permission: domain.PermissionReadDronePlan, want: true},
+		{role: domain.RoleOperator, permission: domain.PermissionPlantTrees, want: false},
+		{role: domain.RoleOperator, permission: domain.PermissionManageEstate, want: false},
+		{role: domain.RoleAdmin, permission: domain.PermissionManageEstate, want: true},
+		{role: "", permission: domain.PermissionReadEstate, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.role+" "+test.permission, func(t *testing.T) {
+			assert.Equal(t, test.want, domain.RoleAllows(test.role, test.permission))
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	permissionRepoMock := mock_domain.NewMockEstatePermissionRepository(ctrl)
+	uc := &estateUsecase{
+		permissionRepo: permissionRepoMock,
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "success admin",
+			ctx:  adminCtx,
+			mock: func() {},
+		},
+		{
+			name: "success role",
+			ctx:  principalCtx("alice"),
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "api_key:alice").Return(domain.RoleSurveyor, nil)
+			},
+		},
+		{
+			name:    "error role lacks permission",
+			ctx:     principalCtx("alice"),
+			wantErr: domain.ErrForbidden,
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "api_key:alice").Return(domain.RoleViewer, nil)
+			},
+		},
+		{
+			name:    "error no role",
+			ctx:     principalCtx("alice"),
+			wantErr: domain.ErrForbidden,
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "api_key:alice").Return("", nil)
+			},
+		},
+		{
+			name:    "error unauthenticated",
+			ctx:     context.Background(),
+			wantErr: domain.ErrUnauthorized,
+			mock:    func() {},
+		},
+		{
+			name:    "error repository",
+			ctx:     principalCtx("alice"),
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "api_key:alice").Return("", errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := uc.authorize(test.ctx, common.UtUuid, domain.PermissionPlantTrees)
+			assert.Equal(t, test.wantErr, err)
+		})
+	}
+}
+
+func TestEstateAccessControl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time {
+		return now
+	}
+	defer func() {
+		helper.Now = tempNow
+	}()
+
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	palmTreeLocationRepoMock := mock_domain.NewMockPalmTreeLocationRepository(ctrl)
+	permissionRepoMock := mock_domain.NewMockEstatePermissionRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+
+	uc := &estateUsecase{
+		estateRepo:           estateRepoMock,
+		palmTreeLocationRepo: palmTreeLocationRepoMock,
+		permissionRepo:       permissionRepoMock,
+		auditRepo:            auditRepoMock,
+		transactor:           transactorMock,
+		sizePolicy:           domain.DefaultSizePolicy(),
+	}
+
+	tempGenerateUUID := generateUUID
+	generateUUID = func() string {
+		return common.UtUuidV4
+	}
+	defer func() {
+		generateUUID = tempGenerateUUID
+	}()
+
+	ctx := principalCtx("alice")
+	estate := &domain.Estate{Uuid: common.UtUuidV4, Length: 5, Width: 5}
+	creatorGrant := &domain.EstatePermission{
+		EstateId:  common.UtUuidV4,
+		Subject:   "api_key:alice",
+		Role:      domain.RoleAdmin,
+		GrantedBy: "api_key:alice",
+		GrantedAt: now,
+	}
+	outOfBounds := []domain.OutOfBoundsPalmTree{
+		{PalmTree: domain.PalmTree{Id: 1, Uuid: common.UtUuidV4, X: 7, Y: 1, Height: 10}, EstateLength: 5, EstateWidth: 5},
+		{PalmTree: domain.PalmTree{Id: 2, Uuid: common.UtUuid, X: 7, Y: 1, Height: 10}, EstateLength: 5, EstateWidth: 5},
+	}
+
+	tests := []struct {
+		name       string
+		call       func() (interface{}, error)
+		wantResult interface{}
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name: "create estate grants the creator admin",
+			call: func() (interface{}, error) {
+				return uc.CreateEstate(ctx, &domain.Estate{Length: 5, Width: 5})
+			},
+			wantResult: &domain.CreateEstateResponse{Id: common.UtUuidV4},
+			mock: func() {
+				estateRepoMock.EXPECT().CreateEstate(gomock.Any(), estate).Return(nil)
+				permissionRepoMock.EXPECT().UpsertEstatePermission(gomock.Any(), creatorGrant).Return(true, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), auditEntry("alice", domain.AuditCreateEstate, common.UtUuidV4)).Return(nil)
+			},
+		},
+		{
+			name: "create estate fails with the grant",
+			call: func() (interface{}, error) {
+				return uc.CreateEstate(ctx, &domain.Estate{Length: 5, Width: 5})
+			},
+			wantResult: (*domain.CreateEstateResponse)(nil),
+			wantErr:    errors.New(common.UtSomeError),
+			mock: func() {
+				estateRepoMock.EXPECT().CreateEstate(gomock.Any(), estate).Return(nil)
+				permissionRepoMock.EXPECT().UpsertEstatePermission(gomock.Any(), creatorGrant).Return(false, errors.New(common.UtSomeError))
+			},
+		},
+		{
+			name: "create estate unauthenticated",
+			call: func() (interface{}, error) {
+				return uc.CreateEstate(context.Background(), &domain.Estate{Length: 5, Width: 5})
+			},
+			wantResult: (*domain.CreateEstateResponse)(nil),
+			wantErr:    domain.ErrUnauthorized,
+			mock:       func() {},
+		},
+		{
+			name: "put new estate grants the creator admin",
+			call: func() (interface{}, error) {
+				return uc.PutEstate(ctx, common.UtUuidV4, &domain.Estate{Length: 5, Width: 5})
+			},
+			wantResult: &domain.PutEstateResponse{Id: common.UtUuidV4, Created: true},
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuidV4).Return(nil, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuidV4).Return([]domain.PalmTree{}, nil)
+				estateRepoMock.EXPECT().UpsertEstate(gomock.Any(), estate).Return(true, nil)
+				permissionRepoMock.EXPECT().UpsertEstatePermission(gomock.Any(), creatorGrant).Return(true, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), auditEntry("alice", domain.AuditCreateEstate, common.UtUuidV4)).Return(nil)
+			},
+		},
+		{
+			name: "put existing estate as its admin",
+			call: func() (interface{}, error) {
+				return uc.PutEstate(ctx, common.UtUuidV4, &domain.Estate{Length: 5, Width: 5})
+			},
+			wantResult: &domain.PutEstateResponse{Id: common.UtUuidV4, Created: false},
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuidV4).Return(estate, nil)
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuidV4, "api_key:alice").Return(domain.RoleAdmin, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuidV4).Return([]domain.PalmTree{}, nil)
+				estateRepoMock.EXPECT().UpsertEstate(gomock.Any(), estate).Return(false, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), auditEntry("alice", domain.AuditReplaceEstate, common.UtUuidV4)).Return(nil)
+			},
+		},
+		{
+			name: "put existing estate without manage permission",
+			call: func() (interface{}, error) {
+				return uc.PutEstate(ctx, common.UtUuidV4, &domain.Estate{Length: 5, Width: 5})
+			},
+			wantResult: (*domain.PutEstateResponse)(nil),
+			wantErr:    domain.ErrForbidden,
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuidV4).Return(estate, nil)
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuidV4, "api_key:alice").Return(domain.RoleSurveyor, nil)
+			},
+		},
+		{
+			name: "put estate created concurrently by someone else",
+			call: func() (interface{}, error) {
+				return uc.PutEstate(ctx, common.UtUuidV4, &domain.Estate{Length: 5, Width: 5})
+			},
+			wantResult: (*domain.PutEstateResponse)(nil),
+			wantErr:    domain.ErrForbidden,
+			mock: func() {
+				estateRepoMock.EXPECT().LockEstate(gomock.Any(), common.UtUuidV4).Return(nil, nil)
+				palmTreeLocationRepoMock.EXPECT().GetPalmTreesByUuid(gomock.Any(), common.UtUuidV4).Return([]domain.PalmTree{}, nil)
+				estateRepoMock.EXPECT().UpsertEstate(gomock.Any(), estate).Return(false, nil)
+			},
+		},
+		{
+			name: "external ref of an unreadable estate is not found",
+			call: func() (interface{}, error) {
+				return uc.GetEstateByExternalRef(ctx, "ERP-1")
+			},
+			wantResult: (*domain.Estate)(nil),
+			wantErr:    domain.ErrEstateNotFound,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByExternalRef(gomock.Any(), "ERP-1").Return(estate, nil)
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuidV4, "api_key:alice").Return("", nil)
+			},
+		},
+		{
+			name: "viewer cannot plant",
+			call: func() (interface{}, error) {
+				return uc.PlantPalmTree(ctx, common.UtUuidV4, &domain.PalmTree{X: 1, Y: 1, Height: 10})
+			},
+			wantResult: (*domain.PlantPalmTreeResponse)(nil),
+			wantErr:    domain.ErrForbidden,
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuidV4, "api_key:alice").Return(domain.RoleViewer, nil)
+			},
+		},
+		{
+			name: "surveyor cannot read the drone plan",
+			call: func() (interface{}, error) {
+				return uc.GetDroneFlyingDistance(ctx, common.UtUuidV4, 0)
+			},
+			wantResult: (*domain.GetDroneFlyingDistanceResponse)(nil),
+			wantErr:    domain.ErrForbidden,
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuidV4, "api_key:alice").Return(domain.RoleSurveyor, nil)
+			},
+		},
+		{
+			name: "operator cannot import",
+			call: func() (interface{}, error) {
+				return uc.ImportPalmTrees(ctx, common.UtUuidV4, &domain.ImportPalmTreesRequest{})
+			},
+			wantResult: (*domain.ImportPalmTreesResponse)(nil),
+			wantErr:    domain.ErrForbidden,
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuidV4, "api_key:alice").Return(domain.RoleOperator, nil)
+			},
+		},
+		{
+			name: "no role on the estate",
+			call: func() (interface{}, error) {
+				return uc.GetTreeStats(ctx, common.UtUuidV4)
+			},
+			wantResult: (*domain.GetTreeStatsResponse)(nil),
+			wantErr:    domain.ErrForbidden,
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuidV4, "api_key:alice").Return("", nil)
+			},
+		},
+		{
+			name: "out of bounds trees of readable estates only",
+			call: func() (interface{}, error) {
+				return uc.FindOutOfBoundsPalmTrees(ctx)
+			},
+			wantResult: &domain.FindOutOfBoundsPalmTreesResponse{
+				Count: 1,
+				Trees: outOfBounds[:1],
+			},
+			mock: func() {
+				palmTreeLocationRepoMock.EXPECT().GetOutOfBoundsPalmTrees(gomock.Any()).Return(outOfBounds, nil)
+				permissionRepoMock.EXPECT().ListSubjectPermissions(gomock.Any(), "api_key:alice").Return([]domain.EstatePermission{
+					{EstateId: common.UtUuidV4, Subject: "api_key:alice", Role: domain.RoleViewer},
+				}, nil)
+			},
+		},
+		{
+			name: "restore needs an administrator",
+			call: func() (interface{}, error) {
+				return uc.RestoreEstates(ctx, &domain.RestoreEstatesRequest{})
+			},
+			wantResult: (*domain.RestoreEstatesResponse)(nil),
+			wantErr:    domain.ErrForbidden,
+			mock:       func() {},
+		},
+		{
+			name: "export needs an administrator",
+			call: func() (interface{}, error) {
+				return nil, uc.ExportEstates(ctx, func(domain.ExportEstate) error { return nil })
+			},
+			wantErr: domain.ErrForbidden,
+			mock:    func() {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := test.call()
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestListEstatePermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	permissionRepoMock := mock_domain.NewMockEstatePermissionRepository(ctrl)
+	uc := &estateUsecase{
+		estateRepo:     estateRepoMock,
+		permissionRepo: permissionRepoMock,
+	}
+
+	permissions := []domain.EstatePermission{{EstateId: common.UtUuid, Subject: "api_key:alice", Role: domain.RoleAdmin}}
+	tests := []struct {
+		name       string
+		wantResult *domain.ListEstatePermissionsResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success",
+			wantResult: &domain.ListEstatePermissionsResponse{Permissions: permissions},
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "api_key:alice").Return(domain.RoleAdmin, nil)
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				permissionRepoMock.EXPECT().ListEstatePermissions(gomock.Any(), common.UtUuid).Return(permissions, nil)
+			},
+		},
+		{
+			name:    "error forbidden",
+			wantErr: domain.ErrForbidden,
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "api_key:alice").Return(domain.RoleOperator, nil)
+			},
+		},
+		{
+			name:    "error estate not found",
+			wantErr: domain.ErrEstateNotFound,
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "api_key:alice").Return(domain.RoleAdmin, nil)
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(nil, nil)
+			},
+		},
+		{
+			name:    "error list",
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "api_key:alice").Return(domain.RoleAdmin, nil)
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				permissionRepoMock.EXPECT().ListEstatePermissions(gomock.Any(), common.UtUuid).Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.ListEstatePermissions(principalCtx("alice"), common.UtUuid)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestGrantEstatePermission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time {
+		return now
+	}
+	defer func() {
+		helper.Now = tempNow
+	}()
+
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	permissionRepoMock := mock_domain.NewMockEstatePermissionRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	uc := &estateUsecase{
+		estateRepo:     estateRepoMock,
+		permissionRepo: permissionRepoMock,
+		auditRepo:      auditRepoMock,
+		transactor:     transactorMock,
+	}
+
+	permission := domain.EstatePermission{
+		EstateId:  common.UtUuid,
+		Subject:   "jwt:bob",
+		Role:      domain.RoleSurveyor,
+		GrantedBy: "jwt:root",
+		GrantedAt: now,
+	}
+	tests := []struct {
+		name       string
+		wantResult *domain.GrantEstatePermissionResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success created",
+			wantResult: &domain.GrantEstatePermissionResponse{EstatePermission: permission, Created: true},
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "jwt:bob").Return("", nil)
+				permissionRepoMock.EXPECT().UpsertEstatePermission(gomock.Any(), &permission).Return(true, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, domain.AuditGrantPermission, entry.Action)
+						assert.Nil(t, entry.Before)
+						assert.JSONEq(t, `{"subject":"jwt:bob","role":"surveyor"}`, string(entry.After))
+						return nil
+					})
+			},
+		},
+		{
+			name:       "success replaced",
+			wantResult: &domain.GrantEstatePermissionResponse{EstatePermission: permission, Created: false},
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "jwt:bob").Return(domain.RoleViewer, nil)
+				permissionRepoMock.EXPECT().UpsertEstatePermission(gomock.Any(), &permission).Return(false, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, "root", entry.Actor)
+						assert.Equal(t, common.UtUuid, entry.EstateId)
+						assert.JSONEq(t, `{"subject":"jwt:bob","role":"viewer"}`, string(entry.Before))
+						assert.JSONEq(t, `{"subject":"jwt:bob","role":"surveyor"}`, string(entry.After))
+						return nil
+					})
+			},
+		},
+		{
+			name:    "error estate not found",
+			wantErr: domain.ErrEstateNotFound,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(nil, nil)
+			},
+		},
+		{
+			name:    "error upsert",
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "jwt:bob").Return("", nil)
+				permissionRepoMock.EXPECT().UpsertEstatePermission(gomock.Any(), &permission).Return(false, errors.New(common.UtSomeError))
+			},
+		},
+		{
+			name:    "error audit",
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "jwt:bob").Return("", nil)
+				permissionRepoMock.EXPECT().UpsertEstatePermission(gomock.Any(), &permission).Return(true, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).Return(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.GrantEstatePermission(adminCtx, common.UtUuid, "jwt:bob", &domain.GrantEstatePermissionRequest{Role: domain.RoleSurveyor})
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestRevokeEstatePermission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateRepoMock := mock_domain.NewMockEstateRepository(ctrl)
+	permissionRepoMock := mock_domain.NewMockEstatePermissionRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	uc := &estateUsecase{
+		estateRepo:     estateRepoMock,
+		permissionRepo: permissionRepoMock,
+		auditRepo:      auditRepoMock,
+		transactor:     transactorMock,
+	}
+	errSome := errors.New(common.UtSomeError)
+	found := func() {
+		estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(&domain.Estate{Uuid: common.UtUuid}, nil)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		subject string
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "success",
+			ctx:  adminCtx,
+			mock: func() {
+				found()
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "jwt:bob").Return(domain.RoleViewer, nil)
+				permissionRepoMock.EXPECT().DeleteEstatePermission(gomock.Any(), common.UtUuid, "jwt:bob").Return(true, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, domain.AuditRevokePermission, entry.Action)
+						assert.JSONEq(t, `{"subject":"jwt:bob","role":"viewer"}`, string(entry.Before))
+						assert.Nil(t, entry.After)
+						return nil
+					})
+			},
+		},
+		{
+			name:    "error not found",
+			ctx:     adminCtx,
+			wantErr: domain.ErrPermissionNotFound,
+			mock: func() {
+				found()
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "jwt:bob").Return("", nil)
+				permissionRepoMock.EXPECT().DeleteEstatePermission(gomock.Any(), common.UtUuid, "jwt:bob").Return(false, nil)
+			},
+		},
+		{
+			name:    "error subject without auth method",
+			ctx:     adminCtx,
+			subject: "bob",
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:    "error estate of another organisation",
+			ctx:     adminCtx,
+			wantErr: domain.ErrEstateNotFound,
+			mock: func() {
+				estateRepoMock.EXPECT().GetEstateByUuid(gomock.Any(), common.UtUuid).Return(nil, nil)
+			},
+		},
+		{
+			name:    "error forbidden",
+			ctx:     principalCtx("alice"),
+			wantErr: domain.ErrForbidden,
+			mock: func() {
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "api_key:alice").Return(domain.RoleViewer, nil)
+			},
+		},
+		{
+			name:    "error delete",
+			ctx:     adminCtx,
+			wantErr: errSome,
+			mock: func() {
+				found()
+				permissionRepoMock.EXPECT().GetEstateRole(gomock.Any(), common.UtUuid, "jwt:bob").Return(domain.RoleViewer, nil)
+				permissionRepoMock.EXPECT().DeleteEstatePermission(gomock.Any(), common.UtUuid, "jwt:bob").Return(false, errSome)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			subject := test.subject
+			if subject == "" {
+				subject = "jwt:bob"
+			}
+			err := uc.RevokeEstatePermission(test.ctx, common.UtUuid, subject)
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+	"github.com/lib/pq"
+)
+
+type healthRepositorySql struct {
+	conn *sql.DB
+}
+
+func NewHealthRepositorySql(conn *sql.DB) domain.HealthRepository {
+	return &healthRepositorySql{
+		conn: conn,
+	}
+}
+
+func (h *healthRepositorySql) PingDatabase(ctx context.Context) error {
+	ctx, span := tracing.StartQuery(ctx, "health", "PingDatabase")
+	defer span.End()
+	defer metrics.ObserveQuery("health", "PingDatabase")()
+
+	return h.conn.PingContext(ctx)
+}
+
+func (h *healthRepositorySql) GetSchemaVersion(ctx context.Context) (int, error) {
+	ctx, span := tracing.StartQuery(ctx, "health", "GetSchemaVersion", tracing.Statement(QueryGetSchemaVersion))
+	defer span.End()
+	defer metrics.ObserveQuery("health", "GetSchemaVersion")()
+
+	var version int
+	err := h.conn.QueryRowContext(ctx, QueryGetSchemaVersion).Scan(&version)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == undefinedTable {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
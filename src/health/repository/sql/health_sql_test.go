@@ -0,0 +1,91 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHealthRepositorySql(t *testing.T) {
+	assert.NotNil(t, NewHealthRepositorySql(nil))
+}
+
+func TestPingDatabase(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &healthRepositorySql{
+		conn: db,
+	}
+
+	mock.ExpectPing()
+	assert.NoError(t, repo.PingDatabase(context.Background()))
+
+	mock.ExpectPing().WillReturnError(errors.New(common.UtSomeError))
+	assert.Error(t, repo.PingDatabase(context.Background()))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSchemaVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &healthRepositorySql{
+		conn: db,
+	}
+
+	tests := []struct {
+		name       string
+		wantResult int
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:       "success",
+			wantResult: 1,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetSchemaVersion)).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+			},
+		},
+		{
+			name:       "success table missing",
+			wantResult: 0,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetSchemaVersion)).
+					WillReturnError(&pq.Error{Code: undefinedTable})
+			},
+		},
+		{
+			name:    "error query",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetSchemaVersion)).
+					WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.GetSchemaVersion(context.Background())
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
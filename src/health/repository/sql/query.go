@@ -0,0 +1,12 @@
+package sql
+
+const (
+	QueryGetSchemaVersion = `SELECT
+		COALESCE(MAX(version), 0)
+	FROM
+		schemaVersion`
+)
+
+// undefinedTable is the SQLSTATE of a missing table, as schemaVersion is in
+// databases created before it.
+const undefinedTable = "42P01"
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+)
+
+type healthUsecase struct {
+	healthRepo    domain.HealthRepository
+	timeout       time.Duration
+	schemaVersion int
+	draining      atomic.Bool
+}
+
+// NewHealthUsecase checks readiness against healthRepo, giving the database
+// checks timeout to answer, and expecting schemaVersion to be applied.
+func NewHealthUsecase(healthRepo domain.HealthRepository, timeout time.Duration, schemaVersion int) domain.HealthUsecase {
+	return &healthUsecase{
+		healthRepo:    healthRepo,
+		timeout:       timeout,
+		schemaVersion: schemaVersion,
+	}
+}
+
+func (h *healthUsecase) Drain() {
+	h.draining.Store(true)
+}
+
+// GetReadiness runs the checks in order and stops at the first failing
+// one: the schema cannot be read from a database that does not answer. The
+// probe is public, so database errors are logged rather than reported.
+func (h *healthUsecase) GetReadiness(ctx context.Context) (*domain.Readiness, error) {
+	readiness := &domain.Readiness{Status: domain.HealthStatusPass}
+	fail := func(name, message string) (*domain.Readiness, error) {
+		readiness.Status = domain.HealthStatusFail
+		readiness.Checks = append(readiness.Checks, domain.HealthCheck{
+			Name:    name,
+			Status:  domain.HealthStatusFail,
+			Message: message,
+		})
+		return nil, domain.ErrNotReady.WithDetails(readiness)
+	}
+	pass := func(name string) {
+		readiness.Checks = append(readiness.Checks, domain.HealthCheck{
+			Name:   name,
+			Status: domain.HealthStatusPass,
+		})
+	}
+
+	if h.draining.Load() {
+		return fail(domain.HealthCheckShutdown, "shutting down")
+	}
+	pass(domain.HealthCheckShutdown)
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	err := h.healthRepo.PingDatabase(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "readiness: ping database", "error", err)
+		return fail(domain.HealthCheckDatabase, "database is unavailable")
+	}
+	pass(domain.HealthCheckDatabase)
+
+	version, err := h.healthRepo.GetSchemaVersion(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "readiness: get schema version", "error", err)
+		return fail(domain.HealthCheckSchema, "schema version is unavailable")
+	}
+	if version < h.schemaVersion {
+		return fail(domain.HealthCheckSchema, fmt.Sprintf("schema version %d is older than %d, migrate the database", version, h.schemaVersion))
+	}
+	pass(domain.HealthCheckSchema)
+
+	return readiness, nil
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewHealthUsecase(t *testing.T) {
+	assert.NotNil(t, NewHealthUsecase(nil, time.Second, domain.SchemaVersion))
+}
+
+func TestGetReadiness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	healthRepoMock := mock_domain.NewMockHealthRepository(ctrl)
+
+	pass := func(name string) domain.HealthCheck {
+		return domain.HealthCheck{Name: name, Status: domain.HealthStatusPass}
+	}
+	fail := func(name, message string) domain.HealthCheck {
+		return domain.HealthCheck{Name: name, Status: domain.HealthStatusFail, Message: message}
+	}
+
+	tests := []struct {
+		name       string
+		draining   bool
+		wantResult *domain.Readiness
+		wantErr    *domain.Readiness
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: &domain.Readiness{
+				Status: domain.HealthStatusPass,
+				Checks: []domain.HealthCheck{
+					pass(domain.HealthCheckShutdown),
+					pass(domain.HealthCheckDatabase),
+					pass(domain.HealthCheckSchema),
+				},
+			},
+			mock: func() {
+				healthRepoMock.EXPECT().PingDatabase(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+					_, ok := ctx.Deadline()
+					assert.True(t, ok)
+					return nil
+				})
+				healthRepoMock.EXPECT().GetSchemaVersion(gomock.Any()).Return(2, nil)
+			},
+		},
+		{
+			name:     "error draining",
+			draining: true,
+			wantErr: &domain.Readiness{
+				Status: domain.HealthStatusFail,
+				Checks: []domain.HealthCheck{
+					fail(domain.HealthCheckShutdown, "shutting down"),
+				},
+			},
+			mock: func() {},
+		},
+		{
+			name: "error ping database",
+			wantErr: &domain.Readiness{
+				Status: domain.HealthStatusFail,
+				Checks: []domain.HealthCheck{
+					pass(domain.HealthCheckShutdown),
+					fail(domain.HealthCheckDatabase, "database is unavailable"),
+				},
+			},
+			mock: func() {
+				healthRepoMock.EXPECT().PingDatabase(gomock.Any()).Return(errors.New(common.UtSomeError))
+			},
+		},
+		{
+			name: "error get schema version",
+			wantErr: &domain.Readiness{
+				Status: domain.HealthStatusFail,
+				Checks: []domain.HealthCheck{
+					pass(domain.HealthCheckShutdown),
+					pass(domain.HealthCheckDatabase),
+					fail(domain.HealthCheckSchema, "schema version is unavailable"),
+				},
+			},
+			mock: func() {
+				healthRepoMock.EXPECT().PingDatabase(gomock.Any()).Return(nil)
+				healthRepoMock.EXPECT().GetSchemaVersion(gomock.Any()).Return(0, errors.New(common.UtSomeError))
+			},
+		},
+		{
+			name: "error schema not migrated",
+			wantErr: &domain.Readiness{
+				Status: domain.HealthStatusFail,
+				Checks: []domain.HealthCheck{
+					pass(domain.HealthCheckShutdown),
+					pass(domain.HealthCheckDatabase),
+					fail(domain.HealthCheckSchema, "schema version 1 is older than 2, migrate the database"),
+				},
+			},
+			mock: func() {
+				healthRepoMock.EXPECT().PingDatabase(gomock.Any()).Return(nil)
+				healthRepoMock.EXPECT().GetSchemaVersion(gomock.Any()).Return(1, nil)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			uc := NewHealthUsecase(healthRepoMock, time.Second, 2)
+			if test.draining {
+				uc.Drain()
+			}
+			test.mock()
+
+			got, err := uc.GetReadiness(context.Background())
+			assert.Equal(t, test.wantResult, got)
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var apiErr *domain.Error
+			assert.ErrorAs(t, err, &apiErr)
+			assert.ErrorIs(t, err, domain.ErrNotReady)
+			assert.Equal(t, test.wantErr, apiErr.Details)
+		})
+	}
+}
@@ -1,16 +1,29 @@
 package helper
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 
+	"github.com/davidyunus/sawitpro-estate/src/common"
 	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	ErrorCodeInternal    = "internal_error"
+	MessageInternalError = "internal server error"
 )
 
 type HttpResponse struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
-	Errors  interface{} `json:"errors"`
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	Data      interface{} `json:"data"`
+	Errors    interface{} `json:"errors"`
+	ErrorCode string      `json:"errorCode,omitempty"`
 }
 
 func Response(code int, message string, data, errors interface{}) HttpResponse {
@@ -23,19 +36,76 @@ func Response(code int, message string, data, errors interface{}) HttpResponse {
 	return res
 }
 
+func ErrorResponse(err error) HttpResponse {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		res := Response(domainErr.Status, err.Error(), nil, err.Error())
+		if domainErr.Details != nil {
+			res.Errors = domainErr.Details
+		}
+		res.ErrorCode = domainErr.Code
+		return res
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorResponse(domain.ErrDeadlineExceeded)
+	case errors.Is(err, context.Canceled):
+		return ErrorResponse(domain.ErrRequestCanceled)
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message := fmt.Sprint(httpErr.Message)
+		res := Response(httpErr.Code, message, nil, message)
+		res.ErrorCode = strings.ToLower(strings.ReplaceAll(http.StatusText(httpErr.Code), " ", "_"))
+		return res
+	}
+
+	res := Response(http.StatusInternalServerError, MessageInternalError, nil, MessageInternalError)
+	res.ErrorCode = ErrorCodeInternal
+	return res
+}
+
 func GetStatusCode(err error) int {
 	if err == nil {
-		return http.StatusCreated
-	}
-
-	switch err.Error() {
-	case domain.ErrInvalidInput.Error():
-		return http.StatusBadRequest
-	case domain.ErrMaxSizeEstate.Error():
-		return http.StatusBadRequest
-	case domain.ErrEstateNotFound.Error():
-		return http.StatusNotFound
-	default:
-		return http.StatusInternalServerError
+		return http.StatusOK
+	}
+	return ErrorResponse(err).Code
+}
+
+// HTTPErrorHandler renders every error returned by a handler with the
+// HttpResponse envelope, or as application/problem+json when the client
+// asks for it. Errors that are not a *domain.Error are logged and reported
+// as 500 without leaking their text, unless the request ran out of time or
+// was canceled, which is reported as 504 or 503.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	// The layer noticing a done context often fails with an error of its
+	// own, such as a query PostgreSQL canceled, rather than ctx.Err().
+	if ctxErr := c.Request().Context().Err(); ctxErr != nil && GetStatusCode(err) == http.StatusInternalServerError {
+		err = errors.Join(ctxErr, err)
+	}
+
+	response := ErrorResponse(err)
+	var body interface{} = response
+	if response.Code >= http.StatusInternalServerError {
+		slog.ErrorContext(c.Request().Context(), "request failed", "error", err)
+	}
+	if AcceptsProblem(c.Request().Header.Get(echo.HeaderAccept)) {
+		c.Response().Header().Set(echo.HeaderContentType, common.ContentTypeProblem)
+		body = ProblemResponse(err, c.Request().URL.RequestURI())
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(response.Code)
+	} else {
+		err = c.JSON(response.Code, body)
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "write error response", "error", err)
 	}
 }
@@ -0,0 +1,216 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		accept     string
+		ctxErr     error
+		err        error
+		wantCode   int
+		wantResult string
+	}{
+		{
+			name:     "domain error",
+			method:   http.MethodGet,
+			err:      domain.ErrEstateNotFound,
+			wantCode: http.StatusNotFound,
+			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found","errorCode":"estate_not_found"}
+`,
+		},
+		{
+			name:     "wrapped domain error",
+			method:   http.MethodPost,
+			err:      fmt.Errorf("plant: %w", domain.ErrLocationFilled),
+			wantCode: http.StatusConflict,
+			wantResult: `{"code":409,"message":"plant: location already filled","data":null,"errors":"plant: location already filled","errorCode":"location_filled"}
+`,
+		},
+		{
+			name:     "domain error with details",
+			method:   http.MethodPost,
+			err:      domain.ErrInvalidInput.WithDetails([]domain.FieldError{{Field: "x", Rule: "gt", Param: "0", Message: "must be greater than 0"}}),
+			wantCode: http.StatusBadRequest,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"x","rule":"gt","param":"0","message":"must be greater than 0"}],"errorCode":"invalid_input"}
+`,
+		},
+		{
+			name:     "echo error",
+			method:   http.MethodGet,
+			err:      echo.ErrNotFound,
+			wantCode: http.StatusNotFound,
+			wantResult: `{"code":404,"message":"Not Found","data":null,"errors":"Not Found","errorCode":"not_found"}
+`,
+		},
+		{
+			name:     "unknown error",
+			method:   http.MethodGet,
+			err:      errors.New("pq: connection refused"),
+			wantCode: http.StatusInternalServerError,
+			wantResult: `{"code":500,"message":"internal server error","data":null,"errors":"internal server error","errorCode":"internal_error"}
+`,
+		},
+		{
+			name:     "deadline exceeded",
+			method:   http.MethodGet,
+			err:      fmt.Errorf("get estate: %w", context.DeadlineExceeded),
+			wantCode: http.StatusGatewayTimeout,
+			wantResult: `{"code":504,"message":"request took too long to complete","data":null,"errors":"request took too long to complete","errorCode":"deadline_exceeded"}
+`,
+		},
+		{
+			name:     "query canceled by deadline",
+			method:   http.MethodGet,
+			ctxErr:   context.DeadlineExceeded,
+			err:      errors.New("pq: canceling statement due to user request"),
+			wantCode: http.StatusGatewayTimeout,
+			wantResult: `{"code":504,"message":"request took too long to complete","data":null,"errors":"request took too long to complete","errorCode":"deadline_exceeded"}
+`,
+		},
+		{
+			name:     "request canceled",
+			method:   http.MethodGet,
+			ctxErr:   context.Canceled,
+			err:      errors.New("pq: canceling statement due to user request"),
+			wantCode: http.StatusServiceUnavailable,
+			wantResult: `{"code":503,"message":"request was canceled before it completed","data":null,"errors":"request was canceled before it completed","errorCode":"request_canceled"}
+`,
+		},
+		{
+			name:     "domain error after deadline",
+			method:   http.MethodGet,
+			ctxErr:   context.DeadlineExceeded,
+			err:      domain.ErrEstateNotFound,
+			wantCode: http.StatusNotFound,
+			wantResult: `{"code":404,"message":"estate not found","data":null,"errors":"estate not found","errorCode":"estate_not_found"}
+`,
+		},
+		{
+			name:     "problem domain error",
+			method:   http.MethodGet,
+			accept:   "application/problem+json",
+			err:      domain.ErrEstateNotFound,
+			wantCode: http.StatusNotFound,
+			wantResult: `{"type":"/problems/estate-not-found","title":"estate not found","status":404,"detail":"estate not found","instance":"/estate/uuid/stats","code":"estate_not_found"}
+`,
+		},
+		{
+			name:     "problem validation error",
+			method:   http.MethodPost,
+			accept:   "application/problem+json, application/json;q=0.9",
+			err:      NewValidator().Validate(&domain.PalmTree{X: 1, Y: 1, Height: 40}),
+			wantCode: http.StatusBadRequest,
+			wantResult: `{"type":"/problems/invalid-input","title":"invalid input","status":400,"detail":"invalid input","instance":"/estate/uuid/stats","code":"invalid_input","errors":[{"field":"height","rule":"lte","param":"30","message":"must be less than or equal to 30"}]}
+`,
+		},
+		{
+			name:     "problem unknown error",
+			method:   http.MethodGet,
+			accept:   "application/problem+json",
+			err:      errors.New("pq: connection refused"),
+			wantCode: http.StatusInternalServerError,
+			wantResult: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal server error","instance":"/estate/uuid/stats","code":"internal_error"}
+`,
+		},
+		{
+			name:       "head request",
+			method:     http.MethodHead,
+			err:        domain.ErrEstateNotFound,
+			wantCode:   http.StatusNotFound,
+			wantResult: ``,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.Logger.SetOutput(io.Discard)
+			req := httptest.NewRequest(test.method, "/estate/uuid/stats", nil)
+			req.Header.Set(echo.HeaderAccept, test.accept)
+			if test.ctxErr != nil {
+				req = req.WithContext(canceledContext(test.ctxErr))
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			HTTPErrorHandler(test.err, c)
+			assert.Equal(t, test.wantCode, rec.Code)
+			if AcceptsProblem(test.accept) {
+				assert.Equal(t, common.ContentTypeProblem, rec.Header().Get(echo.HeaderContentType))
+			}
+			assert.Equal(t, test.wantResult, rec.Body.String())
+		})
+	}
+}
+
+func TestAcceptsProblem(t *testing.T) {
+	tests := []struct {
+		name       string
+		accept     string
+		wantResult bool
+	}{
+		{
+			name:       "empty",
+			accept:     "",
+			wantResult: false,
+		},
+		{
+			name:       "json",
+			accept:     "application/json",
+			wantResult: false,
+		},
+		{
+			name:       "problem",
+			accept:     "application/problem+json",
+			wantResult: true,
+		},
+		{
+			name:       "problem preferred",
+			accept:     "application/json;q=0.5, application/problem+json",
+			wantResult: true,
+		},
+		{
+			name:       "json preferred",
+			accept:     "application/problem+json;q=0.5, application/json",
+			wantResult: false,
+		},
+		{
+			name:       "problem refused",
+			accept:     "application/problem+json;q=0",
+			wantResult: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.wantResult, AcceptsProblem(test.accept))
+		})
+	}
+}
+
+// canceledContext returns a context already done with err, which is
+// context.Canceled or context.DeadlineExceeded.
+func canceledContext(err error) context.Context {
+	if errors.Is(err, context.DeadlineExceeded) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Time{})
+		cancel()
+		return ctx
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
@@ -0,0 +1,58 @@
+package helper
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LevelOff is above every level records are logged at, so a logger at it
+// logs nothing.
+const LevelOff = slog.Level(12)
+
+// NewLogger returns a logger writing JSON lines to w from level on. Records
+// logged with a context, as slog.ErrorContext does, carry the id of the
+// request being served, the trace and span it belongs to and the attributes
+// added with WithLogAttrs.
+func NewLogger(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+type logAttrsKey struct{}
+
+// WithLogAttrs adds attrs to every record logged with ctx.
+func WithLogAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(logAttrsKey{}).([]slog.Attr)
+	return context.WithValue(ctx, logAttrsKey{}, append(existing[:len(existing):len(existing)], attrs...))
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := domain.RequestIdFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	if attrs, ok := ctx.Value(logAttrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,84 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name       string
+		level      slog.Level
+		ctx        context.Context
+		wantResult map[string]interface{}
+	}{
+		{
+			name:  "success request context",
+			level: slog.LevelInfo,
+			ctx: WithLogAttrs(
+				WithLogAttrs(domain.WithRequestId(context.Background(), "req-1"), slog.String("estate_id", "uuid")),
+				slog.Int("trees", 2),
+			),
+			wantResult: map[string]interface{}{
+				"level":      "INFO",
+				"msg":        "planted",
+				"component":  "test",
+				"request_id": "req-1",
+				"estate_id":  "uuid",
+				"trees":      float64(2),
+			},
+		},
+		{
+			name:  "success trace context",
+			level: slog.LevelInfo,
+			ctx: trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+				SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			})),
+			wantResult: map[string]interface{}{
+				"level":     "INFO",
+				"msg":       "planted",
+				"component": "test",
+				"trace_id":  "4bf92f3577b34da6a3ce929d0e0e4736",
+				"span_id":   "00f067aa0ba902b7",
+			},
+		},
+		{
+			name:  "success no request",
+			level: slog.LevelInfo,
+			ctx:   context.Background(),
+			wantResult: map[string]interface{}{
+				"level":     "INFO",
+				"msg":       "planted",
+				"component": "test",
+			},
+		},
+		{
+			name:  "success below level",
+			level: LevelOff,
+			ctx:   context.Background(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			NewLogger(buf, test.level).With("component", "test").InfoContext(test.ctx, "planted")
+
+			if test.wantResult == nil {
+				assert.Empty(t, buf.String())
+				return
+			}
+			got := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			delete(got, "time")
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
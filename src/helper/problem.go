@@ -0,0 +1,78 @@
+package helper
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+)
+
+const (
+	ProblemTypeBlank  = "about:blank"
+	ProblemTypePrefix = "/problems/"
+)
+
+// Problem is an RFC 7807 problem details object. Code and Errors are
+// extension members carrying the domain error code and per-field errors.
+type Problem struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail,omitempty"`
+	Instance string      `json:"instance,omitempty"`
+	Code     string      `json:"code,omitempty"`
+	Errors   interface{} `json:"errors,omitempty"`
+}
+
+func ProblemResponse(err error, instance string) Problem {
+	res := ErrorResponse(err)
+	problem := Problem{
+		Type:     ProblemTypeBlank,
+		Title:    http.StatusText(res.Code),
+		Status:   res.Code,
+		Detail:   res.Message,
+		Instance: instance,
+		Code:     res.ErrorCode,
+	}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		problem.Type = ProblemTypePrefix + strings.ReplaceAll(domainErr.Code, "_", "-")
+		problem.Title = domainErr.Message
+		problem.Errors = domainErr.Details
+	}
+
+	return problem
+}
+
+// AcceptsProblem reports whether the Accept header prefers
+// application/problem+json over application/json.
+func AcceptsProblem(accept string) bool {
+	problemQ, jsonQ := -1.0, -1.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case common.ContentTypeProblem:
+			problemQ = q
+		case common.ContentTypeJson:
+			jsonQ = q
+		}
+	}
+	return problemQ > 0 && problemQ >= jsonQ
+}
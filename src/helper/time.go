@@ -1,20 +1,49 @@
 package helper
 
-import "time"
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+)
 
 var (
 	loc *time.Location
 	Now = now
+
+	// tenantLocations caches the locations of organisation time zones.
+	tenantLocations sync.Map
 )
 
 func now() time.Time {
 	return time.Now().In(loc)
 }
 
-func InitTime() (err error) {
-	loc, err = time.LoadLocation("Asia/Jakarta")
+func InitTime(timezone string) (err error) {
+	loc, err = time.LoadLocation(timezone)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// TenantNow returns Now in the time zone of the organisation the request
+// acts for, falling back to TIMEZONE when it has none.
+func TenantNow(ctx context.Context) time.Time {
+	t := Now()
+	tenant := domain.TenantFromContext(ctx)
+	if tenant == nil || tenant.Timezone == "" {
+		return t
+	}
+
+	cached, ok := tenantLocations.Load(tenant.Timezone)
+	if !ok {
+		l, err := time.LoadLocation(tenant.Timezone)
+		if err != nil {
+			return t
+		}
+		cached, _ = tenantLocations.LoadOrStore(tenant.Timezone, l)
+	}
+	return t.In(cached.(*time.Location))
+}
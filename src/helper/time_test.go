@@ -0,0 +1,53 @@
+package helper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantNow(t *testing.T) {
+	tempNow := Now
+	Now = func() time.Time {
+		return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	}
+	defer func() {
+		Now = tempNow
+	}()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantResult string
+	}{
+		{
+			name:       "success no tenant",
+			ctx:        context.Background(),
+			wantResult: "2024-01-02T03:04:05Z",
+		},
+		{
+			name:       "success tenant without timezone",
+			ctx:        domain.WithTenant(context.Background(), &domain.Organisation{Id: "acme"}),
+			wantResult: "2024-01-02T03:04:05Z",
+		},
+		{
+			name:       "success tenant timezone",
+			ctx:        domain.WithTenant(context.Background(), &domain.Organisation{Id: "acme", Timezone: "Asia/Makassar"}),
+			wantResult: "2024-01-02T11:04:05+08:00",
+		},
+		{
+			name:       "success unknown timezone",
+			ctx:        domain.WithTenant(context.Background(), &domain.Organisation{Id: "acme", Timezone: "Mars/Olympus"}),
+			wantResult: "2024-01-02T03:04:05Z",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := TenantNow(test.ctx)
+			assert.Equal(t, test.wantResult, got.Format(time.RFC3339))
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package helper
 
 import (
+	"context"
 	"database/sql"
+	"log/slog"
 )
 
 type key string
@@ -21,3 +23,28 @@ func NewManager(db *sql.DB, k key) *Manager {
 		key: k,
 	}
 }
+
+// WithinTransaction joins the transaction ctx already carries, or begins
+// one that is committed when fn succeeds and rolled back when it fails.
+func (m *Manager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if tx, _ := ctx.Value(m.key).(*sql.Tx); tx != nil {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				slog.ErrorContext(ctx, "roll back transaction", "error", rollbackErr)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return fn(context.WithValue(ctx, m.key, tx))
+}
@@ -0,0 +1,82 @@
+package helper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithinTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	manager := NewManager(db, common.TransactionContextKey)
+
+	tests := []struct {
+		name    string
+		fnErr   error
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "commit",
+			wantErr: false,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "rollback",
+			fnErr:   errors.New(common.UtSomeError),
+			wantErr: true,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name:    "error begin",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectBegin().WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := manager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+				tx, _ := ctx.Value(manager.GetKey()).(*sql.Tx)
+				assert.NotNil(t, tx)
+				return test.fnErr
+			})
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("join", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		err := manager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			outer := ctx.Value(manager.GetKey())
+			return manager.WithinTransaction(ctx, func(ctx context.Context) error {
+				assert.Equal(t, outer, ctx.Value(manager.GetKey()))
+				return nil
+			})
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
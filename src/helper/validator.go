@@ -1,15 +1,112 @@
 package helper
 
-import "github.com/go-playground/validator/v10"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/go-playground/validator/v10"
+)
 
 type CustomValidator struct {
 	validator *validator.Validate
 }
 
+// Validate checks i against its validate tags and reports every rejected
+// field as the details of domain.ErrInvalidInput.
 func (cv *CustomValidator) Validate(i interface{}) error {
-	return cv.validator.Struct(i)
+	err := cv.validator.Struct(i)
+	if err != nil {
+		details := ValidationDetails(err)
+		if details == nil {
+			return err
+		}
+		return domain.ErrInvalidInput.WithDetails(details).Wrap(err)
+	}
+	return nil
 }
 
 func NewValidator() *CustomValidator {
-	return &CustomValidator{validator: validator.New()}
+	v := validator.New()
+	v.RegisterTagNameFunc(jsonFieldName)
+	return &CustomValidator{validator: v}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// ValidationDetails lists the fields rejected by the validator, or nil when
+// err does not wrap validator.ValidationErrors.
+func ValidationDetails(err error) []domain.FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	details := make([]domain.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		details = append(details, domain.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: validationMessage(fe),
+		})
+	}
+	return details
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", fe.Param())
+	case "uuid":
+		return "must be a valid UUID"
+	case "timezone":
+		return "must be an IANA time zone name"
+	default:
+		return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+	}
+}
+
+// DecodeError turns a JSON decoding error into domain.ErrInvalidInput,
+// naming the offending field when the decoder reports one.
+func DecodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return domain.ErrInvalidInput.WithDetails([]domain.FieldError{
+			{
+				Field:   typeErr.Field,
+				Rule:    "type",
+				Param:   typeErr.Type.String(),
+				Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+			},
+		}).Wrap(err)
+	}
+	return domain.ErrInvalidInput.Wrap(err)
 }
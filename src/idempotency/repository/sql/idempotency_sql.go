@@ -0,0 +1,111 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+)
+
+type idempotencyRepositorySql struct {
+	conn *sql.DB
+}
+
+func NewIdempotencyRepositorySql(conn *sql.DB) domain.IdempotencyRepository {
+	return &idempotencyRepositorySql{
+		conn: conn,
+	}
+}
+
+func (r *idempotencyRepositorySql) ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	ctx, span := tracing.StartQuery(ctx, "idempotency", "ReserveIdempotencyKey")
+	defer span.End()
+	defer metrics.ObserveQuery("idempotency", "ReserveIdempotencyKey")()
+
+	// A record released between the two statements leaves nothing to
+	// return, so the reservation is tried once more.
+	for attempt := 0; attempt < 2; attempt++ {
+		var key string
+		err := r.conn.QueryRowContext(ctx, QueryReserveKey, record.Scope, record.Key, record.Token, record.RequestHash, record.ExpiresAt, now).Scan(&key)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		existing, err := r.get(ctx, record.Scope, record.Key)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+	return nil, fmt.Errorf("idempotency key %q: reservation released concurrently", record.Key)
+}
+
+func (r *idempotencyRepositorySql) get(ctx context.Context, scope, key string) (*domain.IdempotencyRecord, error) {
+	record := &domain.IdempotencyRecord{Scope: scope, Key: key}
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+
+	err := r.conn.QueryRowContext(ctx, QueryGetKey, scope, key).Scan(
+		&record.Token,
+		&record.RequestHash,
+		&statusCode,
+		&contentType,
+		&record.Body,
+		&record.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record.StatusCode = int(statusCode.Int64)
+	record.ContentType = contentType.String
+	return record, nil
+}
+
+func (r *idempotencyRepositorySql) SaveIdempotentResponse(ctx context.Context, record *domain.IdempotencyRecord) error {
+	ctx, span := tracing.StartQuery(ctx, "idempotency", "SaveIdempotentResponse", tracing.Statement(QuerySaveResponse))
+	defer span.End()
+	defer metrics.ObserveQuery("idempotency", "SaveIdempotentResponse")()
+
+	res, err := r.conn.ExecContext(ctx, QuerySaveResponse,
+		record.Scope,
+		record.Key,
+		record.Token,
+		record.StatusCode,
+		record.ContentType,
+		record.Body,
+	)
+	if err != nil {
+		return err
+	}
+	saved, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if saved == 0 {
+		return domain.ErrIdempotencyReservationLost
+	}
+	return nil
+}
+
+func (r *idempotencyRepositorySql) ReleaseIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) error {
+	ctx, span := tracing.StartQuery(ctx, "idempotency", "ReleaseIdempotencyKey", tracing.Statement(QueryReleaseKey))
+	defer span.End()
+	defer metrics.ObserveQuery("idempotency", "ReleaseIdempotencyKey")()
+
+	_, err := r.conn.ExecContext(ctx, QueryReleaseKey, record.Scope, record.Key, record.Token)
+	return err
+}
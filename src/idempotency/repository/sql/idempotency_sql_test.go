@@ -0,0 +1,172 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := &idempotencyRepositorySql{
+		conn: db,
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := &domain.IdempotencyRecord{Scope: "scope", Key: "key", Token: "token", RequestHash: "hash", ExpiresAt: now.Add(time.Hour)}
+	columns := []string{"token", "requestHash", "statusCode", "contentType", "body", "expiresAt"}
+
+	tests := []struct {
+		name       string
+		wantResult *domain.IdempotencyRecord
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name: "success reserved",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryReserveKey)).
+					WithArgs("scope", "key", "token", "hash", now.Add(time.Hour), now).
+					WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key"))
+			},
+		},
+		{
+			name: "success completed record",
+			wantResult: &domain.IdempotencyRecord{
+				Scope:       "scope",
+				Key:         "key",
+				Token:       "other",
+				RequestHash: "hash",
+				StatusCode:  201,
+				ContentType: common.ContentTypeJson,
+				Body:        []byte(`{}`),
+				ExpiresAt:   now,
+			},
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryReserveKey)).WillReturnRows(sqlmock.NewRows([]string{"key"}))
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetKey)).WithArgs("scope", "key").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("other", "hash", 201, common.ContentTypeJson, []byte(`{}`), now))
+			},
+		},
+		{
+			name:       "success record in progress",
+			wantResult: &domain.IdempotencyRecord{Scope: "scope", Key: "key", Token: "other", RequestHash: "hash", ExpiresAt: now},
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryReserveKey)).WillReturnRows(sqlmock.NewRows([]string{"key"}))
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetKey)).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("other", "hash", nil, nil, nil, now))
+			},
+		},
+		{
+			name: "success reserved after concurrent release",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryReserveKey)).WillReturnRows(sqlmock.NewRows([]string{"key"}))
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetKey)).WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectQuery(regexp.QuoteMeta(QueryReserveKey)).WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key"))
+			},
+		},
+		{
+			name:    "error reserve",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryReserveKey)).WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.ReserveIdempotencyKey(ctx, record, now)
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSaveIdempotentResponse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &idempotencyRepositorySql{
+		conn: db,
+	}
+	record := &domain.IdempotencyRecord{
+		Scope:       "scope",
+		Key:         "key",
+		Token:       "token",
+		StatusCode:  201,
+		ContentType: common.ContentTypeJson,
+		Body:        []byte(`{}`),
+	}
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QuerySaveResponse)).
+					WithArgs("scope", "key", "token", 201, common.ContentTypeJson, []byte(`{}`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "error reservation taken over by a retry",
+			wantErr: domain.ErrIdempotencyReservationLost,
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QuerySaveResponse)).
+					WithArgs("scope", "key", "token", 201, common.ContentTypeJson, []byte(`{}`)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := repo.SaveIdempotentResponse(context.Background(), record)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReleaseIdempotencyKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &idempotencyRepositorySql{
+		conn: db,
+	}
+
+	// Only the reservation of the token is dropped; one a retry took over
+	// matches no row and stays.
+	mock.ExpectExec(regexp.QuoteMeta(QueryReleaseKey)).WithArgs("scope", "key", "token").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.ReleaseIdempotencyKey(context.Background(), &domain.IdempotencyRecord{Scope: "scope", Key: "key", Token: "token"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package sql
+
+const (
+	// QueryReserveKey inserts a reservation, or takes over an expired one.
+	// It returns no row when an unexpired record holds the key.
+	QueryReserveKey = `INSERT INTO idempotencyKey
+	(scope, key, token, requestHash, expiresAt, createdAt)
+	VALUES($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (scope, key) DO UPDATE SET
+		token = EXCLUDED.token,
+		requestHash = EXCLUDED.requestHash,
+		statusCode = NULL,
+		contentType = NULL,
+		body = NULL,
+		expiresAt = EXCLUDED.expiresAt,
+		createdAt = EXCLUDED.createdAt
+	WHERE
+		idempotencyKey.expiresAt <= EXCLUDED.createdAt
+	RETURNING key`
+
+	QueryGetKey = `SELECT
+		token,
+		requestHash,
+		statusCode,
+		contentType,
+		body,
+		expiresAt
+	FROM
+		idempotencyKey
+	WHERE
+		scope = $1
+		AND key = $2`
+
+	// QuerySaveResponse and QueryReleaseKey only touch the reservation
+	// the token holds, not one a retry took over after it expired.
+	QuerySaveResponse = `UPDATE idempotencyKey
+	SET
+		statusCode = $4,
+		contentType = $5,
+		body = $6
+	WHERE
+		scope = $1
+		AND key = $2
+		AND token = $3`
+
+	QueryReleaseKey = `DELETE FROM idempotencyKey
+	WHERE
+		scope = $1
+		AND key = $2
+		AND token = $3`
+)
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every collector served on /metrics. It is a registry of
+// its own rather than the global default, so tests can read it and no
+// library registers collectors behind our back.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to serve HTTP requests, by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	QueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Time taken by repository queries, by repository and method.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"repository", "query"})
+
+	EstatesCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "estates_created_total",
+		Help: "Estates created, through POST or PUT.",
+	})
+
+	PalmTreesPlanted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "palm_trees_planted_total",
+		Help: "Palm trees planted, one by one or by import.",
+	})
+
+	DronePlanDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "drone_plan_duration_seconds",
+		Help:    "Time taken to compute the drone flying distance of an estate.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		QueryDuration,
+		EstatesCreated,
+		PalmTreesPlanted,
+		DronePlanDuration,
+	)
+}
+
+// RegisterDB exports the connection pool statistics of db, as reported by
+// sql.DB.Stats, labelled with name.
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveQuery starts timing a repository query and returns the function
+// that records it, to be deferred:
+//
+//	defer metrics.ObserveQuery("estate", "GetEstateByUuid")()
+func ObserveQuery(repository, query string) func() {
+	start := time.Now()
+	return func() {
+		QueryDuration.WithLabelValues(repository, query).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveQuery(t *testing.T) {
+	before := testutil.CollectAndCount(QueryDuration)
+
+	ObserveQuery("estate", "GetEstateByUuid")()
+	ObserveQuery("estate", "GetEstateByUuid")()
+
+	assert.Equal(t, before+1, testutil.CollectAndCount(QueryDuration))
+}
+
+func TestHandler(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	assert.NoError(t, RegisterDB(db, "test"))
+	assert.Error(t, RegisterDB(db, "test"))
+	EstatesCreated.Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body, _ := io.ReadAll(rec.Body)
+	assert.Contains(t, string(body), `go_sql_max_open_connections{db_name="test"} 0`)
+	assert.Contains(t, string(body), "estates_created_total 1")
+	assert.Contains(t, string(body), "go_goroutines")
+}
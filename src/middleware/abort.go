@@ -0,0 +1,22 @@
+package middleware
+
+import "net/http"
+
+// abortedStatus is recorded for a response a handler cut short by
+// panicking with http.ErrAbortHandler after its status was sent, such as an
+// export failing halfway. The status sent says nothing about the failure.
+const abortedStatus = "aborted"
+
+// recordAbort calls record when the handler aborted the response, then
+// panics again so that net/http still drops the connection. It must be
+// deferred directly. Other panics pass through unrecorded.
+func recordAbort(record func()) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if recovered == http.ErrAbortHandler {
+		record()
+	}
+	panic(recovered)
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// Auth authenticates every request except those to the public routes,
+// given as echo paths such as "/ping". A request carries either an API key
+// in X-API-Key or a JWT in "Authorization: Bearer"; the principal it
+// resolves to is put in the request context for the usecases.
+func Auth(uc domain.AuthUsecase, public ...string) echo.MiddlewareFunc {
+	open := map[string]bool{}
+	for _, route := range public {
+		open[route] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if open[c.Path()] {
+				return next(c)
+			}
+
+			req := c.Request()
+			var principal *domain.Principal
+			var err error
+			if key := req.Header.Get(common.HeaderApiKey); key != "" {
+				principal, err = uc.AuthenticateApiKey(req.Context(), key)
+			} else if token, ok := bearerToken(req.Header.Get(echo.HeaderAuthorization)); ok {
+				principal, err = uc.AuthenticateToken(req.Context(), token)
+			} else {
+				err = domain.ErrUnauthorized
+			}
+			if err != nil {
+				if errors.Is(err, domain.ErrUnauthorized) {
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="estate"`)
+				}
+				return err
+			}
+
+			c.SetRequest(req.WithContext(domain.WithPrincipal(req.Context(), principal)))
+			return next(c)
+		}
+	}
+}
+
+// Anonymous stands in for Auth when authentication is disabled: every
+// request acts as an administrator, as the API did before it had auth.
+func Anonymous() echo.MiddlewareFunc {
+	principal := &domain.Principal{
+		Subject: "anonymous",
+		Method:  domain.AuthMethodNone,
+		Admin:   true,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(domain.WithPrincipal(req.Context(), principal)))
+			return next(c)
+		}
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
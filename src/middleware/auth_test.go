@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_domain.NewMockAuthUsecase(ctrl)
+
+	keyPrincipal := &domain.Principal{Subject: common.UtUuid, Method: domain.AuthMethodApiKey}
+	tokenPrincipal := &domain.Principal{Subject: "alice", Method: domain.AuthMethodJwt}
+
+	tests := []struct {
+		name             string
+		target           string
+		header           http.Header
+		wantCode         int
+		wantPrincipal    *domain.Principal
+		wantAuthenticate bool
+		mock             func()
+	}{
+		{
+			name:          "success api key",
+			target:        "/estate",
+			header:        http.Header{common.HeaderApiKey: {"spk_key"}},
+			wantCode:      http.StatusOK,
+			wantPrincipal: keyPrincipal,
+			mock: func() {
+				authMock.EXPECT().AuthenticateApiKey(gomock.Any(), "spk_key").Return(keyPrincipal, nil)
+			},
+		},
+		{
+			name:          "success bearer token",
+			target:        "/estate",
+			header:        http.Header{echo.HeaderAuthorization: {"bearer token"}},
+			wantCode:      http.StatusOK,
+			wantPrincipal: tokenPrincipal,
+			mock: func() {
+				authMock.EXPECT().AuthenticateToken(gomock.Any(), "token").Return(tokenPrincipal, nil)
+			},
+		},
+		{
+			name:     "success public route",
+			target:   "/ping",
+			wantCode: http.StatusOK,
+			mock:     func() {},
+		},
+		{
+			name:             "error no credentials",
+			target:           "/estate",
+			wantCode:         http.StatusUnauthorized,
+			wantAuthenticate: true,
+			mock:             func() {},
+		},
+		{
+			name:             "error basic auth",
+			target:           "/estate",
+			header:           http.Header{echo.HeaderAuthorization: {"Basic dXNlcjpwYXNz"}},
+			wantCode:         http.StatusUnauthorized,
+			wantAuthenticate: true,
+			mock:             func() {},
+		},
+		{
+			name:             "error invalid token",
+			target:           "/estate",
+			header:           http.Header{echo.HeaderAuthorization: {"Bearer token"}},
+			wantCode:         http.StatusUnauthorized,
+			wantAuthenticate: true,
+			mock: func() {
+				authMock.EXPECT().AuthenticateToken(gomock.Any(), "token").
+					Return(nil, domain.ErrUnauthorized.Wrap(errors.New("token is expired")))
+			},
+		},
+		{
+			name:     "error key lookup",
+			target:   "/estate",
+			header:   http.Header{common.HeaderApiKey: {"spk_key"}},
+			wantCode: http.StatusInternalServerError,
+			mock: func() {
+				authMock.EXPECT().AuthenticateApiKey(gomock.Any(), "spk_key").Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			var got *domain.Principal
+			e := echo.New()
+			e.HTTPErrorHandler = helper.HTTPErrorHandler
+			e.Use(Auth(authMock, "/ping"))
+			handler := func(c echo.Context) error {
+				got = domain.PrincipalFromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			}
+			e.GET("/estate", handler)
+			e.GET("/ping", handler)
+
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+			for name, values := range test.header {
+				req.Header.Set(name, values[0])
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantPrincipal, got)
+			assert.Equal(t, test.wantAuthenticate, rec.Header().Get(echo.HeaderWWWAuthenticate) != "")
+		})
+	}
+}
+
+func TestAnonymous(t *testing.T) {
+	var got *domain.Principal
+	e := echo.New()
+	e.Use(Anonymous())
+	e.GET("/estate", func(c echo.Context) error {
+		got = domain.PrincipalFromContext(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/estate", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, &domain.Principal{Subject: "anonymous", Method: domain.AuthMethodNone, Admin: true}, got)
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// responseGrace is how long past its deadline a request may keep writing,
+// so the 504 reporting it still reaches the client.
+const responseGrace = 5 * time.Second
+
+// Deadline cancels the context of every request after timeout, or after the
+// timeout routes gives for its echo path, such as a longer one for drone
+// plans and exports. Handlers are not interrupted: the usecases and queries
+// watching the context give up with context.DeadlineExceeded, rendered as
+// 504 by helper.HTTPErrorHandler. Like RequestLog it renders errors itself,
+// while the context is still the one the handler saw, so an error after the
+// deadline is not mistaken for a canceled request.
+//
+// Routes allowed longer than timeout also get the read and write deadlines
+// of their connection moved, so the server timeouts do not cut off a large
+// upload or a streamed export the route deadline still allows.
+func Deadline(timeout time.Duration, routes map[string]time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			routeTimeout, ok := routes[c.Path()]
+			if !ok {
+				routeTimeout = timeout
+			}
+			deadline := time.Now().Add(routeTimeout)
+
+			if routeTimeout > timeout {
+				rc := http.NewResponseController(c.Response())
+				err := rc.SetReadDeadline(deadline)
+				if err == nil {
+					err = rc.SetWriteDeadline(deadline.Add(responseGrace))
+				}
+				if err != nil && !errors.Is(err, http.ErrNotSupported) {
+					slog.WarnContext(c.Request().Context(), "deadline: extend connection deadlines", "error", err)
+				}
+			}
+
+			ctx, cancel := context.WithDeadline(c.Request().Context(), deadline)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadline(t *testing.T) {
+	routes := map[string]time.Duration{"/estate/:id/drone-plan": time.Minute}
+
+	tests := []struct {
+		name         string
+		target       string
+		wait         bool
+		err          error
+		wantCode     int
+		wantDeadline time.Duration
+	}{
+		{
+			name:         "success default timeout",
+			target:       "/estate/uuid",
+			wantCode:     http.StatusOK,
+			wantDeadline: time.Second,
+		},
+		{
+			name:         "success route timeout",
+			target:       "/estate/uuid/drone-plan",
+			wantCode:     http.StatusOK,
+			wantDeadline: time.Minute,
+		},
+		{
+			name:     "error server",
+			target:   "/estate/uuid",
+			err:      errors.New(common.UtSomeError),
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "error deadline exceeded",
+			target:   "/estate/uuid",
+			wait:     true,
+			wantCode: http.StatusGatewayTimeout,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timeout := time.Second
+			if test.wait {
+				timeout = time.Millisecond
+			}
+
+			e := echo.New()
+			e.HTTPErrorHandler = helper.HTTPErrorHandler
+			e.Use(Deadline(timeout, routes))
+			var remaining time.Duration
+			handler := func(c echo.Context) error {
+				ctx := c.Request().Context()
+				if test.wait {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				if test.err != nil {
+					return test.err
+				}
+				deadline, ok := ctx.Deadline()
+				assert.True(t, ok)
+				remaining = time.Until(deadline)
+				return c.NoContent(http.StatusOK)
+			}
+			e.GET("/estate/:id", handler)
+			e.GET("/estate/:id/drone-plan", handler)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.target, nil))
+			assert.Equal(t, test.wantCode, rec.Code)
+			if test.wantDeadline != 0 {
+				assert.InDelta(t, test.wantDeadline, remaining, float64(time.Second/2))
+			}
+		})
+	}
+}
+
+func TestDeadlineExtendsWriteTimeout(t *testing.T) {
+	e := echo.New()
+	e.Use(Deadline(10*time.Millisecond, map[string]time.Duration{"/export.ndjson": time.Minute}))
+	handler := func(c echo.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return c.String(http.StatusOK, "done")
+	}
+	e.GET("/export.ndjson", handler)
+	e.GET("/estate/:id", handler)
+
+	server := httptest.NewUnstartedServer(e)
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/export.ndjson")
+	if assert.NoError(t, err) {
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, "done", string(body))
+	}
+
+	_, err = http.Get(server.URL + "/estate/uuid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Idempotency makes POST requests to routes, given as echo paths such as
+// "/estate/:id/tree", safe to retry. The first request carrying an
+// Idempotency-Key runs as usual and its response is stored for ttl;
+// repeating the key replays that response instead of running the handler
+// again. Keys are scoped to the caller and the organisation it acts for, so
+// callers never see or collide with each other's keys. A key reused for a
+// different request fails with domain.ErrIdempotencyKeyReused, and one
+// whose first request is still running with domain.ErrIdempotencyKeyInUse.
+// Server errors are not stored, so the request can be retried under the
+// same key.
+func Idempotency(repo domain.IdempotencyRepository, ttl time.Duration, routes ...string) echo.MiddlewareFunc {
+	enabled := map[string]bool{}
+	for _, route := range routes {
+		enabled[route] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(common.HeaderIdempotencyKey)
+			if key == "" || req.Method != http.MethodPost || !enabled[c.Path()] {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := helper.Now()
+			record := &domain.IdempotencyRecord{
+				Scope:       idempotencyScope(req.Context()),
+				Key:         key,
+				Token:       generateReservationToken(),
+				RequestHash: requestHash(req, body),
+				ExpiresAt:   now.Add(ttl),
+			}
+			existing, err := repo.ReserveIdempotencyKey(req.Context(), record, now)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return replay(c, record, existing)
+			}
+
+			// The outcome is recorded even when the client has gone away,
+			// since that is exactly when it will retry.
+			ctx := context.WithoutCancel(req.Context())
+			defer func() {
+				if r := recover(); r != nil {
+					release(ctx, repo, record)
+					panic(r)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			err = next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				release(ctx, repo, record)
+				return nil
+			}
+
+			record.StatusCode = status
+			record.ContentType = c.Response().Header().Get(echo.HeaderContentType)
+			record.Body = recorder.body.Bytes()
+			err = repo.SaveIdempotentResponse(ctx, record)
+			if err != nil {
+				slog.ErrorContext(ctx, "idempotency: save response", "key", key, "error", err)
+			}
+			return nil
+		}
+	}
+}
+
+func replay(c echo.Context, record, existing *domain.IdempotencyRecord) error {
+	if existing.RequestHash != record.RequestHash {
+		return domain.ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == 0 {
+		return domain.ErrIdempotencyKeyInUse
+	}
+
+	c.Response().Header().Set(common.HeaderIdempotentReplayed, "true")
+	return c.Blob(existing.StatusCode, existing.ContentType, existing.Body)
+}
+
+func release(ctx context.Context, repo domain.IdempotencyRepository, record *domain.IdempotencyRecord) {
+	err := repo.ReleaseIdempotencyKey(ctx, record)
+	if err != nil {
+		slog.ErrorContext(ctx, "idempotency: release key", "key", record.Key, "error", err)
+	}
+}
+
+// generateReservationToken marks a reservation as the one this request
+// holds.
+var generateReservationToken = uuid.NewString
+
+// idempotencyScope is the key space of the caller: the way it
+// authenticated, who it is and the organisation it acts for.
+func idempotencyScope(ctx context.Context) string {
+	scope := ""
+	if principal := domain.PrincipalFromContext(ctx); principal != nil {
+		scope = principal.Method + ":" + principal.Subject
+	}
+	return scope + "@" + domain.TenantId(ctx)
+}
+
+// requestHash identifies what a key was first used for within its scope:
+// the route and the exact body.
+func requestHash(req *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, req.Method+" "+req.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
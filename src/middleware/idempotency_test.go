@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIdempotency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mock_domain.NewMockIdempotencyRepository(ctrl)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time {
+		return now
+	}
+	tempGenerateReservationToken := generateReservationToken
+	generateReservationToken = func() string { return "token" }
+	defer func() {
+		helper.Now, generateReservationToken = tempNow, tempGenerateReservationToken
+	}()
+	scope := "@" + domain.DefaultOrganisationId
+
+	body := `{"length":6,"width":3}`
+	created := `{"code":201,"message":"Success create estate","data":{"id":"uuid"},"errors":null}
+`
+	hash := requestHash(httptest.NewRequest(http.MethodPost, "/estate", nil), []byte(body))
+
+	tests := []struct {
+		name         string
+		target       string
+		key          string
+		handlerErr   error
+		wantCode     int
+		wantResult   string
+		wantReplayed bool
+		wantCalls    int
+		mock         func()
+	}{
+		{
+			name:       "success first request stores response",
+			target:     "/estate",
+			key:        "key-1",
+			wantCode:   http.StatusCreated,
+			wantResult: created,
+			wantCalls:  1,
+			mock: func() {
+				repoMock.EXPECT().ReserveIdempotencyKey(gomock.Any(), &domain.IdempotencyRecord{
+					Scope:       scope,
+					Key:         "key-1",
+					Token:       "token",
+					RequestHash: hash,
+					ExpiresAt:   now.Add(time.Hour),
+				}, now).Return(nil, nil)
+				repoMock.EXPECT().SaveIdempotentResponse(gomock.Any(), &domain.IdempotencyRecord{
+					Scope:       scope,
+					Key:         "key-1",
+					Token:       "token",
+					RequestHash: hash,
+					StatusCode:  http.StatusCreated,
+					ContentType: echo.MIMEApplicationJSON,
+					Body:        []byte(created),
+					ExpiresAt:   now.Add(time.Hour),
+				}).Return(nil)
+			},
+		},
+		{
+			name:         "success repeated key replays response",
+			target:       "/estate",
+			key:          "key-1",
+			wantCode:     http.StatusCreated,
+			wantResult:   created,
+			wantReplayed: true,
+			mock: func() {
+				repoMock.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), now).Return(&domain.IdempotencyRecord{
+					Key:         "key-1",
+					RequestHash: hash,
+					StatusCode:  http.StatusCreated,
+					ContentType: echo.MIMEApplicationJSON,
+					Body:        []byte(created),
+				}, nil)
+			},
+		},
+		{
+			name:       "success without key",
+			target:     "/estate",
+			wantCode:   http.StatusCreated,
+			wantResult: created,
+			wantCalls:  1,
+			mock:       func() {},
+		},
+		{
+			name:       "success route without idempotency",
+			target:     "/ping",
+			key:        "key-1",
+			wantCode:   http.StatusCreated,
+			wantResult: created,
+			wantCalls:  1,
+			mock:       func() {},
+		},
+		{
+			name:     "error key reused for different request",
+			target:   "/estate",
+			key:      "key-1",
+			wantCode: http.StatusUnprocessableEntity,
+			wantResult: `{"code":422,"message":"idempotency key was used for a different request","data":null,"errors":"idempotency key was used for a different request","errorCode":"idempotency_key_reused"}
+`,
+			mock: func() {
+				repoMock.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), now).Return(&domain.IdempotencyRecord{
+					Key:         "key-1",
+					RequestHash: "other",
+					StatusCode:  http.StatusCreated,
+				}, nil)
+			},
+		},
+		{
+			name:     "error first request in progress",
+			target:   "/estate",
+			key:      "key-1",
+			wantCode: http.StatusConflict,
+			wantResult: `{"code":409,"message":"a request with this idempotency key is still in progress","data":null,"errors":"a request with this idempotency key is still in progress","errorCode":"idempotency_key_in_use"}
+`,
+			mock: func() {
+				repoMock.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), now).Return(&domain.IdempotencyRecord{
+					Key:         "key-1",
+					RequestHash: hash,
+				}, nil)
+			},
+		},
+		{
+			name:       "error handler client error is stored",
+			target:     "/estate",
+			key:        "key-2",
+			handlerErr: domain.ErrMaxSizeEstate,
+			wantCode:   http.StatusBadRequest,
+			wantResult: `{"code":400,"message":"max size of estate exceeded","data":null,"errors":"max size of estate exceeded","errorCode":"estate_size_exceeded"}
+`,
+			wantCalls: 1,
+			mock: func() {
+				repoMock.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), now).Return(nil, nil)
+				repoMock.EXPECT().SaveIdempotentResponse(gomock.Any(), gomock.Any()).
+					Do(func(_ interface{}, record *domain.IdempotencyRecord) {
+						assert.Equal(t, http.StatusBadRequest, record.StatusCode)
+					}).Return(nil)
+			},
+		},
+		{
+			name:       "error handler server error releases key",
+			target:     "/estate",
+			key:        "key-3",
+			handlerErr: errors.New(common.UtSomeError),
+			wantCode:   http.StatusInternalServerError,
+			wantResult: `{"code":500,"message":"internal server error","data":null,"errors":"internal server error","errorCode":"internal_error"}
+`,
+			wantCalls: 1,
+			mock: func() {
+				repoMock.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), now).Return(nil, nil)
+				repoMock.EXPECT().ReleaseIdempotencyKey(gomock.Any(), gomock.Any()).
+					Do(func(_ interface{}, record *domain.IdempotencyRecord) {
+						assert.Equal(t, "key-3", record.Key)
+						assert.Equal(t, "token", record.Token)
+					}).Return(nil)
+			},
+		},
+		{
+			name:     "error reserve key",
+			target:   "/estate",
+			key:      "key-4",
+			wantCode: http.StatusInternalServerError,
+			wantResult: `{"code":500,"message":"internal server error","data":null,"errors":"internal server error","errorCode":"internal_error"}
+`,
+			mock: func() {
+				repoMock.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), now).Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			handler := func(c echo.Context) error {
+				calls++
+				payload, err := io.ReadAll(c.Request().Body)
+				assert.NoError(t, err)
+				assert.Equal(t, body, string(payload))
+				if test.handlerErr != nil {
+					return test.handlerErr
+				}
+				return c.JSON(http.StatusCreated, helper.Response(http.StatusCreated, "Success create estate", map[string]string{"id": common.UtUuid}, nil))
+			}
+
+			e := echo.New()
+			e.Logger.SetOutput(io.Discard)
+			e.HTTPErrorHandler = helper.HTTPErrorHandler
+			e.Use(Idempotency(repoMock, time.Hour, "/estate"))
+			e.POST("/estate", handler)
+			e.POST("/ping", handler)
+
+			test.mock()
+
+			req := httptest.NewRequest(http.MethodPost, test.target, strings.NewReader(body))
+			req.Header.Set(common.UtContentType, common.ContentTypeJson)
+			if test.key != "" {
+				req.Header.Set(common.HeaderIdempotencyKey, test.key)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantResult, rec.Body.String())
+			assert.Equal(t, test.wantCalls, calls)
+			if test.wantReplayed {
+				assert.Equal(t, "true", rec.Header().Get(common.HeaderIdempotentReplayed))
+			} else {
+				assert.Empty(t, rec.Header().Get(common.HeaderIdempotentReplayed))
+			}
+		})
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	as := func(subject, organisationId string) context.Context {
+		ctx := domain.WithPrincipal(context.Background(), &domain.Principal{Subject: subject, Method: domain.AuthMethodApiKey})
+		return domain.WithTenant(ctx, &domain.Organisation{Id: organisationId})
+	}
+
+	assert.Equal(t, "api_key:a@acme", idempotencyScope(as("a", "acme")))
+	assert.NotEqual(t, idempotencyScope(as("a", "acme")), idempotencyScope(as("b", "acme")))
+	assert.NotEqual(t, idempotencyScope(as("a", "acme")), idempotencyScope(as("a", "globex")))
+	assert.Equal(t, "@"+domain.DefaultOrganisationId, idempotencyScope(context.Background()))
+}
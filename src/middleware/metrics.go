@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+// unmatchedRoute labels requests no route matched, so scanners probing
+// random paths cannot blow up the number of series.
+const unmatchedRoute = "unmatched"
+
+// Metrics counts every request and records its latency by method and
+// route. The route is the echo path, such as /estate/:id, not the request
+// path. Like RequestLog it renders errors itself, so the status counted is
+// the one sent; a response aborted after its status was sent is counted
+// with the status "aborted".
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			observe := func(status string) {
+				route := c.Path()
+				if route == "" {
+					route = unmatchedRoute
+				}
+				method := c.Request().Method
+				metrics.HTTPRequests.WithLabelValues(method, route, status).Inc()
+				metrics.HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+			}
+			defer recordAbort(func() { observe(abortedStatus) })
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			observe(strconv.Itoa(c.Response().Status))
+			return nil
+		}
+	}
+}
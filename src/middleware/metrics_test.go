@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		err        error
+		wantCode   int
+		wantRoute  string
+		wantStatus string
+	}{
+		{
+			name:       "success estate route",
+			method:     http.MethodGet,
+			target:     "/estate/uuid",
+			wantCode:   http.StatusOK,
+			wantRoute:  "/estate/:id",
+			wantStatus: "200",
+		},
+		{
+			name:       "success error rendered",
+			method:     http.MethodPost,
+			target:     "/estate/uuid",
+			err:        domain.ErrEstateNotFound,
+			wantCode:   http.StatusNotFound,
+			wantRoute:  "/estate/:id",
+			wantStatus: "404",
+		},
+		{
+			name:       "success server error",
+			method:     http.MethodPost,
+			target:     "/estate/uuid",
+			err:        errors.New(common.UtSomeError),
+			wantCode:   http.StatusInternalServerError,
+			wantRoute:  "/estate/:id",
+			wantStatus: "500",
+		},
+		{
+			name:       "success unmatched route",
+			method:     http.MethodGet,
+			target:     "/wp-login.php",
+			wantCode:   http.StatusNotFound,
+			wantRoute:  unmatchedRoute,
+			wantStatus: "404",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = helper.HTTPErrorHandler
+			e.Use(Metrics())
+			handler := func(c echo.Context) error {
+				if test.err != nil {
+					return test.err
+				}
+				return c.NoContent(http.StatusOK)
+			}
+			e.GET("/estate/:id", handler)
+			e.POST("/estate/:id", handler)
+
+			counter := metrics.HTTPRequests.WithLabelValues(test.method, test.wantRoute, test.wantStatus)
+			before := testutil.ToFloat64(counter)
+
+			req := httptest.NewRequest(test.method, test.target, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, before+1, testutil.ToFloat64(counter))
+		})
+	}
+}
+
+func TestMetricsAborted(t *testing.T) {
+	e := echo.New()
+	e.Use(Metrics())
+	e.GET("/export.ndjson", func(c echo.Context) error {
+		c.Response().WriteHeader(http.StatusOK)
+		panic(http.ErrAbortHandler)
+	})
+
+	counter := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/export.ndjson", abortedStatus)
+	before := testutil.ToFloat64(counter)
+
+	req := httptest.NewRequest(http.MethodGet, "/export.ndjson", nil)
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	})
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+// OpenAPIValidator validates requests against the operations described in
+// spec and rejects invalid ones with domain.ErrInvalidInput. Routes missing
+// from the spec are passed through untouched. When validateResponses is
+// set, responses are checked too and mismatches are logged. Bodies other
+// than JSON are streamed to and from the handler without validation.
+func OpenAPIValidator(spec *openapi3.T, validateResponses bool) (echo.MiddlewareFunc, error) {
+	// Servers hold absolute URLs; matching on them would tie routing to the
+	// host the service happens to be reached on.
+	spec.Servers = nil
+	router, err := gorillamux.NewRouter(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+	streamOptions := *options
+	streamOptions.ExcludeRequestBody = true
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route, pathParams, err := router.FindRoute(req)
+			if err != nil {
+				return next(c)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			if streamsRequest(route.Operation) {
+				input.Options = &streamOptions
+			}
+			err = openapi3filter.ValidateRequest(req.Context(), input)
+			if err != nil {
+				return requestError(err)
+			}
+
+			if !validateResponses || streamsResponse(route.Operation) {
+				return next(c)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			err = next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			err = openapi3filter.ValidateResponse(req.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 c.Response().Status,
+				Header:                 c.Response().Header(),
+				Body:                   io.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+				Options:                &openapi3filter.Options{IncludeResponseStatus: true, MultiError: true},
+			})
+			if err != nil {
+				slog.ErrorContext(req.Context(), "openapi: response does not match spec", "method", req.Method, "route", route.Path, "error", err)
+			}
+			return nil
+		}
+	}, nil
+}
+
+func requestError(err error) error {
+	details := []domain.FieldError{}
+	for _, e := range flatten(err) {
+		details = append(details, fieldErrors(e)...)
+	}
+	return domain.ErrInvalidInput.WithDetails(details).Wrap(err)
+}
+
+func flatten(err error) []error {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		errs := []error{}
+		for _, e := range multi {
+			errs = append(errs, flatten(e)...)
+		}
+		return errs
+	}
+	return []error{err}
+}
+
+func fieldErrors(err error) []domain.FieldError {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return []domain.FieldError{schemaFieldError("request", err)}
+	}
+
+	field := "body"
+	if reqErr.Parameter != nil {
+		field = reqErr.Parameter.Name
+	}
+	if reqErr.Err == nil {
+		return []domain.FieldError{{Field: field, Rule: "openapi", Message: reqErr.Reason}}
+	}
+
+	details := []domain.FieldError{}
+	for _, e := range flatten(reqErr.Err) {
+		details = append(details, schemaFieldError(field, e))
+	}
+	return details
+}
+
+func schemaFieldError(field string, err error) domain.FieldError {
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		var parseErr *openapi3filter.ParseError
+		if errors.As(err, &parseErr) {
+			return domain.FieldError{Field: field, Rule: "type", Message: parseErr.Reason}
+		}
+		return domain.FieldError{Field: field, Rule: "openapi", Message: err.Error()}
+	}
+
+	if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+		field = strings.Join(pointer, ".")
+	}
+	return domain.FieldError{
+		Field:   field,
+		Rule:    schemaErr.SchemaField,
+		Param:   schemaParam(schemaErr),
+		Message: schemaErr.Reason,
+	}
+}
+
+func schemaParam(err *openapi3.SchemaError) string {
+	schema := err.Schema
+	if schema == nil {
+		return ""
+	}
+
+	switch err.SchemaField {
+	case "minimum":
+		if schema.Min != nil {
+			return fmt.Sprint(*schema.Min)
+		}
+	case "maximum":
+		if schema.Max != nil {
+			return fmt.Sprint(*schema.Max)
+		}
+	case "type":
+		return schema.Type
+	case "enum":
+		return fmt.Sprint(schema.Enum)
+	}
+	return ""
+}
+
+// streamsRequest reports whether an operation takes something other than a
+// JSON document, such as a backup archive. Validating it would mean
+// buffering the whole body, so only its parameters are checked.
+func streamsRequest(op *openapi3.Operation) bool {
+	body := op.RequestBody
+	return body != nil && body.Value != nil && body.Value.Content.Get(echo.MIMEApplicationJSON) == nil
+}
+
+// streamsResponse reports whether an operation succeeds with something
+// other than a JSON document, such as an export. Those responses are passed
+// through rather than buffered for validation.
+func streamsResponse(op *openapi3.Operation) bool {
+	for code, ref := range op.Responses.Map() {
+		if !strings.HasPrefix(code, "2") || ref.Value == nil {
+			continue
+		}
+		if ref.Value.Content.Get(echo.MIMEApplicationJSON) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush it.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davidyunus/sawitpro-estate/generated"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubServer struct {
+	generated.ServerInterface
+}
+
+func (s *stubServer) CreateEstate(c echo.Context, params generated.CreateEstateParams) error {
+	return c.JSON(http.StatusCreated, helper.Response(http.StatusCreated, "Success create estate", map[string]string{"id": common.UtUuid}, nil))
+}
+
+func (s *stubServer) GetTreeStats(c echo.Context, id generated.EstateUuid) error {
+	return c.JSON(http.StatusOK, helper.Response(http.StatusOK, "Success get tree stats", map[string]string{"count": "many"}, nil))
+}
+
+func (s *stubServer) GetDroneFlyingDistance(c echo.Context, id generated.EstateUuid, params generated.GetDroneFlyingDistanceParams) error {
+	return c.JSON(http.StatusOK, helper.Response(http.StatusOK, "Success get drone flying distance", map[string]int{"distance": 10}, nil))
+}
+
+func (s *stubServer) ImportPalmTrees(c echo.Context, id generated.EstateUuid, params generated.ImportPalmTreesParams) error {
+	return c.JSON(http.StatusCreated, helper.Response(http.StatusCreated, "Success import palm trees", map[string]interface{}{
+		"id": id, "mode": "atomic", "total": 1, "imported": 1, "rejected": 0, "errors": []string{},
+	}, nil))
+}
+
+func (s *stubServer) ExportEstatesNdjson(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderContentType, common.ContentTypeNDJSON)
+	c.Response().WriteHeader(http.StatusOK)
+	_, err := c.Response().Write([]byte(`{"uuid":"uuid"}` + "\n"))
+	c.Response().Flush()
+	return err
+}
+
+func (s *stubServer) RestoreEstates(c echo.Context, params generated.RestoreEstatesParams) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, helper.Response(http.StatusOK, string(body), map[string]interface{}{
+		"conflict": *params.Conflict, "total": 0, "created": 0, "remapped": 0, "unchanged": 0, "skipped": 0, "estates": []string{},
+	}, nil))
+}
+
+func TestOpenAPIValidator(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		wantCode    int
+		wantResult  string
+		wantLog     string
+	}{
+		{
+			name:     "success",
+			method:   http.MethodPost,
+			target:   "/estate",
+			body:     `{"length":6,"width":3}`,
+			wantCode: http.StatusCreated,
+			wantResult: `{"code":201,"message":"Success create estate","data":{"id":"uuid"},"errors":null}
+`,
+		},
+		{
+			name:     "error body below minimum",
+			method:   http.MethodPost,
+			target:   "/estate",
+			body:     `{"length":0,"width":3}`,
+			wantCode: http.StatusBadRequest,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"length","rule":"minimum","param":"1","message":"number must be at least 1"}],"errorCode":"invalid_input"}
+`,
+		},
+		{
+			name:     "error body missing field",
+			method:   http.MethodPost,
+			target:   "/estate",
+			body:     `{"length":6}`,
+			wantCode: http.StatusBadRequest,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"width","rule":"required","message":"property \"width\" is missing"}],"errorCode":"invalid_input"}
+`,
+		},
+		{
+			name:     "error query parameter type",
+			method:   http.MethodGet,
+			target:   "/estate/uuid/drone-plan?max-distance=aaa",
+			wantCode: http.StatusBadRequest,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"max-distance","rule":"type","message":"an invalid integer"}],"errorCode":"invalid_input"}
+`,
+		},
+		{
+			name:        "success csv body",
+			method:      http.MethodPost,
+			target:      "/estate/uuid/trees:import?mode=atomic",
+			contentType: common.ContentTypeCSV,
+			body:        "x,y,height\n1,1,10\n",
+			wantCode:    http.StatusCreated,
+			wantResult: `{"code":201,"message":"Success import palm trees","data":{"errors":[],"id":"uuid","imported":1,"mode":"atomic","rejected":0,"total":1},"errors":null}
+`,
+		},
+		{
+			name:        "error query parameter enum",
+			method:      http.MethodPost,
+			target:      "/estate/uuid/trees:import?mode=partial",
+			contentType: common.ContentTypeCSV,
+			body:        "x,y,height\n1,1,10\n",
+			wantCode:    http.StatusBadRequest,
+			wantResult: `{"code":400,"message":"invalid input","data":null,"errors":[{"field":"mode","rule":"enum","param":"[atomic best-effort]","message":"value is not one of the allowed values [\"atomic\",\"best-effort\"]"}],"errorCode":"invalid_input"}
+`,
+		},
+		{
+			name:     "error response does not match spec",
+			method:   http.MethodGet,
+			target:   "/estate/uuid/stats",
+			wantCode: http.StatusOK,
+			wantResult: `{"code":200,"message":"Success get tree stats","data":{"count":"many"},"errors":null}
+`,
+			wantLog: "response does not match spec",
+		},
+		{
+			name:       "streamed response is not buffered",
+			method:     http.MethodGet,
+			target:     "/export.ndjson",
+			wantCode:   http.StatusOK,
+			wantResult: `{"uuid":"uuid"}` + "\n",
+		},
+		{
+			name:        "streamed request is passed through",
+			method:      http.MethodPost,
+			target:      "/admin/restore",
+			contentType: common.ContentTypeNDJSON,
+			body:        `{"format":"sawitpro-estate-backup","version":1}`,
+			wantCode:    http.StatusOK,
+			wantResult: `{"code":200,"message":"{\"format\":\"sawitpro-estate-backup\",\"version\":1}","data":{"conflict":"fail","created":0,"estates":[],"remapped":0,"skipped":0,"total":0,"unchanged":0},"errors":null}
+`,
+		},
+		{
+			name:     "route outside spec",
+			method:   http.MethodGet,
+			target:   "/ping",
+			wantCode: http.StatusOK,
+			wantResult: `{"code":200,"message":"Pong","data":null,"errors":null}
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := generated.GetSwagger()
+			if err != nil {
+				t.Fatal(err)
+			}
+			validator, err := OpenAPIValidator(spec, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logs := &bytes.Buffer{}
+			defaultLogger := slog.Default()
+			slog.SetDefault(helper.NewLogger(logs, slog.LevelInfo))
+			defer slog.SetDefault(defaultLogger)
+			e := echo.New()
+			e.HTTPErrorHandler = helper.HTTPErrorHandler
+			e.Use(validator)
+			generated.RegisterHandlers(e, &stubServer{})
+			e.GET("/ping", func(c echo.Context) error {
+				return c.JSON(http.StatusOK, helper.Response(http.StatusOK, "Pong", nil, nil))
+			})
+
+			req := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+			req.Header.Set(common.UtContentType, common.ContentTypeJson)
+			if test.contentType != "" {
+				req.Header.Set(common.UtContentType, test.contentType)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantResult, rec.Body.String())
+			if test.wantLog == "" {
+				assert.Empty(t, logs.String())
+			} else {
+				assert.Contains(t, logs.String(), test.wantLog)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimit limits requests with a token bucket per caller, named by
+// prefixing what key returns for the request with name. Requests key
+// returns "" for are not limited. With routes, given as echo paths such as
+// "/estate/:id/drone-plan", only those routes are limited, each with its
+// own bucket per caller.
+//
+// Limited requests fail with domain.ErrRateLimited and a Retry-After
+// header. Every response carries RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset for the bucket closest to running out. A failing
+// repository lets requests through rather than take the API down with it.
+func RateLimit(repo domain.RateLimitRepository, name string, key func(echo.Context) string, limit domain.RateLimit, routes ...string) echo.MiddlewareFunc {
+	limited := map[string]bool{}
+	for _, route := range routes {
+		limited[route] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if len(limited) > 0 && !limited[c.Path()] {
+				return next(c)
+			}
+			caller := key(c)
+			if caller == "" {
+				return next(c)
+			}
+
+			bucket := name + ":" + caller
+			if len(limited) > 0 {
+				bucket += ":" + c.Path()
+			}
+			result, err := repo.TakeRateLimitToken(c.Request().Context(), bucket, limit, helper.Now())
+			if err != nil {
+				slog.ErrorContext(c.Request().Context(), "rate limit: take token", "key", bucket, "error", err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			if remaining, err := strconv.Atoi(header.Get(common.HeaderRateLimitRemaining)); err != nil || result.Remaining <= remaining {
+				header.Set(common.HeaderRateLimitLimit, strconv.Itoa(limit.Burst))
+				header.Set(common.HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+				header.Set(common.HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+			}
+			if !result.Allowed {
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
+				return domain.ErrRateLimited
+			}
+			return next(c)
+		}
+	}
+}
+
+// ClientIP keys rate limits by the address of the client, as the
+// IPExtractor of echo finds it.
+func ClientIP(c echo.Context) string {
+	return c.RealIP()
+}
+
+// Caller keys rate limits by the principal Auth resolved, so every API key
+// and token subject has a bucket of its own.
+func Caller(c echo.Context) string {
+	principal := domain.PrincipalFromContext(c.Request().Context())
+	if principal == nil {
+		return ""
+	}
+	return principal.Method + ":" + principal.Subject
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
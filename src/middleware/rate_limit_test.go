@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mock_domain.NewMockRateLimitRepository(ctrl)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time { return now }
+	defer func() {
+		helper.Now = tempNow
+	}()
+
+	principal := &domain.Principal{Subject: "key-1", Method: domain.AuthMethodApiKey}
+	callerLimit := domain.RateLimit{Rate: 10, Burst: 20}
+	droneLimit := domain.RateLimit{Rate: 0.5, Burst: 2}
+
+	tests := []struct {
+		name       string
+		target     string
+		principal  *domain.Principal
+		wantCode   int
+		wantHeader map[string]string
+		mock       func()
+	}{
+		{
+			name:      "success",
+			target:    "/estate/uuid",
+			principal: principal,
+			wantCode:  http.StatusOK,
+			wantHeader: map[string]string{
+				common.HeaderRateLimitLimit:     "20",
+				common.HeaderRateLimitRemaining: "19",
+				common.HeaderRateLimitReset:     "1",
+			},
+			mock: func() {
+				repoMock.EXPECT().TakeRateLimitToken(gomock.Any(), "caller:api_key:key-1", callerLimit, now).
+					Return(&domain.RateLimitResult{Allowed: true, Remaining: 19, Reset: 100 * time.Millisecond}, nil)
+			},
+		},
+		{
+			name:      "success expensive route reports the closer limit",
+			target:    "/estate/uuid/drone-plan",
+			principal: principal,
+			wantCode:  http.StatusOK,
+			wantHeader: map[string]string{
+				common.HeaderRateLimitLimit:     "2",
+				common.HeaderRateLimitRemaining: "1",
+				common.HeaderRateLimitReset:     "2",
+			},
+			mock: func() {
+				repoMock.EXPECT().TakeRateLimitToken(gomock.Any(), "caller:api_key:key-1", callerLimit, now).
+					Return(&domain.RateLimitResult{Allowed: true, Remaining: 19, Reset: 100 * time.Millisecond}, nil)
+				repoMock.EXPECT().TakeRateLimitToken(gomock.Any(), "drone:api_key:key-1:/estate/:id/drone-plan", droneLimit, now).
+					Return(&domain.RateLimitResult{Allowed: true, Remaining: 1, Reset: 2 * time.Second}, nil)
+			},
+		},
+		{
+			name:      "error rate limited",
+			target:    "/estate/uuid/drone-plan",
+			principal: principal,
+			wantCode:  http.StatusTooManyRequests,
+			wantHeader: map[string]string{
+				common.HeaderRateLimitLimit:     "2",
+				common.HeaderRateLimitRemaining: "0",
+				common.HeaderRateLimitReset:     "4",
+				echo.HeaderRetryAfter:           "2",
+			},
+			mock: func() {
+				repoMock.EXPECT().TakeRateLimitToken(gomock.Any(), "caller:api_key:key-1", callerLimit, now).
+					Return(&domain.RateLimitResult{Allowed: true, Remaining: 18, Reset: 200 * time.Millisecond}, nil)
+				repoMock.EXPECT().TakeRateLimitToken(gomock.Any(), "drone:api_key:key-1:/estate/:id/drone-plan", droneLimit, now).
+					Return(&domain.RateLimitResult{RetryAfter: 1500 * time.Millisecond, Reset: 3500 * time.Millisecond}, nil)
+			},
+		},
+		{
+			name:      "error retry after at least a second",
+			target:    "/estate/uuid",
+			principal: principal,
+			wantCode:  http.StatusTooManyRequests,
+			wantHeader: map[string]string{
+				common.HeaderRateLimitLimit:     "20",
+				common.HeaderRateLimitRemaining: "0",
+				common.HeaderRateLimitReset:     "2",
+				echo.HeaderRetryAfter:           "1",
+			},
+			mock: func() {
+				repoMock.EXPECT().TakeRateLimitToken(gomock.Any(), "caller:api_key:key-1", callerLimit, now).
+					Return(&domain.RateLimitResult{Reset: 2 * time.Second}, nil)
+			},
+		},
+		{
+			name:       "success unauthenticated",
+			target:     "/estate/uuid",
+			wantCode:   http.StatusOK,
+			wantHeader: map[string]string{},
+			mock:       func() {},
+		},
+		{
+			name:       "success repository failing",
+			target:     "/estate/uuid",
+			principal:  principal,
+			wantCode:   http.StatusOK,
+			wantHeader: map[string]string{},
+			mock: func() {
+				repoMock.EXPECT().TakeRateLimitToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			e := echo.New()
+			e.HTTPErrorHandler = helper.HTTPErrorHandler
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					if test.principal != nil {
+						req := c.Request()
+						c.SetRequest(req.WithContext(domain.WithPrincipal(req.Context(), test.principal)))
+					}
+					return next(c)
+				}
+			})
+			e.Use(RateLimit(repoMock, "caller", Caller, callerLimit))
+			e.Use(RateLimit(repoMock, "drone", Caller, droneLimit, "/estate/:id/drone-plan"))
+			handler := func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}
+			e.GET("/estate/:id", handler)
+			e.GET("/estate/:id/drone-plan", handler)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.target, nil))
+
+			assert.Equal(t, test.wantCode, rec.Code)
+			for name, value := range test.wantHeader {
+				assert.Equal(t, value, rec.Header().Get(name), name)
+			}
+			if len(test.wantHeader) == 0 {
+				assert.Empty(t, rec.Header().Get(common.HeaderRateLimitRemaining))
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	req := httptest.NewRequest(http.MethodGet, "/estate", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set(echo.HeaderXForwardedFor, "192.0.2.1")
+
+	assert.Equal(t, "10.0.0.1", ClientIP(e.NewContext(req, httptest.NewRecorder())))
+}
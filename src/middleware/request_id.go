@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// maxRequestIdLength bounds the X-Request-Id a client may send; longer ones
+// are replaced by a generated id.
+const maxRequestIdLength = 255
+
+// RequestId gives every request an id, the X-Request-Id the client sent or
+// a new UUID, echoes it in the response and puts it in the request context,
+// where the audit log and every log line of the request pick it up.
+func RequestId() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			id := req.Header.Get(echo.HeaderXRequestID)
+			if id == "" || len(id) > maxRequestIdLength {
+				id = uuid.NewString()
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			c.SetRequest(req.WithContext(domain.WithRequestId(req.Context(), id)))
+			return next(c)
+		}
+	}
+}
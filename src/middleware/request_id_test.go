@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestId(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantId    string
+		generated bool
+	}{
+		{
+			name:   "success client id",
+			header: "req-1",
+			wantId: "req-1",
+		},
+		{
+			name:      "success generated",
+			generated: true,
+		},
+		{
+			name:      "success too long",
+			header:    strings.Repeat("a", maxRequestIdLength+1),
+			generated: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got string
+			e := echo.New()
+			e.Use(RequestId())
+			e.GET("/estate", func(c echo.Context) error {
+				got = domain.RequestIdFromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/estate", nil)
+			if test.header != "" {
+				req.Header.Set(echo.HeaderXRequestID, test.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, got, rec.Header().Get(echo.HeaderXRequestID))
+			if test.generated {
+				_, err := uuid.Parse(got)
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, test.wantId, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+)
+
+// RequestLog logs every request once it is served, with its route, status
+// and latency. On routes of a single estate the estate id is added to the
+// request context, so every record logged while serving the request
+// carries it. It runs after RequestId and renders errors itself, so the
+// status logged is the one sent. A response aborted after its status was
+// sent is logged as an error marked aborted.
+func RequestLog() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+			ctx := req.Context()
+			if id := c.Param("id"); id != "" {
+				ctx = helper.WithLogAttrs(ctx, slog.String("estate_id", id))
+				c.SetRequest(req.WithContext(ctx))
+			}
+
+			log := func(level slog.Level, attrs ...slog.Attr) {
+				res := c.Response()
+				attrs = append([]slog.Attr{
+					slog.String("method", req.Method),
+					slog.String("route", c.Path()),
+					slog.String("path", req.URL.Path),
+					slog.Int("status", res.Status),
+					slog.Duration("latency", time.Since(start)),
+					slog.Int64("bytes", res.Size),
+					slog.String("remote_ip", c.RealIP()),
+				}, attrs...)
+				slog.LogAttrs(ctx, level, "request", attrs...)
+			}
+			defer recordAbort(func() { log(slog.LevelError, slog.Bool("aborted", true)) })
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			level := slog.LevelInfo
+			if c.Response().Status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			log(level)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		err       error
+		wantCode  int
+		wantLevel string
+		wantLog   map[string]interface{}
+	}{
+		{
+			name:      "success estate route",
+			target:    "/estate/uuid",
+			wantCode:  http.StatusOK,
+			wantLevel: "INFO",
+			wantLog: map[string]interface{}{
+				"msg":        "request",
+				"method":     "GET",
+				"route":      "/estate/:id",
+				"path":       "/estate/uuid",
+				"status":     float64(http.StatusOK),
+				"request_id": "req-1",
+				"estate_id":  "uuid",
+			},
+		},
+		{
+			name:      "success error rendered",
+			target:    "/estate",
+			err:       domain.ErrEstateNotFound,
+			wantCode:  http.StatusNotFound,
+			wantLevel: "INFO",
+			wantLog: map[string]interface{}{
+				"msg":        "request",
+				"method":     "GET",
+				"route":      "/estate",
+				"path":       "/estate",
+				"status":     float64(http.StatusNotFound),
+				"request_id": "req-1",
+			},
+		},
+		{
+			name:      "success server error",
+			target:    "/estate",
+			err:       errors.New(common.UtSomeError),
+			wantCode:  http.StatusInternalServerError,
+			wantLevel: "ERROR",
+			wantLog: map[string]interface{}{
+				"msg":        "request",
+				"method":     "GET",
+				"route":      "/estate",
+				"path":       "/estate",
+				"status":     float64(http.StatusInternalServerError),
+				"request_id": "req-1",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logs := &bytes.Buffer{}
+			defaultLogger := slog.Default()
+			slog.SetDefault(helper.NewLogger(logs, slog.LevelInfo))
+			defer slog.SetDefault(defaultLogger)
+
+			e := echo.New()
+			e.HTTPErrorHandler = helper.HTTPErrorHandler
+			e.Use(RequestId())
+			e.Use(RequestLog())
+			handler := func(c echo.Context) error {
+				slog.InfoContext(c.Request().Context(), "handled")
+				if test.err != nil {
+					return test.err
+				}
+				return c.NoContent(http.StatusOK)
+			}
+			e.GET("/estate", handler)
+			e.GET("/estate/:id", handler)
+
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+			req.Header.Set(echo.HeaderXRequestID, "req-1")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, test.wantCode, rec.Code)
+
+			// The handler logs first and the request line comes last, after
+			// the error of a failed request.
+			lines := bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n"))
+			handled := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(lines[0], &handled))
+			assert.Equal(t, "req-1", handled["request_id"])
+			assert.Equal(t, test.wantLog["estate_id"], handled["estate_id"])
+
+			got := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(lines[len(lines)-1], &got))
+			assert.Equal(t, test.wantLevel, got["level"])
+			assert.Contains(t, got, "latency")
+			for _, key := range []string{"time", "level", "latency", "bytes", "remote_ip"} {
+				delete(got, key)
+			}
+			assert.Equal(t, test.wantLog, got)
+		})
+	}
+}
+
+func TestRequestLogAborted(t *testing.T) {
+	logs := &bytes.Buffer{}
+	defaultLogger := slog.Default()
+	slog.SetDefault(helper.NewLogger(logs, slog.LevelInfo))
+	defer slog.SetDefault(defaultLogger)
+
+	e := echo.New()
+	e.Use(RequestLog())
+	e.GET("/export.ndjson", func(c echo.Context) error {
+		c.Response().WriteHeader(http.StatusOK)
+		panic(http.ErrAbortHandler)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/export.ndjson", nil)
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	})
+
+	got := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(bytes.TrimSpace(logs.Bytes()), &got))
+	assert.Equal(t, "ERROR", got["level"])
+	assert.Equal(t, "request", got["msg"])
+	assert.Equal(t, float64(http.StatusOK), got["status"])
+	assert.Equal(t, true, got["aborted"])
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// Tenant resolves the organisation every request except those to the
+// public routes acts for, and puts it in the request context for the
+// repositories to scope their queries by. It runs after Auth: the
+// organisation is the principal's own, or the one an administrator names
+// in X-Organisation-Id.
+func Tenant(uc domain.OrganisationUsecase, public ...string) echo.MiddlewareFunc {
+	open := map[string]bool{}
+	for _, route := range public {
+		open[route] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if open[c.Path()] {
+				return next(c)
+			}
+
+			req := c.Request()
+			principal := domain.PrincipalFromContext(req.Context())
+			tenant, err := uc.ResolveTenant(req.Context(), principal, req.Header.Get(common.HeaderOrganisationId))
+			if err != nil {
+				return err
+			}
+
+			c.SetRequest(req.WithContext(domain.WithTenant(req.Context(), tenant)))
+			return next(c)
+		}
+	}
+}
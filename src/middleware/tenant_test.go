@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	organisationMock := mock_domain.NewMockOrganisationUsecase(ctrl)
+
+	principal := &domain.Principal{Subject: "bob", Method: domain.AuthMethodApiKey, Organisation: "acme"}
+	acme := &domain.Organisation{Id: "acme", Name: "Acme"}
+
+	tests := []struct {
+		name       string
+		target     string
+		header     http.Header
+		wantCode   int
+		wantTenant *domain.Organisation
+		mock       func()
+	}{
+		{
+			name:       "success",
+			target:     "/estate",
+			wantCode:   http.StatusOK,
+			wantTenant: acme,
+			mock: func() {
+				organisationMock.EXPECT().ResolveTenant(gomock.Any(), principal, "").Return(acme, nil)
+			},
+		},
+		{
+			name:       "success requested organisation",
+			target:     "/estate",
+			header:     http.Header{common.HeaderOrganisationId: {"acme"}},
+			wantCode:   http.StatusOK,
+			wantTenant: acme,
+			mock: func() {
+				organisationMock.EXPECT().ResolveTenant(gomock.Any(), principal, "acme").Return(acme, nil)
+			},
+		},
+		{
+			name:     "success public route",
+			target:   "/ping",
+			wantCode: http.StatusOK,
+			mock:     func() {},
+		},
+		{
+			name:     "error another organisation",
+			target:   "/estate",
+			header:   http.Header{common.HeaderOrganisationId: {"globex"}},
+			wantCode: http.StatusForbidden,
+			mock: func() {
+				organisationMock.EXPECT().ResolveTenant(gomock.Any(), principal, "globex").Return(nil, domain.ErrForbidden)
+			},
+		},
+		{
+			name:     "error unknown organisation",
+			target:   "/estate",
+			wantCode: http.StatusNotFound,
+			mock: func() {
+				organisationMock.EXPECT().ResolveTenant(gomock.Any(), principal, "").Return(nil, domain.ErrOrganisationNotFound)
+			},
+		},
+		{
+			name:     "error lookup",
+			target:   "/estate",
+			wantCode: http.StatusInternalServerError,
+			mock: func() {
+				organisationMock.EXPECT().ResolveTenant(gomock.Any(), principal, "").Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			var got *domain.Organisation
+			e := echo.New()
+			e.HTTPErrorHandler = helper.HTTPErrorHandler
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					req := c.Request()
+					c.SetRequest(req.WithContext(domain.WithPrincipal(req.Context(), principal)))
+					return next(c)
+				}
+			})
+			e.Use(Tenant(organisationMock, "/ping"))
+			handler := func(c echo.Context) error {
+				got = domain.TenantFromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			}
+			e.GET("/estate", handler)
+			e.GET("/ping", handler)
+
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+			for name, values := range test.header {
+				req.Header.Set(name, values[0])
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantTenant, got)
+		})
+	}
+}
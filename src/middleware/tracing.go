@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Tracing starts the server span of every request, continuing the trace of
+// the caller when the request carries W3C trace context. The span is named
+// after the method and route, and carries the estate id on routes of a
+// single estate. Like RequestLog it renders errors itself, so the status
+// recorded is the one sent; server errors and responses aborted after their
+// status was sent mark the span as failed.
+func Tracing() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := c.Path()
+			if route == "" {
+				route = unmatchedRoute
+			}
+			ctx, span := tracing.StartServer(ctx, req.Method+" "+route,
+				semconv.HTTPRequestMethodKey.String(req.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(req.URL.Path),
+			)
+			defer span.End()
+			if id := c.Param("id"); id != "" {
+				span.SetAttributes(tracing.EstateId(id))
+			}
+			c.SetRequest(req.WithContext(ctx))
+			// The span records the panic itself when it ends.
+			defer recordAbort(func() {
+				span.SetAttributes(semconv.HTTPResponseStatusCode(c.Response().Status))
+				span.SetStatus(codes.Error, "response aborted")
+			})
+
+			err := next(c)
+			if err != nil {
+				span.RecordError(err)
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return nil
+		}
+	}
+}
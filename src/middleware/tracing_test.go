@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name        string
+		target      string
+		traceparent string
+		err         error
+		wantCode    int
+		wantName    string
+		wantAttrs   []attribute.KeyValue
+		wantStatus  codes.Code
+		wantTraceId string
+	}{
+		{
+			name:        "success continues trace",
+			target:      "/estate/uuid",
+			traceparent: traceparent,
+			wantCode:    http.StatusOK,
+			wantName:    "GET /estate/:id",
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("http.route", "/estate/:id"),
+				attribute.String("estate.id", "uuid"),
+				attribute.Int("http.response.status_code", http.StatusOK),
+			},
+			wantStatus:  codes.Unset,
+			wantTraceId: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:     "success client error",
+			target:   "/estate/uuid",
+			err:      domain.ErrEstateNotFound,
+			wantCode: http.StatusNotFound,
+			wantName: "GET /estate/:id",
+			wantAttrs: []attribute.KeyValue{
+				attribute.Int("http.response.status_code", http.StatusNotFound),
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name:     "success server error",
+			target:   "/estate/uuid",
+			err:      errors.New(common.UtSomeError),
+			wantCode: http.StatusInternalServerError,
+			wantName: "GET /estate/:id",
+			wantAttrs: []attribute.KeyValue{
+				attribute.Int("http.response.status_code", http.StatusInternalServerError),
+			},
+			wantStatus: codes.Error,
+		},
+		{
+			name:     "success unmatched route",
+			target:   "/wp-login.php",
+			wantCode: http.StatusNotFound,
+			wantName: "GET unmatched",
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("url.path", "/wp-login.php"),
+			},
+			wantStatus: codes.Unset,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			defaultProvider := otel.GetTracerProvider()
+			otel.SetTracerProvider(provider)
+			defer otel.SetTracerProvider(defaultProvider)
+			otel.SetTextMapPropagator(propagation.TraceContext{})
+
+			e := echo.New()
+			e.HTTPErrorHandler = helper.HTTPErrorHandler
+			e.Use(Tracing())
+			var handled trace.SpanContext
+			e.GET("/estate/:id", func(c echo.Context) error {
+				handled = trace.SpanContextFromContext(c.Request().Context())
+				if test.err != nil {
+					return test.err
+				}
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+			if test.traceparent != "" {
+				req.Header.Set("traceparent", test.traceparent)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, test.wantCode, rec.Code)
+
+			spans := recorder.Ended()
+			if !assert.Len(t, spans, 1) {
+				return
+			}
+			span := spans[0]
+			assert.Equal(t, test.wantName, span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Subset(t, span.Attributes(), test.wantAttrs)
+			assert.Equal(t, test.wantStatus, span.Status().Code)
+			if test.wantTraceId != "" {
+				assert.Equal(t, test.wantTraceId, span.SpanContext().TraceID().String())
+				assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+			}
+			if handled.IsValid() {
+				assert.Equal(t, span.SpanContext().SpanID(), handled.SpanID())
+			}
+		})
+	}
+}
+
+func TestTracingAborted(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defaultProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(defaultProvider)
+
+	e := echo.New()
+	e.Use(Tracing())
+	e.GET("/export.ndjson", func(c echo.Context) error {
+		c.Response().WriteHeader(http.StatusOK)
+		panic(http.ErrAbortHandler)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/export.ndjson", nil)
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	})
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+	assert.Len(t, spans[0].Events(), 1)
+}
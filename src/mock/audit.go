@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: src/domain/audit.go
+//
+// Generated by this command:
+//
+//	mockgen -source=src/domain/audit.go -destination=src/mock/audit.go
+//
+
+// Package mock_domain is a generated GoMock package.
+package mock_domain
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/davidyunus/sawitpro-estate/src/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAuditUsecase is a mock of AuditUsecase interface.
+type MockAuditUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditUsecaseMockRecorder
+}
+
+// MockAuditUsecaseMockRecorder is the mock recorder for MockAuditUsecase.
+type MockAuditUsecaseMockRecorder struct {
+	mock *MockAuditUsecase
+}
+
+// NewMockAuditUsecase creates a new mock instance.
+func NewMockAuditUsecase(ctrl *gomock.Controller) *MockAuditUsecase {
+	mock := &MockAuditUsecase{ctrl: ctrl}
+	mock.recorder = &MockAuditUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditUsecase) EXPECT() *MockAuditUsecaseMockRecorder {
+	return m.recorder
+}
+
+// ListAuditEntries mocks base method.
+func (m *MockAuditUsecase) ListAuditEntries(ctx context.Context, param *domain.ListAuditEntriesRequest) (*domain.ListAuditEntriesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEntries", ctx, param)
+	ret0, _ := ret[0].(*domain.ListAuditEntriesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEntries indicates an expected call of ListAuditEntries.
+func (mr *MockAuditUsecaseMockRecorder) ListAuditEntries(ctx, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEntries", reflect.TypeOf((*MockAuditUsecase)(nil).ListAuditEntries), ctx, param)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AppendAuditEntry mocks base method.
+func (m *MockAuditRepository) AppendAuditEntry(ctx context.Context, param *domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendAuditEntry", ctx, param)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendAuditEntry indicates an expected call of AppendAuditEntry.
+func (mr *MockAuditRepositoryMockRecorder) AppendAuditEntry(ctx, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAuditEntry", reflect.TypeOf((*MockAuditRepository)(nil).AppendAuditEntry), ctx, param)
+}
+
+// ListAuditEntries mocks base method.
+func (m *MockAuditRepository) ListAuditEntries(ctx context.Context, filter *domain.AuditFilter, after int64, limit int) ([]domain.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEntries", ctx, filter, after, limit)
+	ret0, _ := ret[0].([]domain.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEntries indicates an expected call of ListAuditEntries.
+func (mr *MockAuditRepositoryMockRecorder) ListAuditEntries(ctx, filter, after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEntries", reflect.TypeOf((*MockAuditRepository)(nil).ListAuditEntries), ctx, filter, after, limit)
+}
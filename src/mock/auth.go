@@ -0,0 +1,198 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: src/domain/auth.go
+//
+// Generated by this command:
+//
+//	mockgen -source=src/domain/auth.go -destination=src/mock/auth.go
+//
+
+// Package mock_domain is a generated GoMock package.
+package mock_domain
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/davidyunus/sawitpro-estate/src/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAuthUsecase is a mock of AuthUsecase interface.
+type MockAuthUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthUsecaseMockRecorder
+}
+
+// MockAuthUsecaseMockRecorder is the mock recorder for MockAuthUsecase.
+type MockAuthUsecaseMockRecorder struct {
+	mock *MockAuthUsecase
+}
+
+// NewMockAuthUsecase creates a new mock instance.
+func NewMockAuthUsecase(ctrl *gomock.Controller) *MockAuthUsecase {
+	mock := &MockAuthUsecase{ctrl: ctrl}
+	mock.recorder = &MockAuthUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthUsecase) EXPECT() *MockAuthUsecaseMockRecorder {
+	return m.recorder
+}
+
+// AuthenticateApiKey mocks base method.
+func (m *MockAuthUsecase) AuthenticateApiKey(ctx context.Context, key string) (*domain.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateApiKey", ctx, key)
+	ret0, _ := ret[0].(*domain.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateApiKey indicates an expected call of AuthenticateApiKey.
+func (mr *MockAuthUsecaseMockRecorder) AuthenticateApiKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateApiKey", reflect.TypeOf((*MockAuthUsecase)(nil).AuthenticateApiKey), ctx, key)
+}
+
+// AuthenticateToken mocks base method.
+func (m *MockAuthUsecase) AuthenticateToken(ctx context.Context, token string) (*domain.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateToken", ctx, token)
+	ret0, _ := ret[0].(*domain.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateToken indicates an expected call of AuthenticateToken.
+func (mr *MockAuthUsecaseMockRecorder) AuthenticateToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateToken", reflect.TypeOf((*MockAuthUsecase)(nil).AuthenticateToken), ctx, token)
+}
+
+// CreateApiKey mocks base method.
+func (m *MockAuthUsecase) CreateApiKey(ctx context.Context, param *domain.CreateApiKeyRequest) (*domain.CreateApiKeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApiKey", ctx, param)
+	ret0, _ := ret[0].(*domain.CreateApiKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApiKey indicates an expected call of CreateApiKey.
+func (mr *MockAuthUsecaseMockRecorder) CreateApiKey(ctx, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApiKey", reflect.TypeOf((*MockAuthUsecase)(nil).CreateApiKey), ctx, param)
+}
+
+// ListApiKeys mocks base method.
+func (m *MockAuthUsecase) ListApiKeys(ctx context.Context) ([]domain.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApiKeys", ctx)
+	ret0, _ := ret[0].([]domain.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApiKeys indicates an expected call of ListApiKeys.
+func (mr *MockAuthUsecaseMockRecorder) ListApiKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApiKeys", reflect.TypeOf((*MockAuthUsecase)(nil).ListApiKeys), ctx)
+}
+
+// RevokeApiKey mocks base method.
+func (m *MockAuthUsecase) RevokeApiKey(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeApiKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeApiKey indicates an expected call of RevokeApiKey.
+func (mr *MockAuthUsecaseMockRecorder) RevokeApiKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeApiKey", reflect.TypeOf((*MockAuthUsecase)(nil).RevokeApiKey), ctx, id)
+}
+
+// MockApiKeyRepository is a mock of ApiKeyRepository interface.
+type MockApiKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockApiKeyRepositoryMockRecorder
+}
+
+// MockApiKeyRepositoryMockRecorder is the mock recorder for MockApiKeyRepository.
+type MockApiKeyRepositoryMockRecorder struct {
+	mock *MockApiKeyRepository
+}
+
+// NewMockApiKeyRepository creates a new mock instance.
+func NewMockApiKeyRepository(ctrl *gomock.Controller) *MockApiKeyRepository {
+	mock := &MockApiKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockApiKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockApiKeyRepository) EXPECT() *MockApiKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateApiKey mocks base method.
+func (m *MockApiKeyRepository) CreateApiKey(ctx context.Context, param *domain.ApiKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApiKey", ctx, param)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateApiKey indicates an expected call of CreateApiKey.
+func (mr *MockApiKeyRepositoryMockRecorder) CreateApiKey(ctx, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApiKey", reflect.TypeOf((*MockApiKeyRepository)(nil).CreateApiKey), ctx, param)
+}
+
+// GetApiKeyByHash mocks base method.
+func (m *MockApiKeyRepository) GetApiKeyByHash(ctx context.Context, hash string) (*domain.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiKeyByHash", ctx, hash)
+	ret0, _ := ret[0].(*domain.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiKeyByHash indicates an expected call of GetApiKeyByHash.
+func (mr *MockApiKeyRepositoryMockRecorder) GetApiKeyByHash(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiKeyByHash", reflect.TypeOf((*MockApiKeyRepository)(nil).GetApiKeyByHash), ctx, hash)
+}
+
+// ListApiKeys mocks base method.
+func (m *MockApiKeyRepository) ListApiKeys(ctx context.Context) ([]domain.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApiKeys", ctx)
+	ret0, _ := ret[0].([]domain.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApiKeys indicates an expected call of ListApiKeys.
+func (mr *MockApiKeyRepositoryMockRecorder) ListApiKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApiKeys", reflect.TypeOf((*MockApiKeyRepository)(nil).ListApiKeys), ctx)
+}
+
+// RevokeApiKey mocks base method.
+func (m *MockApiKeyRepository) RevokeApiKey(ctx context.Context, id string, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeApiKey", ctx, id, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeApiKey indicates an expected call of RevokeApiKey.
+func (mr *MockApiKeyRepositoryMockRecorder) RevokeApiKey(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeApiKey", reflect.TypeOf((*MockApiKeyRepository)(nil).RevokeApiKey), ctx, id, at)
+}
@@ -55,6 +55,49 @@ func (mr *MockEstateUsecaseMockRecorder) CreateEstate(ctx, param any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEstate", reflect.TypeOf((*MockEstateUsecase)(nil).CreateEstate), ctx, param)
 }
 
+// ExportEstates mocks base method.
+func (m *MockEstateUsecase) ExportEstates(ctx context.Context, fn func(domain.ExportEstate) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportEstates", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportEstates indicates an expected call of ExportEstates.
+func (mr *MockEstateUsecaseMockRecorder) ExportEstates(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportEstates", reflect.TypeOf((*MockEstateUsecase)(nil).ExportEstates), ctx, fn)
+}
+
+// ExportPalmTrees mocks base method.
+func (m *MockEstateUsecase) ExportPalmTrees(ctx context.Context, id string, fn func(domain.EstateExportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportPalmTrees", ctx, id, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportPalmTrees indicates an expected call of ExportPalmTrees.
+func (mr *MockEstateUsecaseMockRecorder) ExportPalmTrees(ctx, id, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportPalmTrees", reflect.TypeOf((*MockEstateUsecase)(nil).ExportPalmTrees), ctx, id, fn)
+}
+
+// FindOutOfBoundsPalmTrees mocks base method.
+func (m *MockEstateUsecase) FindOutOfBoundsPalmTrees(ctx context.Context) (*domain.FindOutOfBoundsPalmTreesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOutOfBoundsPalmTrees", ctx)
+	ret0, _ := ret[0].(*domain.FindOutOfBoundsPalmTreesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOutOfBoundsPalmTrees indicates an expected call of FindOutOfBoundsPalmTrees.
+func (mr *MockEstateUsecaseMockRecorder) FindOutOfBoundsPalmTrees(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOutOfBoundsPalmTrees", reflect.TypeOf((*MockEstateUsecase)(nil).FindOutOfBoundsPalmTrees), ctx)
+}
+
 // GetDroneFlyingDistance mocks base method.
 func (m *MockEstateUsecase) GetDroneFlyingDistance(ctx context.Context, id string, maxDistance int) (*domain.GetDroneFlyingDistanceResponse, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +113,21 @@ func (mr *MockEstateUsecaseMockRecorder) GetDroneFlyingDistance(ctx, id, maxDist
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDroneFlyingDistance", reflect.TypeOf((*MockEstateUsecase)(nil).GetDroneFlyingDistance), ctx, id, maxDistance)
 }
 
+// GetEstateByExternalRef mocks base method.
+func (m *MockEstateUsecase) GetEstateByExternalRef(ctx context.Context, ref string) (*domain.Estate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEstateByExternalRef", ctx, ref)
+	ret0, _ := ret[0].(*domain.Estate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEstateByExternalRef indicates an expected call of GetEstateByExternalRef.
+func (mr *MockEstateUsecaseMockRecorder) GetEstateByExternalRef(ctx, ref any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEstateByExternalRef", reflect.TypeOf((*MockEstateUsecase)(nil).GetEstateByExternalRef), ctx, ref)
+}
+
 // GetTreeStats mocks base method.
 func (m *MockEstateUsecase) GetTreeStats(ctx context.Context, id string) (*domain.GetTreeStatsResponse, error) {
 	m.ctrl.T.Helper()
@@ -85,6 +143,66 @@ func (mr *MockEstateUsecaseMockRecorder) GetTreeStats(ctx, id any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeStats", reflect.TypeOf((*MockEstateUsecase)(nil).GetTreeStats), ctx, id)
 }
 
+// GrantEstatePermission mocks base method.
+func (m *MockEstateUsecase) GrantEstatePermission(ctx context.Context, id, subject string, param *domain.GrantEstatePermissionRequest) (*domain.GrantEstatePermissionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantEstatePermission", ctx, id, subject, param)
+	ret0, _ := ret[0].(*domain.GrantEstatePermissionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantEstatePermission indicates an expected call of GrantEstatePermission.
+func (mr *MockEstateUsecaseMockRecorder) GrantEstatePermission(ctx, id, subject, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantEstatePermission", reflect.TypeOf((*MockEstateUsecase)(nil).GrantEstatePermission), ctx, id, subject, param)
+}
+
+// ImportPalmTrees mocks base method.
+func (m *MockEstateUsecase) ImportPalmTrees(ctx context.Context, id string, param *domain.ImportPalmTreesRequest) (*domain.ImportPalmTreesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportPalmTrees", ctx, id, param)
+	ret0, _ := ret[0].(*domain.ImportPalmTreesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportPalmTrees indicates an expected call of ImportPalmTrees.
+func (mr *MockEstateUsecaseMockRecorder) ImportPalmTrees(ctx, id, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportPalmTrees", reflect.TypeOf((*MockEstateUsecase)(nil).ImportPalmTrees), ctx, id, param)
+}
+
+// ListEstatePermissions mocks base method.
+func (m *MockEstateUsecase) ListEstatePermissions(ctx context.Context, id string) (*domain.ListEstatePermissionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEstatePermissions", ctx, id)
+	ret0, _ := ret[0].(*domain.ListEstatePermissionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEstatePermissions indicates an expected call of ListEstatePermissions.
+func (mr *MockEstateUsecaseMockRecorder) ListEstatePermissions(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEstatePermissions", reflect.TypeOf((*MockEstateUsecase)(nil).ListEstatePermissions), ctx, id)
+}
+
+// ListPalmTrees mocks base method.
+func (m *MockEstateUsecase) ListPalmTrees(ctx context.Context, id string, param *domain.ListPalmTreesRequest) (*domain.ListPalmTreesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPalmTrees", ctx, id, param)
+	ret0, _ := ret[0].(*domain.ListPalmTreesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPalmTrees indicates an expected call of ListPalmTrees.
+func (mr *MockEstateUsecaseMockRecorder) ListPalmTrees(ctx, id, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPalmTrees", reflect.TypeOf((*MockEstateUsecase)(nil).ListPalmTrees), ctx, id, param)
+}
+
 // PlantPalmTree mocks base method.
 func (m *MockEstateUsecase) PlantPalmTree(ctx context.Context, id string, param *domain.PalmTree) (*domain.PlantPalmTreeResponse, error) {
 	m.ctrl.T.Helper()
@@ -100,6 +218,50 @@ func (mr *MockEstateUsecaseMockRecorder) PlantPalmTree(ctx, id, param any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlantPalmTree", reflect.TypeOf((*MockEstateUsecase)(nil).PlantPalmTree), ctx, id, param)
 }
 
+// PutEstate mocks base method.
+func (m *MockEstateUsecase) PutEstate(ctx context.Context, id string, param *domain.Estate) (*domain.PutEstateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutEstate", ctx, id, param)
+	ret0, _ := ret[0].(*domain.PutEstateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutEstate indicates an expected call of PutEstate.
+func (mr *MockEstateUsecaseMockRecorder) PutEstate(ctx, id, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutEstate", reflect.TypeOf((*MockEstateUsecase)(nil).PutEstate), ctx, id, param)
+}
+
+// RestoreEstates mocks base method.
+func (m *MockEstateUsecase) RestoreEstates(ctx context.Context, param *domain.RestoreEstatesRequest) (*domain.RestoreEstatesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreEstates", ctx, param)
+	ret0, _ := ret[0].(*domain.RestoreEstatesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreEstates indicates an expected call of RestoreEstates.
+func (mr *MockEstateUsecaseMockRecorder) RestoreEstates(ctx, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreEstates", reflect.TypeOf((*MockEstateUsecase)(nil).RestoreEstates), ctx, param)
+}
+
+// RevokeEstatePermission mocks base method.
+func (m *MockEstateUsecase) RevokeEstatePermission(ctx context.Context, id, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeEstatePermission", ctx, id, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeEstatePermission indicates an expected call of RevokeEstatePermission.
+func (mr *MockEstateUsecaseMockRecorder) RevokeEstatePermission(ctx, id, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeEstatePermission", reflect.TypeOf((*MockEstateUsecase)(nil).RevokeEstatePermission), ctx, id, subject)
+}
+
 // MockEstateRepository is a mock of EstateRepository interface.
 type MockEstateRepository struct {
 	ctrl     *gomock.Controller
@@ -137,6 +299,35 @@ func (mr *MockEstateRepositoryMockRecorder) CreateEstate(ctx, param any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEstate", reflect.TypeOf((*MockEstateRepository)(nil).CreateEstate), ctx, param)
 }
 
+// ExportEstates mocks base method.
+func (m *MockEstateRepository) ExportEstates(ctx context.Context, id string, fn func(domain.EstateExportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportEstates", ctx, id, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportEstates indicates an expected call of ExportEstates.
+func (mr *MockEstateRepositoryMockRecorder) ExportEstates(ctx, id, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportEstates", reflect.TypeOf((*MockEstateRepository)(nil).ExportEstates), ctx, id, fn)
+}
+
+// GetEstateByExternalRef mocks base method.
+func (m *MockEstateRepository) GetEstateByExternalRef(ctx context.Context, ref string) (*domain.Estate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEstateByExternalRef", ctx, ref)
+	ret0, _ := ret[0].(*domain.Estate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEstateByExternalRef indicates an expected call of GetEstateByExternalRef.
+func (mr *MockEstateRepositoryMockRecorder) GetEstateByExternalRef(ctx, ref any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEstateByExternalRef", reflect.TypeOf((*MockEstateRepository)(nil).GetEstateByExternalRef), ctx, ref)
+}
+
 // GetEstateByUuid mocks base method.
 func (m *MockEstateRepository) GetEstateByUuid(ctx context.Context, id string) (*domain.Estate, error) {
 	m.ctrl.T.Helper()
@@ -151,3 +342,47 @@ func (mr *MockEstateRepositoryMockRecorder) GetEstateByUuid(ctx, id any) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEstateByUuid", reflect.TypeOf((*MockEstateRepository)(nil).GetEstateByUuid), ctx, id)
 }
+
+// LockEstate mocks base method.
+func (m *MockEstateRepository) LockEstate(ctx context.Context, id string) (*domain.Estate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockEstate", ctx, id)
+	ret0, _ := ret[0].(*domain.Estate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockEstate indicates an expected call of LockEstate.
+func (mr *MockEstateRepositoryMockRecorder) LockEstate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockEstate", reflect.TypeOf((*MockEstateRepository)(nil).LockEstate), ctx, id)
+}
+
+// RestoreEstate mocks base method.
+func (m *MockEstateRepository) RestoreEstate(ctx context.Context, param *domain.ExportEstate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreEstate", ctx, param)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreEstate indicates an expected call of RestoreEstate.
+func (mr *MockEstateRepositoryMockRecorder) RestoreEstate(ctx, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreEstate", reflect.TypeOf((*MockEstateRepository)(nil).RestoreEstate), ctx, param)
+}
+
+// UpsertEstate mocks base method.
+func (m *MockEstateRepository) UpsertEstate(ctx context.Context, param *domain.Estate) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertEstate", ctx, param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertEstate indicates an expected call of UpsertEstate.
+func (mr *MockEstateRepositoryMockRecorder) UpsertEstate(ctx, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEstate", reflect.TypeOf((*MockEstateRepository)(nil).UpsertEstate), ctx, param)
+}
@@ -0,0 +1,120 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: src/domain/health.go
+//
+// Generated by this command:
+//
+//	mockgen -source=src/domain/health.go -destination=src/mock/health.go
+//
+
+// Package mock_domain is a generated GoMock package.
+package mock_domain
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/davidyunus/sawitpro-estate/src/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHealthUsecase is a mock of HealthUsecase interface.
+type MockHealthUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthUsecaseMockRecorder
+}
+
+// MockHealthUsecaseMockRecorder is the mock recorder for MockHealthUsecase.
+type MockHealthUsecaseMockRecorder struct {
+	mock *MockHealthUsecase
+}
+
+// NewMockHealthUsecase creates a new mock instance.
+func NewMockHealthUsecase(ctrl *gomock.Controller) *MockHealthUsecase {
+	mock := &MockHealthUsecase{ctrl: ctrl}
+	mock.recorder = &MockHealthUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthUsecase) EXPECT() *MockHealthUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Drain mocks base method.
+func (m *MockHealthUsecase) Drain() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Drain")
+}
+
+// Drain indicates an expected call of Drain.
+func (mr *MockHealthUsecaseMockRecorder) Drain() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drain", reflect.TypeOf((*MockHealthUsecase)(nil).Drain))
+}
+
+// GetReadiness mocks base method.
+func (m *MockHealthUsecase) GetReadiness(ctx context.Context) (*domain.Readiness, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReadiness", ctx)
+	ret0, _ := ret[0].(*domain.Readiness)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReadiness indicates an expected call of GetReadiness.
+func (mr *MockHealthUsecaseMockRecorder) GetReadiness(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReadiness", reflect.TypeOf((*MockHealthUsecase)(nil).GetReadiness), ctx)
+}
+
+// MockHealthRepository is a mock of HealthRepository interface.
+type MockHealthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthRepositoryMockRecorder
+}
+
+// MockHealthRepositoryMockRecorder is the mock recorder for MockHealthRepository.
+type MockHealthRepositoryMockRecorder struct {
+	mock *MockHealthRepository
+}
+
+// NewMockHealthRepository creates a new mock instance.
+func NewMockHealthRepository(ctrl *gomock.Controller) *MockHealthRepository {
+	mock := &MockHealthRepository{ctrl: ctrl}
+	mock.recorder = &MockHealthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthRepository) EXPECT() *MockHealthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetSchemaVersion mocks base method.
+func (m *MockHealthRepository) GetSchemaVersion(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchemaVersion", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchemaVersion indicates an expected call of GetSchemaVersion.
+func (mr *MockHealthRepositoryMockRecorder) GetSchemaVersion(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchemaVersion", reflect.TypeOf((*MockHealthRepository)(nil).GetSchemaVersion), ctx)
+}
+
+// PingDatabase mocks base method.
+func (m *MockHealthRepository) PingDatabase(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PingDatabase", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PingDatabase indicates an expected call of PingDatabase.
+func (mr *MockHealthRepositoryMockRecorder) PingDatabase(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PingDatabase", reflect.TypeOf((*MockHealthRepository)(nil).PingDatabase), ctx)
+}
@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: src/domain/organisation.go
+//
+// Generated by this command:
+//
+//	mockgen -source=src/domain/organisation.go -destination=src/mock/organisation.go
+//
+
+// Package mock_domain is a generated GoMock package.
+package mock_domain
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/davidyunus/sawitpro-estate/src/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrganisationUsecase is a mock of OrganisationUsecase interface.
+type MockOrganisationUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganisationUsecaseMockRecorder
+}
+
+// MockOrganisationUsecaseMockRecorder is the mock recorder for MockOrganisationUsecase.
+type MockOrganisationUsecaseMockRecorder struct {
+	mock *MockOrganisationUsecase
+}
+
+// NewMockOrganisationUsecase creates a new mock instance.
+func NewMockOrganisationUsecase(ctrl *gomock.Controller) *MockOrganisationUsecase {
+	mock := &MockOrganisationUsecase{ctrl: ctrl}
+	mock.recorder = &MockOrganisationUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganisationUsecase) EXPECT() *MockOrganisationUsecaseMockRecorder {
+	return m.recorder
+}
+
+// ListOrganisations mocks base method.
+func (m *MockOrganisationUsecase) ListOrganisations(ctx context.Context) ([]domain.Organisation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganisations", ctx)
+	ret0, _ := ret[0].([]domain.Organisation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganisations indicates an expected call of ListOrganisations.
+func (mr *MockOrganisationUsecaseMockRecorder) ListOrganisations(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganisations", reflect.TypeOf((*MockOrganisationUsecase)(nil).ListOrganisations), ctx)
+}
+
+// PutOrganisation mocks base method.
+func (m *MockOrganisationUsecase) PutOrganisation(ctx context.Context, id string, param *domain.Organisation) (*domain.PutOrganisationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutOrganisation", ctx, id, param)
+	ret0, _ := ret[0].(*domain.PutOrganisationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutOrganisation indicates an expected call of PutOrganisation.
+func (mr *MockOrganisationUsecaseMockRecorder) PutOrganisation(ctx, id, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutOrganisation", reflect.TypeOf((*MockOrganisationUsecase)(nil).PutOrganisation), ctx, id, param)
+}
+
+// ResolveTenant mocks base method.
+func (m *MockOrganisationUsecase) ResolveTenant(ctx context.Context, principal *domain.Principal, requested string) (*domain.Organisation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveTenant", ctx, principal, requested)
+	ret0, _ := ret[0].(*domain.Organisation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveTenant indicates an expected call of ResolveTenant.
+func (mr *MockOrganisationUsecaseMockRecorder) ResolveTenant(ctx, principal, requested any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveTenant", reflect.TypeOf((*MockOrganisationUsecase)(nil).ResolveTenant), ctx, principal, requested)
+}
+
+// MockOrganisationRepository is a mock of OrganisationRepository interface.
+type MockOrganisationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganisationRepositoryMockRecorder
+}
+
+// MockOrganisationRepositoryMockRecorder is the mock recorder for MockOrganisationRepository.
+type MockOrganisationRepositoryMockRecorder struct {
+	mock *MockOrganisationRepository
+}
+
+// NewMockOrganisationRepository creates a new mock instance.
+func NewMockOrganisationRepository(ctrl *gomock.Controller) *MockOrganisationRepository {
+	mock := &MockOrganisationRepository{ctrl: ctrl}
+	mock.recorder = &MockOrganisationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganisationRepository) EXPECT() *MockOrganisationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetOrganisation mocks base method.
+func (m *MockOrganisationRepository) GetOrganisation(ctx context.Context, id string) (*domain.Organisation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganisation", ctx, id)
+	ret0, _ := ret[0].(*domain.Organisation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganisation indicates an expected call of GetOrganisation.
+func (mr *MockOrganisationRepositoryMockRecorder) GetOrganisation(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganisation", reflect.TypeOf((*MockOrganisationRepository)(nil).GetOrganisation), ctx, id)
+}
+
+// ListOrganisations mocks base method.
+func (m *MockOrganisationRepository) ListOrganisations(ctx context.Context) ([]domain.Organisation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganisations", ctx)
+	ret0, _ := ret[0].([]domain.Organisation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganisations indicates an expected call of ListOrganisations.
+func (mr *MockOrganisationRepositoryMockRecorder) ListOrganisations(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganisations", reflect.TypeOf((*MockOrganisationRepository)(nil).ListOrganisations), ctx)
+}
+
+// UpsertOrganisation mocks base method.
+func (m *MockOrganisationRepository) UpsertOrganisation(ctx context.Context, param *domain.Organisation) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOrganisation", ctx, param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertOrganisation indicates an expected call of UpsertOrganisation.
+func (mr *MockOrganisationRepositoryMockRecorder) UpsertOrganisation(ctx, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOrganisation", reflect.TypeOf((*MockOrganisationRepository)(nil).UpsertOrganisation), ctx, param)
+}
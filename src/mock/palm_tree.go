@@ -40,6 +40,21 @@ func (m *MockPalmTreeLocationRepository) EXPECT() *MockPalmTreeLocationRepositor
 	return m.recorder
 }
 
+// GetOutOfBoundsPalmTrees mocks base method.
+func (m *MockPalmTreeLocationRepository) GetOutOfBoundsPalmTrees(ctx context.Context) ([]domain.OutOfBoundsPalmTree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutOfBoundsPalmTrees", ctx)
+	ret0, _ := ret[0].([]domain.OutOfBoundsPalmTree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutOfBoundsPalmTrees indicates an expected call of GetOutOfBoundsPalmTrees.
+func (mr *MockPalmTreeLocationRepositoryMockRecorder) GetOutOfBoundsPalmTrees(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutOfBoundsPalmTrees", reflect.TypeOf((*MockPalmTreeLocationRepository)(nil).GetOutOfBoundsPalmTrees), ctx)
+}
+
 // GetPalmTreesByUuid mocks base method.
 func (m *MockPalmTreeLocationRepository) GetPalmTreesByUuid(ctx context.Context, id string) ([]domain.PalmTree, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +70,21 @@ func (mr *MockPalmTreeLocationRepositoryMockRecorder) GetPalmTreesByUuid(ctx, id
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPalmTreesByUuid", reflect.TypeOf((*MockPalmTreeLocationRepository)(nil).GetPalmTreesByUuid), ctx, id)
 }
 
+// ListPalmTrees mocks base method.
+func (m *MockPalmTreeLocationRepository) ListPalmTrees(ctx context.Context, id string, filter *domain.PalmTreeFilter, sort string, after int64, limit int) ([]domain.ExportPalmTree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPalmTrees", ctx, id, filter, sort, after, limit)
+	ret0, _ := ret[0].([]domain.ExportPalmTree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPalmTrees indicates an expected call of ListPalmTrees.
+func (mr *MockPalmTreeLocationRepositoryMockRecorder) ListPalmTrees(ctx, id, filter, sort, after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPalmTrees", reflect.TypeOf((*MockPalmTreeLocationRepository)(nil).ListPalmTrees), ctx, id, filter, sort, after, limit)
+}
+
 // PlantPalmTree mocks base method.
 func (m *MockPalmTreeLocationRepository) PlantPalmTree(ctx context.Context, id string, param *domain.PalmTree) error {
 	m.ctrl.T.Helper()
@@ -68,3 +98,31 @@ func (mr *MockPalmTreeLocationRepositoryMockRecorder) PlantPalmTree(ctx, id, par
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlantPalmTree", reflect.TypeOf((*MockPalmTreeLocationRepository)(nil).PlantPalmTree), ctx, id, param)
 }
+
+// PlantPalmTrees mocks base method.
+func (m *MockPalmTreeLocationRepository) PlantPalmTrees(ctx context.Context, id string, trees []domain.PalmTree) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlantPalmTrees", ctx, id, trees)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PlantPalmTrees indicates an expected call of PlantPalmTrees.
+func (mr *MockPalmTreeLocationRepositoryMockRecorder) PlantPalmTrees(ctx, id, trees any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlantPalmTrees", reflect.TypeOf((*MockPalmTreeLocationRepository)(nil).PlantPalmTrees), ctx, id, trees)
+}
+
+// RestorePalmTrees mocks base method.
+func (m *MockPalmTreeLocationRepository) RestorePalmTrees(ctx context.Context, id string, trees []domain.ExportPalmTree) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePalmTrees", ctx, id, trees)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestorePalmTrees indicates an expected call of RestorePalmTrees.
+func (mr *MockPalmTreeLocationRepositoryMockRecorder) RestorePalmTrees(ctx, id, trees any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePalmTrees", reflect.TypeOf((*MockPalmTreeLocationRepository)(nil).RestorePalmTrees), ctx, id, trees)
+}
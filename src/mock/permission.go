@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: src/domain/permission.go
+//
+// Generated by this command:
+//
+//	mockgen -source=src/domain/permission.go -destination=src/mock/permission.go
+//
+
+// Package mock_domain is a generated GoMock package.
+package mock_domain
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/davidyunus/sawitpro-estate/src/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEstatePermissionRepository is a mock of EstatePermissionRepository interface.
+type MockEstatePermissionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEstatePermissionRepositoryMockRecorder
+}
+
+// MockEstatePermissionRepositoryMockRecorder is the mock recorder for MockEstatePermissionRepository.
+type MockEstatePermissionRepositoryMockRecorder struct {
+	mock *MockEstatePermissionRepository
+}
+
+// NewMockEstatePermissionRepository creates a new mock instance.
+func NewMockEstatePermissionRepository(ctrl *gomock.Controller) *MockEstatePermissionRepository {
+	mock := &MockEstatePermissionRepository{ctrl: ctrl}
+	mock.recorder = &MockEstatePermissionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEstatePermissionRepository) EXPECT() *MockEstatePermissionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteEstatePermission mocks base method.
+func (m *MockEstatePermissionRepository) DeleteEstatePermission(ctx context.Context, id, subject string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEstatePermission", ctx, id, subject)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteEstatePermission indicates an expected call of DeleteEstatePermission.
+func (mr *MockEstatePermissionRepositoryMockRecorder) DeleteEstatePermission(ctx, id, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEstatePermission", reflect.TypeOf((*MockEstatePermissionRepository)(nil).DeleteEstatePermission), ctx, id, subject)
+}
+
+// GetEstateRole mocks base method.
+func (m *MockEstatePermissionRepository) GetEstateRole(ctx context.Context, id, subject string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEstateRole", ctx, id, subject)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEstateRole indicates an expected call of GetEstateRole.
+func (mr *MockEstatePermissionRepositoryMockRecorder) GetEstateRole(ctx, id, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEstateRole", reflect.TypeOf((*MockEstatePermissionRepository)(nil).GetEstateRole), ctx, id, subject)
+}
+
+// ListEstatePermissions mocks base method.
+func (m *MockEstatePermissionRepository) ListEstatePermissions(ctx context.Context, id string) ([]domain.EstatePermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEstatePermissions", ctx, id)
+	ret0, _ := ret[0].([]domain.EstatePermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEstatePermissions indicates an expected call of ListEstatePermissions.
+func (mr *MockEstatePermissionRepositoryMockRecorder) ListEstatePermissions(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEstatePermissions", reflect.TypeOf((*MockEstatePermissionRepository)(nil).ListEstatePermissions), ctx, id)
+}
+
+// ListSubjectPermissions mocks base method.
+func (m *MockEstatePermissionRepository) ListSubjectPermissions(ctx context.Context, subject string) ([]domain.EstatePermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectPermissions", ctx, subject)
+	ret0, _ := ret[0].([]domain.EstatePermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectPermissions indicates an expected call of ListSubjectPermissions.
+func (mr *MockEstatePermissionRepositoryMockRecorder) ListSubjectPermissions(ctx, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectPermissions", reflect.TypeOf((*MockEstatePermissionRepository)(nil).ListSubjectPermissions), ctx, subject)
+}
+
+// UpsertEstatePermission mocks base method.
+func (m *MockEstatePermissionRepository) UpsertEstatePermission(ctx context.Context, param *domain.EstatePermission) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertEstatePermission", ctx, param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertEstatePermission indicates an expected call of UpsertEstatePermission.
+func (mr *MockEstatePermissionRepositoryMockRecorder) UpsertEstatePermission(ctx, param any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEstatePermission", reflect.TypeOf((*MockEstatePermissionRepository)(nil).UpsertEstatePermission), ctx, param)
+}
@@ -0,0 +1,122 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+)
+
+type organisationRepositorySql struct {
+	conn    *sql.DB
+	manager *helper.Manager
+}
+
+func NewOrganisationRepositorySql(conn *sql.DB, manager *helper.Manager) domain.OrganisationRepository {
+	return &organisationRepositorySql{
+		conn:    conn,
+		manager: manager,
+	}
+}
+
+// querier is a *sql.DB or *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// db returns the transaction ctx carries, so an organisation is only
+// changed together with its audit entry.
+func (o *organisationRepositorySql) db(ctx context.Context) querier {
+	tx, _ := ctx.Value(o.manager.GetKey()).(*sql.Tx)
+	if tx != nil {
+		return tx
+	}
+	return o.conn
+}
+
+func (o *organisationRepositorySql) fetch(ctx context.Context, query string, args ...interface{}) ([]domain.Organisation, error) {
+	rows, err := o.db(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = rows.Close()
+		if err != nil {
+			slog.ErrorContext(ctx, "close rows", "error", err)
+		}
+	}()
+
+	result := []domain.Organisation{}
+	for rows.Next() {
+		organisation := domain.Organisation{}
+		err = rows.Scan(
+			&organisation.Id,
+			&organisation.Name,
+			&organisation.Timezone,
+			&organisation.SizePolicy.MaxArea,
+			&organisation.SizePolicy.MaxLength,
+			&organisation.SizePolicy.MaxWidth,
+			&organisation.SizePolicy.PlotSize,
+			&organisation.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, organisation)
+	}
+
+	return result, rows.Err()
+}
+
+func (o *organisationRepositorySql) GetOrganisation(ctx context.Context, id string) (*domain.Organisation, error) {
+	ctx, span := tracing.StartQuery(ctx, "organisation", "GetOrganisation", tracing.Statement(QueryGetOrganisation))
+	defer span.End()
+	defer metrics.ObserveQuery("organisation", "GetOrganisation")()
+
+	result, err := o.fetch(ctx, QueryGetOrganisation, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return &result[0], nil
+}
+
+func (o *organisationRepositorySql) ListOrganisations(ctx context.Context) ([]domain.Organisation, error) {
+	ctx, span := tracing.StartQuery(ctx, "organisation", "ListOrganisations", tracing.Statement(QueryListOrganisations))
+	defer span.End()
+	defer metrics.ObserveQuery("organisation", "ListOrganisations")()
+
+	return o.fetch(ctx, QueryListOrganisations)
+}
+
+// UpsertOrganisation sets the CreatedAt of param to the one stored, which
+// an update leaves unchanged.
+func (o *organisationRepositorySql) UpsertOrganisation(ctx context.Context, param *domain.Organisation) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "organisation", "UpsertOrganisation")
+	defer span.End()
+	defer metrics.ObserveQuery("organisation", "UpsertOrganisation")()
+
+	var created bool
+	err := o.db(ctx).QueryRowContext(ctx, QueryUpsertOrganisation,
+		param.Id,
+		param.Name,
+		param.Timezone,
+		param.SizePolicy.MaxArea,
+		param.SizePolicy.MaxLength,
+		param.SizePolicy.MaxWidth,
+		param.SizePolicy.PlotSize,
+		param.CreatedAt,
+	).Scan(&created, &param.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
@@ -0,0 +1,206 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []string{"id", "name", "timezone", "maxArea", "maxLength", "maxWidth", "plotSize", "createdAt"}
+
+func TestNewOrganisationRepositorySql(t *testing.T) {
+	assert.NotNil(t, NewOrganisationRepositorySql(nil, nil))
+}
+
+func TestGetOrganisation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &organisationRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		wantResult *domain.Organisation
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: &domain.Organisation{
+				Id:         "acme",
+				Name:       "Acme",
+				Timezone:   "Asia/Makassar",
+				SizePolicy: domain.SizePolicy{MaxLength: 20},
+				CreatedAt:  createdAt,
+			},
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetOrganisation)).WithArgs("acme").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("acme", "Acme", "Asia/Makassar", 0, 20, 0, 0, createdAt))
+			},
+		},
+		{
+			name: "success not found",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetOrganisation)).WithArgs("acme").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetOrganisation)).WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.GetOrganisation(context.Background(), "acme")
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListOrganisations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &organisationRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		wantResult []domain.Organisation
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: []domain.Organisation{
+				{Id: "acme", Name: "Acme", CreatedAt: createdAt},
+				{Id: domain.DefaultOrganisationId, Name: "Default", CreatedAt: createdAt},
+			},
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryListOrganisations)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("acme", "Acme", "", 0, 0, 0, 0, createdAt).
+						AddRow(domain.DefaultOrganisationId, "Default", "", 0, 0, 0, 0, createdAt))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryListOrganisations)).WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.ListOrganisations(context.Background())
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpsertOrganisation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &organisationRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	createdAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		wantResult    bool
+		wantCreatedAt time.Time
+		wantErr       bool
+		mock          func()
+	}{
+		{
+			name:          "success inserted",
+			wantResult:    true,
+			wantCreatedAt: now,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryUpsertOrganisation)).
+					WithArgs("acme", "Acme", "Asia/Makassar", 0, 20, 0, 0, now).
+					WillReturnRows(sqlmock.NewRows([]string{"created", "createdAt"}).AddRow(true, now))
+			},
+		},
+		{
+			name:          "success updated",
+			wantResult:    false,
+			wantCreatedAt: createdAt,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryUpsertOrganisation)).
+					WithArgs("acme", "Acme", "Asia/Makassar", 0, 20, 0, 0, now).
+					WillReturnRows(sqlmock.NewRows([]string{"created", "createdAt"}).AddRow(false, createdAt))
+			},
+		},
+		{
+			name:          "error",
+			wantErr:       true,
+			wantCreatedAt: now,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryUpsertOrganisation)).WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			param := &domain.Organisation{
+				Id:         "acme",
+				Name:       "Acme",
+				Timezone:   "Asia/Makassar",
+				SizePolicy: domain.SizePolicy{MaxLength: 20},
+				CreatedAt:  now,
+			}
+			got, err := repo.UpsertOrganisation(context.Background(), param)
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.Equal(t, test.wantCreatedAt, param.CreatedAt)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package sql
+
+const (
+	SelectTemplate = `SELECT
+		id,
+		name,
+		timezone,
+		maxArea,
+		maxLength,
+		maxWidth,
+		plotSize,
+		createdAt
+	FROM
+		organisation`
+
+	QueryGetOrganisation = SelectTemplate + `
+	WHERE
+		id = $1`
+
+	QueryListOrganisations = SelectTemplate + `
+	ORDER BY
+		id`
+
+	// QueryUpsertOrganisation reports through xmax whether the row was
+	// inserted rather than updated, and returns the creation time kept on
+	// update.
+	QueryUpsertOrganisation = `INSERT INTO organisation
+	(id, name, timezone, maxArea, maxLength, maxWidth, plotSize, createdAt)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (id) DO UPDATE SET
+		name = EXCLUDED.name,
+		timezone = EXCLUDED.timezone,
+		maxArea = EXCLUDED.maxArea,
+		maxLength = EXCLUDED.maxLength,
+		maxWidth = EXCLUDED.maxWidth,
+		plotSize = EXCLUDED.plotSize
+	RETURNING
+		xmax = 0,
+		createdAt`
+)
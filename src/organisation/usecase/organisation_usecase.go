@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+)
+
+// organisationIdPattern matches the slugs organisations are identified by.
+var organisationIdPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+type organisationUsecase struct {
+	organisationRepo domain.OrganisationRepository
+	auditRepo        domain.AuditRepository
+	transactor       domain.Transactor
+}
+
+func NewOrganisationUsecase(organisationRepo domain.OrganisationRepository, auditRepo domain.AuditRepository, transactor domain.Transactor) domain.OrganisationUsecase {
+	return &organisationUsecase{
+		organisationRepo: organisationRepo,
+		auditRepo:        auditRepo,
+		transactor:       transactor,
+	}
+}
+
+// ResolveTenant falls back to DefaultOrganisationId for principals whose
+// credentials name no organisation.
+func (o *organisationUsecase) ResolveTenant(ctx context.Context, principal *domain.Principal, requested string) (*domain.Organisation, error) {
+	if principal == nil {
+		return nil, domain.ErrUnauthorized
+	}
+
+	id := principal.Organisation
+	if id == "" {
+		id = domain.DefaultOrganisationId
+	}
+	if requested != "" && requested != id {
+		if !principal.Admin {
+			return nil, domain.ErrForbidden
+		}
+		id = requested
+	}
+
+	organisation, err := o.organisationRepo.GetOrganisation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if organisation == nil {
+		return nil, domain.ErrOrganisationNotFound
+	}
+	return organisation, nil
+}
+
+func (o *organisationUsecase) ListOrganisations(ctx context.Context) ([]domain.Organisation, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return o.organisationRepo.ListOrganisations(ctx)
+}
+
+// PutOrganisation creates the organisation under id, or replaces the name
+// and settings of the one already there.
+func (o *organisationUsecase) PutOrganisation(ctx context.Context, id string, param *domain.Organisation) (*domain.PutOrganisationResponse, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !organisationIdPattern.MatchString(id) {
+		return nil, domain.ErrInvalidInput.WithDetails([]domain.FieldError{{
+			Field:   "organisationId",
+			Rule:    "pattern",
+			Message: "organisationId must be a lowercase slug of at most 64 letters, digits and hyphens",
+		}})
+	}
+
+	organisation := domain.Organisation{
+		Id:         id,
+		Name:       param.Name,
+		Timezone:   param.Timezone,
+		SizePolicy: param.SizePolicy,
+		CreatedAt:  helper.Now(),
+	}
+	var created bool
+	err = o.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := o.organisationRepo.GetOrganisation(ctx, id)
+		if err != nil {
+			return err
+		}
+		created, err = o.organisationRepo.UpsertOrganisation(ctx, &organisation)
+		if err != nil {
+			return err
+		}
+		entry, err := domain.NewAuditEntry(ctx, domain.AuditPutOrganisation, "", existing, organisation)
+		if err != nil {
+			return err
+		}
+		// The change shows in the audit log of the organisation it affects,
+		// not in that of the administrator making it.
+		return o.auditRepo.AppendAuditEntry(domain.WithTenant(ctx, &organisation), entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PutOrganisationResponse{
+		Organisation: organisation,
+		Created:      created,
+	}, nil
+}
+
+func requireAdmin(ctx context.Context) error {
+	principal := domain.PrincipalFromContext(ctx)
+	if principal == nil {
+		return domain.ErrUnauthorized
+	}
+	if !principal.Admin {
+		return domain.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	mock_domain "github.com/davidyunus/sawitpro-estate/src/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var adminCtx = domain.WithPrincipal(context.Background(), &domain.Principal{Subject: "alice", Admin: true})
+
+func TestNewOrganisationUsecase(t *testing.T) {
+	assert.NotNil(t, NewOrganisationUsecase(nil, nil, nil))
+}
+
+func TestResolveTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	organisationRepoMock := mock_domain.NewMockOrganisationRepository(ctrl)
+	uc := NewOrganisationUsecase(organisationRepoMock, nil, nil)
+
+	acme := &domain.Organisation{Id: "acme", Name: "Acme"}
+	defaultOrganisation := &domain.Organisation{Id: domain.DefaultOrganisationId, Name: "Default"}
+
+	tests := []struct {
+		name       string
+		principal  *domain.Principal
+		requested  string
+		wantResult *domain.Organisation
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success own organisation",
+			principal:  &domain.Principal{Subject: "bob", Organisation: "acme"},
+			wantResult: acme,
+			mock: func() {
+				organisationRepoMock.EXPECT().GetOrganisation(gomock.Any(), "acme").Return(acme, nil)
+			},
+		},
+		{
+			name:       "success own organisation requested",
+			principal:  &domain.Principal{Subject: "bob", Organisation: "acme"},
+			requested:  "acme",
+			wantResult: acme,
+			mock: func() {
+				organisationRepoMock.EXPECT().GetOrganisation(gomock.Any(), "acme").Return(acme, nil)
+			},
+		},
+		{
+			name:       "success default organisation",
+			principal:  &domain.Principal{Subject: "bob"},
+			wantResult: defaultOrganisation,
+			mock: func() {
+				organisationRepoMock.EXPECT().GetOrganisation(gomock.Any(), domain.DefaultOrganisationId).Return(defaultOrganisation, nil)
+			},
+		},
+		{
+			name:       "success admin acts for another organisation",
+			principal:  &domain.Principal{Subject: "alice", Admin: true},
+			requested:  "acme",
+			wantResult: acme,
+			mock: func() {
+				organisationRepoMock.EXPECT().GetOrganisation(gomock.Any(), "acme").Return(acme, nil)
+			},
+		},
+		{
+			name:      "error another organisation",
+			principal: &domain.Principal{Subject: "bob", Organisation: "acme"},
+			requested: "globex",
+			wantErr:   domain.ErrForbidden,
+			mock:      func() {},
+		},
+		{
+			name:      "error default principal requests another organisation",
+			principal: &domain.Principal{Subject: "bob"},
+			requested: "acme",
+			wantErr:   domain.ErrForbidden,
+			mock:      func() {},
+		},
+		{
+			name:      "error unknown organisation",
+			principal: &domain.Principal{Subject: "alice", Admin: true},
+			requested: "globex",
+			wantErr:   domain.ErrOrganisationNotFound,
+			mock: func() {
+				organisationRepoMock.EXPECT().GetOrganisation(gomock.Any(), "globex").Return(nil, nil)
+			},
+		},
+		{
+			name:    "error unauthenticated",
+			wantErr: domain.ErrUnauthorized,
+			mock:    func() {},
+		},
+		{
+			name:      "error repository",
+			principal: &domain.Principal{Subject: "bob", Organisation: "acme"},
+			wantErr:   errors.New(common.UtSomeError),
+			mock: func() {
+				organisationRepoMock.EXPECT().GetOrganisation(gomock.Any(), "acme").Return(nil, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.ResolveTenant(context.Background(), test.principal, test.requested)
+			if test.wantErr != nil {
+				assert.EqualError(t, err, test.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestListOrganisations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	organisationRepoMock := mock_domain.NewMockOrganisationRepository(ctrl)
+	uc := NewOrganisationUsecase(organisationRepoMock, nil, nil)
+
+	organisations := []domain.Organisation{{Id: "acme", Name: "Acme"}}
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantResult []domain.Organisation
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success",
+			ctx:        adminCtx,
+			wantResult: organisations,
+			mock: func() {
+				organisationRepoMock.EXPECT().ListOrganisations(gomock.Any()).Return(organisations, nil)
+			},
+		},
+		{
+			name:    "error not admin",
+			ctx:     domain.WithPrincipal(context.Background(), &domain.Principal{Subject: "bob", Organisation: "acme"}),
+			wantErr: domain.ErrForbidden,
+			mock:    func() {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.ListOrganisations(test.ctx)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestPutOrganisation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	organisationRepoMock := mock_domain.NewMockOrganisationRepository(ctrl)
+	auditRepoMock := mock_domain.NewMockAuditRepository(ctrl)
+	transactorMock := mock_domain.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	uc := NewOrganisationUsecase(organisationRepoMock, auditRepoMock, transactorMock)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tempNow := helper.Now
+	helper.Now = func() time.Time {
+		return now
+	}
+	defer func() {
+		helper.Now = tempNow
+	}()
+
+	param := &domain.Organisation{Name: "Acme", Timezone: "Asia/Makassar", SizePolicy: domain.SizePolicy{MaxLength: 20}}
+	stored := domain.Organisation{Id: "acme", Name: "Acme", Timezone: "Asia/Makassar", SizePolicy: domain.SizePolicy{MaxLength: 20}, CreatedAt: now}
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		id         string
+		wantResult *domain.PutOrganisationResponse
+		wantErr    error
+		mock       func()
+	}{
+		{
+			name:       "success created",
+			ctx:        adminCtx,
+			id:         "acme",
+			wantResult: &domain.PutOrganisationResponse{Organisation: stored, Created: true},
+			mock: func() {
+				organisationRepoMock.EXPECT().GetOrganisation(gomock.Any(), "acme").Return(nil, nil)
+				organisationRepoMock.EXPECT().UpsertOrganisation(gomock.Any(), &stored).Return(true, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, entry *domain.AuditEntry) error {
+						assert.Equal(t, "acme", domain.TenantId(ctx))
+						assert.Equal(t, domain.AuditPutOrganisation, entry.Action)
+						assert.Nil(t, entry.Before)
+						assert.NotNil(t, entry.After)
+						return nil
+					})
+			},
+		},
+		{
+			name:       "success replaced",
+			ctx:        adminCtx,
+			id:         "acme",
+			wantResult: &domain.PutOrganisationResponse{Organisation: stored},
+			mock: func() {
+				organisationRepoMock.EXPECT().GetOrganisation(gomock.Any(), "acme").Return(&domain.Organisation{Id: "acme", Name: "Old"}, nil)
+				organisationRepoMock.EXPECT().UpsertOrganisation(gomock.Any(), &stored).Return(false, nil)
+				auditRepoMock.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *domain.AuditEntry) error {
+						assert.Contains(t, string(entry.Before), `"name":"Old"`)
+						assert.Contains(t, string(entry.After), `"name":"Acme"`)
+						return nil
+					})
+			},
+		},
+		{
+			name:    "error invalid id",
+			ctx:     adminCtx,
+			id:      "Acme Corp",
+			wantErr: domain.ErrInvalidInput,
+			mock:    func() {},
+		},
+		{
+			name:    "error not admin",
+			ctx:     domain.WithPrincipal(context.Background(), &domain.Principal{Subject: "bob", Organisation: "acme"}),
+			id:      "acme",
+			wantErr: domain.ErrForbidden,
+			mock:    func() {},
+		},
+		{
+			name:    "error repository",
+			ctx:     adminCtx,
+			id:      "acme",
+			wantErr: errors.New(common.UtSomeError),
+			mock: func() {
+				organisationRepoMock.EXPECT().GetOrganisation(gomock.Any(), "acme").Return(nil, nil)
+				organisationRepoMock.EXPECT().UpsertOrganisation(gomock.Any(), &stored).Return(false, errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := uc.PutOrganisation(test.ctx, test.id, param)
+			if test.wantErr != nil {
+				assert.EqualError(t, err, test.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
@@ -3,11 +3,16 @@ package sql
 import (
 	"context"
 	"database/sql"
-	"time"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 
 	"github.com/davidyunus/sawitpro-estate/src/domain"
 	"github.com/davidyunus/sawitpro-estate/src/helper"
-	"github.com/labstack/gommon/log"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+	"github.com/lib/pq"
 )
 
 type palmTreeLocationRepositorySql struct {
@@ -23,7 +28,17 @@ func NewPalmTreeRepositorySql(conn *sql.DB, manager *helper.Manager) domain.Palm
 }
 
 func (p *palmTreeLocationRepositorySql) fetch(ctx context.Context, query string, args ...interface{}) ([]domain.PalmTree, error) {
-	rows, err := p.conn.QueryContext(ctx, query, args...)
+	var dbConn interface {
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	} = p.conn
+
+	// Reads within a transaction see, and lock, what it is about to write.
+	tx, _ := ctx.Value(p.manager.GetKey()).(*sql.Tx)
+	if tx != nil {
+		dbConn = tx
+	}
+
+	rows, err := dbConn.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +46,7 @@ func (p *palmTreeLocationRepositorySql) fetch(ctx context.Context, query string,
 	defer func() {
 		err = rows.Close()
 		if err != nil {
-			log.Error(err)
+			slog.ErrorContext(ctx, "close rows", "error", err)
 		}
 	}()
 
@@ -58,16 +73,144 @@ func (p *palmTreeLocationRepositorySql) fetch(ctx context.Context, query string,
 }
 
 func (p *palmTreeLocationRepositorySql) GetPalmTreesByUuid(ctx context.Context, id string) ([]domain.PalmTree, error) {
-	result, err := p.fetch(ctx, QueryGetByUuid, id)
+	ctx, span := tracing.StartQuery(ctx, "palm_tree", "GetPalmTreesByUuid", tracing.EstateId(id), tracing.Statement(QueryGetByUuid))
+	defer span.End()
+	defer metrics.ObserveQuery("palm_tree", "GetPalmTreesByUuid")()
+
+	result, err := p.fetch(ctx, QueryGetByUuid, domain.TenantId(ctx), id)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+// ListPalmTrees pages through the trees of an estate. The page after a tree
+// is found by comparing (sort column, id) with that tree's, so the cursor
+// stays a plain id whatever the sort.
+func (p *palmTreeLocationRepositorySql) ListPalmTrees(ctx context.Context, id string, filter *domain.PalmTreeFilter, sort string, after int64, limit int) ([]domain.ExportPalmTree, error) {
+	ctx, span := tracing.StartQuery(ctx, "palm_tree", "ListPalmTrees", tracing.EstateId(id))
+	defer span.End()
+	defer metrics.ObserveQuery("palm_tree", "ListPalmTrees")()
+
+	query, args, err := listPalmTreesQuery(domain.TenantId(ctx), id, filter, sort, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cursor is the id of a tree of this estate; any other id would page
+	// from an arbitrary position or, unmatched, end the listing silently.
+	if after > 0 {
+		var exists bool
+		err = p.conn.QueryRowContext(ctx, QueryPalmTreeExists, domain.TenantId(ctx), id, after).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, domain.ErrInvalidInput.WithDetails([]domain.FieldError{domain.InvalidCursor})
+		}
+	}
+
+	rows, err := p.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = rows.Close()
+		if err != nil {
+			slog.ErrorContext(ctx, "close rows", "error", err)
+		}
+	}()
+
+	result := []domain.ExportPalmTree{}
+	for rows.Next() {
+		palmTree := domain.ExportPalmTree{}
+
+		err = rows.Scan(
+			&palmTree.Id,
+			&palmTree.X,
+			&palmTree.Y,
+			&palmTree.Height,
+			&palmTree.PlantedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, palmTree)
+	}
+
+	return result, rows.Err()
+}
+
+func listPalmTreesQuery(organisationId, id string, filter *domain.PalmTreeFilter, sort string, after int64, limit int) (string, []interface{}, error) {
+	order := "ASC"
+	compare := ">"
+	if strings.HasPrefix(sort, "-") {
+		sort = sort[1:]
+		order = "DESC"
+		compare = "<"
+	}
+	column, ok := palmTreeSortColumns[sort]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown palm tree sort key %q", sort)
+	}
+
+	query := QueryListPalmTrees
+	args := []interface{}{organisationId, id}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		query += fmt.Sprintf("\n\t\tAND "+condition, len(args))
+	}
+
+	if filter.MinHeight != nil {
+		where("height >= $%d", *filter.MinHeight)
+	}
+	if filter.MaxHeight != nil {
+		where("height <= $%d", *filter.MaxHeight)
+	}
+	if filter.MinX != nil {
+		where("x >= $%d", *filter.MinX)
+	}
+	if filter.MaxX != nil {
+		where("x <= $%d", *filter.MaxX)
+	}
+	if filter.MinY != nil {
+		where("y >= $%d", *filter.MinY)
+	}
+	if filter.MaxY != nil {
+		where("y <= $%d", *filter.MaxY)
+	}
+	if filter.PlantedAfter != nil {
+		where("createdAt >= $%d", *filter.PlantedAfter)
+	}
+	if filter.PlantedBefore != nil {
+		where("createdAt < $%d", *filter.PlantedBefore)
+	}
+	if after > 0 {
+		if column == "id" {
+			where("id "+compare+" $%d", after)
+		} else {
+			where(fmt.Sprintf("(%[1]s, id) %[2]s (SELECT %[1]s, id FROM palmTreeLocation WHERE organisationId = $1 AND uuid = $2 AND id = $%%d)", column, compare), after)
+		}
+	}
+
+	orderBy := fmt.Sprintf("%s %s, id %s", column, order, order)
+	if column == "id" {
+		orderBy = "id " + order
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf("\n\tORDER BY\n\t\t%s\n\tLIMIT $%d", orderBy, len(args))
+	return query, args, nil
+}
+
 func (p *palmTreeLocationRepositorySql) PlantPalmTree(ctx context.Context, id string, param *domain.PalmTree) error {
+	ctx, span := tracing.StartQuery(ctx, "palm_tree", "PlantPalmTree", tracing.EstateId(id))
+	defer span.End()
+	defer metrics.ObserveQuery("palm_tree", "PlantPalmTree")()
+
 	var dbConn interface {
-		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+		QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 	} = p.conn
 
 	tx, _ := ctx.Value(p.manager.GetKey()).(*sql.Tx)
@@ -75,16 +218,151 @@ func (p *palmTreeLocationRepositorySql) PlantPalmTree(ctx context.Context, id st
 		dbConn = tx
 	}
 
-	_, err := dbConn.ExecContext(ctx, QueryPlantPalmTree,
+	err := dbConn.QueryRowContext(ctx, QueryPlantPalmTree,
+		domain.TenantId(ctx),
 		id,
 		param.X,
 		param.Y,
 		param.Height,
-		time.Now(),
-	)
+		helper.TenantNow(ctx),
+	).Scan(&param.Id)
+	return writeError(err)
+}
+
+// writeError turns a clash on the plot of a tree into
+// domain.ErrLocationFilled.
+func writeError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == plotIndex {
+		return domain.ErrLocationFilled.Wrap(err)
+	}
+	return err
+}
+
+// PlantPalmTrees inserts trees in batches of multi-row inserts within one
+// transaction, so a failing batch leaves no trees behind.
+func (p *palmTreeLocationRepositorySql) PlantPalmTrees(ctx context.Context, id string, trees []domain.PalmTree) error {
+	ctx, span := tracing.StartQuery(ctx, "palm_tree", "PlantPalmTrees", tracing.EstateId(id), tracing.TreeCount(len(trees)))
+	defer span.End()
+	defer metrics.ObserveQuery("palm_tree", "PlantPalmTrees")()
+
+	organisationId := domain.TenantId(ctx)
+	createdAt := helper.TenantNow(ctx)
+	return p.insertBatches(ctx, len(trees), func(i int) []interface{} {
+		return []interface{}{organisationId, id, trees[i].X, trees[i].Y, trees[i].Height, createdAt}
+	}, func(i int, treeId int64) {
+		trees[i].Id = treeId
+	})
+}
+
+func (p *palmTreeLocationRepositorySql) RestorePalmTrees(ctx context.Context, id string, trees []domain.ExportPalmTree) error {
+	ctx, span := tracing.StartQuery(ctx, "palm_tree", "RestorePalmTrees", tracing.EstateId(id), tracing.TreeCount(len(trees)))
+	defer span.End()
+	defer metrics.ObserveQuery("palm_tree", "RestorePalmTrees")()
+
+	organisationId := domain.TenantId(ctx)
+	return p.insertBatches(ctx, len(trees), func(i int) []interface{} {
+		return []interface{}{organisationId, id, trees[i].X, trees[i].Y, trees[i].Height, trees[i].PlantedAt}
+	}, func(i int, treeId int64) {
+		trees[i].Id = treeId
+	})
+}
+
+// insertBatches inserts n trees, taking the organisationId, uuid, x, y,
+// height and createdAt of the i-th one from args and passing its new id to
+// setId.
+func (p *palmTreeLocationRepositorySql) insertBatches(ctx context.Context, n int, args func(i int) []interface{}, setId func(i int, treeId int64)) error {
+	return p.manager.WithinTransaction(ctx, func(ctx context.Context) error {
+		tx := ctx.Value(p.manager.GetKey()).(*sql.Tx)
+
+		for start := 0; start < n; start += plantBatchSize {
+			end := start + plantBatchSize
+			if end > n {
+				end = n
+			}
+
+			values := make([]string, 0, end-start)
+			batch := make([]interface{}, 0, (end-start)*6)
+			for i := start; i < end; i++ {
+				k := len(batch)
+				values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", k+1, k+2, k+3, k+4, k+5, k+6))
+				batch = append(batch, args(i)...)
+			}
+
+			query := QueryPlantPalmTrees + "\n\t" + strings.Join(values, ",\n\t") + QueryPlantPalmTreesReturning
+			err := insertBatch(ctx, tx, query, batch, func(k int, treeId int64) {
+				setId(start+k, treeId)
+			})
+			if err != nil {
+				return writeError(err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func insertBatch(ctx context.Context, tx *sql.Tx, query string, args []interface{}, setId func(k int, treeId int64)) error {
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	defer func() {
+		closeErr := rows.Close()
+		if closeErr != nil {
+			slog.ErrorContext(ctx, "close rows", "error", closeErr)
+		}
+	}()
+
+	for k := 0; rows.Next(); k++ {
+		var treeId int64
+		err = rows.Scan(&treeId)
+		if err != nil {
+			return err
+		}
+		setId(k, treeId)
+	}
+
+	return rows.Err()
+}
+
+func (p *palmTreeLocationRepositorySql) GetOutOfBoundsPalmTrees(ctx context.Context) ([]domain.OutOfBoundsPalmTree, error) {
+	ctx, span := tracing.StartQuery(ctx, "palm_tree", "GetOutOfBoundsPalmTrees")
+	defer span.End()
+	defer metrics.ObserveQuery("palm_tree", "GetOutOfBoundsPalmTrees")()
+
+	rows, err := p.conn.QueryContext(ctx, QueryGetOutOfBounds, domain.TenantId(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = rows.Close()
+		if err != nil {
+			slog.ErrorContext(ctx, "close rows", "error", err)
+		}
+	}()
+
+	result := []domain.OutOfBoundsPalmTree{}
+	for rows.Next() {
+		palmTree := domain.OutOfBoundsPalmTree{}
+
+		err = rows.Scan(
+			&palmTree.Id,
+			&palmTree.Uuid,
+			&palmTree.X,
+			&palmTree.Y,
+			&palmTree.Height,
+			&palmTree.EstateLength,
+			&palmTree.EstateWidth,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, palmTree)
+	}
+
+	return result, nil
 }
@@ -0,0 +1,337 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+const utTenant = "acme"
+
+var tenantCtx = domain.WithTenant(context.Background(), &domain.Organisation{Id: utTenant})
+
+func init() {
+	err := helper.InitTime("Asia/Jakarta")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func TestGetOutOfBoundsPalmTrees(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := tenantCtx
+	repo := &palmTreeLocationRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	tests := []struct {
+		name       string
+		wantResult []domain.OutOfBoundsPalmTree
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name: "success",
+			wantResult: []domain.OutOfBoundsPalmTree{
+				{
+					PalmTree: domain.PalmTree{
+						Id:     1,
+						Uuid:   common.UtUuid,
+						X:      7,
+						Y:      1,
+						Height: 10,
+					},
+					EstateLength: 6,
+					EstateWidth:  3,
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "uuid", "x", "y", "height", "length", "width"}).
+					AddRow(1, common.UtUuid, 7, 1, 10, 6, 3)
+
+				mock.ExpectQuery("SELECT").WithArgs(utTenant).WillReturnRows(rows)
+			},
+		},
+		{
+			name:       "error",
+			wantResult: nil,
+			wantErr:    true,
+			mock: func() {
+				mock.ExpectQuery("SELECT").WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.GetOutOfBoundsPalmTrees(ctx)
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestPlantPalmTree(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &palmTreeLocationRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	mock.ExpectQuery("INSERT INTO palmTreeLocation").
+		WithArgs(utTenant, common.UtUuid, 2, 1, 10, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	tree := &domain.PalmTree{X: 2, Y: 1, Height: 10}
+	err = repo.PlantPalmTree(tenantCtx, common.UtUuid, tree)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, int64(7), tree.Id)
+
+	mock.ExpectQuery("INSERT INTO palmTreeLocation").
+		WithArgs(utTenant, common.UtUuid, 2, 1, 10, sqlmock.AnyArg()).
+		WillReturnError(&pq.Error{Code: uniqueViolation, Constraint: plotIndex})
+
+	err = repo.PlantPalmTree(tenantCtx, common.UtUuid, &domain.PalmTree{X: 2, Y: 1, Height: 10})
+	assert.ErrorIs(t, err, domain.ErrLocationFilled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlantPalmTrees(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := tenantCtx
+	repo := &palmTreeLocationRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	trees := make([]domain.PalmTree, plantBatchSize+1)
+	for i := range trees {
+		trees[i] = domain.PalmTree{X: i + 1, Y: 1, Height: 10}
+	}
+
+	firstBatch := sqlmock.NewRows([]string{"id"})
+	for i := 1; i <= plantBatchSize; i++ {
+		firstBatch.AddRow(i)
+	}
+
+	tests := []struct {
+		name    string
+		trees   []domain.PalmTree
+		wantErr bool
+		wantIds []int64
+		mock    func()
+	}{
+		{
+			name:    "success in batches",
+			trees:   trees,
+			wantErr: false,
+			wantIds: []int64{1, plantBatchSize, plantBatchSize + 1},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO palmTreeLocation").WillReturnRows(firstBatch)
+				mock.ExpectQuery("INSERT INTO palmTreeLocation").
+					WithArgs(utTenant, common.UtUuid, plantBatchSize+1, 1, 10, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(plantBatchSize + 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error rolls back",
+			trees:   trees[:2],
+			wantErr: true,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO palmTreeLocation").
+					WithArgs(utTenant, common.UtUuid, 1, 1, 10, sqlmock.AnyArg(), utTenant, common.UtUuid, 2, 1, 10, sqlmock.AnyArg()).
+					WillReturnError(errors.New(common.UtSomeError))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name:    "error begin",
+			trees:   trees[:1],
+			wantErr: true,
+			mock: func() {
+				mock.ExpectBegin().WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := repo.PlantPalmTrees(ctx, common.UtUuid, test.trees)
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.NoError(t, mock.ExpectationsWereMet())
+			if test.wantIds != nil {
+				n := len(test.trees)
+				assert.Equal(t, test.wantIds, []int64{test.trees[0].Id, test.trees[n-2].Id, test.trees[n-1].Id})
+			}
+		})
+	}
+}
+
+func TestRestorePalmTrees(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := tenantCtx
+	repo := &palmTreeLocationRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+	plantedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO palmTreeLocation").
+		WithArgs(utTenant, common.UtUuid, 2, 1, 10, plantedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectCommit()
+
+	trees := []domain.ExportPalmTree{
+		{Id: 7, X: 2, Y: 1, Height: 10, PlantedAt: plantedAt},
+	}
+	err = repo.RestorePalmTrees(ctx, common.UtUuid, trees)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, int64(12), trees[0].Id)
+}
+
+func TestListPalmTrees(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := tenantCtx
+	repo := &palmTreeLocationRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	plantedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	minHeight, maxX := 5, 10
+
+	type args struct {
+		filter *domain.PalmTreeFilter
+		sort   string
+		after  int64
+		limit  int
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult []domain.ExportPalmTree
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name: "success first page by id",
+			args: args{filter: &domain.PalmTreeFilter{}, sort: "id", limit: 2},
+			wantResult: []domain.ExportPalmTree{
+				{Id: 1, X: 1, Y: 1, Height: 10, PlantedAt: plantedAt},
+			},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "x", "y", "height", "createdAt"}).
+					AddRow(1, 1, 1, 10, plantedAt)
+				mock.ExpectQuery(QueryListPalmTrees+"\n\tORDER BY\n\t\tid ASC\n\tLIMIT $3").
+					WithArgs(utTenant, common.UtUuid, 2).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name: "success filtered page after cursor sorted by height descending",
+			args: args{
+				filter: &domain.PalmTreeFilter{MinHeight: &minHeight, MaxX: &maxX, PlantedBefore: &plantedAt},
+				sort:   "-height",
+				after:  7,
+				limit:  2,
+			},
+			wantResult: []domain.ExportPalmTree{
+				{Id: 3, X: 2, Y: 1, Height: 8, PlantedAt: plantedAt},
+			},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "x", "y", "height", "createdAt"}).
+					AddRow(3, 2, 1, 8, plantedAt)
+				mock.ExpectQuery(QueryPalmTreeExists).
+					WithArgs(utTenant, common.UtUuid, int64(7)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(QueryListPalmTrees+
+					"\n\t\tAND height >= $3"+
+					"\n\t\tAND x <= $4"+
+					"\n\t\tAND createdAt < $5"+
+					"\n\t\tAND (height, id) < (SELECT height, id FROM palmTreeLocation WHERE organisationId = $1 AND uuid = $2 AND id = $6)"+
+					"\n\tORDER BY\n\t\theight DESC, id DESC\n\tLIMIT $7").
+					WithArgs(utTenant, common.UtUuid, 5, 10, plantedAt, int64(7), 2).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name:    "error cursor of another estate",
+			args:    args{filter: &domain.PalmTreeFilter{}, sort: "height", after: 7, limit: 2},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(QueryPalmTreeExists).
+					WithArgs(utTenant, common.UtUuid, int64(7)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+		},
+		{
+			name:    "error unknown sort key",
+			args:    args{filter: &domain.PalmTreeFilter{}, sort: "age", limit: 2},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name:    "error",
+			args:    args{filter: &domain.PalmTreeFilter{}, sort: "id", limit: 2},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(QueryListPalmTrees + "\n\tORDER BY\n\t\tid ASC\n\tLIMIT $3").
+					WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.ListPalmTrees(ctx, common.UtUuid, test.args.filter, test.args.sort, test.args.after, test.args.limit)
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
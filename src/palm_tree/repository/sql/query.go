@@ -12,9 +12,83 @@ const (
 
 	QueryGetByUuid = SelectTemplate + `
 	WHERE
-		uuid = $1`
+		organisationId = $1
+		AND uuid = $2`
+
+	QueryListPalmTrees = `SELECT
+		id,
+		x,
+		y,
+		height,
+		createdAt
+	FROM
+		palmTreeLocation
+	WHERE
+		organisationId = $1
+		AND uuid = $2`
+
+	// QueryPalmTreeExists tells whether a tree belongs to an estate.
+	QueryPalmTreeExists = `SELECT EXISTS (
+		SELECT
+			1
+		FROM
+			palmTreeLocation
+		WHERE
+			organisationId = $1
+			AND uuid = $2
+			AND id = $3
+	)`
+
+	QueryGetOutOfBounds = `SELECT
+		p.id,
+		p.uuid,
+		p.x,
+		p.y,
+		p.height,
+		e.length,
+		e.width
+	FROM
+		palmTreeLocation p
+		JOIN estate e ON e.uuid = p.uuid
+	WHERE
+		p.organisationId = $1
+		AND (p.x < 1 OR p.y < 1 OR p.x > e.length OR p.y > e.width)
+	ORDER BY
+		p.id`
 
 	QueryPlantPalmTree = `INSERT INTO palmTreeLocation
-	(uuid, x, y, height, createdAt)
-	VALUES($1, $2, $3, $4, $5)`
+	(organisationId, uuid, x, y, height, createdAt)
+	VALUES($1, $2, $3, $4, $5, $6)
+	RETURNING
+		id`
+
+	QueryPlantPalmTrees = `INSERT INTO palmTreeLocation
+	(organisationId, uuid, x, y, height, createdAt)
+	VALUES`
+
+	// QueryPlantPalmTreesReturning follows the VALUES of QueryPlantPalmTrees.
+	// PostgreSQL returns the ids in the order of the rows inserted.
+	QueryPlantPalmTreesReturning = `
+	RETURNING
+		id`
 )
+
+// palmTreeSortColumns maps the sort keys of a tree listing to columns.
+var palmTreeSortColumns = map[string]string{
+	"id":        "id",
+	"height":    "height",
+	"x":         "x",
+	"y":         "y",
+	"plantedAt": "createdAt",
+}
+
+// plotIndex is the unique index on the plot of a tree, as PostgreSQL names
+// it in constraint violations.
+const plotIndex = "palmtreelocation_uuid_x_y_idx"
+
+// uniqueViolation is the SQLSTATE of a unique constraint violation.
+const uniqueViolation = "23505"
+
+// plantBatchSize keeps a multi-row insert well below the 65535 bind
+// parameters PostgreSQL accepts per statement.
+const plantBatchSize = 1000
@@ -0,0 +1,141 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/davidyunus/sawitpro-estate/src/metrics"
+	"github.com/davidyunus/sawitpro-estate/src/tracing"
+)
+
+type permissionRepositorySql struct {
+	conn    *sql.DB
+	manager *helper.Manager
+}
+
+func NewPermissionRepositorySql(conn *sql.DB, manager *helper.Manager) domain.EstatePermissionRepository {
+	return &permissionRepositorySql{
+		conn:    conn,
+		manager: manager,
+	}
+}
+
+// querier is a *sql.DB or *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// db returns the transaction ctx carries, so a grant commits or rolls back
+// with the estate it belongs to.
+func (p *permissionRepositorySql) db(ctx context.Context) querier {
+	tx, _ := ctx.Value(p.manager.GetKey()).(*sql.Tx)
+	if tx != nil {
+		return tx
+	}
+	return p.conn
+}
+
+func (p *permissionRepositorySql) GetEstateRole(ctx context.Context, id, subject string) (string, error) {
+	ctx, span := tracing.StartQuery(ctx, "permission", "GetEstateRole", tracing.EstateId(id))
+	defer span.End()
+	defer metrics.ObserveQuery("permission", "GetEstateRole")()
+
+	var role string
+	err := p.db(ctx).QueryRowContext(ctx, QueryGetEstateRole, id, subject).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return role, nil
+}
+
+func (p *permissionRepositorySql) ListEstatePermissions(ctx context.Context, id string) ([]domain.EstatePermission, error) {
+	ctx, span := tracing.StartQuery(ctx, "permission", "ListEstatePermissions", tracing.EstateId(id), tracing.Statement(QueryListEstatePermissions))
+	defer span.End()
+	defer metrics.ObserveQuery("permission", "ListEstatePermissions")()
+
+	return p.fetch(ctx, QueryListEstatePermissions, id)
+}
+
+func (p *permissionRepositorySql) ListSubjectPermissions(ctx context.Context, subject string) ([]domain.EstatePermission, error) {
+	ctx, span := tracing.StartQuery(ctx, "permission", "ListSubjectPermissions", tracing.Statement(QueryListSubjectPermissions))
+	defer span.End()
+	defer metrics.ObserveQuery("permission", "ListSubjectPermissions")()
+
+	return p.fetch(ctx, QueryListSubjectPermissions, subject)
+}
+
+func (p *permissionRepositorySql) fetch(ctx context.Context, query string, args ...interface{}) ([]domain.EstatePermission, error) {
+	rows, err := p.db(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = rows.Close()
+		if err != nil {
+			slog.ErrorContext(ctx, "close rows", "error", err)
+		}
+	}()
+
+	result := []domain.EstatePermission{}
+	for rows.Next() {
+		permission := domain.EstatePermission{}
+		err = rows.Scan(
+			&permission.EstateId,
+			&permission.Subject,
+			&permission.Role,
+			&permission.GrantedBy,
+			&permission.GrantedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, permission)
+	}
+
+	return result, rows.Err()
+}
+
+func (p *permissionRepositorySql) UpsertEstatePermission(ctx context.Context, param *domain.EstatePermission) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "permission", "UpsertEstatePermission")
+	defer span.End()
+	defer metrics.ObserveQuery("permission", "UpsertEstatePermission")()
+
+	var created bool
+	err := p.db(ctx).QueryRowContext(ctx, QueryUpsertEstatePermission,
+		param.EstateId,
+		param.Subject,
+		param.Role,
+		param.GrantedBy,
+		param.GrantedAt,
+	).Scan(&created)
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+func (p *permissionRepositorySql) DeleteEstatePermission(ctx context.Context, id, subject string) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "permission", "DeleteEstatePermission", tracing.EstateId(id), tracing.Statement(QueryDeleteEstatePermission))
+	defer span.End()
+	defer metrics.ObserveQuery("permission", "DeleteEstatePermission")()
+
+	res, err := p.db(ctx).ExecContext(ctx, QueryDeleteEstatePermission, id, subject)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
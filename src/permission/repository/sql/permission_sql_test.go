@@ -0,0 +1,287 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/davidyunus/sawitpro-estate/src/common"
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/davidyunus/sawitpro-estate/src/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []string{"estateUuid", "subject", "role", "grantedBy", "grantedAt"}
+
+func TestNewPermissionRepositorySql(t *testing.T) {
+	assert.NotNil(t, NewPermissionRepositorySql(nil, nil))
+}
+
+func TestGetEstateRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &permissionRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	tests := []struct {
+		name       string
+		wantResult string
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:       "success",
+			wantResult: domain.RoleSurveyor,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetEstateRole)).
+					WithArgs(common.UtUuid, "alice").
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleSurveyor))
+			},
+		},
+		{
+			name: "success no role",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetEstateRole)).
+					WithArgs(common.UtUuid, "alice").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryGetEstateRole)).
+					WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.GetEstateRole(context.Background(), common.UtUuid, "alice")
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListPermissions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &permissionRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	permission := domain.EstatePermission{EstateId: common.UtUuid, Subject: "alice", Role: domain.RoleViewer, GrantedBy: "bob", GrantedAt: now}
+
+	tests := []struct {
+		name       string
+		call       func() ([]domain.EstatePermission, error)
+		wantResult []domain.EstatePermission
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name: "success by estate",
+			call: func() ([]domain.EstatePermission, error) {
+				return repo.ListEstatePermissions(context.Background(), common.UtUuid)
+			},
+			wantResult: []domain.EstatePermission{permission},
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryListEstatePermissions)).
+					WithArgs(common.UtUuid).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(common.UtUuid, "alice", domain.RoleViewer, "bob", now))
+			},
+		},
+		{
+			name: "success by subject",
+			call: func() ([]domain.EstatePermission, error) {
+				return repo.ListSubjectPermissions(context.Background(), "alice")
+			},
+			wantResult: []domain.EstatePermission{},
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryListSubjectPermissions)).
+					WithArgs("alice").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			name: "error query",
+			call: func() ([]domain.EstatePermission, error) {
+				return repo.ListEstatePermissions(context.Background(), common.UtUuid)
+			},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryListEstatePermissions)).
+					WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+		{
+			name: "error scan",
+			call: func() ([]domain.EstatePermission, error) {
+				return repo.ListEstatePermissions(context.Background(), common.UtUuid)
+			},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryListEstatePermissions)).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(common.UtUuid, "alice", domain.RoleViewer, "bob", "not a time"))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := test.call()
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpsertEstatePermission(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	manager := helper.NewManager(db, common.TransactionContextKey)
+	repo := &permissionRepositorySql{
+		conn:    db,
+		manager: manager,
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	permission := &domain.EstatePermission{EstateId: common.UtUuid, Subject: "alice", Role: domain.RoleAdmin, GrantedBy: "bob", GrantedAt: now}
+
+	tests := []struct {
+		name       string
+		inTx       bool
+		wantResult bool
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:       "success created",
+			wantResult: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryUpsertEstatePermission)).
+					WithArgs(common.UtUuid, "alice", domain.RoleAdmin, "bob", now).
+					WillReturnRows(sqlmock.NewRows([]string{"created"}).AddRow(true))
+			},
+		},
+		{
+			name: "success replaced in transaction",
+			inTx: true,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(QueryUpsertEstatePermission)).
+					WithArgs(common.UtUuid, "alice", domain.RoleAdmin, "bob", now).
+					WillReturnRows(sqlmock.NewRows([]string{"created"}).AddRow(false))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(QueryUpsertEstatePermission)).
+					WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			var got bool
+			if test.inTx {
+				err = manager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+					got, err = repo.UpsertEstatePermission(ctx, permission)
+					return err
+				})
+			} else {
+				got, err = repo.UpsertEstatePermission(context.Background(), permission)
+			}
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteEstatePermission(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &permissionRepositorySql{
+		conn:    db,
+		manager: helper.NewManager(db, common.TransactionContextKey),
+	}
+
+	tests := []struct {
+		name       string
+		wantResult bool
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:       "success",
+			wantResult: true,
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QueryDeleteEstatePermission)).
+					WithArgs(common.UtUuid, "alice").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "success nothing deleted",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QueryDeleteEstatePermission)).
+					WithArgs(common.UtUuid, "alice").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(QueryDeleteEstatePermission)).
+					WillReturnError(errors.New(common.UtSomeError))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := repo.DeleteEstatePermission(context.Background(), common.UtUuid, "alice")
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.wantResult, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
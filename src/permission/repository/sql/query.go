@@ -0,0 +1,49 @@
+package sql
+
+const (
+	QueryGetEstateRole = `SELECT
+		role
+	FROM
+		estatePermission
+	WHERE
+		estateUuid = $1
+		AND subject = $2`
+
+	SelectTemplate = `SELECT
+		estateUuid,
+		subject,
+		role,
+		grantedBy,
+		grantedAt
+	FROM
+		estatePermission`
+
+	QueryListEstatePermissions = SelectTemplate + `
+	WHERE
+		estateUuid = $1
+	ORDER BY
+		subject`
+
+	QueryListSubjectPermissions = SelectTemplate + `
+	WHERE
+		subject = $1
+	ORDER BY
+		estateUuid`
+
+	// QueryUpsertEstatePermission reports through xmax whether the row was
+	// inserted rather than updated.
+	QueryUpsertEstatePermission = `INSERT INTO estatePermission
+	(estateUuid, subject, role, grantedBy, grantedAt)
+	VALUES($1, $2, $3, $4, $5)
+	ON CONFLICT (estateUuid, subject) DO UPDATE SET
+		role = EXCLUDED.role,
+		grantedBy = EXCLUDED.grantedBy,
+		grantedAt = EXCLUDED.grantedAt
+	RETURNING
+		xmax = 0`
+
+	QueryDeleteEstatePermission = `DELETE FROM estatePermission
+	WHERE
+		estateUuid = $1
+		AND subject = $2`
+)
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+)
+
+// sweepInterval is how often buckets that have refilled are dropped, so
+// callers that went away do not keep their bucket forever.
+const sweepInterval = time.Minute
+
+type (
+	rateLimitRepositoryMemory struct {
+		mu        sync.Mutex
+		buckets   map[string]*bucket
+		lastSweep time.Time
+	}
+
+	bucket struct {
+		tokens  float64
+		updated time.Time
+		limit   domain.RateLimit
+	}
+)
+
+func NewRateLimitRepositoryMemory() domain.RateLimitRepository {
+	return &rateLimitRepositoryMemory{
+		buckets: map[string]*bucket{},
+	}
+}
+
+func (r *rateLimitRepositoryMemory) TakeRateLimitToken(_ context.Context, key string, limit domain.RateLimit, now time.Time) (*domain.RateLimitResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Sub(r.lastSweep) >= sweepInterval {
+		r.sweep(now)
+	}
+
+	b, ok := r.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		r.buckets[key] = b
+	}
+	b.limit = limit
+	b.refill(now)
+
+	result := &domain.RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return result, nil
+}
+
+// sweep drops the buckets that are full by now, which are the same as the
+// fresh bucket a new request would get.
+func (r *rateLimitRepositoryMemory) sweep(now time.Time) {
+	for key, b := range r.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(r.buckets, key)
+		}
+	}
+	r.lastSweep = now
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.updated = now
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/davidyunus/sawitpro-estate/src/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRateLimitRepositoryMemory(t *testing.T) {
+	assert.NotNil(t, NewRateLimitRepositoryMemory())
+}
+
+func TestTakeRateLimitToken(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	limit := domain.RateLimit{Rate: 2, Burst: 3}
+
+	tests := []struct {
+		name       string
+		key        string
+		at         time.Duration
+		wantResult *domain.RateLimitResult
+	}{
+		{
+			name:       "success new bucket starts full",
+			key:        "a",
+			wantResult: &domain.RateLimitResult{Allowed: true, Remaining: 2, Reset: 500 * time.Millisecond},
+		},
+		{
+			name:       "success burst",
+			key:        "a",
+			wantResult: &domain.RateLimitResult{Allowed: true, Remaining: 1, Reset: time.Second},
+		},
+		{
+			name:       "success last token",
+			key:        "a",
+			wantResult: &domain.RateLimitResult{Allowed: true, Remaining: 0, Reset: 1500 * time.Millisecond},
+		},
+		{
+			name:       "denied empty bucket",
+			key:        "a",
+			wantResult: &domain.RateLimitResult{Remaining: 0, RetryAfter: 500 * time.Millisecond, Reset: 1500 * time.Millisecond},
+		},
+		{
+			name:       "success other key",
+			key:        "b",
+			wantResult: &domain.RateLimitResult{Allowed: true, Remaining: 2, Reset: 500 * time.Millisecond},
+		},
+		{
+			name:       "success refilled",
+			key:        "a",
+			at:         time.Second,
+			wantResult: &domain.RateLimitResult{Allowed: true, Remaining: 1, Reset: time.Second},
+		},
+		{
+			name:       "success refill stops at burst",
+			key:        "a",
+			at:         time.Hour,
+			wantResult: &domain.RateLimitResult{Allowed: true, Remaining: 2, Reset: 500 * time.Millisecond},
+		},
+	}
+
+	repo := NewRateLimitRepositoryMemory()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := repo.TakeRateLimitToken(ctx, test.key, limit, now.Add(test.at))
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantResult, got)
+		})
+	}
+}
+
+func TestSweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	limit := domain.RateLimit{Rate: 1, Burst: 1}
+
+	repo := NewRateLimitRepositoryMemory().(*rateLimitRepositoryMemory)
+	_, err := repo.TakeRateLimitToken(ctx, "idle", limit, now)
+	assert.NoError(t, err)
+	_, err = repo.TakeRateLimitToken(ctx, "busy", domain.RateLimit{Rate: 0.001, Burst: 1}, now)
+	assert.NoError(t, err)
+
+	_, err = repo.TakeRateLimitToken(ctx, "busy", domain.RateLimit{Rate: 0.001, Burst: 1}, now.Add(sweepInterval))
+	assert.NoError(t, err)
+	assert.NotContains(t, repo.buckets, "idle")
+	assert.Contains(t, repo.buckets, "busy")
+}
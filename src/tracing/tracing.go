@@ -0,0 +1,158 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ServiceName = "sawitpro-estate"
+
+	instrumentation = "github.com/davidyunus/sawitpro-estate"
+)
+
+// Exporters spans can be sent to. ExporterFile writes the same JSON lines
+// as ExporterStdout to a file, to look at traces without a collector.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+var Exporters = []string{ExporterNone, ExporterOTLP, ExporterStdout, ExporterFile}
+
+// Options configures Setup. Endpoint is the OTLP/HTTP collector URL, such
+// as http://localhost:4318; when empty the OTEL_EXPORTER_OTLP_* variables
+// apply. File is the path ExporterFile appends to.
+type Options struct {
+	Exporter    string
+	Endpoint    string
+	File        string
+	SampleRatio float64
+}
+
+// Setup installs the W3C trace context propagator and a tracer provider
+// sending spans to the configured exporter. Root spans are sampled at
+// SampleRatio; spans with a parent follow its decision. With
+// ExporterNone, spans are neither recorded nor exported, but incoming trace
+// context is still passed on. The returned function flushes the spans not
+// yet exported and must be called before exiting.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+		err      error
+	)
+	switch opts.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if opts.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		var f *os.File
+		f, err = os.OpenFile(opts.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("tracing: open %s: %w", opts.File, err)
+		}
+		closer = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing: create %s exporter: %w", opts.Exporter, err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("tracing: build resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+// Start starts a span named name, a child of the span in ctx if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartServer starts the span of a request served, a child of the span
+// propagated by the caller if any.
+func StartServer(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// StartQuery starts the span of a repository method, named after the
+// repository and the method, such as estate.GetEstateByUuid.
+func StartQuery(ctx context.Context, repository, query string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(query),
+		attribute.String("db.repository", repository),
+	)
+	return otel.Tracer(instrumentation).Start(ctx, repository+"."+query,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// EstateId is the attribute naming the estate a span works on.
+func EstateId(id string) attribute.KeyValue {
+	return attribute.String("estate.id", id)
+}
+
+// TreeCount is the attribute counting the palm trees a span works on.
+func TreeCount(n int) attribute.KeyValue {
+	return attribute.Int("tree.count", n)
+}
+
+// Statement is the attribute holding the SQL a repository method runs, for
+// methods that run a single statement.
+func Statement(query string) attribute.KeyValue {
+	return semconv.DBQueryText(query)
+}
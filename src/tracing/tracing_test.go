@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		opts     Options
+		wantSpan bool
+		wantErr  bool
+	}{
+		{
+			name: "success none",
+			opts: Options{Exporter: ExporterNone},
+		},
+		{
+			name:     "success file",
+			opts:     Options{Exporter: ExporterFile, File: filepath.Join(dir, "spans.json"), SampleRatio: 1},
+			wantSpan: true,
+		},
+		{
+			name: "success file not sampled",
+			opts: Options{Exporter: ExporterFile, File: filepath.Join(dir, "unsampled.json"), SampleRatio: 0},
+		},
+		{
+			name:    "error file directory missing",
+			opts:    Options{Exporter: ExporterFile, File: filepath.Join(dir, "missing", "spans.json")},
+			wantErr: true,
+		},
+		{
+			name:    "error unknown exporter",
+			opts:    Options{Exporter: "jaeger"},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defaultProvider := otel.GetTracerProvider()
+			defer otel.SetTracerProvider(defaultProvider)
+
+			shutdown, err := Setup(context.Background(), test.opts)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			_, span := StartQuery(context.Background(), "estate", "GetEstateByUuid", EstateId("uuid"), TreeCount(2))
+			span.End()
+			assert.NoError(t, shutdown(context.Background()))
+
+			if test.opts.File == "" {
+				return
+			}
+			b, err := os.ReadFile(test.opts.File)
+			assert.NoError(t, err)
+			if !test.wantSpan {
+				assert.Empty(t, b)
+				return
+			}
+			assert.Contains(t, string(b), `"Name":"estate.GetEstateByUuid"`)
+			assert.Contains(t, string(b), `"Key":"estate.id"`)
+			assert.Contains(t, string(b), `"Key":"tree.count"`)
+			assert.Contains(t, string(b), `"Value":"sawitpro-estate"`)
+		})
+	}
+}